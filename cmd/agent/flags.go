@@ -2,8 +2,15 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/validators"
 )
 
 var (
@@ -12,36 +19,141 @@ var (
 	reportInterval int
 	numWorkers     int
 	logLevel       string
+	key            string
+	retryIntervals string
+	labels         string
+	transport      string
+	cryptoKey      string
+	adminAddress   string
+	configPath     string
 )
 
-func parseFlags() {
+// configFields maps the fields of the JSON configuration file to the flags they set.
+var configFields = map[string]string{
+	"address":         "a",
+	"poll_interval":   "p",
+	"report_interval": "r",
+	"num_workers":     "workers",
+	"log_level":       "l",
+	"key":             "k",
+	"retry_intervals": "retry-intervals",
+	"labels":          "labels",
+	"transport":       "transport",
+	"crypto_key":      "crypto-key",
+	"admin_address":   "admin-address",
+}
+
+func parseFlags() error {
 	flag.StringVar(&serverAddr, "a", "localhost:8080", "server address")
 	flag.IntVar(&pollInterval, "p", 2, "polling interval in seconds")
 	flag.IntVar(&reportInterval, "r", 10, "reporting interval in seconds")
 	flag.IntVar(&numWorkers, "workers", 4, "number of workers")
 	flag.StringVar(&logLevel, "l", "info", "log level")
+	flag.StringVar(&key, "k", "", "shared key for HMAC-SHA256 signing")
+	flag.StringVar(&retryIntervals, "retry-intervals", "1s,3s,5s", "comma-separated delays between retries of failed reports")
+	flag.StringVar(&labels, "labels", "", "comma-separated key=value labels attached to every metric, e.g. env=prod,service=api")
+	flag.StringVar(&transport, "transport", "http", "transport to report metrics over, http or grpc; with grpc, -a is the server's gRPC address")
+	flag.StringVar(&cryptoKey, "crypto-key", "", "path to the server's PEM-encoded RSA public key to encrypt reports with")
+
+	flag.StringVar(&adminAddress, "admin-address", "", "loopback address to serve the admin endpoints on, e.g. localhost:8081; empty disables them")
+	flag.StringVar(&configPath, "c", "", "path to the JSON configuration file")
+	flag.StringVar(&configPath, "config", "", "path to the JSON configuration file (same as -c)")
 
 	flag.Parse()
 
-	if env := os.Getenv("ADDRESS"); env != "" {
+	// Flags given on the command line take precedence over the environment and the configuration file
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if env := os.Getenv("CONFIG"); env != "" && !set["c"] && !set["config"] {
+		configPath = env
+	}
+	if configPath != "" {
+		if err := configs.ApplyFile(configPath, flag.CommandLine, configFields, set); err != nil {
+			return err
+		}
+	}
+
+	if env := os.Getenv("ADDRESS"); env != "" && !set["a"] {
 		serverAddr = env
 	}
-	if env := os.Getenv("POLL_INTERVAL"); env != "" {
+	if env := os.Getenv("POLL_INTERVAL"); env != "" && !set["p"] {
 		if v, err := strconv.Atoi(env); err == nil {
 			pollInterval = v
 		}
 	}
-	if env := os.Getenv("REPORT_INTERVAL"); env != "" {
+	if env := os.Getenv("REPORT_INTERVAL"); env != "" && !set["r"] {
 		if v, err := strconv.Atoi(env); err == nil {
 			reportInterval = v
 		}
 	}
-	if env := os.Getenv("NUM_WORKERS"); env != "" {
+	if env := os.Getenv("NUM_WORKERS"); env != "" && !set["workers"] {
 		if v, err := strconv.Atoi(env); err == nil {
 			numWorkers = v
 		}
 	}
-	if env := os.Getenv("LOG_LEVEL"); env != "" {
+	if env := os.Getenv("LOG_LEVEL"); env != "" && !set["l"] {
 		logLevel = env
 	}
+	if env := os.Getenv("KEY"); env != "" && !set["k"] {
+		key = env
+	}
+	// An empty RETRY_INTERVALS is honored and disables retries
+	if env, ok := os.LookupEnv("RETRY_INTERVALS"); ok && !set["retry-intervals"] {
+		retryIntervals = env
+	}
+	if env := os.Getenv("LABELS"); env != "" && !set["labels"] {
+		labels = env
+	}
+	if env := os.Getenv("TRANSPORT"); env != "" && !set["transport"] {
+		transport = env
+	}
+	if env := os.Getenv("CRYPTO_KEY"); env != "" && !set["crypto-key"] {
+		cryptoKey = env
+	}
+	if env := os.Getenv("ADMIN_ADDRESS"); env != "" && !set["admin-address"] {
+		adminAddress = env
+	}
+
+	return nil
+}
+
+// parseDurations parses a comma-separated list of durations such as "1s,3s,5s".
+// An empty string yields no durations.
+func parseDurations(s string) ([]time.Duration, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var durations []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry interval %q: %w", part, err)
+		}
+		durations = append(durations, d)
+	}
+	return durations, nil
+}
+
+// parseLabels parses a comma-separated list of key=value labels such as "env=prod,service=api"
+// and adds the host label with the machine's hostname, unless it is set explicitly.
+func parseLabels(s string) (types.Labels, error) {
+	l, ok := types.ParseLabels(s)
+	if !ok {
+		return "", fmt.Errorf("invalid labels %q: expected comma-separated key=value pairs", s)
+	}
+
+	if _, ok := l.Map()["host"]; !ok {
+		if hostname, err := os.Hostname(); err == nil && hostname != "" {
+			l = l.With(map[string]string{"host": hostname})
+		}
+	}
+
+	if err := validators.ValidateMetricLabels(l); err != nil {
+		return "", fmt.Errorf("invalid labels %q: %w", s, err)
+	}
+	return l, nil
 }
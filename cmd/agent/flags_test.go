@@ -3,34 +3,55 @@ package main
 import (
 	"flag"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
 func resetFlags() {
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 }
 
-func TestParseFlags_EnvOverridesFlags(t *testing.T) {
+func TestParseFlags(t *testing.T) {
 	tests := []struct {
-		name         string
-		env          map[string]string
-		args         []string
-		wantAddr     string
-		wantPoll     int
-		wantReport   int
-		wantWorkers  int
-		wantLogLevel string
+		name          string
+		config        string // contents of the config file, referred to as {config} in env and args
+		env           map[string]string
+		args          []string
+		wantErr       []string // fields reported as invalid
+		wantAddr      string
+		wantPoll      int
+		wantReport    int
+		wantWorkers   int
+		wantLogLevel  string
+		wantKey       string
+		wantRetry     string
+		wantLabels    string
+		wantTransport string
+		wantCrypto    string
+		wantAdmin     string
 	}{
 		{
-			name: "env overrides flags",
+			name: "flags override env",
 			env: map[string]string{
 				"ADDRESS":         "envhost:9090",
 				"POLL_INTERVAL":   "5",
 				"REPORT_INTERVAL": "20",
 				"NUM_WORKERS":     "8",
 				"LOG_LEVEL":       "debug",
+				"KEY":             "envkey",
+				"RETRY_INTERVALS": "2s,4s",
+				"LABELS":          "env=prod",
+				"TRANSPORT":       "grpc",
+				"CRYPTO_KEY":      "/tmp/env.pem",
+				"ADMIN_ADDRESS":   "localhost:9091",
 			},
 			args: []string{"cmd",
 				"-a", "flaghost:7070",
@@ -38,12 +59,24 @@ func TestParseFlags_EnvOverridesFlags(t *testing.T) {
 				"-r", "25",
 				"-workers", "16",
 				"-l", "warn",
+				"-k", "flagkey",
+				"-retry-intervals", "1s",
+				"-labels", "env=dev",
+				"-transport", "http",
+				"-crypto-key", "/tmp/flag.pem",
+				"-admin-address", "localhost:8081",
 			},
-			wantAddr:     "envhost:9090",
-			wantPoll:     5,
-			wantReport:   20,
-			wantWorkers:  8,
-			wantLogLevel: "debug",
+			wantAddr:      "flaghost:7070",
+			wantPoll:      15,
+			wantReport:    25,
+			wantWorkers:   16,
+			wantLogLevel:  "warn",
+			wantKey:       "flagkey",
+			wantRetry:     "1s",
+			wantLabels:    "env=dev",
+			wantTransport: "http",
+			wantCrypto:    "/tmp/flag.pem",
+			wantAdmin:     "localhost:8081",
 		},
 		{
 			name: "flags only",
@@ -54,12 +87,24 @@ func TestParseFlags_EnvOverridesFlags(t *testing.T) {
 				"-r", "25",
 				"-workers", "16",
 				"-l", "warn",
+				"-k", "flagkey",
+				"-retry-intervals", "1s",
+				"-labels", "env=dev",
+				"-transport", "grpc",
+				"-crypto-key", "/tmp/flag.pem",
+				"-admin-address", "localhost:8081",
 			},
-			wantAddr:     "flaghost:7070",
-			wantPoll:     15,
-			wantReport:   25,
-			wantWorkers:  16,
-			wantLogLevel: "warn",
+			wantAddr:      "flaghost:7070",
+			wantPoll:      15,
+			wantReport:    25,
+			wantWorkers:   16,
+			wantLogLevel:  "warn",
+			wantKey:       "flagkey",
+			wantRetry:     "1s",
+			wantLabels:    "env=dev",
+			wantTransport: "grpc",
+			wantCrypto:    "/tmp/flag.pem",
+			wantAdmin:     "localhost:8081",
 		},
 		{
 			name: "env only",
@@ -69,13 +114,72 @@ func TestParseFlags_EnvOverridesFlags(t *testing.T) {
 				"REPORT_INTERVAL": "20",
 				"NUM_WORKERS":     "8",
 				"LOG_LEVEL":       "debug",
+				"KEY":             "envkey",
+				"RETRY_INTERVALS": "",
+				"ADMIN_ADDRESS":   "localhost:9091",
 			},
-			args:         []string{"cmd"},
-			wantAddr:     "envhost:9090",
-			wantPoll:     5,
-			wantReport:   20,
-			wantWorkers:  8,
-			wantLogLevel: "debug",
+			args:          []string{"cmd"},
+			wantAddr:      "envhost:9090",
+			wantPoll:      5,
+			wantReport:    20,
+			wantWorkers:   8,
+			wantLogLevel:  "debug",
+			wantKey:       "envkey",
+			wantRetry:     "",
+			wantTransport: "http",
+			wantAdmin:     "localhost:9091",
+		},
+		{
+			name: "config file only",
+			config: `{
+				"address": "filehost:6060",
+				"poll_interval": 1,
+				"report_interval": 30,
+				"num_workers": 2,
+				"log_level": "error",
+				"key": "filekey",
+				"retry_intervals": ["500ms", "1s"],
+				"labels": ["env=stage", "dc=eu"],
+				"transport": "grpc",
+				"crypto_key": "/tmp/file.pem",
+				"admin_address": "127.0.0.1:8081"
+			}`,
+			env:           map[string]string{"CONFIG": "{config}"},
+			args:          []string{"cmd"},
+			wantAddr:      "filehost:6060",
+			wantPoll:      1,
+			wantReport:    30,
+			wantWorkers:   2,
+			wantLogLevel:  "error",
+			wantKey:       "filekey",
+			wantRetry:     "500ms,1s",
+			wantLabels:    "env=stage,dc=eu",
+			wantTransport: "grpc",
+			wantCrypto:    "/tmp/file.pem",
+			wantAdmin:     "127.0.0.1:8081",
+		},
+		{
+			name:   "flags override env override config file",
+			config: `{"address": "filehost:6060", "poll_interval": 1, "report_interval": 30}`,
+			env: map[string]string{
+				"ADDRESS":       "envhost:9090",
+				"POLL_INTERVAL": "5",
+			},
+			args:          []string{"cmd", "-c", "{config}", "-a", "flaghost:7070"},
+			wantAddr:      "flaghost:7070",
+			wantPoll:      5,
+			wantReport:    30,
+			wantWorkers:   4,
+			wantLogLevel:  "info",
+			wantRetry:     "1s,3s,5s",
+			wantTransport: "http",
+		},
+		{
+			name:    "invalid config file",
+			config:  `{"address": "filehost:6060", "poll_interval": "often", "labels": {"env": "prod"}, "proxy": null}`,
+			env:     map[string]string{"CONFIG": "{config}"},
+			args:    []string{"cmd"},
+			wantErr: []string{`"poll_interval"`, `"labels"`, `"proxy"`},
 		},
 	}
 
@@ -85,31 +189,123 @@ func TestParseFlags_EnvOverridesFlags(t *testing.T) {
 			for k := range tt.env {
 				os.Unsetenv(k)
 			}
+			os.Unsetenv("CONFIG")
+
+			configFile := filepath.Join(t.TempDir(), "config.json")
+			if tt.config != "" {
+				require.NoError(t, os.WriteFile(configFile, []byte(tt.config), 0o600))
+			}
+
 			// Set env vars for this test
 			for k, v := range tt.env {
-				os.Setenv(k, v)
+				os.Setenv(k, strings.ReplaceAll(v, "{config}", configFile))
 			}
 
 			resetFlags()
-			os.Args = tt.args
+			os.Args = nil
+			for _, arg := range tt.args {
+				os.Args = append(os.Args, strings.ReplaceAll(arg, "{config}", configFile))
+			}
 
 			serverAddr = ""
 			pollInterval = 0
 			reportInterval = 0
 			numWorkers = 0
 			logLevel = ""
+			key = ""
+			retryIntervals = ""
+			labels = ""
+			transport = ""
+			cryptoKey = ""
+			adminAddress = ""
+			configPath = ""
+
+			err := parseFlags()
 
-			parseFlags()
+			for k := range tt.env {
+				os.Unsetenv(k)
+			}
+
+			if len(tt.wantErr) > 0 {
+				require.ErrorIs(t, err, internalErrors.ErrConfigInvalid)
+				for _, field := range tt.wantErr {
+					assert.Contains(t, err.Error(), field)
+				}
+				return
+			}
+			require.NoError(t, err)
 
 			assert.Equal(t, tt.wantAddr, serverAddr)
 			assert.Equal(t, tt.wantPoll, pollInterval)
 			assert.Equal(t, tt.wantReport, reportInterval)
 			assert.Equal(t, tt.wantWorkers, numWorkers)
 			assert.Equal(t, tt.wantLogLevel, logLevel)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantRetry, retryIntervals)
+			assert.Equal(t, tt.wantLabels, labels)
+			assert.Equal(t, tt.wantTransport, transport)
+			assert.Equal(t, tt.wantCrypto, cryptoKey)
+			assert.Equal(t, tt.wantAdmin, adminAddress)
+		})
+	}
+}
 
-			for k := range tt.env {
-				os.Unsetenv(k)
+func TestParseDurations(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []time.Duration
+		wantErr bool
+	}{
+		{name: "default schedule", input: "1s,3s,5s", want: []time.Duration{time.Second, 3 * time.Second, 5 * time.Second}},
+		{name: "spaces are trimmed", input: " 100ms , 2s ", want: []time.Duration{100 * time.Millisecond, 2 * time.Second}},
+		{name: "empty disables retries", input: "", want: nil},
+		{name: "invalid duration", input: "1s,soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDurations(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    types.Labels
+		wantErr bool
+	}{
+		{name: "hostname only", input: "", want: types.NewLabels(map[string]string{"host": hostname})},
+		{
+			name:  "configured labels and hostname",
+			input: "env=prod, service=api",
+			want:  types.NewLabels(map[string]string{"env": "prod", "service": "api", "host": hostname}),
+		},
+		{name: "explicit host wins", input: "host=web-1", want: types.NewLabels(map[string]string{"host": "web-1"})},
+		{name: "malformed pair", input: "env", wantErr: true},
+		{name: "invalid label name", input: "1env=prod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLabels(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
 			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
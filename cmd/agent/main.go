@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
 )
 
 func main() {
-	parseFlags()
+	// Invalid configuration is reported before anything starts
+	if err := parseFlags(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 	err := run(context.Background())
 	if err != nil {
 		panic(err)
@@ -10,24 +10,49 @@ import (
 )
 
 func run(ctx context.Context) error {
+	intervals, err := parseDurations(retryIntervals)
+	if err != nil {
+		return err
+	}
+
+	metricLabels, err := parseLabels(labels)
+	if err != nil {
+		return err
+	}
+
 	config := configs.NewAgentConfig(
 		configs.WithAgentServerAddress(serverAddr),
 		configs.WithAgentPollInterval(pollInterval),
 		configs.WithAgentReportInterval(reportInterval),
 		configs.WithAgentNumWorkers(numWorkers),
 		configs.WithAgentLogLevel(logLevel),
+		configs.WithAgentKey(key),
+		configs.WithAgentRetryIntervals(intervals),
+		configs.WithAgentLabels(metricLabels),
+		configs.WithAgentTransport(transport),
+		configs.WithAgentCryptoKey(cryptoKey),
+		configs.WithAgentAdminAddress(adminAddress),
 	)
 
-	err := logger.Initialize(config.LogLevel)
+	err = logger.Initialize(config.LogLevel)
 	if err != nil {
 		return err
 	}
-	logger.Log.Infof("Agent config initialized: %+v", config)
+	logger.Log.Infof("Agent config initialized: %+v", config.Masked())
 
 	app, err := apps.NewAgentApp(config)
 	if err != nil {
 		return err
 	}
 
-	return runners.Run(ctx, app)
+	runnables := []runners.Runnable{app}
+	if config.AdminAddress != "" {
+		admin, err := apps.NewAdminApp(config)
+		if err != nil {
+			return err
+		}
+		runnables = append(runnables, admin)
+	}
+
+	return runners.Run(ctx, runnables...)
 }
@@ -3,23 +3,201 @@ package main
 import (
 	"flag"
 	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
 )
 
 var (
-	addr     string
-	logLevel string
+	addr             string
+	logLevel         string
+	storeInterval    int
+	fileStoragePath  string
+	restore          bool
+	databaseDSN      string
+	key              string
+	alertRulesPath   string
+	alertInterval    int
+	webhookURLs      string
+	notifyInterval   int
+	notifyRepeat     int
+	historyRetention int
+	statsdAddress    string
+	statsdFlush      int
+	grpcAddress      string
+	cryptoKey        string
+	trustedSubnet    string
+	walPath          string
+	walSyncInterval  int
+	storage          string
+	dataDir          string
+	configPath       string
 )
 
-func parseFlags() {
+// configFields maps the fields of the JSON configuration file to the flags they set.
+var configFields = map[string]string{
+	"address":                "a",
+	"log_level":              "l",
+	"store_interval":         "i",
+	"file_storage_path":      "f",
+	"restore":                "r",
+	"database_dsn":           "d",
+	"key":                    "k",
+	"alert_rules":            "alert-rules",
+	"alert_interval":         "alert-interval",
+	"webhook_urls":           "webhook",
+	"notify_interval":        "notify-interval",
+	"notify_repeat_interval": "notify-repeat",
+	"history_retention":      "history-retention",
+	"statsd_address":         "statsd",
+	"statsd_flush_interval":  "statsd-flush-interval",
+	"grpc_address":           "grpc-address",
+	"crypto_key":             "crypto-key",
+	"trusted_subnet":         "t",
+	"wal_path":               "wal",
+	"wal_sync_interval":      "wal-sync-interval",
+	"storage":                "storage",
+	"data_dir":               "data-dir",
+}
+
+func parseFlags() error {
 	flag.StringVar(&addr, "a", "localhost:8080", "address and port to run server")
 	flag.StringVar(&logLevel, "l", "info", "log level")
+	flag.IntVar(&storeInterval, "i", 300, "interval in seconds between metric snapshots to the file")
+	flag.StringVar(&fileStoragePath, "f", "metrics-db.json", "path to the metric snapshot file")
+	flag.BoolVar(&restore, "r", true, "restore metrics from the snapshot file on start")
+	flag.StringVar(&databaseDSN, "d", "", "PostgreSQL DSN; when set, metrics are stored in the database")
+	flag.StringVar(&key, "k", "", "shared key for HMAC-SHA256 signing")
+	flag.StringVar(&alertRulesPath, "alert-rules", "", "path to the YAML/JSON alert rules file; empty disables alerting")
+	flag.IntVar(&alertInterval, "alert-interval", 10, "interval in seconds between alert rule evaluations")
+	flag.StringVar(&webhookURLs, "webhook", "", "comma-separated URLs firing and resolved alerts are posted to")
+	flag.IntVar(&notifyInterval, "notify-interval", 10, "interval in seconds between checks for alerts to notify about")
+	flag.IntVar(&notifyRepeat, "notify-repeat", 3600, "interval in seconds after which a still-firing alert is notified again")
+	flag.IntVar(&historyRetention, "history-retention", 3600, "interval in seconds metric history is kept for; 0 disables the history. The history is kept in memory only and is lost on restart, whatever the storage")
+	flag.StringVar(&statsdAddress, "statsd", "", "UDP address to receive StatsD samples on, e.g. :8125; empty disables StatsD")
+	flag.IntVar(&statsdFlush, "statsd-flush-interval", 10, "interval in seconds between flushes of aggregated StatsD samples")
+	flag.StringVar(&grpcAddress, "grpc-address", "", "address and port to run the gRPC server, e.g. :3200; empty disables gRPC")
+	flag.StringVar(&cryptoKey, "crypto-key", "", "path to the PEM-encoded RSA private key to decrypt request bodies with")
+	flag.StringVar(&trustedSubnet, "t", "", "CIDR metric updates and log level changes must originate from, per the X-Real-IP header; empty allows updates from any origin and disables log level changes")
+	flag.StringVar(&walPath, "wal", "", "path to the write-ahead log of in-memory metric updates, requires -f; empty disables the log")
+	flag.IntVar(&walSyncInterval, "wal-sync-interval", 0, "interval in milliseconds between flushes of the write-ahead log to disk; 0 flushes every update")
+	flag.StringVar(&storage, "storage", configs.StorageMemory, "storage backend: memory (PostgreSQL when -d is set) or embedded")
+	flag.StringVar(&dataDir, "data-dir", "data", "directory the embedded database is kept in")
+
+	flag.StringVar(&configPath, "c", "", "path to the JSON configuration file")
+	flag.StringVar(&configPath, "config", "", "path to the JSON configuration file (same as -c)")
 
 	flag.Parse()
 
-	if env := os.Getenv("ADDRESS"); env != "" {
+	// Flags given on the command line take precedence over the environment and the configuration file
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if env := os.Getenv("CONFIG"); env != "" && !set["c"] && !set["config"] {
+		configPath = env
+	}
+	if configPath != "" {
+		if err := configs.ApplyFile(configPath, flag.CommandLine, configFields, set); err != nil {
+			return err
+		}
+	}
+
+	if env := os.Getenv("ADDRESS"); env != "" && !set["a"] {
 		addr = env
 	}
-	if env := os.Getenv("LOG_LEVEL"); env != "" {
+	if env := os.Getenv("LOG_LEVEL"); env != "" && !set["l"] {
 		logLevel = env
 	}
+	if env := os.Getenv("STORE_INTERVAL"); env != "" && !set["i"] {
+		if v, err := strconv.Atoi(env); err == nil {
+			storeInterval = v
+		}
+	}
+	if env := os.Getenv("FILE_STORAGE_PATH"); env != "" && !set["f"] {
+		fileStoragePath = env
+	}
+	if env := os.Getenv("RESTORE"); env != "" && !set["r"] {
+		if v, err := strconv.ParseBool(env); err == nil {
+			restore = v
+		}
+	}
+	if env := os.Getenv("DATABASE_DSN"); env != "" && !set["d"] {
+		databaseDSN = env
+	}
+	if env := os.Getenv("KEY"); env != "" && !set["k"] {
+		key = env
+	}
+	if env := os.Getenv("ALERT_RULES"); env != "" && !set["alert-rules"] {
+		alertRulesPath = env
+	}
+	if env := os.Getenv("ALERT_INTERVAL"); env != "" && !set["alert-interval"] {
+		if v, err := strconv.Atoi(env); err == nil {
+			alertInterval = v
+		}
+	}
+	if env := os.Getenv("WEBHOOK_URLS"); env != "" && !set["webhook"] {
+		webhookURLs = env
+	}
+	if env := os.Getenv("NOTIFY_INTERVAL"); env != "" && !set["notify-interval"] {
+		if v, err := strconv.Atoi(env); err == nil {
+			notifyInterval = v
+		}
+	}
+	if env := os.Getenv("NOTIFY_REPEAT_INTERVAL"); env != "" && !set["notify-repeat"] {
+		if v, err := strconv.Atoi(env); err == nil {
+			notifyRepeat = v
+		}
+	}
+	if env := os.Getenv("HISTORY_RETENTION"); env != "" && !set["history-retention"] {
+		if v, err := strconv.Atoi(env); err == nil {
+			historyRetention = v
+		}
+	}
+	if env := os.Getenv("STATSD_ADDRESS"); env != "" && !set["statsd"] {
+		statsdAddress = env
+	}
+	if env := os.Getenv("STATSD_FLUSH_INTERVAL"); env != "" && !set["statsd-flush-interval"] {
+		if v, err := strconv.Atoi(env); err == nil {
+			statsdFlush = v
+		}
+	}
+	if env := os.Getenv("GRPC_ADDRESS"); env != "" && !set["grpc-address"] {
+		grpcAddress = env
+	}
+	if env := os.Getenv("CRYPTO_KEY"); env != "" && !set["crypto-key"] {
+		cryptoKey = env
+	}
+	if env := os.Getenv("TRUSTED_SUBNET"); env != "" && !set["t"] {
+		trustedSubnet = env
+	}
+	if env := os.Getenv("WAL_PATH"); env != "" && !set["wal"] {
+		walPath = env
+	}
+	if env := os.Getenv("WAL_SYNC_INTERVAL"); env != "" && !set["wal-sync-interval"] {
+		if v, err := strconv.Atoi(env); err == nil {
+			walSyncInterval = v
+		}
+	}
+	if env := os.Getenv("STORAGE"); env != "" && !set["storage"] {
+		storage = env
+	}
+	if env := os.Getenv("DATA_DIR"); env != "" && !set["data-dir"] {
+		dataDir = env
+	}
+
+	return nil
+}
+
+// parseList splits a comma-separated list, dropping empty items.
+func parseList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
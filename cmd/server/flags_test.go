@@ -3,9 +3,14 @@ package main
 import (
 	"flag"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
 )
 
 func resetFlags() {
@@ -14,45 +19,226 @@ func resetFlags() {
 
 func TestParseFlags(t *testing.T) {
 	tests := []struct {
-		name       string
-		env        map[string]string
-		args       []string
-		wantAddr   string
-		wantLogLvl string
+		name         string
+		config       string // contents of the config file, referred to as {config} in env and args
+		env          map[string]string
+		args         []string
+		wantErr      []string // fields reported as invalid
+		wantAddr     string
+		wantLogLvl   string
+		wantInterval int
+		wantFilePath string
+		wantRestore  bool
+		wantDSN      string
+		wantKey      string
+		wantRules    string
+		wantAlertInt int
+		wantWebhooks string
+		wantNotify   int
+		wantRepeat   int
+		wantHistory  int
+		wantStatsD   string
+		wantFlush    int
+		wantGRPC     string
+		wantCrypto   string
+		wantSubnet   string
+		wantWAL      string
+		wantWALSync  int
+		wantStorage  string
+		wantDataDir  string
 	}{
 		{
-			name: "env overrides flags",
+			name: "flags override env",
 			env: map[string]string{
 				"ADDRESS":   "envhost:9090",
 				"LOG_LEVEL": "debug",
 			},
-			args:       []string{"cmd", "-a", "flaghost:7070", "-l", "warn"},
-			wantAddr:   "envhost:9090",
-			wantLogLvl: "debug",
+			args:         []string{"cmd", "-a", "flaghost:7070", "-l", "warn"},
+			wantAddr:     "flaghost:7070",
+			wantLogLvl:   "warn",
+			wantInterval: 300,
+			wantFilePath: "metrics-db.json",
+			wantRestore:  true,
+			wantAlertInt: 10,
+			wantNotify:   10,
+			wantRepeat:   3600,
+			wantHistory:  3600,
+			wantFlush:    10,
+			wantStorage:  "memory",
+			wantDataDir:  "data",
 		},
 		{
-			name:       "flags only",
-			env:        nil,
-			args:       []string{"cmd", "-a", "flaghost:7070", "-l", "warn"},
-			wantAddr:   "flaghost:7070",
-			wantLogLvl: "warn",
+			name:         "flags only",
+			env:          nil,
+			args:         []string{"cmd", "-a", "flaghost:7070", "-l", "warn", "-i", "10", "-f", "/tmp/flag.json", "-r=false", "-d", "postgres://flag", "-k", "flagkey", "-alert-rules", "/tmp/flag-rules.yaml", "-alert-interval", "5", "-webhook", "http://flag/hook", "-notify-interval", "3", "-notify-repeat", "60", "-history-retention", "600", "-statsd", ":9125", "-statsd-flush-interval", "2", "-grpc-address", ":4200", "-crypto-key", "/tmp/flag.pem", "-t", "192.168.0.0/16", "-wal", "/tmp/flag.wal", "-wal-sync-interval", "100", "-storage", "embedded", "-data-dir", "/tmp/flag-data"},
+			wantAddr:     "flaghost:7070",
+			wantLogLvl:   "warn",
+			wantInterval: 10,
+			wantFilePath: "/tmp/flag.json",
+			wantRestore:  false,
+			wantDSN:      "postgres://flag",
+			wantKey:      "flagkey",
+			wantRules:    "/tmp/flag-rules.yaml",
+			wantAlertInt: 5,
+			wantWebhooks: "http://flag/hook",
+			wantNotify:   3,
+			wantRepeat:   60,
+			wantHistory:  600,
+			wantStatsD:   ":9125",
+			wantFlush:    2,
+			wantGRPC:     ":4200",
+			wantCrypto:   "/tmp/flag.pem",
+			wantSubnet:   "192.168.0.0/16",
+			wantWAL:      "/tmp/flag.wal",
+			wantWALSync:  100,
+			wantStorage:  "embedded",
+			wantDataDir:  "/tmp/flag-data",
 		},
 		{
 			name: "env only",
+			env: map[string]string{
+				"ADDRESS":                "envhost:9090",
+				"LOG_LEVEL":              "debug",
+				"STORE_INTERVAL":         "0",
+				"FILE_STORAGE_PATH":      "/tmp/env.json",
+				"RESTORE":                "false",
+				"DATABASE_DSN":           "postgres://env",
+				"KEY":                    "envkey",
+				"ALERT_RULES":            "/tmp/env-rules.yaml",
+				"ALERT_INTERVAL":         "30",
+				"WEBHOOK_URLS":           "http://env/a,http://env/b",
+				"NOTIFY_INTERVAL":        "20",
+				"NOTIFY_REPEAT_INTERVAL": "120",
+				"HISTORY_RETENTION":      "0",
+				"STATSD_ADDRESS":         ":8125",
+				"STATSD_FLUSH_INTERVAL":  "30",
+				"GRPC_ADDRESS":           ":3200",
+				"CRYPTO_KEY":             "/tmp/env.pem",
+				"TRUSTED_SUBNET":         "10.0.0.0/8",
+				"WAL_PATH":               "/tmp/env.wal",
+				"WAL_SYNC_INTERVAL":      "50",
+				"STORAGE":                "embedded",
+				"DATA_DIR":               "/tmp/env-data",
+			},
+			args:         []string{"cmd"},
+			wantAddr:     "envhost:9090",
+			wantLogLvl:   "debug",
+			wantInterval: 0,
+			wantFilePath: "/tmp/env.json",
+			wantRestore:  false,
+			wantDSN:      "postgres://env",
+			wantKey:      "envkey",
+			wantRules:    "/tmp/env-rules.yaml",
+			wantAlertInt: 30,
+			wantWebhooks: "http://env/a,http://env/b",
+			wantNotify:   20,
+			wantRepeat:   120,
+			wantHistory:  0,
+			wantStatsD:   ":8125",
+			wantFlush:    30,
+			wantGRPC:     ":3200",
+			wantCrypto:   "/tmp/env.pem",
+			wantSubnet:   "10.0.0.0/8",
+			wantWAL:      "/tmp/env.wal",
+			wantWALSync:  50,
+			wantStorage:  "embedded",
+			wantDataDir:  "/tmp/env-data",
+		},
+		{
+			name: "config file only",
+			config: `{
+				"address": "filehost:6060",
+				"log_level": "error",
+				"store_interval": 60,
+				"file_storage_path": "/tmp/file.json",
+				"restore": false,
+				"database_dsn": "postgres://file",
+				"key": "filekey",
+				"alert_rules": "/tmp/file-rules.yaml",
+				"alert_interval": 15,
+				"webhook_urls": ["http://file/a", "http://file/b"],
+				"notify_interval": 25,
+				"notify_repeat_interval": 900,
+				"history_retention": 1800,
+				"statsd_address": ":7125",
+				"statsd_flush_interval": 5,
+				"grpc_address": ":5200",
+				"crypto_key": "/tmp/file.pem",
+				"trusted_subnet": "172.16.0.0/12",
+				"wal_path": "/tmp/file.wal",
+				"wal_sync_interval": 200,
+				"storage": "embedded",
+				"data_dir": "/tmp/file-data"
+			}`,
+			env:          map[string]string{"CONFIG": "{config}"},
+			args:         []string{"cmd"},
+			wantAddr:     "filehost:6060",
+			wantLogLvl:   "error",
+			wantInterval: 60,
+			wantFilePath: "/tmp/file.json",
+			wantRestore:  false,
+			wantDSN:      "postgres://file",
+			wantKey:      "filekey",
+			wantRules:    "/tmp/file-rules.yaml",
+			wantAlertInt: 15,
+			wantWebhooks: "http://file/a,http://file/b",
+			wantNotify:   25,
+			wantRepeat:   900,
+			wantHistory:  1800,
+			wantStatsD:   ":7125",
+			wantFlush:    5,
+			wantGRPC:     ":5200",
+			wantCrypto:   "/tmp/file.pem",
+			wantSubnet:   "172.16.0.0/12",
+			wantWAL:      "/tmp/file.wal",
+			wantWALSync:  200,
+			wantStorage:  "embedded",
+			wantDataDir:  "/tmp/file-data",
+		},
+		{
+			name:   "flags override env override config file",
+			config: `{"address": "filehost:6060", "log_level": "error", "store_interval": 60}`,
 			env: map[string]string{
 				"ADDRESS":   "envhost:9090",
 				"LOG_LEVEL": "debug",
+				"CONFIG":    "/nonexistent/config.json",
 			},
-			args:       []string{"cmd"},
-			wantAddr:   "envhost:9090",
-			wantLogLvl: "debug",
+			args:         []string{"cmd", "-config", "{config}", "-a", "flaghost:7070"},
+			wantAddr:     "flaghost:7070",
+			wantLogLvl:   "debug",
+			wantInterval: 60,
+			wantFilePath: "metrics-db.json",
+			wantRestore:  true,
+			wantAlertInt: 10,
+			wantNotify:   10,
+			wantRepeat:   3600,
+			wantHistory:  3600,
+			wantFlush:    10,
+			wantStorage:  "memory",
+			wantDataDir:  "data",
 		},
 		{
-			name:       "defaults without env or flags",
-			env:        nil,
-			args:       []string{"cmd"},
-			wantAddr:   "localhost:8080",
-			wantLogLvl: "info",
+			name:    "invalid config file",
+			config:  `{"address": "filehost:6060", "store_interval": "often", "restore": 1, "port": 8080, "webhook_urls": [1]}`,
+			args:    []string{"cmd", "-c", "{config}"},
+			wantErr: []string{`"store_interval"`, `"restore"`, `"port"`, `"webhook_urls"`},
+		},
+		{
+			name:         "defaults without env or flags",
+			env:          nil,
+			args:         []string{"cmd"},
+			wantAddr:     "localhost:8080",
+			wantLogLvl:   "info",
+			wantInterval: 300,
+			wantFilePath: "metrics-db.json",
+			wantRestore:  true,
+			wantAlertInt: 10,
+			wantNotify:   10,
+			wantRepeat:   3600,
+			wantHistory:  3600,
+			wantFlush:    10,
+			wantStorage:  "memory",
+			wantDataDir:  "data",
 		},
 	}
 
@@ -61,28 +247,125 @@ func TestParseFlags(t *testing.T) {
 			// Clear env first
 			os.Unsetenv("ADDRESS")
 			os.Unsetenv("LOG_LEVEL")
+			os.Unsetenv("STORE_INTERVAL")
+			os.Unsetenv("FILE_STORAGE_PATH")
+			os.Unsetenv("RESTORE")
+			os.Unsetenv("DATABASE_DSN")
+			os.Unsetenv("KEY")
+			os.Unsetenv("ALERT_RULES")
+			os.Unsetenv("ALERT_INTERVAL")
+			os.Unsetenv("WEBHOOK_URLS")
+			os.Unsetenv("NOTIFY_INTERVAL")
+			os.Unsetenv("NOTIFY_REPEAT_INTERVAL")
+			os.Unsetenv("HISTORY_RETENTION")
+			os.Unsetenv("STATSD_ADDRESS")
+			os.Unsetenv("STATSD_FLUSH_INTERVAL")
+			os.Unsetenv("GRPC_ADDRESS")
+			os.Unsetenv("CRYPTO_KEY")
+			os.Unsetenv("TRUSTED_SUBNET")
+			os.Unsetenv("WAL_PATH")
+			os.Unsetenv("WAL_SYNC_INTERVAL")
+			os.Unsetenv("STORAGE")
+			os.Unsetenv("DATA_DIR")
+			os.Unsetenv("CONFIG")
+
+			configFile := filepath.Join(t.TempDir(), "config.json")
+			if tt.config != "" {
+				require.NoError(t, os.WriteFile(configFile, []byte(tt.config), 0o600))
+			}
 
 			// Set env vars for test
 			for k, v := range tt.env {
-				os.Setenv(k, v)
+				os.Setenv(k, strings.ReplaceAll(v, "{config}", configFile))
 			}
 
 			resetFlags()
-			os.Args = tt.args
+			os.Args = nil
+			for _, arg := range tt.args {
+				os.Args = append(os.Args, strings.ReplaceAll(arg, "{config}", configFile))
+			}
 
 			// Reset globals before parsing
 			addr = ""
 			logLevel = ""
+			storeInterval = 0
+			fileStoragePath = ""
+			restore = false
+			databaseDSN = ""
+			key = ""
+			alertRulesPath = ""
+			alertInterval = 0
+			webhookURLs = ""
+			notifyInterval = 0
+			notifyRepeat = 0
+			historyRetention = 0
+			statsdAddress = ""
+			statsdFlush = 0
+			grpcAddress = ""
+			cryptoKey = ""
+			trustedSubnet = ""
+			walPath = ""
+			walSyncInterval = 0
+			storage = ""
+			dataDir = ""
+			configPath = ""
 
-			parseFlags()
-
-			assert.Equal(t, tt.wantAddr, addr)
-			assert.Equal(t, tt.wantLogLvl, logLevel)
+			err := parseFlags()
 
 			// Clean up env
 			for k := range tt.env {
 				os.Unsetenv(k)
 			}
+
+			if len(tt.wantErr) > 0 {
+				require.ErrorIs(t, err, internalErrors.ErrConfigInvalid)
+				for _, field := range tt.wantErr {
+					assert.Contains(t, err.Error(), field)
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantAddr, addr)
+			assert.Equal(t, tt.wantLogLvl, logLevel)
+			assert.Equal(t, tt.wantInterval, storeInterval)
+			assert.Equal(t, tt.wantFilePath, fileStoragePath)
+			assert.Equal(t, tt.wantRestore, restore)
+			assert.Equal(t, tt.wantDSN, databaseDSN)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantRules, alertRulesPath)
+			assert.Equal(t, tt.wantAlertInt, alertInterval)
+			assert.Equal(t, tt.wantWebhooks, webhookURLs)
+			assert.Equal(t, tt.wantNotify, notifyInterval)
+			assert.Equal(t, tt.wantRepeat, notifyRepeat)
+			assert.Equal(t, tt.wantHistory, historyRetention)
+			assert.Equal(t, tt.wantStatsD, statsdAddress)
+			assert.Equal(t, tt.wantFlush, statsdFlush)
+			assert.Equal(t, tt.wantGRPC, grpcAddress)
+			assert.Equal(t, tt.wantCrypto, cryptoKey)
+			assert.Equal(t, tt.wantSubnet, trustedSubnet)
+			assert.Equal(t, tt.wantWAL, walPath)
+			assert.Equal(t, tt.wantWALSync, walSyncInterval)
+			assert.Equal(t, tt.wantStorage, storage)
+			assert.Equal(t, tt.wantDataDir, dataDir)
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "single", in: "http://a", want: []string{"http://a"}},
+		{name: "trims and drops empty items", in: " http://a , ,http://b,", want: []string{"http://a", "http://b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseList(tt.in))
 		})
 	}
 }
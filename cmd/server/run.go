@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"flag"
+	"os"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/apps"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
@@ -9,18 +11,52 @@ import (
 	"github.com/sbilibin2017/yandex-go-advanced/internal/runners"
 )
 
-func run(ctx context.Context) error {
-	config := configs.NewServerConfig(
+// newServerConfig builds the server configuration from the parsed flags.
+func newServerConfig() *configs.ServerConfig {
+	return configs.NewServerConfig(
 		configs.WithServerAddress(addr),
 		configs.WithServerLogLevel(logLevel),
+		configs.WithServerStoreInterval(storeInterval),
+		configs.WithServerFileStoragePath(fileStoragePath),
+		configs.WithServerRestore(restore),
+		configs.WithServerDatabaseDSN(databaseDSN),
+		configs.WithServerKey(key),
+		configs.WithServerAlertRulesPath(alertRulesPath),
+		configs.WithServerAlertInterval(alertInterval),
+		configs.WithServerWebhookURLs(parseList(webhookURLs)),
+		configs.WithServerNotifyInterval(notifyInterval),
+		configs.WithServerNotifyRepeat(notifyRepeat),
+		configs.WithServerHistoryRetention(historyRetention),
+		configs.WithServerStatsDAddress(statsdAddress),
+		configs.WithServerStatsDFlush(statsdFlush),
+		configs.WithServerGRPCAddress(grpcAddress),
+		configs.WithServerCryptoKey(cryptoKey),
+		configs.WithServerTrustedSubnet(trustedSubnet),
+		configs.WithServerWALPath(walPath),
+		configs.WithServerWALSyncInterval(walSyncInterval),
+		configs.WithServerStorage(storage),
+		configs.WithServerDataDir(dataDir),
 	)
+}
+
+// reloadServerConfig re-reads the flags, environment and configuration file the way they are read on start.
+func reloadServerConfig() (*configs.ServerConfig, error) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	if err := parseFlags(); err != nil {
+		return nil, err
+	}
+	return newServerConfig(), nil
+}
+
+func run(ctx context.Context) error {
+	config := newServerConfig()
 
 	err := logger.Initialize(config.LogLevel)
 	if err != nil {
 		logger.Log.Errorf("Failed to initialize logger: %v", err)
 		return err
 	}
-	logger.Log.Infof("Server config initialized: %+v", config)
+	logger.Log.Infof("Server config initialized: %+v", config.Masked())
 
 	app, err := apps.NewServerApp(config)
 	if err != nil {
@@ -28,7 +64,38 @@ func run(ctx context.Context) error {
 		return err
 	}
 
-	err = runners.Run(ctx, app)
+	runnables := []runners.Runnable{app}
+	reloadTargets := []apps.ReloadReconfigurer{app}
+	if len(config.WebhookURLs) > 0 {
+		notifier, err := apps.NewNotifierApp(config, app.AlertService(), app.AlertChanges())
+		if err != nil {
+			logger.Log.Errorf("Failed to create notifier app: %v", err)
+			return err
+		}
+		runnables = append(runnables, notifier)
+	}
+	if config.StatsDAddress != "" {
+		statsd, err := apps.NewStatsDApp(config, app.UpdateService())
+		if err != nil {
+			logger.Log.Errorf("Failed to create StatsD app: %v", err)
+			return err
+		}
+		runnables = append(runnables, statsd)
+	}
+	if config.GRPCAddress != "" {
+		grpcServer, err := apps.NewGRPCServerApp(config, app.UpdateService(), app.GetService(), app.ListService())
+		if err != nil {
+			logger.Log.Errorf("Failed to create gRPC server app: %v", err)
+			return err
+		}
+		runnables = append(runnables, grpcServer)
+		reloadTargets = append(reloadTargets, grpcServer)
+	}
+
+	// On SIGHUP the configuration is re-read and applied to the running apps
+	runnables = append(runnables, apps.NewReloadApp(config, reloadServerConfig, reloadTargets...))
+
+	err = runners.Run(ctx, runnables...)
 	if err != nil {
 		logger.Log.Errorf("Error running the app: %v", err)
 		return err
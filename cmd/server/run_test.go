@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRun(t *testing.T) {
@@ -20,3 +23,30 @@ func TestRun(t *testing.T) {
 	err := run(ctx)
 	assert.NoError(t, err)
 }
+
+func TestReloadServerConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.Unsetenv("LOG_LEVEL")
+	os.Unsetenv("TRUSTED_SUBNET")
+	os.Unsetenv("CONFIG")
+	os.Args = []string{"cmd", "-c", path, "-a", "localhost:7070"}
+	configPath = ""
+
+	// The configuration file is re-read on every reload
+	require.NoError(t, os.WriteFile(path, []byte(`{"log_level": "warn", "trusted_subnet": "10.0.0.0/8"}`), 0o600))
+	config, err := reloadServerConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:7070", config.Address)
+	assert.Equal(t, "warn", config.LogLevel)
+	assert.Equal(t, "10.0.0.0/8", config.TrustedSubnet)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"log_level": "debug"}`), 0o600))
+	config, err = reloadServerConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "debug", config.LogLevel)
+	assert.Empty(t, config.TrustedSubnet)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"restore": "yes"}`), 0o600))
+	_, err = reloadServerConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/handlers"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/middlewares"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/routers"
+)
+
+// AdminApp represents the agent's local administration HTTP server.
+//
+// It serves the admin endpoints, such as /admin/loglevel, on a loopback address only,
+// so that they cannot be reached from other hosts. Implements the Runnable interface
+// for lifecycle management.
+type AdminApp struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewAdminApp initializes and returns a new AdminApp.
+//
+// It starts listening on the configured admin address right away, so that an unusable
+// address is reported before any runnable is started.
+//
+// Parameters:
+//   - config: AgentConfig containing the admin address.
+//
+// Returns:
+//   - Pointer to an AdminApp instance ready to be started.
+//   - An error if the address is not a loopback address or cannot be listened on.
+func NewAdminApp(config *configs.AgentConfig) (*AdminApp, error) {
+	if err := checkLoopbackAddress(config.AdminAddress); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", config.AdminAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	router := routers.NewAdminRouter(
+		handlers.NewLogLevelGetHandler(logger.AtomicLevel()),
+		handlers.NewLogLevelSetHandler(logger.AtomicLevel()),
+		middlewares.LoggingMiddleware,
+	)
+
+	return &AdminApp{
+		server:   &http.Server{Handler: router},
+		listener: listener,
+	}, nil
+}
+
+// checkLoopbackAddress checks that the host of the address is localhost or a loopback IP.
+func checkLoopbackAddress(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid admin address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("admin address %q is not a loopback address", addr)
+}
+
+// Start serves the admin endpoints and blocks until the server shuts down or encounters an error.
+//
+// It satisfies the Runnable interface.
+//
+// Parameters:
+//   - ctx: Context for managing cancellation and timeout (unused; the server is stopped by Stop).
+//
+// Returns:
+//   - http.ErrServerClosed once the server is stopped, or an error if it fails while serving.
+func (app *AdminApp) Start(ctx context.Context) error {
+	return app.server.Serve(app.listener)
+}
+
+// Stop gracefully shuts down the admin server using the provided context.
+//
+// It satisfies the Runnable interface.
+//
+// Parameters:
+//   - ctx: Context for controlling shutdown timeout and cancellation.
+//
+// Returns:
+//   - An error if shutdown fails or times out.
+func (app *AdminApp) Stop(ctx context.Context) error {
+	return app.server.Shutdown(ctx)
+}
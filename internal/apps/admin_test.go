@@ -0,0 +1,74 @@
+package apps
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+)
+
+func TestNewAdminApp_Address(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{name: "loopback IPv4", addr: "127.0.0.1:0"},
+		{name: "localhost", addr: "localhost:0"},
+		{name: "all interfaces", addr: ":0", wantErr: true},
+		{name: "non-loopback address", addr: "0.0.0.0:0", wantErr: true},
+		{name: "malformed address", addr: "localhost", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := NewAdminApp(&configs.AgentConfig{AdminAddress: tt.addr})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, app)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, app.listener.Close())
+		})
+	}
+}
+
+func TestAdminApp_LogLevel(t *testing.T) {
+	level := logger.AtomicLevel()
+	defer level.SetLevel(level.Level())
+	level.SetLevel(zap.InfoLevel)
+
+	app, err := NewAdminApp(&configs.AgentConfig{AdminAddress: "127.0.0.1:0"})
+	require.NoError(t, err)
+
+	go func() {
+		assert.ErrorIs(t, app.Start(context.Background()), http.ErrServerClosed)
+	}()
+	defer app.Stop(context.Background())
+
+	url := "http://" + app.listener.Addr().String() + "/admin/loglevel"
+
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(`{"level":"debug"}`))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, zap.DebugLevel, level.Level())
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"level":"debug"}`, string(body))
+}
@@ -5,8 +5,12 @@ package apps
 
 import (
 	"context"
+	"crypto/rsa"
+	"fmt"
+	"sync"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/cryptos"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/facades"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/workers"
 )
@@ -17,37 +21,73 @@ import (
 // to a remote server. Implements the Runnable interface for lifecycle management.
 type AgentApp struct {
 	worker func(ctx context.Context)
+	close  func() error // releases the transport's connection; nil if there is none
+
+	mu   sync.Mutex
+	done chan struct{} // closed once the started worker has returned; nil until Start is called
 }
 
 // NewAgentApp initializes and returns a new AgentApp.
 //
-// It creates a MetricUpdateFacade based on the provided configuration and
-// constructs a worker function that handles polling and reporting intervals.
+// It creates a facade for the configured transport, a MetricUpdateFacade for HTTP
+// (the default) or a MetricGRPCFacade for gRPC, and constructs a worker function
+// that handles polling and reporting intervals. When a crypto key is configured,
+// reports are encrypted with the server's public key; only HTTP supports this.
 //
 // Parameters:
-//   - config: AgentConfig containing server address, polling, and reporting intervals.
+//   - config: AgentConfig containing server address, transport, polling and reporting intervals, number of workers, signing and crypto keys.
 //
 // Returns:
 //   - Pointer to an AgentApp instance ready to be started.
-//   - An error if initialization fails (currently always nil).
+//   - An error if the crypto key cannot be loaded, or the transport is unknown or its facade cannot be created.
 func NewAgentApp(
 	config *configs.AgentConfig,
 ) (*AgentApp, error) {
-	metricUpdateFacade := facades.NewMetricUpdateFacade(config.ServerAddress)
+	var publicKey *rsa.PublicKey
+	if config.CryptoKey != "" {
+		var err error
+		publicKey, err = cryptos.LoadPublicKey(config.CryptoKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		metricUpdater workers.MetricUpdater
+		closeFn       func() error
+	)
+	switch config.Transport {
+	case "", configs.TransportHTTP:
+		metricUpdater = facades.NewMetricUpdateFacade(config.ServerAddress, config.Key, publicKey, config.RetryIntervals)
+	case configs.TransportGRPC:
+		if publicKey != nil {
+			return nil, fmt.Errorf("encryption is not supported over the %s transport", config.Transport)
+		}
+		metricGRPCFacade, err := facades.NewMetricGRPCFacade(config.ServerAddress, config.Key, config.RetryIntervals)
+		if err != nil {
+			return nil, err
+		}
+		metricUpdater = metricGRPCFacade
+		closeFn = metricGRPCFacade.Close
+	default:
+		return nil, fmt.Errorf("unknown transport %q", config.Transport)
+	}
 
 	worker := workers.NewMetricAgentWorker(
-		metricUpdateFacade,
+		metricUpdater,
 		config.PollInterval,
 		config.ReportInterval,
+		config.NumWorkers,
+		config.Labels,
 	)
 
-	return &AgentApp{worker: worker}, nil
+	return &AgentApp{worker: worker, close: closeFn}, nil
 }
 
 // Start launches the background metric agent worker.
 //
-// This method blocks until the provided context is canceled.
-// It satisfies the Runnable interface.
+// This method blocks until the provided context is canceled and the worker
+// has sent its final report. It satisfies the Runnable interface.
 //
 // Parameters:
 //   - ctx: Context to control cancellation and timeout of the worker.
@@ -55,20 +95,41 @@ func NewAgentApp(
 // Returns:
 //   - An error if the worker exits unexpectedly (currently always nil).
 func (app *AgentApp) Start(ctx context.Context) error {
+	done := make(chan struct{})
+	app.mu.Lock()
+	app.done = done
+	app.mu.Unlock()
+	defer close(done)
+
 	app.worker(ctx)
 	return nil
 }
 
 // Stop performs cleanup or shutdown of the agent.
 //
-// For AgentApp, Stop is a no-op because the worker respects context cancellation.
+// The worker stops once the context passed to Start is canceled, reporting the metrics
+// collected since its last report. If the agent was started, Stop waits for that final
+// report, or for ctx to be done, and then closes the connection of the gRPC transport, if it is used.
 // It satisfies the Runnable interface.
 //
 // Parameters:
 //   - ctx: Context to control timeout or cancellation.
 //
 // Returns:
-//   - An error if shutdown fails (currently always nil).
+//   - An error if the connection cannot be closed.
 func (app *AgentApp) Stop(ctx context.Context) error {
+	app.mu.Lock()
+	done := app.done
+	app.mu.Unlock()
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+	if app.close != nil {
+		return app.close()
+	}
 	return nil
 }
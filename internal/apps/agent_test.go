@@ -2,12 +2,19 @@ package apps
 
 import (
 	"context"
-	"sync"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewAgentApp(t *testing.T) {
@@ -24,17 +31,88 @@ func TestNewAgentApp(t *testing.T) {
 	assert.NotNil(t, app.worker, "worker func should not be nil")
 }
 
+func TestNewAgentApp_Transports(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport string
+		wantErr   bool
+		wantClose bool
+	}{
+		{name: "default", transport: ""},
+		{name: "http", transport: configs.TransportHTTP},
+		{name: "grpc", transport: configs.TransportGRPC, wantClose: true},
+		{name: "unknown", transport: "carrier-pigeon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configs.AgentConfig{
+				ServerAddress:  "localhost:3200",
+				PollInterval:   1000,
+				ReportInterval: 2000,
+				Transport:      tt.transport,
+			}
+
+			app, err := NewAgentApp(cfg)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantClose, app.close != nil)
+			assert.NoError(t, app.Stop(context.Background()))
+		})
+	}
+}
+
+func TestNewAgentApp_CryptoKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	publicPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	tests := []struct {
+		name      string
+		path      string
+		transport string
+		wantErr   bool
+	}{
+		{name: "http", path: publicPath, transport: configs.TransportHTTP},
+		{name: "grpc is not supported", path: publicPath, transport: configs.TransportGRPC, wantErr: true},
+		{name: "missing key file", path: filepath.Join(dir, "missing.pem"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAgentApp(&configs.AgentConfig{
+				ServerAddress: "localhost:8080",
+				Transport:     tt.transport,
+				CryptoKey:     tt.path,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestAgentApp_StartAndStop(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Setup a mock worker function which respects context cancellation
-	var wg sync.WaitGroup
-	wg.Add(1)
-
+	// Setup a mock worker function which keeps reporting for a while after cancellation
+	var flushed atomic.Bool
 	mockWorker := func(ctx context.Context) {
-		defer wg.Done()
 		<-ctx.Done() // block until context canceled
+		time.Sleep(100 * time.Millisecond)
+		flushed.Store(true)
 	}
 
 	app := &AgentApp{
@@ -53,10 +131,27 @@ func TestAgentApp_StartAndStop(t *testing.T) {
 	// Stop by canceling context, which should cause worker to exit
 	cancel()
 
-	// Wait for worker to finish
-	wg.Wait()
-
-	// Stop currently does nothing but test it anyway
+	// Stop waits for the worker's final report
 	err := app.Stop(context.Background())
 	assert.NoError(t, err)
+	assert.True(t, flushed.Load())
+}
+
+func TestAgentApp_StopTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A worker that never finishes its final report
+	release := make(chan struct{})
+	defer close(release)
+	app := &AgentApp{worker: func(ctx context.Context) { <-release }}
+
+	go app.Start(ctx)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	// Stop gives up once its own context is done
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer stopCancel()
+	assert.NoError(t, app.Stop(stopCtx))
 }
@@ -0,0 +1,203 @@
+package apps
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip decompressor for compressed requests
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/grpcservers"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/interceptors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/validators"
+)
+
+// GRPCServerApp represents the gRPC server application.
+//
+// It serves the MetricService gRPC API alongside the HTTP API, backed by the
+// same services. Implements the Runnable interface for lifecycle management.
+type GRPCServerApp struct {
+	server       *grpc.Server
+	listener     net.Listener
+	interceptors atomic.Pointer[grpcInterceptors]
+}
+
+// grpcInterceptors are the interceptors of the GRPCServerApp that depend on settings
+// which can be changed while the server runs.
+type grpcInterceptors struct {
+	unary  []grpc.UnaryServerInterceptor
+	stream []grpc.StreamServerInterceptor
+}
+
+// newGRPCInterceptors creates the trusted subnet and hash interceptors for the given settings.
+func newGRPCInterceptors(key string, trustedSubnet *net.IPNet) *grpcInterceptors {
+	return &grpcInterceptors{
+		unary: []grpc.UnaryServerInterceptor{
+			interceptors.NewTrustedSubnetUnaryInterceptor(trustedSubnet),
+			interceptors.NewHashUnaryInterceptor(key),
+		},
+		stream: []grpc.StreamServerInterceptor{
+			interceptors.NewTrustedSubnetStreamInterceptor(trustedSubnet),
+			interceptors.NewHashStreamInterceptor(key),
+		},
+	}
+}
+
+// NewGRPCServerApp initializes and returns a new GRPCServerApp.
+//
+// It starts listening on the configured gRPC address right away, so that an unusable
+// address is reported before any runnable is started. Calls are logged and, when a key
+// is configured, signed the same way HTTP requests are. When a trusted subnet is configured,
+// updates are only accepted from addresses inside it, per the `x-real-ip` metadata.
+// The signing key and the trusted subnet can later be changed with PrepareReconfigure.
+//
+// Parameters:
+//   - config: ServerConfig containing the gRPC address, signing key and trusted subnet.
+//   - updater: Service applying metric updates, usually the ServerApp's update service.
+//   - getter: Service fetching single metrics, usually the ServerApp's get service.
+//   - lister: Service listing metrics, usually the ServerApp's list service.
+//
+// Returns:
+//   - Pointer to a GRPCServerApp instance ready to be started.
+//   - An error if the trusted subnet is malformed or the address cannot be listened on.
+func NewGRPCServerApp(
+	config *configs.ServerConfig,
+	updater grpcservers.MetricServerUpdater,
+	getter grpcservers.MetricServerGetter,
+	lister grpcservers.MetricServerLister,
+) (*GRPCServerApp, error) {
+	trustedSubnet, err := parseTrustedSubnet(config.TrustedSubnet)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", config.GRPCAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &GRPCServerApp{listener: listener}
+	app.interceptors.Store(newGRPCInterceptors(config.Key, trustedSubnet))
+
+	app.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(interceptors.LoggingUnaryInterceptor, app.unaryInterceptor),
+		grpc.ChainStreamInterceptor(interceptors.LoggingStreamInterceptor, app.streamInterceptor),
+	)
+	pb.RegisterMetricServiceServer(app.server, grpcservers.NewMetricServer(
+		validators.ValidateMetric,
+		validators.ValidateMetricID,
+		updater,
+		getter,
+		lister,
+	))
+
+	return app, nil
+}
+
+// PrepareReconfigure checks the signing key and the trusted subnet of the given configuration
+// and returns a function applying them. Calls in flight finish with the previous settings.
+//
+// Returns an error if the trusted subnet is malformed.
+func (app *GRPCServerApp) PrepareReconfigure(config *configs.ServerConfig) (func(), error) {
+	trustedSubnet, err := parseTrustedSubnet(config.TrustedSubnet)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		app.interceptors.Store(newGRPCInterceptors(config.Key, trustedSubnet))
+	}, nil
+}
+
+// unaryInterceptor runs the current unary interceptors around the handler.
+func (app *GRPCServerApp) unaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	return chainUnary(ctx, app.interceptors.Load().unary, req, info, handler)
+}
+
+// streamInterceptor runs the current stream interceptors around the handler.
+func (app *GRPCServerApp) streamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return chainStream(app.interceptors.Load().stream, srv, ss, info, handler)
+}
+
+// chainUnary calls the interceptors in order, the last one calling the handler.
+func chainUnary(
+	ctx context.Context,
+	chain []grpc.UnaryServerInterceptor,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if len(chain) == 0 {
+		return handler(ctx, req)
+	}
+	return chain[0](ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		return chainUnary(ctx, chain[1:], req, info, handler)
+	})
+}
+
+// chainStream calls the interceptors in order, the last one calling the handler.
+func chainStream(
+	chain []grpc.StreamServerInterceptor,
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if len(chain) == 0 {
+		return handler(srv, ss)
+	}
+	return chain[0](srv, ss, info, func(srv any, ss grpc.ServerStream) error {
+		return chainStream(chain[1:], srv, ss, info, handler)
+	})
+}
+
+// Start serves gRPC calls and blocks until the server is stopped or encounters an error.
+//
+// It satisfies the Runnable interface.
+//
+// Parameters:
+//   - ctx: Context for managing cancellation and timeout (unused; the server is stopped by Stop).
+//
+// Returns:
+//   - An error if the server fails while serving.
+func (app *GRPCServerApp) Start(ctx context.Context) error {
+	return app.server.Serve(app.listener)
+}
+
+// Stop gracefully shuts down the gRPC server, letting ongoing calls finish.
+// If the context is done first, the remaining calls are canceled.
+//
+// It satisfies the Runnable interface.
+//
+// Parameters:
+//   - ctx: Context for controlling shutdown timeout and cancellation.
+//
+// Returns:
+//   - An error if shutdown was cut short by the context.
+func (app *GRPCServerApp) Stop(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		app.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		app.server.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,91 @@
+package apps
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/facades"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestNewGRPCServerApp_InvalidAddress(t *testing.T) {
+	_, err := NewGRPCServerApp(&configs.ServerConfig{GRPCAddress: "not an address"}, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestNewGRPCServerApp_InvalidTrustedSubnet(t *testing.T) {
+	_, err := NewGRPCServerApp(&configs.ServerConfig{GRPCAddress: "127.0.0.1:0", TrustedSubnet: "not a subnet"}, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestGRPCServerApp_ServesServerMetrics(t *testing.T) {
+	cfg := &configs.ServerConfig{
+		Address:       "127.0.0.1:0",
+		GRPCAddress:   "127.0.0.1:0",
+		TrustedSubnet: "127.0.0.0/8",
+	}
+
+	server, err := NewServerApp(cfg)
+	require.NoError(t, err)
+	grpcServer, err := NewGRPCServerApp(cfg, server.UpdateService(), server.GetService(), server.ListService())
+	require.NoError(t, err)
+
+	go func() {
+		assert.NoError(t, grpcServer.Start(context.Background()))
+	}()
+	defer grpcServer.Stop(context.Background())
+
+	addr := grpcServer.listener.Addr().String()
+
+	// Updates sent by the agent's gRPC transport, from the loopback address, are accumulated like HTTP updates
+	facade, err := facades.NewMetricGRPCFacade(addr, "", nil)
+	require.NoError(t, err)
+	defer facade.Close()
+
+	delta := int64(2)
+	labels := types.NewLabels(map[string]string{"host": "grpc"})
+	metric := &types.Metrics{ID: "GRPCAppHits", Type: types.Counter, Labels: labels, Delta: &delta}
+	for range 2 {
+		require.NoError(t, facade.Update(context.Background(), []*types.Metrics{metric}))
+	}
+
+	rec := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/value/counter/GRPCAppHits?labels=host=grpc", nil))
+	assert.Equal(t, "4", rec.Body.String())
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewMetricServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got, err := client.GetMetric(ctx, &pb.GetMetricRequest{Id: &pb.MetricID{Id: "GRPCAppHits", Type: types.Counter, Labels: labels.Map()}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), got.GetMetric().GetDelta())
+
+	list, err := client.ListMetrics(ctx, &pb.ListMetricsRequest{Labels: labels.Map()})
+	require.NoError(t, err)
+	require.Len(t, list.GetMetrics(), 1)
+	assert.Equal(t, "GRPCAppHits", list.GetMetrics()[0].GetId())
+
+	_, err = client.GetMetric(ctx, &pb.GetMetricRequest{Id: &pb.MetricID{Id: "GRPCAppMissing", Type: types.Counter}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Updates without the real IP of the agent are rejected
+	_, err = client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{pb.NewMetric(metric)}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
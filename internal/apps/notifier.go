@@ -0,0 +1,84 @@
+package apps
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/facades"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/workers"
+)
+
+// webhookRetryIntervals are the delays between retries of a failed webhook delivery.
+var webhookRetryIntervals = []time.Duration{time.Second, 3 * time.Second, 5 * time.Second}
+
+// NotifierApp represents the alert notification delivery.
+//
+// It posts firing and resolved alerts to the configured webhooks as they change,
+// and periodically repeats the alerts that keep firing.
+// Implements the Runnable interface for lifecycle management.
+type NotifierApp struct {
+	worker *workers.AlertNotifyWorker
+}
+
+// NewNotifierApp initializes and returns a new NotifierApp.
+//
+// It creates an AlertWebhookFacade posting to the configured webhook URLs and
+// a worker delivering the alert changes received from changes as well as the alerts
+// returned by the provided lister.
+//
+// Parameters:
+//   - config: ServerConfig containing the webhook URLs and notification intervals.
+//   - alerts: Source of the current alert states, usually the ServerApp's alert service.
+//   - changes: Alerts whose state changed, usually the ServerApp's alert changes; may be nil.
+//
+// Returns:
+//   - Pointer to a NotifierApp instance ready to be started.
+//   - An error if initialization fails (currently always nil).
+func NewNotifierApp(
+	config *configs.ServerConfig,
+	alerts workers.AlertNotifyLister,
+	changes <-chan types.Alert,
+) (*NotifierApp, error) {
+	alertWebhookFacade := facades.NewAlertWebhookFacade(config.WebhookURLs, webhookRetryIntervals)
+
+	worker := workers.NewAlertNotifyWorker(
+		alerts,
+		changes,
+		alertWebhookFacade,
+		config.NotifyInterval,
+		config.NotifyRepeat,
+	)
+
+	return &NotifierApp{worker: worker}, nil
+}
+
+// Start launches the notification worker.
+//
+// This method blocks until the provided context is canceled.
+// It satisfies the Runnable interface.
+//
+// Parameters:
+//   - ctx: Context to control cancellation of the worker.
+//
+// Returns:
+//   - An error if the worker exits unexpectedly (currently always nil).
+func (app *NotifierApp) Start(ctx context.Context) error {
+	app.worker.Start(ctx)
+	return nil
+}
+
+// Stop performs cleanup or shutdown of the notifier.
+//
+// For NotifierApp, Stop is a no-op because the worker respects context cancellation.
+// It satisfies the Runnable interface.
+//
+// Parameters:
+//   - ctx: Context to control timeout or cancellation.
+//
+// Returns:
+//   - An error if shutdown fails (currently always nil).
+func (app *NotifierApp) Stop(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,163 @@
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotifierApp(t *testing.T) {
+	cfg := &configs.ServerConfig{
+		WebhookURLs:    []string{"http://localhost:9999/hook"},
+		NotifyInterval: 10,
+		NotifyRepeat:   3600,
+	}
+
+	app, err := NewNotifierApp(cfg, nil, nil)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, app)
+	assert.NotNil(t, app.worker)
+}
+
+func TestNotifierApp_DeliversServerAlerts(t *testing.T) {
+	received := make(chan types.Alert, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert types.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err == nil {
+			received <- alert
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - name: HighLoad\n    expr: gauge Load > 1\n"), 0o644))
+
+	cfg := &configs.ServerConfig{
+		Address:        "127.0.0.1:0",
+		AlertRulesPath: path,
+		AlertInterval:  1,
+		WebhookURLs:    []string{receiver.URL},
+		NotifyInterval: 1,
+		NotifyRepeat:   3600,
+	}
+
+	server, err := NewServerApp(cfg)
+	require.NoError(t, err)
+	notifier, err := NewNotifierApp(cfg, server.AlertService(), server.AlertChanges())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		err := server.Start(ctx)
+		assert.ErrorIs(t, err, http.ErrServerClosed)
+	}()
+	go func() {
+		assert.NoError(t, notifier.Start(ctx))
+	}()
+
+	rec := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/update/gauge/Load/2", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	select {
+	case alert := <-received:
+		assert.Equal(t, "HighLoad", alert.Rule)
+		assert.Equal(t, types.AlertFiring, alert.State)
+		assert.Equal(t, types.MetricID{ID: "Load", Type: types.Gauge}, alert.MetricID)
+		require.NotNil(t, alert.Value)
+		assert.Equal(t, 2.0, *alert.Value)
+		assert.NotNil(t, alert.FiredAt)
+	case <-time.After(5 * time.Second):
+		t.Fatal("firing alert was not delivered to the webhook")
+	}
+
+	cancel()
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), time.Second)
+	defer stopCancel()
+	require.NoError(t, notifier.Stop(stopCtx))
+	require.NoError(t, server.Stop(stopCtx))
+}
+
+func TestNotifierApp_DeliversChangesBetweenChecks(t *testing.T) {
+	received := make(chan types.Alert, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert types.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err == nil {
+			received <- alert
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - name: HighLoad\n    expr: gauge Load > 1\n"), 0o644))
+
+	// Alerts are checked far less often than they change, so only the changes deliver them
+	cfg := &configs.ServerConfig{
+		Address:        "127.0.0.1:0",
+		AlertRulesPath: path,
+		AlertInterval:  1,
+		WebhookURLs:    []string{receiver.URL},
+		NotifyInterval: 3600,
+	}
+
+	server, err := NewServerApp(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, server.AlertChanges())
+	notifier, err := NewNotifierApp(cfg, server.AlertService(), server.AlertChanges())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		err := server.Start(ctx)
+		assert.ErrorIs(t, err, http.ErrServerClosed)
+	}()
+	go func() {
+		assert.NoError(t, notifier.Start(ctx))
+	}()
+
+	update := func(path string) {
+		rec := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	for _, step := range []struct {
+		path  string
+		state string
+	}{
+		{path: "/update/gauge/Load/2", state: types.AlertFiring},
+		{path: "/update/gauge/Load/0", state: types.AlertResolved},
+	} {
+		update(step.path)
+		select {
+		case alert := <-received:
+			assert.Equal(t, "HighLoad", alert.Rule)
+			assert.Equal(t, step.state, alert.State)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s alert was not delivered to the webhook", step.state)
+		}
+	}
+
+	cancel()
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), time.Second)
+	defer stopCancel()
+	require.NoError(t, notifier.Stop(stopCtx))
+	require.NoError(t, server.Stop(stopCtx))
+}
@@ -0,0 +1,105 @@
+package apps
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+)
+
+// ReloadReconfigurer defines the interface of the applications a ReloadApp applies
+// reloaded configurations to.
+type ReloadReconfigurer interface {
+	// PrepareReconfigure checks the settings of the configuration that can change at runtime
+	// and returns a function applying them. Applying cannot fail, so the settings of every
+	// target are checked before any of them is applied.
+	PrepareReconfigure(config *configs.ServerConfig) (func(), error)
+}
+
+// ReloadApp reloads the server configuration while the server runs.
+//
+// It does nothing until it is asked to reload, usually on SIGHUP, at which point it
+// re-reads the configuration and applies the settings that can change at runtime to the
+// running applications. Implements the Reloadable interface for lifecycle management.
+type ReloadApp struct {
+	config  *configs.ServerConfig
+	load    func() (*configs.ServerConfig, error)
+	targets []ReloadReconfigurer
+}
+
+// NewReloadApp initializes and returns a new ReloadApp.
+//
+// Parameters:
+//   - config: ServerConfig the applications were started with; it is not modified.
+//   - load: Function re-reading the configuration from its source, e.g. flags, environment and file.
+//   - targets: Applications reloaded settings are applied to, in order, usually the ServerApp and GRPCServerApp.
+//
+// Returns:
+//   - Pointer to a ReloadApp instance ready to be started.
+func NewReloadApp(
+	config *configs.ServerConfig,
+	load func() (*configs.ServerConfig, error),
+	targets ...ReloadReconfigurer,
+) *ReloadApp {
+	return &ReloadApp{config: config, load: load, targets: targets}
+}
+
+// Start blocks until the context is done.
+//
+// It satisfies the Runnable interface.
+func (app *ReloadApp) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Stop does nothing; there is nothing to release.
+//
+// It satisfies the Runnable interface.
+func (app *ReloadApp) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Reload re-reads the configuration and applies the log level and the settings the targets
+// can change at runtime: the signing key, trusted subnet, alert rules and snapshot interval.
+// Changed settings that only take effect after a restart are logged as such.
+//
+// Every setting is checked before any is applied, so a reload either applies all of them
+// or, if one is invalid, leaves the running configuration unchanged.
+//
+// It satisfies the Reloadable interface.
+//
+// Returns an error if the configuration cannot be read or a setting is invalid.
+func (app *ReloadApp) Reload(ctx context.Context) error {
+	config, err := app.load()
+	if err != nil {
+		return err
+	}
+
+	level, err := zap.ParseAtomicLevel(config.LogLevel)
+	if err != nil {
+		return err
+	}
+	applies := make([]func(), 0, len(app.targets))
+	for _, target := range app.targets {
+		apply, err := target.PrepareReconfigure(config)
+		if err != nil {
+			return err
+		}
+		applies = append(applies, apply)
+	}
+
+	logger.AtomicLevel().SetLevel(level.Level())
+	for _, apply := range applies {
+		apply()
+	}
+
+	for _, field := range app.config.RestartFields(config) {
+		logger.Log.Warnf("Config field %s changed; restart the server to apply it", field)
+	}
+	running := app.config.Reloaded(config)
+	app.config = &running
+	logger.Log.Infof("Server config reloaded: %+v", running.Masked())
+	return nil
+}
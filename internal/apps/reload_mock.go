@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/apps/reload.go
+
+// Package apps is a generated GoMock package.
+package apps
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	configs "github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+)
+
+// MockReloadReconfigurer is a mock of ReloadReconfigurer interface.
+type MockReloadReconfigurer struct {
+	ctrl     *gomock.Controller
+	recorder *MockReloadReconfigurerMockRecorder
+}
+
+// MockReloadReconfigurerMockRecorder is the mock recorder for MockReloadReconfigurer.
+type MockReloadReconfigurerMockRecorder struct {
+	mock *MockReloadReconfigurer
+}
+
+// NewMockReloadReconfigurer creates a new mock instance.
+func NewMockReloadReconfigurer(ctrl *gomock.Controller) *MockReloadReconfigurer {
+	mock := &MockReloadReconfigurer{ctrl: ctrl}
+	mock.recorder = &MockReloadReconfigurerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReloadReconfigurer) EXPECT() *MockReloadReconfigurerMockRecorder {
+	return m.recorder
+}
+
+// PrepareReconfigure mocks base method.
+func (m *MockReloadReconfigurer) PrepareReconfigure(config *configs.ServerConfig) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareReconfigure", config)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrepareReconfigure indicates an expected call of PrepareReconfigure.
+func (mr *MockReloadReconfigurerMockRecorder) PrepareReconfigure(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareReconfigure", reflect.TypeOf((*MockReloadReconfigurer)(nil).PrepareReconfigure), config)
+}
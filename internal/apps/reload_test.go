@@ -0,0 +1,200 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestReloadApp_AppliesRuntimeSettings(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte("rules:\n  - name: HighLoad\n    expr: gauge Load > 1\n"), 0o644))
+
+	cfg := &configs.ServerConfig{Address: "127.0.0.1:0", GRPCAddress: "127.0.0.1:0", LogLevel: "info"}
+	server, err := NewServerApp(cfg)
+	require.NoError(t, err)
+	grpcServer, err := NewGRPCServerApp(cfg, server.UpdateService(), server.GetService(), server.ListService())
+	require.NoError(t, err)
+	go func() {
+		assert.NoError(t, grpcServer.Start(context.Background()))
+	}()
+	defer grpcServer.Stop(context.Background())
+
+	next := *cfg
+	load := func() (*configs.ServerConfig, error) {
+		reloaded := next
+		return &reloaded, nil
+	}
+	reload := NewReloadApp(cfg, load, server, grpcServer)
+
+	update := func(realIP string) int {
+		req := httptest.NewRequest(http.MethodPost, "/update/gauge/ReloadLoad/1", nil)
+		if realIP != "" {
+			req.Header.Set("X-Real-IP", realIP)
+		}
+		rec := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	alerts := func() string {
+		rec := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/alerts", nil))
+		return rec.Body.String()
+	}
+
+	conn, err := grpc.NewClient(grpcServer.listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewMetricServiceClient(conn)
+	value := 1.0
+	grpcUpdate := func() codes.Code {
+		_, err := client.UpdateMetrics(context.Background(), &pb.UpdateMetricsRequest{
+			Metrics: []*pb.Metric{pb.NewMetric(&types.Metrics{ID: "ReloadLoad", Type: types.Gauge, Value: &value})},
+		})
+		return status.Code(err)
+	}
+
+	assert.Equal(t, http.StatusOK, update(""))
+	assert.Equal(t, codes.OK, grpcUpdate())
+	assert.NotContains(t, alerts(), "HighLoad")
+
+	next.LogLevel = "debug"
+	next.TrustedSubnet = "10.0.0.0/24"
+	next.AlertRulesPath = rulesPath
+	next.Address = "127.0.0.1:1" // requires a restart and is only reported
+	require.NoError(t, reload.Reload(context.Background()))
+
+	assert.Equal(t, http.StatusForbidden, update(""))
+	assert.Equal(t, http.StatusOK, update("10.0.0.7"))
+	assert.Equal(t, codes.PermissionDenied, grpcUpdate())
+	assert.Contains(t, alerts(), "HighLoad")
+
+	// An invalid setting leaves the running configuration as is
+	next.LogLevel = "warn"
+	next.TrustedSubnet = "10.0.0.0"
+	assert.Error(t, reload.Reload(context.Background()))
+	assert.Equal(t, http.StatusForbidden, update(""))
+	assert.Equal(t, http.StatusOK, update("10.0.0.7"))
+}
+
+func TestReloadApp_Reload_Errors(t *testing.T) {
+	cfg := &configs.ServerConfig{LogLevel: "info"}
+
+	tests := []struct {
+		name    string
+		loadErr error
+		level   string
+	}{
+		{name: "config cannot be loaded", loadErr: errors.New("invalid config file")},
+		{name: "invalid log level", level: "loud"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Targets are not reconfigured
+			target := NewMockReloadReconfigurer(ctrl)
+			load := func() (*configs.ServerConfig, error) {
+				if tt.loadErr != nil {
+					return nil, tt.loadErr
+				}
+				return &configs.ServerConfig{LogLevel: tt.level}, nil
+			}
+
+			err := NewReloadApp(cfg, load, target).Reload(context.Background())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReloadApp_Reload_TargetError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	level := logger.AtomicLevel()
+	defer level.SetLevel(level.Level())
+	level.SetLevel(zap.InfoLevel)
+
+	wantErr := errors.New("reconfigure error")
+	first := NewMockReloadReconfigurer(ctrl)
+	second := NewMockReloadReconfigurer(ctrl)
+	// The settings of the first target are checked but not applied
+	first.EXPECT().PrepareReconfigure(gomock.Any()).Return(func() {
+		t.Error("settings applied although the reload failed")
+	}, nil)
+	second.EXPECT().PrepareReconfigure(gomock.Any()).Return(nil, wantErr)
+
+	load := func() (*configs.ServerConfig, error) {
+		return &configs.ServerConfig{LogLevel: "debug"}, nil
+	}
+
+	cfg := &configs.ServerConfig{LogLevel: "info"}
+	app := NewReloadApp(cfg, load, first, second)
+	err := app.Reload(context.Background())
+	assert.ErrorIs(t, err, wantErr)
+	assert.Equal(t, zap.InfoLevel, level.Level())
+	assert.Same(t, cfg, app.config)
+}
+
+func TestReloadApp_Reload_KeepsRunningConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	level := logger.AtomicLevel()
+	defer level.SetLevel(level.Level())
+
+	applied := 0
+	target := NewMockReloadReconfigurer(ctrl)
+	target.EXPECT().PrepareReconfigure(gomock.Any()).Return(func() { applied++ }, nil).Times(2)
+
+	next := configs.ServerConfig{Address: ":9090", LogLevel: "debug", Key: "new"}
+	load := func() (*configs.ServerConfig, error) {
+		reloaded := next
+		return &reloaded, nil
+	}
+
+	cfg := &configs.ServerConfig{Address: ":8080", LogLevel: "info", Key: "old"}
+	app := NewReloadApp(cfg, load, target)
+	require.NoError(t, app.Reload(context.Background()))
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, zap.DebugLevel, level.Level())
+
+	// The reloaded settings are kept, while the address still awaits a restart
+	assert.Equal(t, &configs.ServerConfig{Address: ":8080", LogLevel: "debug", Key: "new"}, app.config)
+	assert.Equal(t, []string{"Address"}, app.config.RestartFields(&next))
+	assert.Equal(t, "info", cfg.LogLevel)
+
+	next.LogLevel = "warn"
+	require.NoError(t, app.Reload(context.Background()))
+	assert.Equal(t, 2, applied)
+	assert.Equal(t, "warn", app.config.LogLevel)
+}
+
+func TestReloadApp_StartAndStop(t *testing.T) {
+	app := NewReloadApp(&configs.ServerConfig{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, app.Start(ctx))
+	assert.NoError(t, app.Stop(context.Background()))
+}
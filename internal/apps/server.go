@@ -5,17 +5,39 @@ package apps
 
 import (
 	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.etcd.io/bbolt"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/cryptos"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/databases"
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/handlers"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/middlewares"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/repositories"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/routers"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/services"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/validators"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/workers"
 )
 
+// databaseSetupTimeout bounds connecting to the database and migrating its schema on start.
+const databaseSetupTimeout = 30 * time.Second
+
+// alertChangesBuffer is the number of alert changes waiting to be read by the notifications
+// beyond which the evaluation of alert rules drops further changes.
+const alertChangesBuffer = 64
+
 // ServerApp represents the HTTP server application.
 //
 // It encapsulates the full setup and lifecycle of the server, including
@@ -23,29 +45,209 @@ import (
 //
 // This struct is intended to be managed by a runner that supports the Runnable interface.
 type ServerApp struct {
-	server *http.Server
+	server         *http.Server
+	handler        *swapHandler
+	newRouter      func(key string, trustedSubnet *net.IPNet) http.Handler
+	snapshotWorker *workers.MetricSnapshotWorker // nil when file persistence is disabled
+	wal            *repositories.MetricWALStore  // nil when the write-ahead log is disabled
+	alertWorker    *workers.AlertEvaluateWorker
+	alertService   *services.AlertService
+	alertChanges   chan types.Alert // nil when alerts are not notified
+	updateService  *services.MetricUpdateService
+	getService     *services.MetricGetService
+	listService    *services.MetricListService
+	restore        bool
+	db             *sqlx.DB  // nil unless metrics are kept in PostgreSQL
+	boltDB         *bbolt.DB // nil unless metrics are kept in the embedded database
 }
 
 // NewServerApp creates and initializes a new instance of ServerApp using the provided server configuration.
 //
 // This function wires together repositories, services, validators, handlers, middleware, and the router.
+// With the embedded storage, metrics are stored in an embedded database in the data directory.
+// Otherwise, when a database DSN is configured, metrics are stored in PostgreSQL and the schema
+// is created on startup; without one they are kept in memory with optional file persistence
+// and an optional write-ahead log of updates.
+// Accepted updates are recorded in an in-memory metric history for the configured retention period;
+// the history is not persisted with any storage and starts empty on every start.
+// When an alert rules file is configured, its rules are loaded and evaluated periodically.
+// When a crypto key is configured, request bodies are decrypted with that RSA private key.
+// When a trusted subnet is configured, updates and log level changes from other addresses are rejected.
+// The log level can be read and changed at /admin/loglevel.
+// The signing key, trusted subnet, alert rules and snapshot interval can later be changed
+// with PrepareReconfigure.
 //
 // Parameters:
-//   - config: Pointer to a ServerConfig that defines the server address and log level.
+//   - config: Pointer to a ServerConfig that defines the server address, log level and storage options.
 //
 // Returns:
 //   - A pointer to a ServerApp instance ready to be started.
 //   - An error, if any setup fails.
 func NewServerApp(config *configs.ServerConfig) (*ServerApp, error) {
+	// Load the private key and the trusted subnet first, so that bad settings are reported before any storage is opened
+	if err := checkStorage(config); err != nil {
+		return nil, err
+	}
+	trustedSubnet, err := parseTrustedSubnet(config.TrustedSubnet)
+	if err != nil {
+		return nil, err
+	}
+	var privateKey *rsa.PrivateKey
+	if config.CryptoKey != "" {
+		privateKey, err = cryptos.LoadPrivateKey(config.CryptoKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize repositories
-	metricMemorySaveRepository := repositories.NewMetricMemorySaveRepository()
-	metricMemoryGetRepository := repositories.NewMetricMemoryGetRepository()
-	metricMemoryListRepository := repositories.NewMetricMemoryListRepository()
+	var (
+		metricSaveRepository      services.MetricUpdateSaver
+		metricGetRepository       services.MetricGetGetter
+		metricGetListRepository   services.MetricGetLister
+		metricUpsertRepository    services.MetricUpdateUpserter
+		metricListRepository      services.MetricListLister
+		metricIncrementRepository services.MetricUpdateIncrementer
+		metricTransactor          services.MetricUpdateTransactor
+		snapshotWorker            *workers.MetricSnapshotWorker
+		wal                       *repositories.MetricWALStore
+		db                        *sqlx.DB
+		boltDB                    *bbolt.DB
+	)
+
+	if config.Storage == configs.StorageEmbedded {
+		boltDB, err = databases.NewBoltDB(config.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		if err := databases.MigrateBolt(boltDB); err != nil {
+			boltDB.Close()
+			return nil, err
+		}
+
+		// The embedded database is a MetricStore, so the store repositories work on top of it
+		metricStore := repositories.NewMetricBoltStore(boltDB)
+		metricSaveRepository = repositories.NewMetricMemorySaveRepository(metricStore)
+		metricGetRepository = repositories.NewMetricMemoryGetRepository(metricStore)
+		metricGetListRepository = repositories.NewMetricMemoryGetRepository(metricStore)
+		metricUpsertRepository = repositories.NewMetricMemoryUpsertRepository(metricStore)
+		metricListRepository = repositories.NewMetricMemoryListRepository(metricStore)
+		metricIncrementRepository = repositories.NewMetricMemoryIncrementRepository(metricStore)
+		// Batches run in a single transaction of the embedded database, committed and synced once
+		metricTransactor = databases.NewBoltTransactor(boltDB)
+	} else if config.DatabaseDSN != "" {
+		// Connecting and migrating are bounded, so an unreachable database fails the start instead of hanging it
+		dbCtx, cancel := context.WithTimeout(context.Background(), databaseSetupTimeout)
+		defer cancel()
+
+		db, err = databases.NewPostgresDB(dbCtx, config.DatabaseDSN)
+		if err != nil {
+			return nil, err
+		}
+		if err := databases.MigratePostgres(dbCtx, db); err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		metricSaveRepository = repositories.NewMetricDBSaveRepository(db)
+		metricGetRepository = repositories.NewMetricDBGetRepository(db)
+		metricGetListRepository = repositories.NewMetricDBGetRepository(db)
+		metricUpsertRepository = repositories.NewMetricDBUpsertRepository(db)
+		metricListRepository = repositories.NewMetricDBListRepository(db)
+		metricIncrementRepository = repositories.NewMetricDBIncrementRepository(db)
+		metricTransactor = databases.NewTransactor(db)
+	} else {
+		// Every server gets its own store, so several servers can run in one process;
+		// the store is sharded, so writers of different metrics rarely wait for each other
+		baseStore := repositories.NewMetricShardedStore(repositories.DefaultMetricStoreShards)
+
+		// Updates go through the write-ahead log, if configured, before they reach the store
+		var metricStore repositories.MetricStore = baseStore
+		var metricLog workers.MetricSnapshotCheckpointer
+		if config.WALPath != "" {
+			wal, err = repositories.NewMetricWALStore(
+				baseStore,
+				config.WALPath,
+				time.Duration(config.WALSyncInterval)*time.Millisecond,
+			)
+			if err != nil {
+				return nil, err
+			}
+			metricStore = wal
+			metricLog = wal
+		}
+
+		// Batches lock the metrics they touch until they end and are rolled back if they fail
+		txStore := repositories.NewMetricTxStore(metricStore)
+		metricStore = txStore
+		metricTransactor = txStore
+
+		metricMemoryListRepository := repositories.NewMetricMemoryListRepository(metricStore)
+
+		metricSaveRepository = repositories.NewMetricMemorySaveRepository(metricStore)
+		metricGetRepository = repositories.NewMetricMemoryGetRepository(metricStore)
+		metricGetListRepository = repositories.NewMetricMemoryGetRepository(metricStore)
+		metricUpsertRepository = repositories.NewMetricMemoryUpsertRepository(metricStore)
+		metricListRepository = metricMemoryListRepository
+		metricIncrementRepository = repositories.NewMetricMemoryIncrementRepository(metricStore)
+
+		// Initialize file persistence if a snapshot path is configured; the snapshot is restored
+		// into the store directly, as its metrics are not to be recorded in the log again
+		if config.FileStoragePath != "" {
+			snapshotWorker = workers.NewMetricSnapshotWorker(
+				metricMemoryListRepository,
+				repositories.NewMetricMemorySaveRepository(baseStore),
+				repositories.NewMetricFileListRepository(config.FileStoragePath),
+				repositories.NewMetricFileSaveRepository(config.FileStoragePath),
+				metricLog,
+				config.StoreInterval,
+			)
+		}
+	}
+
+	// Initialize the metric history; it is kept in memory regardless of the storage, so it is lost on restart
+	var metricHistoryAppender services.MetricUpdateHistoryAppender
+	metricHistoryRepository := repositories.NewMetricHistoryMemoryRepository(
+		time.Duration(config.HistoryRetention) * time.Second,
+	)
+	if config.HistoryRetention > 0 {
+		metricHistoryAppender = metricHistoryRepository
+	}
 
 	// Initialize services
-	metricUpdateService := services.NewMetricUpdateService(metricMemorySaveRepository, metricMemoryGetRepository)
-	metricGetService := services.NewMetricGetService(metricMemoryGetRepository)
-	metricListService := services.NewMetricListService(metricMemoryListRepository)
+	metricUpdateService := services.NewMetricUpdateService(
+		metricSaveRepository,
+		metricUpsertRepository,
+		metricIncrementRepository,
+		metricTransactor,
+		metricHistoryAppender,
+	)
+	metricGetService := services.NewMetricGetService(metricGetRepository, metricGetListRepository)
+	metricListService := services.NewMetricListService(metricListRepository)
+	metricQueryRangeService := services.NewMetricQueryRangeService(metricHistoryRepository)
+
+	// Initialize alerting; without a rules file there is nothing to evaluate until rules are reloaded
+	alertRules, err := loadAlertRules(config.AlertRulesPath)
+	if err != nil {
+		if db != nil {
+			db.Close()
+		}
+		if wal != nil {
+			wal.Close()
+		}
+		if boltDB != nil {
+			boltDB.Close()
+		}
+		return nil, err
+	}
+	alertService := services.NewAlertService(metricListRepository, alertRules)
+
+	// Alert changes are published to the notifications, if any, so that changes between checks are not missed
+	var alertChanges chan types.Alert
+	if len(config.WebhookURLs) > 0 && config.NotifyInterval > 0 {
+		alertChanges = make(chan types.Alert, alertChangesBuffer)
+	}
+	alertWorker := workers.NewAlertEvaluateWorker(alertService, config.AlertInterval, alertChanges)
 
 	// Initialize handlers with validation
 	metricUpdatePathHandler := handlers.NewMetricUpdatePathHandler(
@@ -56,6 +258,10 @@ func NewServerApp(config *configs.ServerConfig) (*ServerApp, error) {
 		validators.ValidateMetric,
 		metricUpdateService,
 	)
+	metricUpdateBatchBodyHandler := handlers.NewMetricUpdateBatchBodyHandler(
+		validators.ValidateMetric,
+		metricUpdateService,
+	)
 	metricGetPathHandler := handlers.NewMetricGetPathHandler(
 		validators.ValidateMetricIDAttributes,
 		metricGetService,
@@ -64,37 +270,193 @@ func NewServerApp(config *configs.ServerConfig) (*ServerApp, error) {
 		validators.ValidateMetricID,
 		metricGetService,
 	)
-	metricListHTMLHandler := handlers.NewMetricListHTMLHandler(metricListService)
-
-	// Register middleware
-	middlewareList := []func(http.Handler) http.Handler{
-		middlewares.LoggingMiddleware,
-		middlewares.GzipMiddleware,
-	}
-
-	// Set up router
-	metricRouter := routers.NewMetricRouter(
-		metricUpdatePathHandler,
-		metricUpdateBodyHandler,
-		metricGetPathHandler,
-		metricGetBodyHandler,
-		metricListHTMLHandler,
-		middlewareList...,
+	metricListHTMLHandler := handlers.NewMetricListHTMLHandler(metricListService, alertService)
+	metricListPrometheusHandler := handlers.NewMetricListPrometheusHandler(metricListService)
+	metricQueryRangeHandler := handlers.NewMetricQueryRangeHandler(
+		validators.ValidateMetricIDAttributes,
+		metricQueryRangeService,
 	)
+	alertListHandler := handlers.NewAlertListHandler(alertService)
+	logLevelGetHandler := handlers.NewLogLevelGetHandler(logger.AtomicLevel())
+	logLevelSetHandler := handlers.NewLogLevelSetHandler(logger.AtomicLevel())
+
+	// Set up the router; it is rebuilt whenever the signing key or the trusted subnet is reloaded
+	newRouter := func(key string, trustedSubnet *net.IPNet) http.Handler {
+		// Updates and log level changes are only accepted from the trusted subnet, if any
+		trustedSubnetMiddleware := middlewares.NewTrustedSubnetMiddleware(trustedSubnet)
+
+		// The log level can only be changed if a trusted subnet restricts who may change it
+		var logLevelSet http.HandlerFunc
+		if trustedSubnet != nil {
+			logLevelSet = trustedSubnetMiddleware(logLevelSetHandler).ServeHTTP
+		}
+
+		// Metrics in update bodies may carry their own signatures, which are checked as well
+		metricHashMiddleware := middlewares.NewMetricHashMiddleware(key)
+
+		// Register middleware; bodies are encrypted after compression, so decryption goes before gzip,
+		// and signatures are checked on the decompressed body, so hashing goes after gzip
+		middlewareList := []func(http.Handler) http.Handler{
+			middlewares.LoggingMiddleware,
+			middlewares.NewDecryptMiddleware(privateKey),
+			middlewares.GzipMiddleware,
+			middlewares.NewHashMiddleware(key),
+		}
+
+		return routers.NewMetricRouter(
+			trustedSubnetMiddleware(metricUpdatePathHandler).ServeHTTP,
+			trustedSubnetMiddleware(metricHashMiddleware(metricUpdateBodyHandler)).ServeHTTP,
+			trustedSubnetMiddleware(metricHashMiddleware(metricUpdateBatchBodyHandler)).ServeHTTP,
+			metricGetPathHandler,
+			metricGetBodyHandler,
+			metricListHTMLHandler,
+			metricListPrometheusHandler,
+			metricQueryRangeHandler,
+			alertListHandler,
+			logLevelGetHandler,
+			logLevelSet,
+			middlewareList...,
+		)
+	}
+	handler := newSwapHandler(newRouter(config.Key, trustedSubnet))
 
 	// Create HTTP server
 	httpServer := &http.Server{
 		Addr:    config.Address,
-		Handler: metricRouter,
+		Handler: handler,
 	}
 
 	return &ServerApp{
-		server: httpServer,
+		server:         httpServer,
+		handler:        handler,
+		newRouter:      newRouter,
+		snapshotWorker: snapshotWorker,
+		wal:            wal,
+		alertWorker:    alertWorker,
+		alertService:   alertService,
+		alertChanges:   alertChanges,
+		updateService:  metricUpdateService,
+		getService:     metricGetService,
+		listService:    metricListService,
+		restore:        config.Restore,
+		db:             db,
+		boltDB:         boltDB,
+	}, nil
+}
+
+// loadAlertRules reads the alert rules from the given file. An empty path gives no rules.
+func loadAlertRules(path string) ([]types.AlertRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return repositories.NewAlertRuleFileListRepository(path).List(context.Background())
+}
+
+// checkStorage checks that the configured storage backend is known and does not conflict with the database DSN,
+// and that the write-ahead log, which only records in-memory updates, comes with the snapshot it is truncated by.
+func checkStorage(config *configs.ServerConfig) error {
+	if config.WALPath != "" {
+		switch {
+		case config.DatabaseDSN != "":
+			return fmt.Errorf("%w: the write-ahead log cannot be used with a database DSN", internalErrors.ErrStorageInvalid)
+		case config.Storage == configs.StorageEmbedded:
+			return fmt.Errorf("%w: the write-ahead log cannot be used with %s storage", internalErrors.ErrStorageInvalid, config.Storage)
+		case config.FileStoragePath == "":
+			return fmt.Errorf("%w: the write-ahead log requires a file storage path to snapshot into", internalErrors.ErrStorageInvalid)
+		}
+	}
+
+	switch config.Storage {
+	case "", configs.StorageMemory:
+		return nil
+	case configs.StorageEmbedded:
+		if config.DatabaseDSN != "" {
+			return fmt.Errorf("%w: %s storage cannot be used with a database DSN", internalErrors.ErrStorageInvalid, config.Storage)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown storage %q", internalErrors.ErrStorageInvalid, config.Storage)
+	}
+}
+
+// parseTrustedSubnet parses the CIDR of the trusted subnet. An empty CIDR gives a nil subnet.
+func parseTrustedSubnet(cidr string) (*net.IPNet, error) {
+	if cidr == "" {
+		return nil, nil
+	}
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted subnet %q: %w", cidr, err)
+	}
+	return subnet, nil
+}
+
+// PrepareReconfigure checks the settings of the given configuration that can change while
+// the server runs: the signing key, the trusted subnet, the alert rules, which are read
+// from the rules file now, and the snapshot interval. It returns a function applying them;
+// requests in flight then finish with the previous settings and connections are kept open.
+//
+// Other settings are ignored; see configs.ServerConfig.RestartFields.
+//
+// Returns an error if the trusted subnet or the alert rules are invalid.
+func (app *ServerApp) PrepareReconfigure(config *configs.ServerConfig) (func(), error) {
+	trustedSubnet, err := parseTrustedSubnet(config.TrustedSubnet)
+	if err != nil {
+		return nil, err
+	}
+	alertRules, err := loadAlertRules(config.AlertRulesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		app.handler.Store(app.newRouter(config.Key, trustedSubnet))
+		app.alertService.SetRules(alertRules)
+		if app.snapshotWorker != nil {
+			app.snapshotWorker.SetStoreInterval(config.StoreInterval)
+		}
 	}, nil
 }
 
+// AlertService returns the service holding the state of the server's alert rules.
+// It allows other runnables, such as the NotifierApp, to observe the alerts.
+func (app *ServerApp) AlertService() *services.AlertService {
+	return app.alertService
+}
+
+// AlertChanges returns the channel the alerts whose state changed are published to.
+// It is nil unless webhooks and a notification interval are configured, and must then
+// be read by the NotifierApp for as long as the server runs.
+func (app *ServerApp) AlertChanges() <-chan types.Alert {
+	return app.alertChanges
+}
+
+// UpdateService returns the service applying metric updates to the server's storage.
+// It allows other runnables, such as the StatsDApp, to ingest metrics.
+func (app *ServerApp) UpdateService() *services.MetricUpdateService {
+	return app.updateService
+}
+
+// GetService returns the service fetching single metrics from the server's storage.
+// It allows other runnables, such as the GRPCServerApp, to serve metrics.
+func (app *ServerApp) GetService() *services.MetricGetService {
+	return app.getService
+}
+
+// ListService returns the service listing the metrics of the server's storage.
+// It allows other runnables, such as the GRPCServerApp, to serve metrics.
+func (app *ServerApp) ListService() *services.MetricListService {
+	return app.listService
+}
+
 // Start runs the HTTP server and blocks until it shuts down or encounters an error.
 //
+// If file persistence is enabled, metrics are restored from the snapshot file
+// (when configured to) before the server starts serving, and periodic snapshots
+// run in the background until the context is canceled. The write-ahead log, if any,
+// is then replayed over the snapshot; when metrics are not restored, it is emptied instead. Alert rules, if any,
+// are evaluated in the background as well.
+//
 // This method satisfies the Runnable interface.
 //
 // Parameters:
@@ -103,19 +465,103 @@ func NewServerApp(config *configs.ServerConfig) (*ServerApp, error) {
 // Returns:
 //   - An error if the server fails to start or crashes during runtime.
 func (app *ServerApp) Start(ctx context.Context) error {
+	if app.snapshotWorker != nil && app.restore {
+		if err := app.snapshotWorker.Restore(ctx); err != nil {
+			return err
+		}
+	}
+	if app.wal != nil {
+		if err := app.replayWAL(ctx); err != nil {
+			return err
+		}
+	}
+	if app.snapshotWorker != nil {
+		go app.snapshotWorker.Start(ctx)
+	}
+	if app.alertWorker != nil {
+		go app.alertWorker.Start(ctx)
+	}
 	return app.server.ListenAndServe()
 }
 
+// replayWAL applies the updates recorded in the write-ahead log since the last snapshot,
+// or drops them if metrics are not restored on start.
+func (app *ServerApp) replayWAL(ctx context.Context) error {
+	if !app.restore {
+		return app.wal.Reset(ctx)
+	}
+
+	applied, skipped, err := app.wal.Replay(ctx)
+	if err != nil {
+		return err
+	}
+	if skipped > 0 {
+		logger.Log.Warnf("Skipped %d bytes of corrupted write-ahead log after %d valid records", skipped, applied)
+	}
+	logger.Log.Infof("Replayed %d metric updates from write-ahead log", applied)
+	return nil
+}
+
 // Stop gracefully shuts down the HTTP server using the provided context.
 //
 // This method satisfies the Runnable interface and allows the server to finish
-// processing ongoing requests before terminating.
+// processing ongoing requests before terminating. If file persistence is enabled,
+// a final snapshot is written once no more requests are being served. The write-ahead
+// log and the databases, if any, are closed last. Every step runs even if an earlier
+// one fails, so that nothing is left open.
 //
 // Parameters:
 //   - ctx: Context for controlling shutdown timeout and cancellation.
 //
 // Returns:
-//   - An error if shutdown fails or times out.
+//   - The errors of every failed step joined, or nil if all of them succeed.
 func (app *ServerApp) Stop(ctx context.Context) error {
-	return app.server.Shutdown(ctx)
+	var errs []error
+	if err := app.server.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if app.snapshotWorker != nil {
+		if err := app.snapshotWorker.Snapshot(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if app.wal != nil {
+		if err := app.wal.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if app.boltDB != nil {
+		if err := app.boltDB.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if app.db != nil {
+		if err := app.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// swapHandler is an http.Handler forwarding requests to a handler that can be replaced
+// while the server runs.
+type swapHandler struct {
+	current atomic.Pointer[http.Handler]
+}
+
+// newSwapHandler creates a swapHandler forwarding requests to the given handler.
+func newSwapHandler(handler http.Handler) *swapHandler {
+	h := &swapHandler{}
+	h.Store(handler)
+	return h
+}
+
+// Store replaces the handler requests are forwarded to.
+func (h *swapHandler) Store(handler http.Handler) {
+	h.current.Store(&handler)
+}
+
+// ServeHTTP forwards the request to the current handler.
+func (h *swapHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.current.Load()).ServeHTTP(w, r)
 }
@@ -0,0 +1,79 @@
+package apps
+
+import (
+	"context"
+	"net"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/validators"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/workers"
+)
+
+// StatsDApp represents the StatsD ingestion endpoint.
+//
+// It receives StatsD samples over UDP, aggregates them per flush interval and
+// feeds them to the metric update service. Implements the Runnable interface
+// for lifecycle management.
+type StatsDApp struct {
+	worker *workers.StatsDListenWorker
+}
+
+// NewStatsDApp initializes and returns a new StatsDApp.
+//
+// It starts listening on the configured UDP address right away, so that an unusable
+// address is reported before any runnable is started.
+//
+// Parameters:
+//   - config: ServerConfig containing the StatsD address and flush interval.
+//   - updater: Destination of the aggregated metrics, usually the ServerApp's update service.
+//
+// Returns:
+//   - Pointer to a StatsDApp instance ready to be started.
+//   - An error if the UDP address cannot be listened on.
+func NewStatsDApp(
+	config *configs.ServerConfig,
+	updater workers.StatsDUpdater,
+) (*StatsDApp, error) {
+	conn, err := net.ListenPacket("udp", config.StatsDAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	worker := workers.NewStatsDListenWorker(
+		conn,
+		updater,
+		validators.ValidateMetric,
+		config.StatsDFlush,
+	)
+
+	return &StatsDApp{worker: worker}, nil
+}
+
+// Start receives StatsD samples until the provided context is canceled,
+// then flushes the samples aggregated so far.
+//
+// It satisfies the Runnable interface.
+//
+// Parameters:
+//   - ctx: Context to control cancellation of the listener.
+//
+// Returns:
+//   - An error if the listener exits unexpectedly (currently always nil).
+func (app *StatsDApp) Start(ctx context.Context) error {
+	app.worker.Start(ctx)
+	return nil
+}
+
+// Stop performs cleanup or shutdown of the StatsD listener.
+//
+// For StatsDApp, Stop is a no-op because the worker closes its connection
+// once the context is canceled. It satisfies the Runnable interface.
+//
+// Parameters:
+//   - ctx: Context to control timeout or cancellation.
+//
+// Returns:
+//   - An error if shutdown fails (currently always nil).
+func (app *StatsDApp) Stop(ctx context.Context) error {
+	return nil
+}
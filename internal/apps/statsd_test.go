@@ -0,0 +1,60 @@
+package apps
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatsDApp_InvalidAddress(t *testing.T) {
+	_, err := NewStatsDApp(&configs.ServerConfig{StatsDAddress: "not an address"}, nil)
+	assert.Error(t, err)
+}
+
+func TestStatsDApp_FeedsServerMetrics(t *testing.T) {
+	cfg := &configs.ServerConfig{
+		Address:       "127.0.0.1:0",
+		StatsDAddress: "127.0.0.1:0",
+		StatsDFlush:   1,
+	}
+
+	server, err := NewServerApp(cfg)
+	require.NoError(t, err)
+	statsd, err := NewStatsDApp(cfg, server.UpdateService())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		assert.NoError(t, statsd.Start(ctx))
+	}()
+
+	client, err := net.Dial("udp", statsd.worker.LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Counters are accumulated across flushes like any other counter update
+	for _, packet := range []string{"StatsDAppHits:2|c\nStatsDAppLoad:1.5|g", "StatsDAppHits:3|c"} {
+		_, err = client.Write([]byte(packet))
+		require.NoError(t, err)
+		time.Sleep(1100 * time.Millisecond)
+	}
+
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+	assert.Eventually(t, func() bool {
+		return get("/value/counter/StatsDAppHits") == "5"
+	}, 2*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "1.5", get("/value/gauge/StatsDAppLoad"))
+}
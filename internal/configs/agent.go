@@ -2,13 +2,39 @@
 // for the agent application.
 package configs
 
+import (
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// Transports the agent can report metrics over.
+const (
+	TransportHTTP = "http" // JSON over HTTP
+	TransportGRPC = "grpc" // Protobuf over gRPC
+)
+
 // AgentConfig holds configuration parameters for the agent.
 type AgentConfig struct {
-	ServerAddress  string // Address of the server to send metrics to
-	LogLevel       string // Logging level (e.g., debug, info, warn, error)
-	PollInterval   int    // Time interval (in seconds) between metric polling
-	ReportInterval int    // Time interval (in seconds) between sending metrics
-	NumWorkers     int    // Number of concurrent workers for sending metrics
+	ServerAddress  string          // Address of the server to send metrics to
+	LogLevel       string          // Logging level (e.g., debug, info, warn, error)
+	PollInterval   int             // Time interval (in seconds) between metric polling
+	ReportInterval int             // Time interval (in seconds) between sending metrics
+	NumWorkers     int             // Number of concurrent workers for sending metrics
+	Key            string          // Shared key for HMAC-SHA256 signing; empty disables signing
+	RetryIntervals []time.Duration // Delays between retries of failed reports; empty disables retries
+	Labels         types.Labels    // Labels attached to every reported metric
+	Transport      string          // Transport metrics are reported over, TransportHTTP or TransportGRPC
+	CryptoKey      string          // Path to the server's PEM-encoded RSA public key; empty disables encryption
+	AdminAddress   string          // Loopback address the admin endpoints listen on (e.g., "localhost:8081"); empty disables them
+}
+
+// Masked returns a copy of the configuration that is safe to log:
+// the signing key is replaced with a placeholder.
+func (c *AgentConfig) Masked() AgentConfig {
+	masked := *c
+	masked.Key = maskSecret(c.Key)
+	return masked
 }
 
 // AgentOption defines a function that modifies an AgentConfig.
@@ -72,3 +98,45 @@ func WithAgentNumWorkers(workers int) AgentOption {
 		cfg.NumWorkers = workers
 	}
 }
+
+// WithAgentKey sets the shared key used to sign payloads.
+func WithAgentKey(key string) AgentOption {
+	return func(cfg *AgentConfig) {
+		cfg.Key = key
+	}
+}
+
+// WithAgentRetryIntervals sets the delays between retries of failed reports.
+func WithAgentRetryIntervals(intervals []time.Duration) AgentOption {
+	return func(cfg *AgentConfig) {
+		cfg.RetryIntervals = intervals
+	}
+}
+
+// WithAgentLabels sets the labels attached to every reported metric.
+func WithAgentLabels(labels types.Labels) AgentOption {
+	return func(cfg *AgentConfig) {
+		cfg.Labels = labels
+	}
+}
+
+// WithAgentTransport sets the transport metrics are reported over.
+func WithAgentTransport(transport string) AgentOption {
+	return func(cfg *AgentConfig) {
+		cfg.Transport = transport
+	}
+}
+
+// WithAgentCryptoKey sets the path to the server's RSA public key reports are encrypted with.
+func WithAgentCryptoKey(path string) AgentOption {
+	return func(cfg *AgentConfig) {
+		cfg.CryptoKey = path
+	}
+}
+
+// WithAgentAdminAddress sets the loopback address the admin endpoints listen on.
+func WithAgentAdminAddress(addr string) AgentOption {
+	return func(cfg *AgentConfig) {
+		cfg.AdminAddress = addr
+	}
+}
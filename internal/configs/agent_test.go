@@ -2,8 +2,11 @@ package configs
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
 func TestAgentOption_ServerAddress(t *testing.T) {
@@ -55,3 +58,50 @@ func TestAgentOption_NumWorkers(t *testing.T) {
 	cfg := NewAgentConfig(opt)
 	assert.Equal(t, expected, cfg.NumWorkers)
 }
+
+func TestAgentOption_Key(t *testing.T) {
+	expected := "secret"
+
+	cfg := NewAgentConfig(WithAgentKey(expected))
+	assert.Equal(t, expected, cfg.Key)
+}
+
+func TestAgentOption_RetryIntervals(t *testing.T) {
+	expected := []time.Duration{time.Second, 3 * time.Second, 5 * time.Second}
+
+	cfg := NewAgentConfig(WithAgentRetryIntervals(expected))
+	assert.Equal(t, expected, cfg.RetryIntervals)
+}
+
+func TestAgentOption_Labels(t *testing.T) {
+	expected := types.NewLabels(map[string]string{"env": "prod", "host": "web-1"})
+
+	cfg := NewAgentConfig(WithAgentLabels(expected))
+	assert.Equal(t, expected, cfg.Labels)
+}
+
+func TestAgentOption_Transport(t *testing.T) {
+	cfg := NewAgentConfig(WithAgentTransport(TransportGRPC))
+	assert.Equal(t, TransportGRPC, cfg.Transport)
+}
+
+func TestAgentOption_CryptoKey(t *testing.T) {
+	cfg := NewAgentConfig(WithAgentCryptoKey("/etc/metrics/public.pem"))
+	assert.Equal(t, "/etc/metrics/public.pem", cfg.CryptoKey)
+}
+
+func TestAgentOption_AdminAddress(t *testing.T) {
+	cfg := NewAgentConfig(WithAgentAdminAddress("localhost:8081"))
+	assert.Equal(t, "localhost:8081", cfg.AdminAddress)
+}
+
+func TestAgentConfig_Masked(t *testing.T) {
+	config := &AgentConfig{ServerAddress: "localhost:8080", Key: "secret"}
+
+	masked := config.Masked()
+	assert.Equal(t, "***", masked.Key)
+	assert.Equal(t, "localhost:8080", masked.ServerAddress)
+	assert.Equal(t, "secret", config.Key)
+
+	assert.Empty(t, (&AgentConfig{}).Masked().Key)
+}
@@ -0,0 +1,112 @@
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+)
+
+// ApplyFile reads the JSON configuration file at path and sets the flags its fields map to,
+// except for the flags in skip, usually those given on the command line.
+//
+// fields maps the JSON field names to the names of the flags of fs. Values are passed to the
+// flags the way they are written on the command line: strings as is, numbers and booleans
+// as written in the file and arrays of strings joined with commas. Boolean flags only accept
+// JSON booleans.
+//
+// Returns an error wrapping ErrConfigInvalid if the file is not a JSON object, or if any
+// field is unknown, has an unsupported type or is rejected by its flag. Every invalid field
+// is reported by name.
+func ApplyFile(path string, fs *flag.FlagSet, fields map[string]string, skip map[string]bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("%w %s: %v", internalErrors.ErrConfigInvalid, path, err)
+	}
+
+	// Report fields in a stable order
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fieldErrs []error
+	for _, name := range names {
+		flagName, ok := fields[name]
+		if !ok {
+			fieldErrs = append(fieldErrs, fmt.Errorf("field %q: unknown field", name))
+			continue
+		}
+		value, err := flagValue(values[name])
+		if err != nil {
+			fieldErrs = append(fieldErrs, fmt.Errorf("field %q: %v", name, err))
+			continue
+		}
+		if isBoolFlag(fs.Lookup(flagName)) && value != "true" && value != "false" {
+			fieldErrs = append(fieldErrs, fmt.Errorf("field %q: expected a boolean", name))
+			continue
+		}
+		if skip[flagName] {
+			continue
+		}
+		if err := fs.Set(flagName, value); err != nil {
+			fieldErrs = append(fieldErrs, fmt.Errorf("field %q: invalid value %q: %v", name, value, err))
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return fmt.Errorf("%w %s: %w", internalErrors.ErrConfigInvalid, path, errors.Join(fieldErrs...))
+	}
+	return nil
+}
+
+// isBoolFlag reports whether the flag is a boolean flag, which the file must set with a JSON boolean.
+func isBoolFlag(f *flag.Flag) bool {
+	if f == nil {
+		return false
+	}
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagValue converts a JSON value to its command-line form.
+func flagValue(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "", fmt.Errorf("missing value")
+	}
+
+	switch raw[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	case '[':
+		var items []string
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return "", fmt.Errorf("expected an array of strings")
+		}
+		return strings.Join(items, ","), nil
+	case '{':
+		return "", fmt.Errorf("unexpected object")
+	case 'n':
+		return "", fmt.Errorf("unexpected null")
+	default:
+		// Numbers and booleans are written the same way on the command line
+		return string(raw), nil
+	}
+}
@@ -0,0 +1,101 @@
+package configs_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/configs"
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFile(t *testing.T) {
+	fields := map[string]string{
+		"address":  "a",
+		"interval": "i",
+		"restore":  "r",
+		"urls":     "u",
+	}
+
+	tests := []struct {
+		name         string
+		content      string
+		skip         map[string]bool
+		wantAddr     string
+		wantInterval int
+		wantRestore  bool
+		wantURLs     string
+		wantErr      []string // fields reported as invalid; empty for a malformed file
+		wantAnyErr   bool
+	}{
+		{
+			name:         "all fields",
+			content:      `{"address": "filehost:6060", "interval": 30, "restore": false, "urls": ["http://a", "http://b"]}`,
+			wantAddr:     "filehost:6060",
+			wantInterval: 30,
+			wantRestore:  false,
+			wantURLs:     "http://a,http://b",
+		},
+		{
+			name:         "missing fields keep defaults",
+			content:      `{"interval": 30}`,
+			wantAddr:     "localhost:8080",
+			wantInterval: 30,
+			wantRestore:  true,
+		},
+		{
+			name:         "skipped flags are left alone",
+			content:      `{"address": "filehost:6060", "interval": 30}`,
+			skip:         map[string]bool{"a": true},
+			wantAddr:     "localhost:8080",
+			wantInterval: 30,
+			wantRestore:  true,
+		},
+		{
+			name:    "field errors",
+			content: `{"address": {}, "interval": 1.5, "restore": "yes", "urls": [1], "port": 8080, "extra": null}`,
+			wantErr: []string{`"address"`, `"interval"`, `"restore"`, `"urls"`, `"port"`, `"extra"`},
+		},
+		{
+			name:       "not an object",
+			content:    `["address"]`,
+			wantAnyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			addr := fs.String("a", "localhost:8080", "")
+			interval := fs.Int("i", 10, "")
+			restore := fs.Bool("r", true, "")
+			urls := fs.String("u", "", "")
+
+			err := configs.ApplyFile(path, fs, fields, tt.skip)
+
+			if tt.wantAnyErr || len(tt.wantErr) > 0 {
+				require.ErrorIs(t, err, internalErrors.ErrConfigInvalid)
+				for _, field := range tt.wantErr {
+					assert.Contains(t, err.Error(), field)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAddr, *addr)
+			assert.Equal(t, tt.wantInterval, *interval)
+			assert.Equal(t, tt.wantRestore, *restore)
+			assert.Equal(t, tt.wantURLs, *urls)
+		})
+	}
+}
+
+func TestApplyFile_MissingFile(t *testing.T) {
+	err := configs.ApplyFile(filepath.Join(t.TempDir(), "missing.json"), flag.NewFlagSet("test", flag.ContinueOnError), nil, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,30 @@
+package configs
+
+import (
+	"net/url"
+	"regexp"
+)
+
+// maskedValue replaces secrets in configurations that are logged.
+const maskedValue = "***"
+
+// dsnPasswordRegexp matches the password of a DSN given as key=value pairs,
+// with the value optionally single-quoted.
+var dsnPasswordRegexp = regexp.MustCompile(`(password=)('(?:[^'\\]|\\.)*'|\S*)`)
+
+// maskSecret returns maskedValue in place of a non-empty secret.
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return maskedValue
+}
+
+// maskDSN hides the password of a PostgreSQL DSN given either as a URL
+// or as key=value pairs, keeping the rest of it readable.
+func maskDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+	return dsnPasswordRegexp.ReplaceAllString(dsn, "${1}"+maskedValue)
+}
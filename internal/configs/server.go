@@ -2,10 +2,92 @@
 // for the server application.
 package configs
 
+import "reflect"
+
+// Storage backends a server can keep metrics in.
+const (
+	// StorageMemory keeps metrics in memory, or in PostgreSQL when a database DSN is set.
+	StorageMemory = "memory"
+
+	// StorageEmbedded keeps metrics in an embedded database in the data directory.
+	StorageEmbedded = "embedded"
+)
+
 // ServerConfig holds configuration parameters for the HTTP server.
 type ServerConfig struct {
-	Address  string // Address on which the server listens (e.g., ":8080")
-	LogLevel string // Logging level (e.g., debug, info, warn, error)
+	Address          string   // Address on which the server listens (e.g., ":8080")
+	LogLevel         string   // Logging level (e.g., debug, info, warn, error)
+	StoreInterval    int      // Time interval (in seconds) between metric snapshots to the file
+	FileStoragePath  string   // Path to the metric snapshot file; empty disables file persistence
+	Restore          bool     // Whether to load metrics from the snapshot file on start
+	DatabaseDSN      string   // PostgreSQL DSN; when set, metrics are stored in the database
+	Key              string   // Shared key for HMAC-SHA256 signing; empty disables signing
+	AlertRulesPath   string   // Path to the YAML/JSON alert rules file; empty disables alerting
+	AlertInterval    int      // Time interval (in seconds) between alert rule evaluations
+	WebhookURLs      []string // URLs firing and resolved alerts are posted to; empty disables notifications
+	NotifyInterval   int      // Time interval (in seconds) between checks for alerts to notify about
+	NotifyRepeat     int      // Time interval (in seconds) after which a still-firing alert is notified again
+	HistoryRetention int      // Time interval (in seconds) metric history is kept for; zero disables the history
+	StatsDAddress    string   // UDP address StatsD samples are received on (e.g., ":8125"); empty disables StatsD
+	StatsDFlush      int      // Time interval (in seconds) between flushes of aggregated StatsD samples
+	GRPCAddress      string   // Address on which the gRPC server listens (e.g., ":3200"); empty disables gRPC
+	CryptoKey        string   // Path to the PEM-encoded RSA private key request bodies are decrypted with; empty disables decryption
+	TrustedSubnet    string   // CIDR updates must originate from, per X-Real-IP (e.g., "10.0.0.0/24"); empty allows any origin
+	WALPath          string   // Path to the write-ahead log of in-memory metric updates; empty disables the log
+	WALSyncInterval  int      // Time interval (in milliseconds) between flushes of the write-ahead log to disk; zero flushes every update
+	Storage          string   // Storage backend, StorageMemory or StorageEmbedded; empty means StorageMemory
+	DataDir          string   // Directory the embedded database is kept in
+}
+
+// serverReloadableFields are the ServerConfig fields a running server applies on reload.
+var serverReloadableFields = map[string]bool{
+	"LogLevel":       true,
+	"StoreInterval":  true,
+	"Key":            true,
+	"AlertRulesPath": true,
+	"TrustedSubnet":  true,
+}
+
+// RestartFields returns the names of the fields that differ between the configuration
+// and next and cannot be applied to a running server, in declaration order.
+// Changes to these fields only take effect once the server is restarted.
+func (c *ServerConfig) RestartFields(next *ServerConfig) []string {
+	current, updated := reflect.ValueOf(c).Elem(), reflect.ValueOf(next).Elem()
+
+	var fields []string
+	for i := 0; i < current.NumField(); i++ {
+		name := current.Type().Field(i).Name
+		if serverReloadableFields[name] {
+			continue
+		}
+		if !reflect.DeepEqual(current.Field(i).Interface(), updated.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// Reloaded returns the configuration a running server has once next is reloaded:
+// the fields of next a running server applies and the other fields of the configuration,
+// which only change on restart.
+func (c *ServerConfig) Reloaded(next *ServerConfig) ServerConfig {
+	reloaded := *c
+	current, updated := reflect.ValueOf(&reloaded).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		if serverReloadableFields[current.Type().Field(i).Name] {
+			current.Field(i).Set(updated.Field(i))
+		}
+	}
+	return reloaded
+}
+
+// Masked returns a copy of the configuration that is safe to log:
+// the signing key and the database password are replaced with a placeholder.
+func (c *ServerConfig) Masked() ServerConfig {
+	masked := *c
+	masked.Key = maskSecret(c.Key)
+	masked.DatabaseDSN = maskDSN(c.DatabaseDSN)
+	return masked
 }
 
 // ServerOption defines a function that modifies a ServerConfig.
@@ -48,3 +130,143 @@ func WithServerLogLevel(level string) ServerOption {
 		c.LogLevel = level
 	}
 }
+
+// WithServerStoreInterval sets the interval between metric snapshots.
+func WithServerStoreInterval(interval int) ServerOption {
+	return func(c *ServerConfig) {
+		c.StoreInterval = interval
+	}
+}
+
+// WithServerFileStoragePath sets the path to the metric snapshot file.
+func WithServerFileStoragePath(path string) ServerOption {
+	return func(c *ServerConfig) {
+		c.FileStoragePath = path
+	}
+}
+
+// WithServerRestore sets whether metrics are restored from the snapshot file on start.
+func WithServerRestore(restore bool) ServerOption {
+	return func(c *ServerConfig) {
+		c.Restore = restore
+	}
+}
+
+// WithServerDatabaseDSN sets the PostgreSQL data source name.
+func WithServerDatabaseDSN(dsn string) ServerOption {
+	return func(c *ServerConfig) {
+		c.DatabaseDSN = dsn
+	}
+}
+
+// WithServerKey sets the shared key used to check and sign payloads.
+func WithServerKey(key string) ServerOption {
+	return func(c *ServerConfig) {
+		c.Key = key
+	}
+}
+
+// WithServerAlertRulesPath sets the path to the alert rules file.
+func WithServerAlertRulesPath(path string) ServerOption {
+	return func(c *ServerConfig) {
+		c.AlertRulesPath = path
+	}
+}
+
+// WithServerAlertInterval sets the interval in seconds between alert rule evaluations.
+func WithServerAlertInterval(interval int) ServerOption {
+	return func(c *ServerConfig) {
+		c.AlertInterval = interval
+	}
+}
+
+// WithServerWebhookURLs sets the URLs alert notifications are posted to.
+func WithServerWebhookURLs(urls []string) ServerOption {
+	return func(c *ServerConfig) {
+		c.WebhookURLs = urls
+	}
+}
+
+// WithServerNotifyInterval sets the interval in seconds between checks for alerts to notify about.
+func WithServerNotifyInterval(interval int) ServerOption {
+	return func(c *ServerConfig) {
+		c.NotifyInterval = interval
+	}
+}
+
+// WithServerNotifyRepeat sets the interval in seconds after which a still-firing alert is notified again.
+func WithServerNotifyRepeat(interval int) ServerOption {
+	return func(c *ServerConfig) {
+		c.NotifyRepeat = interval
+	}
+}
+
+// WithServerHistoryRetention sets the interval in seconds metric history is kept for.
+func WithServerHistoryRetention(retention int) ServerOption {
+	return func(c *ServerConfig) {
+		c.HistoryRetention = retention
+	}
+}
+
+// WithServerStatsDAddress sets the UDP address StatsD samples are received on.
+func WithServerStatsDAddress(addr string) ServerOption {
+	return func(c *ServerConfig) {
+		c.StatsDAddress = addr
+	}
+}
+
+// WithServerStatsDFlush sets the interval in seconds between flushes of aggregated StatsD samples.
+func WithServerStatsDFlush(interval int) ServerOption {
+	return func(c *ServerConfig) {
+		c.StatsDFlush = interval
+	}
+}
+
+// WithServerGRPCAddress sets the address the gRPC server listens on.
+func WithServerGRPCAddress(addr string) ServerOption {
+	return func(c *ServerConfig) {
+		c.GRPCAddress = addr
+	}
+}
+
+// WithServerCryptoKey sets the path to the RSA private key request bodies are decrypted with.
+func WithServerCryptoKey(path string) ServerOption {
+	return func(c *ServerConfig) {
+		c.CryptoKey = path
+	}
+}
+
+// WithServerTrustedSubnet sets the CIDR metric updates must originate from.
+func WithServerTrustedSubnet(cidr string) ServerOption {
+	return func(c *ServerConfig) {
+		c.TrustedSubnet = cidr
+	}
+}
+
+// WithServerWALPath sets the path to the write-ahead log of metric updates.
+func WithServerWALPath(path string) ServerOption {
+	return func(c *ServerConfig) {
+		c.WALPath = path
+	}
+}
+
+// WithServerWALSyncInterval sets the interval in milliseconds between flushes of the write-ahead log to disk.
+func WithServerWALSyncInterval(interval int) ServerOption {
+	return func(c *ServerConfig) {
+		c.WALSyncInterval = interval
+	}
+}
+
+// WithServerStorage sets the storage backend metrics are kept in.
+func WithServerStorage(storage string) ServerOption {
+	return func(c *ServerConfig) {
+		c.Storage = storage
+	}
+}
+
+// WithServerDataDir sets the directory the embedded database is kept in.
+func WithServerDataDir(dir string) ServerOption {
+	return func(c *ServerConfig) {
+		c.DataDir = dir
+	}
+}
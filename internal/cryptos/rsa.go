@@ -0,0 +1,159 @@
+// Package cryptos provides hybrid RSA+AES encryption of payloads.
+//
+// A payload is encrypted with a fresh AES-256-GCM key, and that key is encrypted
+// with the recipient's RSA public key using RSA-OAEP with SHA-256. The result is laid out as
+//
+//	| encrypted key length (2 bytes, big endian) | encrypted key | GCM nonce | AES-GCM ciphertext |
+//
+// so payloads of any size can be encrypted for the holder of the private key.
+package cryptos
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+)
+
+// aesKeySize is the size in bytes of the AES key generated for every payload (AES-256).
+const aesKeySize = 32
+
+// LoadPublicKey reads a PEM-encoded RSA public key, in PKIX or PKCS #1 form, from the file at path.
+//
+// Returns an error wrapping ErrCryptoKeyInvalid if the file does not contain an RSA public key.
+func LoadPublicKey(path string) (*rsa.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errors.ErrCryptoKeyInvalid, path, err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: not an RSA public key", errors.ErrCryptoKeyInvalid, path)
+	}
+	return rsaKey, nil
+}
+
+// LoadPrivateKey reads a PEM-encoded RSA private key, in PKCS #1 or PKCS #8 form, from the file at path.
+//
+// Returns an error wrapping ErrCryptoKeyInvalid if the file does not contain an RSA private key.
+func LoadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errors.ErrCryptoKeyInvalid, path, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: not an RSA private key", errors.ErrCryptoKeyInvalid, path)
+	}
+	return rsaKey, nil
+}
+
+// readPEM reads the first PEM block of the file at path.
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s: no PEM data found", errors.ErrCryptoKeyInvalid, path)
+	}
+	return block, nil
+}
+
+// Encrypt encrypts the plaintext for the holder of the private key matching the given public key.
+func Encrypt(key *rsa.PublicKey, plaintext []byte) ([]byte, error) {
+	aesKey := make([]byte, aesKeySize)
+	if _, err := rand.Read(aesKey); err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, aesKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 2, 2+len(encryptedKey)+len(nonce)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint16(out, uint16(len(encryptedKey)))
+	out = append(out, encryptedKey...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts a payload produced by Encrypt with the matching private key.
+//
+// Returns ErrCiphertextInvalid if the payload is malformed, was encrypted for another key
+// or has been tampered with.
+func Decrypt(key *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 2 {
+		return nil, errors.ErrCiphertextInvalid
+	}
+	keyLen := int(binary.BigEndian.Uint16(ciphertext))
+	ciphertext = ciphertext[2:]
+	if len(ciphertext) < keyLen {
+		return nil, errors.ErrCiphertextInvalid
+	}
+
+	aesKey, err := rsa.DecryptOAEP(sha256.New(), nil, key, ciphertext[:keyLen], nil)
+	if err != nil || len(aesKey) != aesKeySize {
+		return nil, errors.ErrCiphertextInvalid
+	}
+	ciphertext = ciphertext[keyLen:]
+
+	gcm, err := newGCM(aesKey)
+	if err != nil {
+		return nil, errors.ErrCiphertextInvalid
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.ErrCiphertextInvalid
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, errors.ErrCiphertextInvalid
+	}
+	return plaintext, nil
+}
+
+// newGCM creates an AES-GCM cipher for the given key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
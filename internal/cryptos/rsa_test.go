@@ -0,0 +1,110 @@
+package cryptos
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+)
+
+// writePEM writes a PEM block of the given type to a file in a temporary directory and returns its path.
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	plaintext := []byte(`[{"id":"Alloc","type":"gauge","value":1.5}]`)
+
+	ciphertext, err := Encrypt(&key.PublicKey, plaintext)
+	require.NoError(t, err)
+	assert.NotContains(t, string(ciphertext), "Alloc")
+
+	got, err := Decrypt(key, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name       string
+		key        *rsa.PrivateKey
+		ciphertext []byte
+	}{
+		{name: "empty", key: key, ciphertext: nil},
+		{name: "truncated key", key: key, ciphertext: ciphertext[:100]},
+		{name: "plaintext", key: key, ciphertext: plaintext},
+		{name: "tampered", key: key, ciphertext: tampered},
+		{name: "wrong key", key: other, ciphertext: ciphertext},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decrypt(tt.key, tt.ciphertext)
+			assert.ErrorIs(t, err, errors.ErrCiphertextInvalid)
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	for name, path := range map[string]string{
+		"PKIX":    writePEM(t, "PUBLIC KEY", pkix),
+		"PKCS #1": writePEM(t, "RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&key.PublicKey)),
+	} {
+		t.Run("public "+name, func(t *testing.T) {
+			got, err := LoadPublicKey(path)
+			require.NoError(t, err)
+			assert.True(t, key.PublicKey.Equal(got))
+		})
+	}
+
+	for name, path := range map[string]string{
+		"PKCS #8": writePEM(t, "PRIVATE KEY", pkcs8),
+		"PKCS #1": writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)),
+	} {
+		t.Run("private "+name, func(t *testing.T) {
+			got, err := LoadPrivateKey(path)
+			require.NoError(t, err)
+			assert.True(t, key.Equal(got))
+		})
+	}
+
+	t.Run("invalid files", func(t *testing.T) {
+		notPEM := filepath.Join(t.TempDir(), "key.pem")
+		require.NoError(t, os.WriteFile(notPEM, []byte("not a key"), 0o600))
+		garbage := writePEM(t, "PUBLIC KEY", []byte("garbage"))
+
+		_, err := LoadPublicKey(notPEM)
+		assert.ErrorIs(t, err, errors.ErrCryptoKeyInvalid)
+		_, err = LoadPublicKey(garbage)
+		assert.ErrorIs(t, err, errors.ErrCryptoKeyInvalid)
+		_, err = LoadPrivateKey(garbage)
+		assert.ErrorIs(t, err, errors.ErrCryptoKeyInvalid)
+		_, err = LoadPrivateKey(filepath.Join(t.TempDir(), "missing.pem"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,45 @@
+package databases
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// BoltFileName is the name of the embedded database file in the data directory.
+const BoltFileName = "metrics.db"
+
+// BoltMetricsBucket is the bucket of the embedded database metrics are stored in.
+var BoltMetricsBucket = []byte("metrics")
+
+// boltOpenTimeout bounds the wait for the lock on the database file, which is held
+// by another process, or another server of this one, while it has the database open.
+const boltOpenTimeout = time.Second
+
+// NewBoltDB opens the embedded database in the given data directory, creating the directory
+// and the database file if they do not exist.
+//
+// Parameters:
+//   - dir: Path to the data directory.
+//
+// Returns:
+//   - A ready-to-use *bbolt.DB.
+//   - An error if the directory cannot be created or the database is invalid or in use.
+func NewBoltDB(dir string) (*bbolt.DB, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return bbolt.Open(filepath.Join(dir, BoltFileName), 0o600, &bbolt.Options{Timeout: boltOpenTimeout})
+}
+
+// MigrateBolt creates the metrics bucket if it does not exist yet.
+//
+// It is safe to call on every start.
+func MigrateBolt(db *bbolt.DB) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(BoltMetricsBucket)
+		return err
+	})
+}
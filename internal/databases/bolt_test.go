@@ -0,0 +1,52 @@
+package databases
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestNewBoltDB(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+
+	db, err := NewBoltDB(dir)
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, BoltFileName))
+	assert.NoError(t, err)
+
+	// The database is locked while it is open
+	_, err = NewBoltDB(dir)
+	assert.Error(t, err)
+
+	require.NoError(t, db.Close())
+}
+
+func TestNewBoltDB_InvalidDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	db, err := NewBoltDB(file)
+	assert.Error(t, err)
+	assert.Nil(t, db)
+}
+
+func TestMigrateBolt(t *testing.T) {
+	db, err := NewBoltDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Migrating twice is safe
+	require.NoError(t, MigrateBolt(db))
+	require.NoError(t, MigrateBolt(db))
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		assert.NotNil(t, tx.Bucket(BoltMetricsBucket))
+		return nil
+	})
+	assert.NoError(t, err)
+}
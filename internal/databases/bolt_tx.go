@@ -0,0 +1,48 @@
+package databases
+
+import (
+	"context"
+
+	"go.etcd.io/bbolt"
+)
+
+// boltTxKey is the context key under which the current embedded database transaction is stored.
+type boltTxKey struct{}
+
+// BoltTransactor runs functions inside a read-write transaction of the embedded database.
+type BoltTransactor struct {
+	db *bbolt.DB
+}
+
+// NewBoltTransactor creates and returns a new BoltTransactor for the given embedded database.
+func NewBoltTransactor(db *bbolt.DB) *BoltTransactor {
+	return &BoltTransactor{db: db}
+}
+
+// Do begins a read-write transaction, stores it in the context passed to fn and commits it
+// if fn succeeds, so that all of fn's changes are synced to disk at once. If fn returns an error,
+// the transaction is rolled back and the error is returned.
+//
+// Stores obtain the transaction through GetBoltTx, so every operation made by fn with the
+// provided context becomes part of the same transaction. The embedded database has a single
+// writer, so other updates wait until the transaction ends.
+//
+// A Do called with the context of a running transaction joins it.
+func (t *BoltTransactor) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if GetBoltTx(ctx, t.db) != nil {
+		return fn(ctx)
+	}
+
+	return t.db.Update(func(tx *bbolt.Tx) error {
+		return fn(context.WithValue(ctx, boltTxKey{}, tx))
+	})
+}
+
+// GetBoltTx returns the transaction of db stored in ctx by BoltTransactor.Do,
+// or nil if the context carries no transaction of db.
+func GetBoltTx(ctx context.Context, db *bbolt.DB) *bbolt.Tx {
+	if tx, ok := ctx.Value(boltTxKey{}).(*bbolt.Tx); ok && tx.DB() == db {
+		return tx
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestBoltTransactor_Do(t *testing.T) {
+	errFn := errors.New("fn error")
+
+	tests := []struct {
+		name    string
+		err     error
+		wantKey bool
+	}{
+		{name: "commit on success", wantKey: true},
+		{name: "rollback on error", err: errFn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := NewBoltDB(t.TempDir())
+			require.NoError(t, err)
+			defer db.Close()
+			require.NoError(t, MigrateBolt(db))
+
+			transactor := NewBoltTransactor(db)
+			err = transactor.Do(context.Background(), func(ctx context.Context) error {
+				tx := GetBoltTx(ctx, db)
+				require.NotNil(t, tx)
+				require.NoError(t, tx.Bucket(BoltMetricsBucket).Put([]byte("a"), []byte("1")))
+
+				// A nested call joins the running transaction
+				return transactor.Do(ctx, func(ctx context.Context) error {
+					assert.Same(t, tx, GetBoltTx(ctx, db))
+					if err := tx.Bucket(BoltMetricsBucket).Put([]byte("b"), []byte("2")); err != nil {
+						return err
+					}
+					return tt.err
+				})
+			})
+			assert.ErrorIs(t, err, tt.err)
+
+			err = db.View(func(tx *bbolt.Tx) error {
+				bucket := tx.Bucket(BoltMetricsBucket)
+				assert.Equal(t, tt.wantKey, bucket.Get([]byte("a")) != nil)
+				assert.Equal(t, tt.wantKey, bucket.Get([]byte("b")) != nil)
+				return nil
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGetBoltTx(t *testing.T) {
+	db, err := NewBoltDB(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+	other, err := NewBoltDB(t.TempDir())
+	require.NoError(t, err)
+	defer other.Close()
+
+	assert.Nil(t, GetBoltTx(context.Background(), db))
+
+	err = NewBoltTransactor(db).Do(context.Background(), func(ctx context.Context) error {
+		assert.NotNil(t, GetBoltTx(ctx, db))
+		// The transaction belongs to another database
+		assert.Nil(t, GetBoltTx(ctx, other))
+		return nil
+	})
+	assert.NoError(t, err)
+}
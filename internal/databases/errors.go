@@ -0,0 +1,50 @@
+package databases
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+)
+
+// retriablePgCodes lists PostgreSQL error codes, besides the connection exception class,
+// after which the same statement may succeed if executed again.
+var retriablePgCodes = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// WrapRetriableError marks transient database errors with ErrStorageUnavailable
+// so callers can tell them apart with errors.Is. Other errors are returned unchanged.
+func WrapRetriableError(err error) error {
+	if err == nil || errors.Is(err, internalErrors.ErrStorageUnavailable) {
+		return err
+	}
+	if isRetriableError(err) {
+		return fmt.Errorf("%w: %w", internalErrors.ErrStorageUnavailable, err)
+	}
+	return err
+}
+
+// isRetriableError reports whether err is a connection problem or a PostgreSQL
+// error that is expected to go away on its own.
+func isRetriableError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || retriablePgCodes[pgErr.Code]
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		pgconn.Timeout(err) ||
+		pgconn.SafeToRetry(err)
+}
@@ -0,0 +1,45 @@
+package databases
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+)
+
+func TestWrapRetriableError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRetriable bool
+	}{
+		{name: "nil", err: nil},
+		{name: "no rows", err: sql.ErrNoRows},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, wantRetriable: true},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, wantRetriable: true},
+		{name: "cannot connect now", err: &pgconn.PgError{Code: "57P03"}, wantRetriable: true},
+		{name: "bad connection", err: driver.ErrBadConn, wantRetriable: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, wantRetriable: true},
+		{name: "already wrapped", err: internalErrors.ErrStorageUnavailable, wantRetriable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapRetriableError(tt.err)
+			if tt.err == nil {
+				assert.NoError(t, got)
+				return
+			}
+			assert.ErrorIs(t, got, tt.err)
+			assert.Equal(t, tt.wantRetriable, errors.Is(got, internalErrors.ErrStorageUnavailable))
+		})
+	}
+}
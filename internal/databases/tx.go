@@ -0,0 +1,57 @@
+package databases
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// txKey is the context key under which the current transaction is stored.
+type txKey struct{}
+
+// Transactor runs functions inside a database transaction.
+type Transactor struct {
+	db *sqlx.DB
+}
+
+// NewTransactor creates and returns a new Transactor for the given database.
+func NewTransactor(db *sqlx.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Do begins a transaction, stores it in the context passed to fn and commits it
+// if fn succeeds. If fn returns an error, the transaction is rolled back and
+// the error is returned.
+//
+// Repositories obtain the transaction through GetExecutor, so every query made
+// by fn with the provided context becomes part of the same transaction.
+// Transient failures to begin or commit are wrapped with ErrStorageUnavailable.
+//
+// A Do called with the context of a running transaction joins it: fn runs inside
+// that transaction, which is committed or rolled back by the outer Do.
+func (t *Transactor) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return WrapRetriableError(err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return WrapRetriableError(tx.Commit())
+}
+
+// GetExecutor returns the transaction stored in ctx by Transactor.Do,
+// or db itself if the context carries no transaction.
+func GetExecutor(ctx context.Context, db *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
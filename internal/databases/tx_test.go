@@ -0,0 +1,110 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactor_Do(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		fn      func(ctx context.Context, db *sqlx.DB) error
+		wantErr bool
+	}{
+		{
+			name: "commit on success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE metrics").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			fn: func(ctx context.Context, db *sqlx.DB) error {
+				_, err := GetExecutor(ctx, db).ExecContext(ctx, "UPDATE metrics")
+				return err
+			},
+		},
+		{
+			name: "rollback on error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			fn: func(ctx context.Context, db *sqlx.DB) error {
+				return errors.New("fn error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "nested call joins transaction",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE metrics").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			fn: func(ctx context.Context, db *sqlx.DB) error {
+				return NewTransactor(db).Do(ctx, func(ctx context.Context) error {
+					_, err := GetExecutor(ctx, db).ExecContext(ctx, "UPDATE metrics")
+					return err
+				})
+			},
+		},
+		{
+			name: "begin error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(errors.New("begin error"))
+			},
+			fn: func(ctx context.Context, db *sqlx.DB) error {
+				t.Fatal("fn must not be called when begin fails")
+				return nil
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			db := sqlx.NewDb(sqlDB, "pgx")
+			tt.setup(mock)
+
+			err = NewTransactor(db).Do(context.Background(), func(ctx context.Context) error {
+				return tt.fn(ctx, db)
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetExecutor(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db := sqlx.NewDb(sqlDB, "pgx")
+
+	assert.Equal(t, db, GetExecutor(context.Background(), db))
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	err = NewTransactor(db).Do(context.Background(), func(ctx context.Context) error {
+		_, ok := GetExecutor(ctx, db).(*sqlx.Tx)
+		assert.True(t, ok, "executor inside Do should be the transaction")
+		return nil
+	})
+	assert.NoError(t, err)
+}
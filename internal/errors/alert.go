@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	// ErrAlertRuleInvalid indicates that an alert rule expression is malformed.
+	ErrAlertRuleInvalid = errors.New("invalid alert rule")
+)
@@ -7,4 +7,13 @@ import "errors"
 var (
 	// ErrInternalServerError indicates that an unexpected server-side error occurred.
 	ErrInternalServerError = errors.New("internal server error")
+
+	// ErrStorageUnavailable indicates a transient storage failure; the same request may succeed if retried.
+	ErrStorageUnavailable = errors.New("storage temporarily unavailable")
+
+	// ErrLogLevelInvalid indicates that a requested log level is malformed or unknown.
+	ErrLogLevelInvalid = errors.New("invalid log level")
+
+	// ErrStorageInvalid indicates that the configured storage backend is unknown or conflicts with other settings.
+	ErrStorageInvalid = errors.New("invalid storage")
 )
@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	// ErrConfigInvalid indicates that a configuration file is malformed or has invalid fields.
+	ErrConfigInvalid = errors.New("invalid config file")
+)
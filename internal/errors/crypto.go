@@ -0,0 +1,11 @@
+package errors
+
+import "errors"
+
+var (
+	// ErrCiphertextInvalid indicates that an encrypted payload is malformed or cannot be decrypted with the key.
+	ErrCiphertextInvalid = errors.New("invalid ciphertext")
+
+	// ErrCryptoKeyInvalid indicates that a key file does not contain a usable PEM-encoded RSA key.
+	ErrCryptoKeyInvalid = errors.New("invalid crypto key")
+)
@@ -20,6 +20,23 @@ var (
 	// ErrMetricValueInvalid indicates that a provided metric value is invalid or cannot be processed.
 	ErrMetricValueInvalid = errors.New("invalid metric value")
 
+	// ErrMetricLabelsInvalid indicates that the provided metric labels are malformed or too many.
+	ErrMetricLabelsInvalid = errors.New("invalid metric labels")
+
+	// ErrMetricLabelsAmbiguous indicates that the labels of a metric reference match several metrics.
+	ErrMetricLabelsAmbiguous = errors.New("metric labels match several metrics")
+
 	// ErrMetricDeltaInvalid indicates that a provided metric delta is invalid or cannot be processed.
 	ErrMetricDeltaInvalid = errors.New("invalid metric delta")
+
+	// ErrMetricHistogramInvalid indicates that a histogram is missing its sum or count, or has malformed buckets.
+	ErrMetricHistogramInvalid = errors.New("invalid metric histogram")
+
+	// ErrMetricSummaryInvalid indicates that a summary is missing its sum or count, or has malformed quantiles.
+	ErrMetricSummaryInvalid = errors.New("invalid metric summary")
+)
+
+var (
+	// ErrMetricRangeInvalid indicates that the requested time range, or its resolution, is invalid.
+	ErrMetricRangeInvalid = errors.New("invalid metric range")
 )
@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	// ErrStatsDLineInvalid indicates that a StatsD line is malformed or of an unsupported type.
+	ErrStatsDLineInvalid = errors.New("invalid statsd line")
+)
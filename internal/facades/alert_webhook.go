@@ -0,0 +1,79 @@
+package facades
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/retries"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// AlertWebhookFacade delivers alert notifications to a set of webhook URLs.
+type AlertWebhookFacade struct {
+	urls           []string
+	retryIntervals []time.Duration
+	client         *resty.Client
+}
+
+// NewAlertWebhookFacade creates and returns a new AlertWebhookFacade.
+// It initializes an HTTP client and accepts the webhook URLs notifications
+// are posted to and the delays between retries of failed deliveries.
+// No intervals disable retries.
+func NewAlertWebhookFacade(urls []string, retryIntervals []time.Duration) *AlertWebhookFacade {
+	return &AlertWebhookFacade{
+		urls:           urls,
+		retryIntervals: retryIntervals,
+		client:         resty.New(),
+	}
+}
+
+// Notify posts the alert as a JSON object to every configured webhook URL.
+//
+// Deliveries that fail with a retriable error (connection refused, timeout,
+// or a 502, 503 or 504 response) are repeated after each of the configured retry
+// intervals. A failure to deliver to one URL does not prevent delivery to the others.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout.
+//   - alert: The alert to deliver.
+//
+// Returns:
+//   - An error joining the failures of every URL the alert could not be delivered to.
+func (f *AlertWebhookFacade) Notify(ctx context.Context, alert types.Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, url := range f.urls {
+		err := retries.Do(ctx, f.retryIntervals, isRetriableError, func() error {
+			resp, err := f.client.R().
+				SetContext(ctx).
+				SetHeader("Content-Type", "application/json").
+				SetBody(body).
+				Post(url)
+
+			if err != nil {
+				return fmt.Errorf("failed to send webhook request to %s: %w", url, err)
+			}
+
+			if resp.IsError() {
+				return &responseError{op: "webhook " + url, statusCode: resp.StatusCode(), status: resp.Status()}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Log.Errorf("Failed to deliver alert %s to %s: %v", alert.Rule, url, err)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,130 @@
+package facades
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertWebhookFacade_Notify(t *testing.T) {
+	firedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	value := 600.0
+	alert := types.Alert{
+		Rule:     "HighHeap",
+		Expr:     "gauge HeapAlloc > 500 for 2m",
+		MetricID: types.MetricID{ID: "HeapAlloc", Type: types.Gauge},
+		State:    types.AlertFiring,
+		Value:    &value,
+		FiredAt:  &firedAt,
+	}
+
+	var (
+		mu       sync.Mutex
+		received []types.Alert
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var got types.Alert
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&got)) {
+			return
+		}
+		mu.Lock()
+		received = append(received, got)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	facade := NewAlertWebhookFacade([]string{receiver.URL, receiver.URL + "/second"}, nil)
+	require.NoError(t, facade.Notify(context.Background(), alert))
+
+	require.Len(t, received, 2)
+	for _, got := range received {
+		assert.Equal(t, alert.Rule, got.Rule)
+		assert.Equal(t, alert.MetricID, got.MetricID)
+		assert.Equal(t, alert.State, got.State)
+		require.NotNil(t, got.Value)
+		assert.Equal(t, value, *got.Value)
+		require.NotNil(t, got.FiredAt)
+		assert.True(t, firedAt.Equal(*got.FiredAt))
+	}
+}
+
+func TestAlertWebhookFacade_Notify_Retries(t *testing.T) {
+	retryIntervals := []time.Duration{time.Millisecond, 2 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantRequests int
+	}{
+		{
+			name:         "succeeds after transient failure",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantRequests: 2,
+		},
+		{
+			name:         "gives up after exhausting retries",
+			statuses:     []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			wantErr:      true,
+			wantRequests: 3,
+		},
+		{
+			name:         "does not retry client errors",
+			statuses:     []int{http.StatusNotFound},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[requests])
+				requests++
+			}))
+			defer receiver.Close()
+
+			facade := NewAlertWebhookFacade([]string{receiver.URL}, retryIntervals)
+			err := facade.Notify(context.Background(), types.Alert{Rule: "r", State: types.AlertFiring})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestAlertWebhookFacade_Notify_OneURLFails(t *testing.T) {
+	var delivered int
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ok.Close()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	facade := NewAlertWebhookFacade([]string{downURL, ok.URL}, nil)
+	err := facade.Notify(context.Background(), types.Alert{Rule: "r", State: types.AlertResolved})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, delivered)
+}
@@ -4,93 +4,244 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/rsa"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/cryptos"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/headers"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/retries"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
 // MetricUpdateFacade provides a simplified interface for sending
 // metric update requests to a remote server.
 type MetricUpdateFacade struct {
-	serverAddress string
-	client        *resty.Client
+	serverAddress  string
+	key            string
+	publicKey      *rsa.PublicKey
+	retryIntervals []time.Duration
+	client         *resty.Client
 }
 
 // NewMetricUpdateFacade creates and returns a new MetricUpdateFacade.
 // It initializes an HTTP client and accepts the server address to which
-// the metrics will be sent.
-func NewMetricUpdateFacade(serverAddress string) *MetricUpdateFacade {
+// the metrics will be sent, the shared key used to sign them, the server's public key
+// used to encrypt them and the delays between retries of failed requests.
+// An empty key disables signing, a nil public key disables encryption,
+// and no intervals disable retries.
+func NewMetricUpdateFacade(
+	serverAddress string,
+	key string,
+	publicKey *rsa.PublicKey,
+	retryIntervals []time.Duration,
+) *MetricUpdateFacade {
 	client := resty.New()
 	return &MetricUpdateFacade{
-		serverAddress: serverAddress,
-		client:        client,
+		serverAddress:  serverAddress,
+		key:            key,
+		publicKey:      publicKey,
+		retryIntervals: retryIntervals,
+		client:         client,
 	}
 }
 
+// responseError is returned when the server responds with an error status.
+type responseError struct {
+	op         string // Short description of the failed request
+	statusCode int
+	status     string
+}
+
+// Error implements the error interface.
+func (e *responseError) Error() string {
+	return fmt.Sprintf("%s request failed: %s", e.op, e.status)
+}
+
 // Update sends the provided slice of metrics to the configured server address
-// using individual POST requests to the /update/ endpoint.
+// as a single gzip-compressed JSON array in one POST request to the /updates/ endpoint.
 //
 // It ensures the server address has the proper protocol prefix,
-// and returns an error if any request fails or if the response indicates a failure.
+// and returns an error if the request fails or if the response indicates a failure.
+// An empty slice results in no request at all. If a key is configured, every metric is sent
+// with its HMAC-SHA256 in the Hash field, and the HMAC-SHA256 of the uncompressed JSON body
+// is sent in the `HashSHA256` header. If a public key
+// is configured, the compressed body is encrypted with cryptos.Encrypt. The address of the
+// interface the server is reached through is sent in the `X-Real-IP` header.
+//
+// Requests that fail with a retriable error (connection refused, timeout,
+// or a 502, 503 or 504 response) are repeated after each of the configured retry intervals.
 //
 // Parameters:
 //   - ctx: Context for request cancellation and timeout.
 //   - metrics: Slice of metric pointers to be sent.
 //
 // Returns:
-//   - An error if any update fails or the server responds with an error status.
+//   - An error if the update fails or the server responds with an error status.
 func (m *MetricUpdateFacade) Update(ctx context.Context, metrics []*types.Metrics) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
 	serverAddress := m.serverAddress
 	if !strings.HasPrefix(serverAddress, "http://") && !strings.HasPrefix(serverAddress, "https://") {
 		serverAddress = "http://" + serverAddress
 	}
 
-	url := fmt.Sprintf("%s/update/", serverAddress)
+	url := fmt.Sprintf("%s/updates/", serverAddress)
+	realIP := httpOutboundIP(serverAddress)
+
+	payload := metrics
+	if m.key != "" {
+		signed, err := signMetrics(metrics, m.key)
+		if err != nil {
+			return err
+		}
+		payload = signed
+	}
 
-	for _, metric := range metrics {
-		body, err := compressMetrics(metric)
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	body, err := compress(data)
+	if err != nil {
+		return err
+	}
+	if m.publicKey != nil {
+		body, err = cryptos.Encrypt(m.publicKey, body)
 		if err != nil {
 			return err
 		}
-		resp, err := m.client.R().
+	}
+
+	var hash string
+	if m.key != "" {
+		hash = hashes.HashSHA256(data, m.key)
+	}
+
+	return retries.Do(ctx, m.retryIntervals, isRetriableError, func() error {
+		req := m.client.R().
 			SetContext(ctx).
 			SetHeader("Content-Type", "application/json").
 			SetHeader("Content-Encoding", "gzip").
-			SetBody(body).
-			Post(url)
+			SetBody(body)
+		if hash != "" {
+			req.SetHeader(hashes.Header, hash)
+		}
+		if realIP != "" {
+			req.SetHeader(headers.RealIP, realIP)
+		}
+
+		resp, err := req.Post(url)
 
 		if err != nil {
-			logger.Log.Errorf("Failed to send metrics update request for metric ID=%s: %v", metric.ID, err)
+			logger.Log.Errorf("Failed to send metrics update request for %d metrics: %v", len(metrics), err)
 			return fmt.Errorf("failed to send metrics update request: %w", err)
 		}
 
 		if resp.IsError() {
-			logger.Log.Errorf("Metrics update request failed for metric ID=%s: %s", metric.ID, resp.Status())
-			return fmt.Errorf("metrics update request failed: %s", resp.Status())
+			logger.Log.Errorf("Metrics update request failed for %d metrics: %s", len(metrics), resp.Status())
+			return &responseError{op: "metrics update", statusCode: resp.StatusCode(), status: resp.Status()}
 		}
 
-	}
+		return nil
+	})
+}
 
-	return nil
+// httpOutboundIP returns the local IP address used to reach the server at the given URL,
+// or an empty string if it cannot be determined.
+func httpOutboundIP(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return outboundIP(net.JoinHostPort(u.Hostname(), port))
 }
 
-func compressMetrics(metrics *types.Metrics) ([]byte, error) {
-	data, err := json.Marshal(metrics)
+// outboundIP returns the local IP address used to reach the given host and port,
+// or an empty string if it cannot be determined.
+//
+// Connecting a UDP socket only selects the route, so no packets are sent.
+func outboundIP(hostport string) string {
+	conn, err := net.Dial("udp", hostport)
 	if err != nil {
-		return nil, err
+		logger.Log.Warnf("Failed to determine the outbound address for %s: %v", hostport, err)
+		return ""
+	}
+	defer conn.Close()
+
+	addr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok {
+		return ""
 	}
+	return addr.IP.String()
+}
+
+// isRetriableError reports whether a failed request is worth repeating.
+//
+// Refused connections, timeouts and gateway or availability errors
+// reported by the server are considered transient.
+func isRetriableError(err error) bool {
+	var respErr *responseError
+	if errors.As(err, &respErr) {
+		switch respErr.statusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// signMetrics returns copies of the metrics with their Hash fields set
+// to their HMAC-SHA256 computed with the given key.
+func signMetrics(metrics []*types.Metrics, key string) ([]*types.Metrics, error) {
+	signed := make([]*types.Metrics, 0, len(metrics))
+	for _, m := range metrics {
+		hash, err := hashes.MetricSHA256(*m, key)
+		if err != nil {
+			return nil, err
+		}
+		c := *m
+		c.Hash = hash
+		signed = append(signed, &c)
+	}
+	return signed, nil
+}
 
+// compress compresses the given data with gzip.
+func compress(data []byte) ([]byte, error) {
 	var buf bytes.Buffer
 	gz := gzip.NewWriter(&buf)
 	defer func() {
 		_ = gz.Close()
 	}()
 
-	_, err = gz.Write(data)
+	_, err := gz.Write(data)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,118 @@
+package facades
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/headers"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/retries"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricGRPCFacade provides a simplified interface for sending
+// metric updates to a remote server over gRPC.
+type MetricGRPCFacade struct {
+	serverAddress  string
+	key            string
+	retryIntervals []time.Duration
+	conn           *grpc.ClientConn
+	client         pb.MetricServiceClient
+}
+
+// NewMetricGRPCFacade creates and returns a new MetricGRPCFacade.
+// It accepts the address of the server's gRPC endpoint, the shared key used to sign
+// the requests and the delays between retries of failed requests. An empty key
+// disables signing, and no intervals disable retries.
+//
+// The connection is established lazily, on the first update.
+// Returns an error if the address cannot be used as a gRPC target.
+func NewMetricGRPCFacade(serverAddress string, key string, retryIntervals []time.Duration) (*MetricGRPCFacade, error) {
+	conn, err := grpc.NewClient(
+		serverAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricGRPCFacade{
+		serverAddress:  serverAddress,
+		key:            key,
+		retryIntervals: retryIntervals,
+		conn:           conn,
+		client:         pb.NewMetricServiceClient(conn),
+	}, nil
+}
+
+// Update sends the provided slice of metrics to the server as a single
+// gzip-compressed UpdateMetrics call.
+//
+// An empty slice results in no call at all. If a key is configured, the HMAC-SHA256
+// of the deterministically marshaled request is sent in the `hashsha256` metadata.
+// The address of the interface the server is reached through is sent in the `x-real-ip` metadata.
+//
+// Calls that fail with Unavailable or DeadlineExceeded are repeated after each
+// of the configured retry intervals.
+//
+// Parameters:
+//   - ctx: Context for call cancellation and timeout.
+//   - metrics: Slice of metric pointers to be sent.
+//
+// Returns:
+//   - An error if the update fails or the server responds with an error status.
+func (m *MetricGRPCFacade) Update(ctx context.Context, metrics []*types.Metrics) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	req := &pb.UpdateMetricsRequest{Metrics: pb.NewMetrics(metrics)}
+
+	if realIP := outboundIP(m.serverAddress); realIP != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, headers.RealIPMetadataKey, realIP)
+	}
+
+	if m.key != "" {
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+		if err != nil {
+			return err
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, hashes.MetadataKey, hashes.HashSHA256(data, m.key))
+	}
+
+	return retries.Do(ctx, m.retryIntervals, isRetriableGRPCError, func() error {
+		_, err := m.client.UpdateMetrics(ctx, req)
+		if err != nil {
+			logger.Log.Errorf("Metrics update call failed for %d metrics: %v", len(metrics), err)
+			return fmt.Errorf("metrics update call failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// Close closes the connection to the server.
+func (m *MetricGRPCFacade) Close() error {
+	return m.conn.Close()
+}
+
+// isRetriableGRPCError reports whether a failed call is worth repeating.
+//
+// Unavailable servers or storages and timeouts are considered transient.
+func isRetriableGRPCError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package facades
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/headers"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// stubMetricServer records UpdateMetrics calls and fails the first ones with the configured errors.
+type stubMetricServer struct {
+	pb.UnimplementedMetricServiceServer
+
+	mu       sync.Mutex
+	errs     []error
+	requests []*pb.UpdateMetricsRequest
+	hashes   []string
+	realIPs  []string
+}
+
+// UpdateMetrics records the request and its signature.
+func (s *stubMetricServer) UpdateMetrics(ctx context.Context, req *pb.UpdateMetricsRequest) (*pb.UpdateMetricsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req)
+	s.hashes = append(s.hashes, metadata.ValueFromIncomingContext(ctx, hashes.MetadataKey)...)
+	s.realIPs = append(s.realIPs, metadata.ValueFromIncomingContext(ctx, headers.RealIPMetadataKey)...)
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+	return &pb.UpdateMetricsResponse{Metrics: req.GetMetrics()}, nil
+}
+
+// startStubMetricServer serves the stub on a random local port and returns its address.
+func startStubMetricServer(t *testing.T, stub *stubMetricServer) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterMetricServiceServer(server, stub)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestMetricGRPCFacade_Update(t *testing.T) {
+	delta := int64(5)
+	value := 1.5
+	metrics := []*types.Metrics{
+		{ID: "PollCount", Type: types.Counter, Delta: &delta},
+		{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: &value},
+	}
+
+	tests := []struct {
+		name          string
+		key           string
+		errs          []error
+		wantErr       bool
+		wantCalls     int
+		wantSignature bool
+	}{
+		{
+			name:      "success",
+			wantCalls: 1,
+		},
+		{
+			name:          "signed",
+			key:           "secret",
+			wantCalls:     1,
+			wantSignature: true,
+		},
+		{
+			name:      "retries unavailable",
+			errs:      []error{status.Error(codes.Unavailable, "storage temporarily unavailable")},
+			wantCalls: 2,
+		},
+		{
+			name:      "does not retry invalid argument",
+			errs:      []error{status.Error(codes.InvalidArgument, "invalid metric value")},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubMetricServer{errs: tt.errs}
+			addr := startStubMetricServer(t, stub)
+
+			facade, err := NewMetricGRPCFacade(addr, tt.key, []time.Duration{10 * time.Millisecond})
+			require.NoError(t, err)
+			defer facade.Close()
+
+			err = facade.Update(context.Background(), metrics)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			require.Len(t, stub.requests, tt.wantCalls)
+			got := stub.requests[len(stub.requests)-1]
+			require.Len(t, got.GetMetrics(), len(metrics))
+			for i, m := range got.GetMetrics() {
+				assert.Equal(t, metrics[i], m.ToMetrics())
+			}
+
+			assert.Equal(t, "127.0.0.1", stub.realIPs[len(stub.realIPs)-1])
+
+			if tt.wantSignature {
+				data, err := proto.MarshalOptions{Deterministic: true}.Marshal(got)
+				require.NoError(t, err)
+				assert.Equal(t, []string{hashes.HashSHA256(data, tt.key)}, stub.hashes)
+			} else {
+				assert.Empty(t, stub.hashes)
+			}
+		})
+	}
+}
+
+func TestMetricGRPCFacade_Update_Empty(t *testing.T) {
+	stub := &stubMetricServer{}
+	facade, err := NewMetricGRPCFacade(startStubMetricServer(t, stub), "", nil)
+	require.NoError(t, err)
+	defer facade.Close()
+
+	assert.NoError(t, facade.Update(context.Background(), nil))
+	assert.Empty(t, stub.requests)
+}
+
+func TestIsRetriableGRPCError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{status.Error(codes.Unavailable, "unavailable"), true},
+		{status.Error(codes.DeadlineExceeded, "timeout"), true},
+		{status.Error(codes.InvalidArgument, "invalid"), false},
+		{status.Error(codes.Internal, "internal"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(status.Code(tt.err).String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetriableGRPCError(tt.err))
+		})
+	}
+}
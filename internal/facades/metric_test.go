@@ -4,17 +4,27 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/sbilibin2017/yandex-go-advanced/internal/cryptos"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/headers"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Helper function to decompress gzip request body if needed
@@ -48,8 +58,8 @@ func TestMetricUpdateFacade_Update(t *testing.T) {
 				}
 				defer bodyReader.Close()
 
-				var m types.Metrics
-				if err := json.NewDecoder(bodyReader).Decode(&m); err != nil {
+				var m []types.Metrics
+				if err := json.NewDecoder(bodyReader).Decode(&m); err != nil || len(m) != 1 {
 					http.Error(w, "bad request", http.StatusBadRequest)
 					return
 				}
@@ -131,8 +141,8 @@ func TestMetricUpdateFacade_Update(t *testing.T) {
 				}
 				defer bodyReader.Close()
 
-				var m types.Metrics
-				if err := json.NewDecoder(bodyReader).Decode(&m); err != nil {
+				var m []types.Metrics
+				if err := json.NewDecoder(bodyReader).Decode(&m); err != nil || len(m) != 1 {
 					http.Error(w, "bad request", http.StatusBadRequest)
 					return
 				}
@@ -162,7 +172,7 @@ func TestMetricUpdateFacade_Update(t *testing.T) {
 				ts.Close()
 			} else {
 				r := chi.NewRouter()
-				r.Post("/update/", tt.handlerFunc)
+				r.Post("/updates/", tt.handlerFunc)
 				ts = httptest.NewServer(r)
 			}
 
@@ -176,7 +186,7 @@ func TestMetricUpdateFacade_Update(t *testing.T) {
 				}
 			}
 
-			facade := NewMetricUpdateFacade(serverAddr)
+			facade := NewMetricUpdateFacade(serverAddr, "", nil, nil)
 
 			err := facade.Update(context.Background(), tt.metrics)
 
@@ -192,6 +202,264 @@ func TestMetricUpdateFacade_Update(t *testing.T) {
 	}
 }
 
+func TestMetricUpdateFacade_Update_SingleBatchRequest(t *testing.T) {
+	var requests int
+	var received []types.Metrics
+
+	r := chi.NewRouter()
+	r.Post("/updates/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		bodyReader, err := decompressRequestBody(r)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer bodyReader.Close()
+		assert.NoError(t, json.NewDecoder(bodyReader).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	v := 1.5
+	d := int64(2)
+	metrics := []*types.Metrics{
+		{ID: "g", Type: types.Gauge, Value: &v},
+		{ID: "c", Type: types.Counter, Delta: &d},
+	}
+
+	facade := NewMetricUpdateFacade(ts.URL, "", nil, nil)
+
+	assert.NoError(t, facade.Update(context.Background(), metrics))
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, []types.Metrics{*metrics[0], *metrics[1]}, received)
+
+	// An empty report produces no request
+	assert.NoError(t, facade.Update(context.Background(), nil))
+	assert.Equal(t, 1, requests)
+}
+
+func TestMetricUpdateFacade_Update_SignsBody(t *testing.T) {
+	const key = "secret"
+
+	tests := []struct {
+		name     string
+		key      string
+		wantHash bool
+	}{
+		{name: "with key", key: key, wantHash: true},
+		{name: "without key", key: "", wantHash: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotHash string
+			var gotBody []byte
+
+			r := chi.NewRouter()
+			r.Post("/updates/", func(w http.ResponseWriter, r *http.Request) {
+				gotHash = r.Header.Get(hashes.Header)
+				bodyReader, err := decompressRequestBody(r)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer bodyReader.Close()
+				gotBody, _ = io.ReadAll(bodyReader)
+				w.WriteHeader(http.StatusOK)
+			})
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			v := 1.5
+			metrics := []*types.Metrics{{ID: "g", Type: types.Gauge, Value: &v}}
+			facade := NewMetricUpdateFacade(ts.URL, tt.key, nil, nil)
+			assert.NoError(t, facade.Update(context.Background(), metrics))
+			assert.Empty(t, metrics[0].Hash, "the caller's metrics must not be modified")
+
+			var sent []types.Metrics
+			require.NoError(t, json.Unmarshal(gotBody, &sent))
+			require.Len(t, sent, 1)
+
+			if tt.wantHash {
+				assert.True(t, hashes.VerifySHA256(gotBody, key, gotHash), "signature must match the uncompressed body")
+				assert.True(t, hashes.VerifyMetricSHA256(sent[0], key), "every metric must carry its own signature")
+			} else {
+				assert.Empty(t, gotHash)
+				assert.Empty(t, sent[0].Hash)
+			}
+		})
+	}
+}
+
+func TestMetricUpdateFacade_Update_EncryptsBody(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var received []types.Metrics
+	r := chi.NewRouter()
+	r.Post("/updates/", func(w http.ResponseWriter, r *http.Request) {
+		encrypted, _ := io.ReadAll(r.Body)
+		compressed, err := cryptos.Decrypt(key, encrypted)
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		gr, err := gzip.NewReader(bytes.NewReader(compressed))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, json.NewDecoder(gr).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	v := 1.5
+	metrics := []*types.Metrics{{ID: "g", Type: types.Gauge, Value: &v}}
+
+	facade := NewMetricUpdateFacade(ts.URL, "", &key.PublicKey, nil)
+
+	assert.NoError(t, facade.Update(context.Background(), metrics))
+	assert.Equal(t, []types.Metrics{*metrics[0]}, received)
+}
+
+func TestMetricUpdateFacade_Update_SetsRealIP(t *testing.T) {
+	var gotIP string
+	r := chi.NewRouter()
+	r.Post("/updates/", func(w http.ResponseWriter, r *http.Request) {
+		gotIP = r.Header.Get(headers.RealIP)
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	v := 1.5
+	facade := NewMetricUpdateFacade(ts.URL, "", nil, nil)
+	assert.NoError(t, facade.Update(context.Background(), []*types.Metrics{{ID: "g", Type: types.Gauge, Value: &v}}))
+
+	// The test server listens on the loopback interface
+	assert.Equal(t, "127.0.0.1", gotIP)
+}
+
+func TestHTTPOutboundIP(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "explicit port", url: "http://127.0.0.1:8080", want: "127.0.0.1"},
+		{name: "default http port", url: "http://127.0.0.1", want: "127.0.0.1"},
+		{name: "default https port", url: "https://127.0.0.1", want: "127.0.0.1"},
+		{name: "unresolvable host", url: "http://host.invalid:8080", want: ""},
+		{name: "malformed url", url: "http://[::1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, httpOutboundIP(tt.url))
+		})
+	}
+}
+
+func TestMetricUpdateFacade_Update_Retries(t *testing.T) {
+	retryIntervals := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantRequests int
+	}{
+		{
+			name:         "succeeds after transient failures",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantErr:      false,
+			wantRequests: 3,
+		},
+		{
+			name:         "gives up after exhausting retries",
+			statuses:     []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout},
+			wantErr:      true,
+			wantRequests: 4,
+		},
+		{
+			name:         "does not retry client errors",
+			statuses:     []int{http.StatusBadRequest},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:         "does not retry internal server errors",
+			statuses:     []int{http.StatusInternalServerError},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+
+			r := chi.NewRouter()
+			r.Post("/updates/", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[requests])
+				requests++
+			})
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			v := 1.5
+			facade := NewMetricUpdateFacade(ts.URL, "", nil, retryIntervals)
+			err := facade.Update(context.Background(), []*types.Metrics{{ID: "g", Type: types.Gauge, Value: &v}})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestMetricUpdateFacade_Update_RetriesConnectionRefused(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	addr := ts.URL
+	ts.Close()
+
+	v := 1.5
+	facade := NewMetricUpdateFacade(addr, "", nil, []time.Duration{time.Millisecond, time.Millisecond})
+	err := facade.Update(context.Background(), []*types.Metrics{{ID: "g", Type: types.Gauge, Value: &v}})
+
+	assert.Error(t, err)
+	assert.True(t, isRetriableError(err), "connection refused must be considered retriable")
+}
+
+func TestIsRetriableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: true},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: true},
+		{name: "context canceled", err: context.Canceled, want: false},
+		{name: "bad gateway", err: &responseError{statusCode: http.StatusBadGateway}, want: true},
+		{name: "service unavailable", err: &responseError{statusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "gateway timeout", err: &responseError{statusCode: http.StatusGatewayTimeout}, want: true},
+		{name: "bad request", err: &responseError{statusCode: http.StatusBadRequest}, want: false},
+		{name: "other error", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetriableError(tt.err))
+		})
+	}
+}
+
 func TestCompressMetrics(t *testing.T) {
 	metric := &types.Metrics{
 		ID:    "testMetric",
@@ -199,7 +467,10 @@ func TestCompressMetrics(t *testing.T) {
 		Value: func() *float64 { v := 123.456; return &v }(),
 	}
 
-	compressedData, err := compressMetrics(metric)
+	data, err := json.Marshal([]*types.Metrics{metric})
+	assert.NoError(t, err)
+
+	compressedData, err := compress(data)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, compressedData)
 
@@ -214,9 +485,11 @@ func TestCompressMetrics(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Unmarshal decompressed JSON
-	var result types.Metrics
-	err = json.Unmarshal(decompressedData, &result)
+	var results []types.Metrics
+	err = json.Unmarshal(decompressedData, &results)
 	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	result := results[0]
 
 	// Compare result with original metric
 	assert.Equal(t, metric.ID, result.ID)
@@ -228,7 +501,10 @@ func TestCompressMetrics(t *testing.T) {
 func TestCompressMetrics_EmptyMetric(t *testing.T) {
 	emptyMetric := &types.Metrics{}
 
-	compressedData, err := compressMetrics(emptyMetric)
+	data, err := json.Marshal([]*types.Metrics{emptyMetric})
+	assert.NoError(t, err)
+
+	compressedData, err := compress(data)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, compressedData)
 
@@ -240,8 +516,8 @@ func TestCompressMetrics_EmptyMetric(t *testing.T) {
 	decompressedData, err := io.ReadAll(reader)
 	assert.NoError(t, err)
 
-	var result types.Metrics
+	var result []*types.Metrics
 	err = json.Unmarshal(decompressedData, &result)
 	assert.NoError(t, err)
-	assert.Equal(t, emptyMetric, &result)
+	assert.Equal(t, []*types.Metrics{emptyMetric}, result)
 }
@@ -0,0 +1,192 @@
+// Package grpcservers provides the gRPC service implementations of the server.
+// They play the role HTTP handlers play for the HTTP API and are backed by the same services.
+package grpcservers
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricServerUpdater defines the interface for applying a batch of metric updates.
+type MetricServerUpdater interface {
+	// Update processes a batch of metrics and returns the updated metrics or an error.
+	Update(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error)
+}
+
+// MetricServerGetter defines the interface for fetching a metric by its ID.
+type MetricServerGetter interface {
+	// Get retrieves a metric given its ID.
+	// Returns the metric, nil if it does not exist, or an error if retrieval fails.
+	Get(ctx context.Context, id types.MetricID) (*types.Metrics, error)
+}
+
+// MetricServerLister defines the interface for listing metrics.
+type MetricServerLister interface {
+	// List retrieves the metrics whose labels contain every pair of the filter.
+	// Returns a slice of Metrics or an error if retrieval fails.
+	List(ctx context.Context, filter types.Labels) ([]types.Metrics, error)
+}
+
+// MetricServer implements the MetricService gRPC service.
+type MetricServer struct {
+	pb.UnimplementedMetricServiceServer
+
+	valMetric func(metric types.Metrics) error
+	valID     func(id types.MetricID) error
+	updater   MetricServerUpdater
+	getter    MetricServerGetter
+	lister    MetricServerLister
+}
+
+// NewMetricServer creates a new MetricServer.
+//
+// Parameters:
+//   - valMetric: a validation function that checks the integrity of a single metric.
+//   - valID: a validation function to verify a metric ID.
+//   - updater: a service implementing MetricServerUpdater to perform updates.
+//   - getter: a service implementing MetricServerGetter to fetch metrics.
+//   - lister: a service implementing MetricServerLister to list metrics.
+//
+// Returns:
+//   - A pointer to a MetricServer ready to be registered with a gRPC server.
+func NewMetricServer(
+	valMetric func(metric types.Metrics) error,
+	valID func(id types.MetricID) error,
+	updater MetricServerUpdater,
+	getter MetricServerGetter,
+	lister MetricServerLister,
+) *MetricServer {
+	return &MetricServer{
+		valMetric: valMetric,
+		valID:     valID,
+		updater:   updater,
+		getter:    getter,
+		lister:    lister,
+	}
+}
+
+// UpdateMetrics validates every metric of the batch and applies the batch as a whole,
+// like the HTTP /updates/ endpoint. It responds with the metrics as stored after the update.
+func (s *MetricServer) UpdateMetrics(
+	ctx context.Context,
+	req *pb.UpdateMetricsRequest,
+) (*pb.UpdateMetricsResponse, error) {
+	metrics, err := s.update(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateMetricsResponse{Metrics: pb.NewMetrics(metrics)}, nil
+}
+
+// UpdateMetricsStream applies every batch received over the stream as it arrives.
+// Once the client closes the stream, it responds with the number of metrics updated.
+//
+// A failing batch aborts the stream; the batches applied before it stay applied.
+func (s *MetricServer) UpdateMetricsStream(stream pb.MetricService_UpdateMetricsStreamServer) error {
+	var updated int64
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&pb.UpdateMetricsStreamResponse{Updated: updated})
+		}
+		if err != nil {
+			return err
+		}
+
+		metrics, err := s.update(stream.Context(), req)
+		if err != nil {
+			return err
+		}
+		updated += int64(len(metrics))
+	}
+}
+
+// update validates and applies a single batch of metrics.
+func (s *MetricServer) update(ctx context.Context, req *pb.UpdateMetricsRequest) ([]*types.Metrics, error) {
+	metrics := make([]*types.Metrics, 0, len(req.GetMetrics()))
+	for _, msg := range req.GetMetrics() {
+		metric := msg.ToMetrics()
+		if err := s.valMetric(*metric); err != nil {
+			return nil, metricStatusError(err)
+		}
+		metrics = append(metrics, metric)
+	}
+
+	metrics, err := s.updater.Update(ctx, metrics)
+	if err != nil {
+		return nil, metricStatusError(err)
+	}
+	return metrics, nil
+}
+
+// GetMetric returns the metric with the requested ID.
+func (s *MetricServer) GetMetric(
+	ctx context.Context,
+	req *pb.GetMetricRequest,
+) (*pb.GetMetricResponse, error) {
+	id := req.GetId().ToMetricID()
+	if err := s.valID(id); err != nil {
+		return nil, metricStatusError(err)
+	}
+
+	metric, err := s.getter.Get(ctx, id)
+	if err != nil {
+		return nil, metricStatusError(err)
+	}
+	if metric == nil {
+		return nil, metricStatusError(internalErrors.ErrMetricNotFound)
+	}
+	return &pb.GetMetricResponse{Metric: pb.NewMetric(metric)}, nil
+}
+
+// ListMetrics returns the metrics having all of the requested labels, or all metrics if none are requested.
+func (s *MetricServer) ListMetrics(
+	ctx context.Context,
+	req *pb.ListMetricsRequest,
+) (*pb.ListMetricsResponse, error) {
+	metrics, err := s.lister.List(ctx, types.NewLabels(req.GetLabels()))
+	if err != nil {
+		return nil, metricStatusError(err)
+	}
+
+	resp := &pb.ListMetricsResponse{Metrics: make([]*pb.Metric, 0, len(metrics))}
+	for i := range metrics {
+		resp.Metrics = append(resp.Metrics, pb.NewMetric(&metrics[i]))
+	}
+	return resp, nil
+}
+
+// metricStatusError converts an error to a gRPC status error the same way
+// the HTTP handlers choose their status codes.
+//
+// Unknown metrics are reported as NotFound, malformed ones as InvalidArgument
+// and transient storage failures as Unavailable, so clients may retry them.
+// Any other error is reported as Internal without its details.
+func metricStatusError(err error) error {
+	if errors.Is(err, internalErrors.ErrStorageUnavailable) {
+		return status.Error(codes.Unavailable, internalErrors.ErrStorageUnavailable.Error())
+	}
+
+	switch err {
+	case internalErrors.ErrMetricIDInvalid, internalErrors.ErrMetricNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case internalErrors.ErrMetricNameMissing,
+		internalErrors.ErrMetricTypeInvalid,
+		internalErrors.ErrMetricLabelsInvalid,
+		internalErrors.ErrMetricLabelsAmbiguous,
+		internalErrors.ErrMetricValueInvalid,
+		internalErrors.ErrMetricDeltaInvalid,
+		internalErrors.ErrMetricHistogramInvalid,
+		internalErrors.ErrMetricSummaryInvalid:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, internalErrors.ErrInternalServerError.Error())
+}
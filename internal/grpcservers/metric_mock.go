@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/grpcservers/metric.go
+
+// Package grpcservers is a generated GoMock package.
+package grpcservers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockMetricServerUpdater is a mock of MetricServerUpdater interface.
+type MockMetricServerUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricServerUpdaterMockRecorder
+}
+
+// MockMetricServerUpdaterMockRecorder is the mock recorder for MockMetricServerUpdater.
+type MockMetricServerUpdaterMockRecorder struct {
+	mock *MockMetricServerUpdater
+}
+
+// NewMockMetricServerUpdater creates a new mock instance.
+func NewMockMetricServerUpdater(ctrl *gomock.Controller) *MockMetricServerUpdater {
+	mock := &MockMetricServerUpdater{ctrl: ctrl}
+	mock.recorder = &MockMetricServerUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricServerUpdater) EXPECT() *MockMetricServerUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockMetricServerUpdater) Update(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].([]*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMetricServerUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricServerUpdater)(nil).Update), ctx, metrics)
+}
+
+// MockMetricServerGetter is a mock of MetricServerGetter interface.
+type MockMetricServerGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricServerGetterMockRecorder
+}
+
+// MockMetricServerGetterMockRecorder is the mock recorder for MockMetricServerGetter.
+type MockMetricServerGetterMockRecorder struct {
+	mock *MockMetricServerGetter
+}
+
+// NewMockMetricServerGetter creates a new mock instance.
+func NewMockMetricServerGetter(ctrl *gomock.Controller) *MockMetricServerGetter {
+	mock := &MockMetricServerGetter{ctrl: ctrl}
+	mock.recorder = &MockMetricServerGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricServerGetter) EXPECT() *MockMetricServerGetterMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockMetricServerGetter) Get(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockMetricServerGetterMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMetricServerGetter)(nil).Get), ctx, id)
+}
+
+// MockMetricServerLister is a mock of MetricServerLister interface.
+type MockMetricServerLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricServerListerMockRecorder
+}
+
+// MockMetricServerListerMockRecorder is the mock recorder for MockMetricServerLister.
+type MockMetricServerListerMockRecorder struct {
+	mock *MockMetricServerLister
+}
+
+// NewMockMetricServerLister creates a new mock instance.
+func NewMockMetricServerLister(ctrl *gomock.Controller) *MockMetricServerLister {
+	mock := &MockMetricServerLister{ctrl: ctrl}
+	mock.recorder = &MockMetricServerListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricServerLister) EXPECT() *MockMetricServerListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMetricServerLister) List(ctx context.Context, filter types.Labels) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMetricServerListerMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricServerLister)(nil).List), ctx, filter)
+}
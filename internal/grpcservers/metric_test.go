@@ -0,0 +1,261 @@
+package grpcservers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// newTestClient serves the given MetricServer over an in-memory connection and returns a client for it.
+func newTestClient(t *testing.T, srv *MetricServer) pb.MetricServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterMetricServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewMetricServiceClient(conn)
+}
+
+func TestMetricServer_UpdateMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	delta := int64(5)
+	metric := &types.Metrics{ID: "PollCount", Type: types.Counter, Delta: &delta}
+
+	tests := []struct {
+		name     string
+		valErr   error
+		mockFunc func(m *MockMetricServerUpdater)
+		wantCode codes.Code
+	}{
+		{
+			name:     "validation error",
+			valErr:   internalErrors.ErrMetricDeltaInvalid,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid id",
+			valErr:   internalErrors.ErrMetricIDInvalid,
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "storage unavailable",
+			mockFunc: func(m *MockMetricServerUpdater) {
+				m.EXPECT().Update(gomock.Any(), []*types.Metrics{metric}).
+					Return(nil, fmt.Errorf("%w: connection reset", internalErrors.ErrStorageUnavailable))
+			},
+			wantCode: codes.Unavailable,
+		},
+		{
+			name: "internal error",
+			mockFunc: func(m *MockMetricServerUpdater) {
+				m.EXPECT().Update(gomock.Any(), []*types.Metrics{metric}).
+					Return(nil, internalErrors.ErrInternalServerError)
+			},
+			wantCode: codes.Internal,
+		},
+		{
+			name: "success",
+			mockFunc: func(m *MockMetricServerUpdater) {
+				m.EXPECT().Update(gomock.Any(), []*types.Metrics{metric}).
+					Return([]*types.Metrics{metric}, nil)
+			},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updater := NewMockMetricServerUpdater(ctrl)
+			if tt.mockFunc != nil {
+				tt.mockFunc(updater)
+			}
+			val := func(types.Metrics) error { return tt.valErr }
+			client := newTestClient(t, NewMetricServer(val, nil, updater, nil, nil))
+
+			resp, err := client.UpdateMetrics(context.Background(), &pb.UpdateMetricsRequest{
+				Metrics: []*pb.Metric{pb.NewMetric(metric)},
+			})
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				require.Len(t, resp.GetMetrics(), 1)
+				assert.Equal(t, metric, resp.GetMetrics()[0].ToMetrics())
+			}
+		})
+	}
+}
+
+func TestMetricServer_UpdateMetricsStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	value := 1.5
+	gauge := &types.Metrics{ID: "Alloc", Type: types.Gauge, Value: &value}
+
+	updater := NewMockMetricServerUpdater(ctrl)
+	updater.EXPECT().Update(gomock.Any(), []*types.Metrics{gauge, gauge}).Return([]*types.Metrics{gauge, gauge}, nil)
+	updater.EXPECT().Update(gomock.Any(), []*types.Metrics{gauge}).Return([]*types.Metrics{gauge}, nil)
+
+	val := func(types.Metrics) error { return nil }
+	client := newTestClient(t, NewMetricServer(val, nil, updater, nil, nil))
+
+	stream, err := client.UpdateMetricsStream(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{pb.NewMetric(gauge), pb.NewMetric(gauge)}}))
+	require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{pb.NewMetric(gauge)}}))
+
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.GetUpdated())
+}
+
+func TestMetricServer_UpdateMetricsStream_Error(t *testing.T) {
+	value := 1.5
+	gauge := &types.Metrics{ID: "Alloc", Type: types.Gauge, Value: &value}
+
+	val := func(types.Metrics) error { return internalErrors.ErrMetricValueInvalid }
+	client := newTestClient(t, NewMetricServer(val, nil, nil, nil, nil))
+
+	stream, err := client.UpdateMetricsStream(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{pb.NewMetric(gauge)}}))
+
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMetricServer_GetMetric(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	value := 1.5
+	id := types.MetricID{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"})}
+	metric := &types.Metrics{ID: id.ID, Type: id.Type, Labels: id.Labels, Value: &value}
+
+	tests := []struct {
+		name     string
+		valErr   error
+		mockFunc func(m *MockMetricServerGetter)
+		wantCode codes.Code
+	}{
+		{
+			name:     "invalid type",
+			valErr:   internalErrors.ErrMetricTypeInvalid,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "not found",
+			mockFunc: func(m *MockMetricServerGetter) {
+				m.EXPECT().Get(gomock.Any(), id).Return(nil, nil)
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "getter error",
+			mockFunc: func(m *MockMetricServerGetter) {
+				m.EXPECT().Get(gomock.Any(), id).Return(nil, fmt.Errorf("db error"))
+			},
+			wantCode: codes.Internal,
+		},
+		{
+			name: "success",
+			mockFunc: func(m *MockMetricServerGetter) {
+				m.EXPECT().Get(gomock.Any(), id).Return(metric, nil)
+			},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getter := NewMockMetricServerGetter(ctrl)
+			if tt.mockFunc != nil {
+				tt.mockFunc(getter)
+			}
+			val := func(types.MetricID) error { return tt.valErr }
+			client := newTestClient(t, NewMetricServer(nil, val, nil, getter, nil))
+
+			resp, err := client.GetMetric(context.Background(), &pb.GetMetricRequest{Id: pb.NewMetricID(id)})
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, metric, resp.GetMetric().ToMetrics())
+			}
+		})
+	}
+}
+
+func TestMetricServer_ListMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	value := 1.5
+	filter := types.NewLabels(map[string]string{"host": "a"})
+	metrics := []types.Metrics{{ID: "Alloc", Type: types.Gauge, Labels: filter, Value: &value}}
+
+	tests := []struct {
+		name     string
+		mockFunc func(m *MockMetricServerLister)
+		wantCode codes.Code
+	}{
+		{
+			name: "lister error",
+			mockFunc: func(m *MockMetricServerLister) {
+				m.EXPECT().List(gomock.Any(), filter).Return(nil, fmt.Errorf("db error"))
+			},
+			wantCode: codes.Internal,
+		},
+		{
+			name: "success",
+			mockFunc: func(m *MockMetricServerLister) {
+				m.EXPECT().List(gomock.Any(), filter).Return(metrics, nil)
+			},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister := NewMockMetricServerLister(ctrl)
+			tt.mockFunc(lister)
+			client := newTestClient(t, NewMetricServer(nil, nil, nil, nil, lister))
+
+			resp, err := client.ListMetrics(context.Background(), &pb.ListMetricsRequest{Labels: filter.Map()})
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				require.Len(t, resp.GetMetrics(), 1)
+				assert.Equal(t, &metrics[0], resp.GetMetrics()[0].ToMetrics())
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// AlertLister defines the interface for listing the state of alert rules.
+type AlertLister interface {
+	// List retrieves the current state of every alert rule.
+	// Returns a slice of Alert or an error if retrieval fails.
+	List(ctx context.Context) ([]types.Alert, error)
+}
+
+// NewAlertListHandler returns an HTTP handler function that
+// serves the current state of all alert rules as a JSON array.
+//
+// Parameters:
+//   - svc: a service implementing AlertLister to fetch the alerts.
+//
+// Returns:
+//   - http.HandlerFunc that can be registered to serve alert listings.
+func NewAlertListHandler(
+	svc AlertLister,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		alerts, err := svc.List(r.Context())
+		if err != nil {
+			handleAlertListError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(alerts)
+	}
+}
+
+// handleAlertListError handles errors that occur during alert listing
+// by sending an HTTP 500 Internal Server Error response with a generic message.
+func handleAlertListError(w http.ResponseWriter, err error) {
+	switch err {
+	default:
+		http.Error(w, errors.ErrInternalServerError.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/alert_list.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockAlertLister is a mock of AlertLister interface.
+type MockAlertLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertListerMockRecorder
+}
+
+// MockAlertListerMockRecorder is the mock recorder for MockAlertLister.
+type MockAlertListerMockRecorder struct {
+	mock *MockAlertLister
+}
+
+// NewMockAlertLister creates a new mock instance.
+func NewMockAlertLister(ctrl *gomock.Controller) *MockAlertLister {
+	mock := &MockAlertLister{ctrl: ctrl}
+	mock.recorder = &MockAlertListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertLister) EXPECT() *MockAlertListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAlertLister) List(ctx context.Context) ([]types.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]types.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAlertListerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAlertLister)(nil).List), ctx)
+}
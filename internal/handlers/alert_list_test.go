@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAlertListHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockAlertLister(ctrl)
+
+	handler := NewAlertListHandler(mockSvc)
+
+	value := 2.0
+	alerts := []types.Alert{
+		{Rule: "HighLoad", Expr: "gauge Load > 1", State: types.AlertFiring, Value: &value},
+		{Rule: "Stalled", Expr: "counter PollCount rate == 0 for 1m", State: types.AlertInactive},
+	}
+
+	tests := []struct {
+		name       string
+		setupMock  func()
+		wantCode   int
+		wantAlerts []types.Alert
+	}{
+		{
+			name: "success returns JSON",
+			setupMock: func() {
+				mockSvc.EXPECT().List(gomock.Any()).Return(alerts, nil)
+			},
+			wantCode:   http.StatusOK,
+			wantAlerts: alerts,
+		},
+		{
+			name: "no rules returns empty array",
+			setupMock: func() {
+				mockSvc.EXPECT().List(gomock.Any()).Return([]types.Alert{}, nil)
+			},
+			wantCode:   http.StatusOK,
+			wantAlerts: []types.Alert{},
+		},
+		{
+			name: "service returns error",
+			setupMock: func() {
+				mockSvc.EXPECT().List(gomock.Any()).Return(nil, errors.New("fail"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/alerts", nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantAlerts == nil {
+				return
+			}
+
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			var got []types.Alert
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+			assert.Equal(t, tt.wantAlerts, got)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// labelsQueryParam is the query parameter selecting metrics by labels,
+// written as comma-separated key=value pairs, e.g. "?labels=host=a,env=prod".
+const labelsQueryParam = "labels"
+
+// parseLabelsQuery returns the labels given in the request's labels query parameter.
+// It returns the empty set if the parameter is absent, or ErrMetricLabelsInvalid
+// if it is malformed.
+func parseLabelsQuery(r *http.Request) (types.Labels, error) {
+	labels, ok := types.ParseLabels(r.URL.Query().Get(labelsQueryParam))
+	if !ok {
+		return "", errors.ErrMetricLabelsInvalid
+	}
+	return labels, nil
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// LogLevelGetter defines the interface for reading the current log level.
+type LogLevelGetter interface {
+	// Level returns the current minimum enabled log level.
+	Level() zapcore.Level
+}
+
+// LogLevelSetter defines the interface for changing the log level at runtime.
+type LogLevelSetter interface {
+	// SetLevel changes the minimum enabled log level.
+	SetLevel(level zapcore.Level)
+}
+
+// NewLogLevelGetHandler returns an HTTP handler function that
+// serves the current log level as JSON, e.g. {"level":"info"}.
+//
+// Parameters:
+//   - level: the log level to serve, usually logger.AtomicLevel().
+//
+// Returns:
+//   - http.HandlerFunc that can be registered to serve the log level.
+func NewLogLevelGetHandler(
+	level LogLevelGetter,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeLogLevel(w, level.Level())
+	}
+}
+
+// NewLogLevelSetHandler returns an HTTP handler function that
+// changes the log level to the one given in the JSON request body,
+// e.g. {"level":"debug"}, and responds with the new level.
+//
+// Parameters:
+//   - level: the log level to change, usually logger.AtomicLevel().
+//
+// Returns:
+//   - http.HandlerFunc that can be registered to change the log level.
+func NewLogLevelSetHandler(
+	level LogLevelSetter,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.LogLevel
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, errors.ErrLogLevelInvalid.Error(), http.StatusBadRequest)
+			return
+		}
+
+		lvl, err := zapcore.ParseLevel(req.Level)
+		if err != nil {
+			http.Error(w, errors.ErrLogLevelInvalid.Error(), http.StatusBadRequest)
+			return
+		}
+
+		level.SetLevel(lvl)
+		writeLogLevel(w, lvl)
+	}
+}
+
+// writeLogLevel writes the log level as a JSON response.
+func writeLogLevel(w http.ResponseWriter, level zapcore.Level) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(types.LogLevel{Level: level.String()})
+}
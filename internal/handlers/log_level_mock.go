@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/log_level.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	zapcore "go.uber.org/zap/zapcore"
+)
+
+// MockLogLevelGetter is a mock of LogLevelGetter interface.
+type MockLogLevelGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLogLevelGetterMockRecorder
+}
+
+// MockLogLevelGetterMockRecorder is the mock recorder for MockLogLevelGetter.
+type MockLogLevelGetterMockRecorder struct {
+	mock *MockLogLevelGetter
+}
+
+// NewMockLogLevelGetter creates a new mock instance.
+func NewMockLogLevelGetter(ctrl *gomock.Controller) *MockLogLevelGetter {
+	mock := &MockLogLevelGetter{ctrl: ctrl}
+	mock.recorder = &MockLogLevelGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLogLevelGetter) EXPECT() *MockLogLevelGetterMockRecorder {
+	return m.recorder
+}
+
+// Level mocks base method.
+func (m *MockLogLevelGetter) Level() zapcore.Level {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Level")
+	ret0, _ := ret[0].(zapcore.Level)
+	return ret0
+}
+
+// Level indicates an expected call of Level.
+func (mr *MockLogLevelGetterMockRecorder) Level() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Level", reflect.TypeOf((*MockLogLevelGetter)(nil).Level))
+}
+
+// MockLogLevelSetter is a mock of LogLevelSetter interface.
+type MockLogLevelSetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLogLevelSetterMockRecorder
+}
+
+// MockLogLevelSetterMockRecorder is the mock recorder for MockLogLevelSetter.
+type MockLogLevelSetterMockRecorder struct {
+	mock *MockLogLevelSetter
+}
+
+// NewMockLogLevelSetter creates a new mock instance.
+func NewMockLogLevelSetter(ctrl *gomock.Controller) *MockLogLevelSetter {
+	mock := &MockLogLevelSetter{ctrl: ctrl}
+	mock.recorder = &MockLogLevelSetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLogLevelSetter) EXPECT() *MockLogLevelSetterMockRecorder {
+	return m.recorder
+}
+
+// SetLevel mocks base method.
+func (m *MockLogLevelSetter) SetLevel(level zapcore.Level) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetLevel", level)
+}
+
+// SetLevel indicates an expected call of SetLevel.
+func (mr *MockLogLevelSetterMockRecorder) SetLevel(level interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLevel", reflect.TypeOf((*MockLogLevelSetter)(nil).SetLevel), level)
+}
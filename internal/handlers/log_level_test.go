@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewLogLevelGetHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLevel := NewMockLogLevelGetter(ctrl)
+	mockLevel.EXPECT().Level().Return(zapcore.WarnLevel)
+
+	handler := NewLogLevelGetHandler(mockLevel)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/loglevel", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"level":"warn"}`, rec.Body.String())
+}
+
+func TestNewLogLevelSetHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLevel := NewMockLogLevelSetter(ctrl)
+
+	handler := NewLogLevelSetHandler(mockLevel)
+
+	tests := []struct {
+		name      string
+		body      string
+		setupMock func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name: "valid level",
+			body: `{"level":"debug"}`,
+			setupMock: func() {
+				mockLevel.EXPECT().SetLevel(zapcore.DebugLevel)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"level":"debug"}`,
+		},
+		{
+			name:      "unknown level",
+			body:      `{"level":"loud"}`,
+			setupMock: func() {},
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			name:      "malformed body",
+			body:      `debug`,
+			setupMock: func() {},
+			wantCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
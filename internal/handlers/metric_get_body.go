@@ -68,12 +68,12 @@ func NewMetricGetBodyHandler(
 // based on the provided error when processing a metric get request.
 //
 // It distinguishes between invalid metric IDs, not found errors,
-// invalid metric types, and internal server errors.
+// invalid metric types, invalid or ambiguous labels, and internal server errors.
 func handleMetricGetBodyError(w http.ResponseWriter, err error) {
 	switch err {
 	case errors.ErrMetricIDInvalid, errors.ErrMetricNotFound:
 		http.Error(w, err.Error(), http.StatusNotFound)
-	case errors.ErrMetricTypeInvalid:
+	case errors.ErrMetricTypeInvalid, errors.ErrMetricLabelsInvalid, errors.ErrMetricLabelsAmbiguous:
 		http.Error(w, err.Error(), http.StatusBadRequest)
 	default:
 		http.Error(w, errors.ErrInternalServerError.Error(), http.StatusInternalServerError)
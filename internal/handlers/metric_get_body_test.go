@@ -84,6 +84,18 @@ func TestNewMetricGetBodyHandler(t *testing.T) {
 			wantCode: http.StatusNotFound,
 			wantBody: internalErrors.ErrMetricNotFound.Error() + "\n",
 		},
+		{
+			name:         "service reports ambiguous labels",
+			requestBody:  types.MetricID{ID: "name", Type: "gauge"},
+			validateFunc: validate(nil),
+			setupMock: func(metricID types.MetricID) {
+				mockSvc.EXPECT().
+					Get(gomock.Any(), metricID).
+					Return(nil, internalErrors.ErrMetricLabelsAmbiguous)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: internalErrors.ErrMetricLabelsAmbiguous.Error() + "\n",
+		},
 		{
 			name:         "success returns metric JSON",
 			requestBody:  types.MetricID{ID: "name", Type: "gauge"},
@@ -21,7 +21,8 @@ type MetricPathGetter interface {
 // metric retrieval requests with the metric type and name provided as URL path parameters.
 //
 // The handler extracts the "type" and "name" parameters from the URL path,
-// validates them using the provided validation function,
+// validates them using the provided validation function, takes the metric's labels
+// from the labels query parameter, e.g. "?labels=host=a", if any,
 // fetches the metric from the service, and writes the metric's string value
 // in the response body.
 //
@@ -45,7 +46,14 @@ func NewMetricGetPathHandler(
 			return
 		}
 
+		labels, err := parseLabelsQuery(r)
+		if err != nil {
+			handleMetricGetPathError(w, err)
+			return
+		}
+
 		id := types.NewMetricID(metricType, metricName)
+		id.Labels = labels
 
 		metric, err := svc.Get(r.Context(), *id)
 		if err != nil {
@@ -66,12 +74,12 @@ func NewMetricGetPathHandler(
 // provided error when processing a metric get request from the URL path.
 //
 // It distinguishes between missing metric names, not found errors,
-// invalid metric types, and internal server errors.
+// invalid metric types, invalid or ambiguous labels, and internal server errors.
 func handleMetricGetPathError(w http.ResponseWriter, err error) {
 	switch err {
 	case errors.ErrMetricNameMissing, errors.ErrMetricNotFound:
 		http.Error(w, err.Error(), http.StatusNotFound)
-	case errors.ErrMetricTypeInvalid:
+	case errors.ErrMetricTypeInvalid, errors.ErrMetricLabelsInvalid, errors.ErrMetricLabelsAmbiguous:
 		http.Error(w, err.Error(), http.StatusBadRequest)
 	default:
 		http.Error(w, errors.ErrInternalServerError.Error(), http.StatusInternalServerError)
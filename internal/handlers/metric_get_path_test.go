@@ -34,6 +34,7 @@ func TestNewMetricGetPathHandler(t *testing.T) {
 		name         string
 		metricType   string
 		metricName   string
+		query        string
 		validateFunc func(string, string) error
 		setupMock    func()
 		wantCode     int
@@ -121,6 +122,46 @@ func TestNewMetricGetPathHandler(t *testing.T) {
 			wantCode: http.StatusOK,
 			wantBody: "100",
 		},
+		{
+			name:         "labels are part of the metric ID",
+			metricType:   "gauge",
+			metricName:   "name",
+			query:        "?labels=host=a",
+			validateFunc: validate(nil),
+			setupMock: func() {
+				mockSvc.EXPECT().
+					Get(gomock.Any(), types.MetricID{
+						ID:     "name",
+						Type:   "gauge",
+						Labels: types.NewLabels(map[string]string{"host": "a"}),
+					}).
+					Return(&types.Metrics{ID: "name", Type: "gauge", Value: float64Ptr(1)}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "1",
+		},
+		{
+			name:         "ambiguous labels",
+			metricType:   "gauge",
+			metricName:   "name",
+			validateFunc: validate(nil),
+			setupMock: func() {
+				mockSvc.EXPECT().
+					Get(gomock.Any(), types.MetricID{ID: "name", Type: "gauge"}).
+					Return(nil, internalErrors.ErrMetricLabelsAmbiguous)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: internalErrors.ErrMetricLabelsAmbiguous.Error() + "\n",
+		},
+		{
+			name:         "malformed labels",
+			metricType:   "gauge",
+			metricName:   "name",
+			query:        "?labels=host",
+			validateFunc: validate(nil),
+			wantCode:     http.StatusBadRequest,
+			wantBody:     internalErrors.ErrMetricLabelsInvalid.Error() + "\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -130,7 +171,7 @@ func TestNewMetricGetPathHandler(t *testing.T) {
 			}
 
 			req := httptest.NewRequest(http.MethodGet,
-				"/metric/"+tt.metricType+"/"+tt.metricName,
+				"/metric/"+tt.metricType+"/"+tt.metricName+tt.query,
 				nil)
 
 			// Setup chi route context with URL params
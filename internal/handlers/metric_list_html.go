@@ -11,44 +11,71 @@ import (
 // MetricHTMLLister defines the interface for listing metrics as a slice.
 // Implementations should provide a method to retrieve all metrics.
 type MetricHTMLLister interface {
-	// List retrieves all available metrics.
+	// List retrieves the metrics whose labels contain every pair of the filter.
 	// Returns a slice of Metrics or an error if retrieval fails.
-	List(ctx context.Context) ([]types.Metrics, error)
+	List(ctx context.Context, filter types.Labels) ([]types.Metrics, error)
+}
+
+// MetricHTMLAlertLister defines the interface for listing the state of alert rules.
+type MetricHTMLAlertLister interface {
+	// List retrieves the current state of every alert rule.
+	// Returns a slice of Alert or an error if retrieval fails.
+	List(ctx context.Context) ([]types.Alert, error)
 }
 
 // NewMetricListHTMLHandler returns an HTTP handler function that
-// serves an HTML page listing all metrics.
+// serves an HTML page listing all metrics and the state of alert rules.
 //
-// It fetches the metrics from the provided MetricHTMLLister service,
-// sets the appropriate Content-Type header, and writes the HTML response.
+// It fetches the metrics from the provided MetricHTMLLister service and
+// the alerts from the provided MetricHTMLAlertLister service, sets the
+// appropriate Content-Type header, and writes the HTML response.
+// The metrics may be filtered by the labels query parameter, e.g. "?labels=host=a".
 //
 // Parameters:
 //   - svc: a service implementing MetricHTMLLister to fetch the metrics.
+//   - alertSvc: a service implementing MetricHTMLAlertLister to fetch the alerts; may be nil.
 //
 // Returns:
 //   - http.HandlerFunc that can be registered to serve metric listings.
 func NewMetricListHTMLHandler(
 	svc MetricHTMLLister,
+	alertSvc MetricHTMLAlertLister,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		metrics, err := svc.List(r.Context())
+		filter, err := parseLabelsQuery(r)
+		if err != nil {
+			handleMetricListHTMLError(w, err)
+			return
+		}
+
+		metrics, err := svc.List(r.Context(), filter)
 		if err != nil {
 			handleMetricListHTMLError(w, err)
 			return
 		}
 
+		var alerts []types.Alert
+		if alertSvc != nil {
+			alerts, err = alertSvc.List(r.Context())
+			if err != nil {
+				handleMetricListHTMLError(w, err)
+				return
+			}
+		}
+
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(types.NewMetricsHTML(metrics)))
+		w.Write([]byte(types.NewMetricsHTML(metrics, alerts)))
 	}
 }
 
 // handleMetricListHTMLError handles errors that occur during metric listing
-// by sending an HTTP 500 Internal Server Error response with a generic message.
-//
-// This function can be extended to handle more specific errors as needed.
+// by sending an HTTP 400 Bad Request response for a malformed label filter,
+// and an HTTP 500 Internal Server Error response with a generic message otherwise.
 func handleMetricListHTMLError(w http.ResponseWriter, err error) {
 	switch err {
+	case errors.ErrMetricLabelsInvalid:
+		http.Error(w, err.Error(), http.StatusBadRequest)
 	default:
 		http.Error(w, errors.ErrInternalServerError.Error(), http.StatusInternalServerError)
 	}
@@ -36,16 +36,54 @@ func (m *MockMetricHTMLLister) EXPECT() *MockMetricHTMLListerMockRecorder {
 }
 
 // List mocks base method.
-func (m *MockMetricHTMLLister) List(ctx context.Context) ([]types.Metrics, error) {
+func (m *MockMetricHTMLLister) List(ctx context.Context, filter types.Labels) ([]types.Metrics, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
+	ret := m.ctrl.Call(m, "List", ctx, filter)
 	ret0, _ := ret[0].([]types.Metrics)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockMetricHTMLListerMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockMetricHTMLListerMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricHTMLLister)(nil).List), ctx, filter)
+}
+
+// MockMetricHTMLAlertLister is a mock of MetricHTMLAlertLister interface.
+type MockMetricHTMLAlertLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricHTMLAlertListerMockRecorder
+}
+
+// MockMetricHTMLAlertListerMockRecorder is the mock recorder for MockMetricHTMLAlertLister.
+type MockMetricHTMLAlertListerMockRecorder struct {
+	mock *MockMetricHTMLAlertLister
+}
+
+// NewMockMetricHTMLAlertLister creates a new mock instance.
+func NewMockMetricHTMLAlertLister(ctrl *gomock.Controller) *MockMetricHTMLAlertLister {
+	mock := &MockMetricHTMLAlertLister{ctrl: ctrl}
+	mock.recorder = &MockMetricHTMLAlertListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricHTMLAlertLister) EXPECT() *MockMetricHTMLAlertListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMetricHTMLAlertLister) List(ctx context.Context) ([]types.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]types.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMetricHTMLAlertListerMockRecorder) List(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricHTMLLister)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricHTMLAlertLister)(nil).List), ctx)
 }
@@ -17,11 +17,13 @@ func TestNewMetricListHTMLHandler(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockSvc := NewMockMetricHTMLLister(ctrl)
+	mockAlertSvc := NewMockMetricHTMLAlertLister(ctrl)
 
-	handler := NewMetricListHTMLHandler(mockSvc)
+	handler := NewMetricListHTMLHandler(mockSvc, mockAlertSvc)
 
 	tests := []struct {
 		name          string
+		target        string
 		setupMock     func()
 		wantCode      int
 		wantBodyParts []string // parts expected in body string
@@ -30,19 +32,60 @@ func TestNewMetricListHTMLHandler(t *testing.T) {
 			name: "success returns HTML",
 			setupMock: func() {
 				mockSvc.EXPECT().
-					List(gomock.Any()).
+					List(gomock.Any(), types.Labels("")).
 					Return([]types.Metrics{
 						{ID: "m1", Type: "gauge"},
 						{ID: "m2", Type: "counter"},
 					}, nil)
+				mockAlertSvc.EXPECT().
+					List(gomock.Any()).
+					Return([]types.Alert{
+						{Rule: "HighLoad", Expr: "gauge m1 > 1", State: types.AlertFiring},
+					}, nil)
+			},
+			wantCode:      http.StatusOK,
+			wantBodyParts: []string{"m1", "m2", "n/a", "HighLoad [firing]"}, // check IDs, the "n/a" values and alerts your HTML produces
+		},
+		{
+			name:   "labels filter is passed to the service",
+			target: "/?labels=host=a",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					List(gomock.Any(), types.NewLabels(map[string]string{"host": "a"})).
+					Return([]types.Metrics{
+						{ID: "m1", Type: "gauge", Labels: types.NewLabels(map[string]string{"host": "a"})},
+					}, nil)
+				mockAlertSvc.EXPECT().
+					List(gomock.Any()).
+					Return(nil, nil)
 			},
 			wantCode:      http.StatusOK,
-			wantBodyParts: []string{"m1", "m2", "n/a"}, // check IDs and the "n/a" values your HTML produces
+			wantBodyParts: []string{"m1{host=&#34;a&#34;}"},
+		},
+		{
+			name:          "malformed labels filter",
+			target:        "/?labels=host",
+			setupMock:     func() {},
+			wantCode:      http.StatusBadRequest,
+			wantBodyParts: []string{"invalid metric labels"},
 		},
 		{
 			name: "service returns error",
 			setupMock: func() {
 				mockSvc.EXPECT().
+					List(gomock.Any(), types.Labels("")).
+					Return(nil, errors.New("fail"))
+			},
+			wantCode:      http.StatusInternalServerError,
+			wantBodyParts: []string{"internal server error"},
+		},
+		{
+			name: "alert service returns error",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					List(gomock.Any(), types.Labels("")).
+					Return([]types.Metrics{}, nil)
+				mockAlertSvc.EXPECT().
 					List(gomock.Any()).
 					Return(nil, errors.New("fail"))
 			},
@@ -55,7 +98,11 @@ func TestNewMetricListHTMLHandler(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMock()
 
-			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			target := tt.target
+			if target == "" {
+				target = "/"
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
 			w := httptest.NewRecorder()
 
 			handler(w, req)
@@ -72,3 +119,20 @@ func TestNewMetricListHTMLHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMetricListHTMLHandler_WithoutAlerts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricHTMLLister(ctrl)
+	mockSvc.EXPECT().List(gomock.Any(), types.Labels("")).Return([]types.Metrics{{ID: "m1", Type: "gauge"}}, nil)
+
+	handler := NewMetricListHTMLHandler(mockSvc, nil)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "m1")
+	assert.NotContains(t, w.Body.String(), "Alerts")
+}
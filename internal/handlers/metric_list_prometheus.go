@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricPrometheusLister defines the interface for listing metrics as a slice.
+// Implementations should provide a method to retrieve all metrics.
+type MetricPrometheusLister interface {
+	// List retrieves the metrics whose labels contain every pair of the filter.
+	// Returns a slice of Metrics or an error if retrieval fails.
+	List(ctx context.Context, filter types.Labels) ([]types.Metrics, error)
+}
+
+// NewMetricListPrometheusHandler returns an HTTP handler function that
+// serves all metrics in the Prometheus text exposition format.
+//
+// It fetches the metrics from the provided MetricPrometheusLister service,
+// sets the appropriate Content-Type header, and writes the exposition.
+// The metrics may be filtered by the labels query parameter, e.g. "?labels=host=a".
+//
+// Parameters:
+//   - svc: a service implementing MetricPrometheusLister to fetch the metrics.
+//
+// Returns:
+//   - http.HandlerFunc that can be registered to serve metric scrapes.
+func NewMetricListPrometheusHandler(
+	svc MetricPrometheusLister,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseLabelsQuery(r)
+		if err != nil {
+			handleMetricListPrometheusError(w, err)
+			return
+		}
+
+		metrics, err := svc.List(r.Context(), filter)
+		if err != nil {
+			handleMetricListPrometheusError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(types.NewMetricsPrometheus(metrics)))
+	}
+}
+
+// handleMetricListPrometheusError handles errors that occur during metric listing
+// by sending an HTTP 400 Bad Request response for a malformed label filter,
+// and an HTTP 500 Internal Server Error response with a generic message otherwise.
+func handleMetricListPrometheusError(w http.ResponseWriter, err error) {
+	switch err {
+	case errors.ErrMetricLabelsInvalid:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, errors.ErrInternalServerError.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/metric_list_prometheus.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockMetricPrometheusLister is a mock of MetricPrometheusLister interface.
+type MockMetricPrometheusLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricPrometheusListerMockRecorder
+}
+
+// MockMetricPrometheusListerMockRecorder is the mock recorder for MockMetricPrometheusLister.
+type MockMetricPrometheusListerMockRecorder struct {
+	mock *MockMetricPrometheusLister
+}
+
+// NewMockMetricPrometheusLister creates a new mock instance.
+func NewMockMetricPrometheusLister(ctrl *gomock.Controller) *MockMetricPrometheusLister {
+	mock := &MockMetricPrometheusLister{ctrl: ctrl}
+	mock.recorder = &MockMetricPrometheusListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricPrometheusLister) EXPECT() *MockMetricPrometheusListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMetricPrometheusLister) List(ctx context.Context, filter types.Labels) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMetricPrometheusListerMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricPrometheusLister)(nil).List), ctx, filter)
+}
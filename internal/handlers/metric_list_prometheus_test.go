@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetricListPrometheusHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricPrometheusLister(ctrl)
+
+	handler := NewMetricListPrometheusHandler(mockSvc)
+
+	gv := 1.5
+	cv := int64(3)
+
+	tests := []struct {
+		name            string
+		target          string
+		setupMock       func()
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "success returns exposition",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					List(gomock.Any(), types.Labels("")).
+					Return([]types.Metrics{
+						{ID: "Alloc", Type: types.Gauge, Value: &gv},
+						{ID: "PollCount", Type: types.Counter, Delta: &cv},
+					}, nil)
+			},
+			wantCode:        http.StatusOK,
+			wantContentType: "text/plain; version=0.0.4; charset=utf-8",
+			wantBody: "# TYPE Alloc gauge\nAlloc 1.5\n" +
+				"# TYPE PollCount counter\nPollCount 3\n",
+		},
+		{
+			name:   "labels filter is passed to the service",
+			target: "/metrics?labels=host=a",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					List(gomock.Any(), types.NewLabels(map[string]string{"host": "a"})).
+					Return([]types.Metrics{
+						{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: &gv},
+					}, nil)
+			},
+			wantCode:        http.StatusOK,
+			wantContentType: "text/plain; version=0.0.4; charset=utf-8",
+			wantBody:        "# TYPE Alloc gauge\nAlloc{host=\"a\"} 1.5\n",
+		},
+		{
+			name:            "malformed labels filter",
+			target:          "/metrics?labels=host",
+			setupMock:       func() {},
+			wantCode:        http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "invalid metric labels\n",
+		},
+		{
+			name: "service returns error",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					List(gomock.Any(), types.Labels("")).
+					Return(nil, errors.New("fail"))
+			},
+			wantCode:        http.StatusInternalServerError,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "internal server error\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			target := tt.target
+			if target == "" {
+				target = "/metrics"
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+			assert.Equal(t, tt.wantContentType, resp.Header.Get("Content-Type"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// defaultMetricRange is the time range queried when the request sets no start time.
+const defaultMetricRange = time.Hour
+
+// MetricRangeQuerier defines the interface for querying the time series of a metric.
+type MetricRangeQuerier interface {
+	// QueryRange returns the metric's time series with its points within [from, to],
+	// resampled to the given step if it is positive.
+	QueryRange(ctx context.Context, id types.MetricID, from time.Time, to time.Time, step time.Duration) (types.MetricSeries, error)
+}
+
+// NewMetricQueryRangeHandler creates an HTTP handler function that serves
+// the time series of a metric as JSON.
+//
+// The metric is selected by the "type" and "name" query parameters, which are
+// validated using the provided validation function, and by the optional "labels"
+// parameter, e.g. "labels=host=a", which may name only some of the series' labels
+// if no other series matches them. The "from" and "to" parameters
+// bound the time range and accept either RFC 3339 timestamps or Unix seconds;
+// they default to the last hour up to now. The optional "step" parameter accepts
+// either a duration such as "15s" or a number of seconds and resamples the series.
+//
+// Parameters:
+//   - val: A validation function that validates metric type and name strings.
+//   - svc: A service implementing MetricRangeQuerier to query the time series.
+//
+// Returns:
+//   - An http.HandlerFunc that can be registered with an HTTP server/router.
+func NewMetricQueryRangeHandler(
+	val func(metricType string, metricName string) error,
+	svc MetricRangeQuerier,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		metricType := query.Get("type")
+		metricName := query.Get("name")
+
+		err := val(metricType, metricName)
+		if err != nil {
+			handleMetricQueryRangeError(w, err)
+			return
+		}
+
+		labels, err := parseLabelsQuery(r)
+		if err != nil {
+			handleMetricQueryRangeError(w, err)
+			return
+		}
+
+		to := time.Now()
+		if v := query.Get("to"); v != "" {
+			if to, err = parseRangeTime(v); err != nil {
+				handleMetricQueryRangeError(w, errors.ErrMetricRangeInvalid)
+				return
+			}
+		}
+		from := to.Add(-defaultMetricRange)
+		if v := query.Get("from"); v != "" {
+			if from, err = parseRangeTime(v); err != nil {
+				handleMetricQueryRangeError(w, errors.ErrMetricRangeInvalid)
+				return
+			}
+		}
+		var step time.Duration
+		if v := query.Get("step"); v != "" {
+			if step, err = parseRangeStep(v); err != nil {
+				handleMetricQueryRangeError(w, errors.ErrMetricRangeInvalid)
+				return
+			}
+		}
+
+		id := types.NewMetricID(metricType, metricName)
+		id.Labels = labels
+
+		series, err := svc.QueryRange(r.Context(), *id, from, to, step)
+		if err != nil {
+			handleMetricQueryRangeError(w, err)
+			return
+		}
+		if series.Points == nil {
+			series.Points = []types.MetricPoint{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(series)
+	}
+}
+
+// parseRangeTime parses a range bound given as an RFC 3339 timestamp or as Unix seconds.
+func parseRangeTime(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return time.Time{}, errors.ErrMetricRangeInvalid
+		}
+		whole, frac := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(frac*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// parseRangeStep parses a step given as a duration such as "15s" or as a number of seconds.
+func parseRangeStep(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return 0, errors.ErrMetricRangeInvalid
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// handleMetricQueryRangeError writes appropriate HTTP error responses based on the
+// provided error when processing a range query.
+//
+// Missing metric names, invalid metric types, labels and ranges, and labels matching
+// several series are reported as bad requests, any other error as an internal server error.
+func handleMetricQueryRangeError(w http.ResponseWriter, err error) {
+	switch err {
+	case errors.ErrMetricNameMissing, errors.ErrMetricTypeInvalid, errors.ErrMetricLabelsInvalid,
+		errors.ErrMetricRangeInvalid, errors.ErrMetricLabelsAmbiguous:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, errors.ErrInternalServerError.Error(), http.StatusInternalServerError)
+	}
+}
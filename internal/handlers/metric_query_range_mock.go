@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/metric_query_range.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockMetricRangeQuerier is a mock of MetricRangeQuerier interface.
+type MockMetricRangeQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricRangeQuerierMockRecorder
+}
+
+// MockMetricRangeQuerierMockRecorder is the mock recorder for MockMetricRangeQuerier.
+type MockMetricRangeQuerierMockRecorder struct {
+	mock *MockMetricRangeQuerier
+}
+
+// NewMockMetricRangeQuerier creates a new mock instance.
+func NewMockMetricRangeQuerier(ctrl *gomock.Controller) *MockMetricRangeQuerier {
+	mock := &MockMetricRangeQuerier{ctrl: ctrl}
+	mock.recorder = &MockMetricRangeQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricRangeQuerier) EXPECT() *MockMetricRangeQuerierMockRecorder {
+	return m.recorder
+}
+
+// QueryRange mocks base method.
+func (m *MockMetricRangeQuerier) QueryRange(ctx context.Context, id types.MetricID, from, to time.Time, step time.Duration) (types.MetricSeries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryRange", ctx, id, from, to, step)
+	ret0, _ := ret[0].(types.MetricSeries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryRange indicates an expected call of QueryRange.
+func (mr *MockMetricRangeQuerierMockRecorder) QueryRange(ctx, id, from, to, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRange", reflect.TypeOf((*MockMetricRangeQuerier)(nil).QueryRange), ctx, id, from, to, step)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/validators"
+)
+
+func TestNewMetricQueryRangeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricRangeQuerier(ctrl)
+
+	id := types.MetricID{ID: "HeapAlloc", Type: types.Gauge}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	points := []types.MetricPoint{
+		{Timestamp: from.Add(time.Minute), Value: 1},
+		{Timestamp: from.Add(2 * time.Minute), Value: 2},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		setupMock  func()
+		wantCode   int
+		wantLabels types.Labels
+		wantPoints []types.MetricPoint
+	}{
+		{
+			name:  "RFC 3339 range with duration step",
+			query: "type=gauge&name=HeapAlloc&from=2025-01-01T00:00:00Z&to=2025-01-01T01:00:00Z&step=1m",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					QueryRange(gomock.Any(), id, gomock.Any(), gomock.Any(), time.Minute).
+					DoAndReturn(func(_ any, _ types.MetricID, gotFrom, gotTo time.Time, _ time.Duration) (types.MetricSeries, error) {
+						assert.True(t, from.Equal(gotFrom))
+						assert.True(t, to.Equal(gotTo))
+						return types.MetricSeries{ID: id.ID, Type: id.Type, Points: points}, nil
+					})
+			},
+			wantCode:   http.StatusOK,
+			wantPoints: points,
+		},
+		{
+			name:  "unix seconds range with numeric step",
+			query: "type=gauge&name=HeapAlloc&from=1735689600&to=1735693200&step=15",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					QueryRange(gomock.Any(), id, gomock.Any(), gomock.Any(), 15*time.Second).
+					DoAndReturn(func(_ any, _ types.MetricID, gotFrom, gotTo time.Time, _ time.Duration) (types.MetricSeries, error) {
+						assert.True(t, from.Equal(gotFrom))
+						assert.True(t, to.Equal(gotTo))
+						return types.MetricSeries{ID: id.ID, Type: id.Type}, nil
+					})
+			},
+			wantCode:   http.StatusOK,
+			wantPoints: []types.MetricPoint{},
+		},
+		{
+			name:  "defaults to the last hour of raw points",
+			query: "type=gauge&name=HeapAlloc",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					QueryRange(gomock.Any(), id, gomock.Any(), gomock.Any(), time.Duration(0)).
+					DoAndReturn(func(_ any, _ types.MetricID, gotFrom, gotTo time.Time, _ time.Duration) (types.MetricSeries, error) {
+						assert.Equal(t, time.Hour, gotTo.Sub(gotFrom))
+						return types.MetricSeries{ID: id.ID, Type: id.Type, Points: points}, nil
+					})
+			},
+			wantCode:   http.StatusOK,
+			wantPoints: points,
+		},
+		{
+			name:  "labels select the series",
+			query: "type=gauge&name=HeapAlloc&labels=host=a",
+			setupMock: func() {
+				labeled := id
+				labeled.Labels = types.NewLabels(map[string]string{"host": "a"})
+				// The series found has more labels than the requested ones
+				mockSvc.EXPECT().
+					QueryRange(gomock.Any(), labeled, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(types.MetricSeries{
+						ID:     id.ID,
+						Type:   id.Type,
+						Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"}),
+						Points: points,
+					}, nil)
+			},
+			wantCode:   http.StatusOK,
+			wantLabels: types.NewLabels(map[string]string{"host": "a", "env": "prod"}),
+			wantPoints: points,
+		},
+		{
+			name:  "labels matching several series",
+			query: "type=gauge&name=HeapAlloc&labels=env=prod",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					QueryRange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(types.MetricSeries{}, internalErrors.ErrMetricLabelsAmbiguous)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "malformed labels",
+			query:    "type=gauge&name=HeapAlloc&labels=host",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing name",
+			query:    "type=gauge",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid type",
+			query:    "type=unknown&name=HeapAlloc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid from",
+			query:    "type=gauge&name=HeapAlloc&from=yesterday",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid to",
+			query:    "type=gauge&name=HeapAlloc&to=NaN",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid step",
+			query:    "type=gauge&name=HeapAlloc&step=often",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:  "service rejects range",
+			query: "type=gauge&name=HeapAlloc&from=2025-01-01T01:00:00Z&to=2025-01-01T00:00:00Z",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					QueryRange(gomock.Any(), id, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(types.MetricSeries{}, internalErrors.ErrMetricRangeInvalid)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:  "service error",
+			query: "type=gauge&name=HeapAlloc",
+			setupMock: func() {
+				mockSvc.EXPECT().
+					QueryRange(gomock.Any(), id, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(types.MetricSeries{}, internalErrors.ErrInternalServerError)
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			handler := NewMetricQueryRangeHandler(validators.ValidateMetricIDAttributes, mockSvc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/query_range?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var series types.MetricSeries
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &series))
+			assert.Equal(t, id.ID, series.ID)
+			assert.Equal(t, id.Type, series.Type)
+			assert.Equal(t, tt.wantLabels, series.Labels)
+			require.Len(t, series.Points, len(tt.wantPoints))
+			for i, p := range tt.wantPoints {
+				assert.True(t, p.Timestamp.Equal(series.Points[i].Timestamp))
+				assert.Equal(t, p.Value, series.Points[i].Value)
+			}
+		})
+	}
+}
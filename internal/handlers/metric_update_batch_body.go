@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricUpdaterBatchBody defines an interface for updating a batch of metrics.
+// Implementations should apply the whole batch atomically and return the updated metrics or an error.
+type MetricUpdaterBatchBody interface {
+	// Update processes and updates the given slice of metric pointers.
+	// Returns the updated slice of metrics or an error if the update fails.
+	Update(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error)
+}
+
+// NewMetricUpdateBatchBodyHandler returns an HTTP handler function that processes
+// a batch of metric updates sent in the request body as a JSON array.
+//
+// Every metric of the batch is validated using the provided validation function
+// before any of them is applied. The batch is then passed to the update service
+// as a whole and the resulting metrics are written back as a JSON array.
+//
+// Parameters:
+//   - val: a validation function that checks the integrity of a single metric.
+//   - svc: a service implementing MetricUpdaterBatchBody to perform the update.
+//
+// Returns:
+//   - http.HandlerFunc to be used as an HTTP handler.
+func NewMetricUpdateBatchBodyHandler(
+	val func(metric types.Metrics) error,
+	svc MetricUpdaterBatchBody,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var metrics []*types.Metrics
+
+		err := json.NewDecoder(r.Body).Decode(&metrics)
+		if err != nil {
+			log.Printf("failed to decode JSON body: %v", err)
+			http.Error(w, "invalid JSON format", http.StatusBadRequest)
+			return
+		}
+
+		for _, metric := range metrics {
+			if metric == nil {
+				http.Error(w, "invalid JSON format", http.StatusBadRequest)
+				return
+			}
+			err = val(*metric)
+			if err != nil {
+				handleMetricUpdateBodyError(w, err)
+				return
+			}
+		}
+
+		metrics, err = svc.Update(r.Context(), metrics)
+		if err != nil {
+			handleMetricUpdateBodyError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(metrics)
+	}
+}
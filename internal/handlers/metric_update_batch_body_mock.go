@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/metric_update_batch_body.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockMetricUpdaterBatchBody is a mock of MetricUpdaterBatchBody interface.
+type MockMetricUpdaterBatchBody struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricUpdaterBatchBodyMockRecorder
+}
+
+// MockMetricUpdaterBatchBodyMockRecorder is the mock recorder for MockMetricUpdaterBatchBody.
+type MockMetricUpdaterBatchBodyMockRecorder struct {
+	mock *MockMetricUpdaterBatchBody
+}
+
+// NewMockMetricUpdaterBatchBody creates a new mock instance.
+func NewMockMetricUpdaterBatchBody(ctrl *gomock.Controller) *MockMetricUpdaterBatchBody {
+	mock := &MockMetricUpdaterBatchBody{ctrl: ctrl}
+	mock.recorder = &MockMetricUpdaterBatchBodyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricUpdaterBatchBody) EXPECT() *MockMetricUpdaterBatchBodyMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockMetricUpdaterBatchBody) Update(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].([]*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMetricUpdaterBatchBodyMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricUpdaterBatchBody)(nil).Update), ctx, metrics)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestNewMetricUpdateBatchBodyHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	float64Ptr := func(f float64) *float64 {
+		return &f
+	}
+	int64Ptr := func(i int64) *int64 {
+		return &i
+	}
+
+	validBatch := []*types.Metrics{
+		{ID: "gaugeMetric", Type: types.Gauge, Value: float64Ptr(1.5)},
+		{ID: "counterMetric", Type: types.Counter, Delta: int64Ptr(3)},
+	}
+
+	type testCase struct {
+		name             string
+		body             string
+		valFunc          func(types.Metrics) error
+		setupMock        func(m *MockMetricUpdaterBatchBody)
+		wantStatus       int
+		wantBodyContains string
+		wantBody         []*types.Metrics
+	}
+
+	mustMarshal := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	tests := []testCase{
+		{
+			name:             "invalid JSON body",
+			body:             "{invalid json",
+			valFunc:          func(m types.Metrics) error { return nil },
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: "invalid JSON format",
+		},
+		{
+			name:             "object instead of array",
+			body:             mustMarshal(validBatch[0]),
+			valFunc:          func(m types.Metrics) error { return nil },
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: "invalid JSON format",
+		},
+		{
+			name:             "null element",
+			body:             "[null]",
+			valFunc:          func(m types.Metrics) error { return nil },
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: "invalid JSON format",
+		},
+		{
+			name: "second element fails validation",
+			body: mustMarshal(validBatch),
+			valFunc: func(m types.Metrics) error {
+				if m.Type == types.Counter {
+					return internalErrors.ErrMetricDeltaInvalid
+				}
+				return nil
+			},
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: internalErrors.ErrMetricDeltaInvalid.Error(),
+		},
+		{
+			name:    "service update returns error",
+			body:    mustMarshal(validBatch),
+			valFunc: func(m types.Metrics) error { return nil },
+			setupMock: func(m *MockMetricUpdaterBatchBody) {
+				m.EXPECT().
+					Update(gomock.Any(), gomock.Eq(validBatch)).
+					Return(nil, internalErrors.ErrInternalServerError)
+			},
+			wantStatus:       http.StatusInternalServerError,
+			wantBodyContains: internalErrors.ErrInternalServerError.Error(),
+		},
+		{
+			name:    "success - returns updated metrics",
+			body:    mustMarshal(validBatch),
+			valFunc: func(m types.Metrics) error { return nil },
+			setupMock: func(m *MockMetricUpdaterBatchBody) {
+				m.EXPECT().
+					Update(gomock.Any(), gomock.Eq(validBatch)).
+					Return([]*types.Metrics{
+						validBatch[0],
+						{ID: "counterMetric", Type: types.Counter, Delta: int64Ptr(10)},
+					}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: []*types.Metrics{
+				validBatch[0],
+				{ID: "counterMetric", Type: types.Counter, Delta: int64Ptr(10)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := NewMockMetricUpdaterBatchBody(ctrl)
+			if tt.setupMock != nil {
+				tt.setupMock(mockSvc)
+			}
+
+			handler := NewMetricUpdateBatchBodyHandler(tt.valFunc, mockSvc)
+
+			req := httptest.NewRequest(http.MethodPost, "/updates/", bytes.NewReader([]byte(tt.body)))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantBodyContains != "" {
+				require.Contains(t, rr.Body.String(), tt.wantBodyContains)
+			}
+			if tt.wantBody != nil {
+				var got []*types.Metrics
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+				require.Equal(t, tt.wantBody, got)
+			}
+		})
+	}
+}
@@ -3,10 +3,11 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 
-	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
@@ -68,17 +69,26 @@ func NewMetricUpdateBodyHandler(
 // handleMetricUpdateBodyError writes an appropriate HTTP error response
 // depending on the error type encountered during metric update.
 //
-// Known errors map to specific HTTP status codes, and unknown errors
-// result in a 500 Internal Server Error response.
+// Known errors map to specific HTTP status codes, transient storage failures
+// result in a 503 Service Unavailable response so that clients may retry,
+// and unknown errors result in a 500 Internal Server Error response.
 func handleMetricUpdateBodyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, internalErrors.ErrStorageUnavailable) {
+		http.Error(w, internalErrors.ErrStorageUnavailable.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
 	switch err {
-	case errors.ErrMetricIDInvalid:
+	case internalErrors.ErrMetricIDInvalid:
 		http.Error(w, err.Error(), http.StatusNotFound)
-	case errors.ErrMetricTypeInvalid,
-		errors.ErrMetricDeltaInvalid,
-		errors.ErrMetricValueInvalid:
+	case internalErrors.ErrMetricTypeInvalid,
+		internalErrors.ErrMetricLabelsInvalid,
+		internalErrors.ErrMetricDeltaInvalid,
+		internalErrors.ErrMetricValueInvalid,
+		internalErrors.ErrMetricHistogramInvalid,
+		internalErrors.ErrMetricSummaryInvalid:
 		http.Error(w, err.Error(), http.StatusBadRequest)
 	default:
-		http.Error(w, errors.ErrInternalServerError.Error(), http.StatusInternalServerError)
+		http.Error(w, internalErrors.ErrInternalServerError.Error(), http.StatusInternalServerError)
 	}
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -60,6 +61,27 @@ func TestNewMetricUpdateBodyHandler(t *testing.T) {
 			wantStatus:       http.StatusBadRequest,
 			wantBodyContains: internalErrors.ErrMetricTypeInvalid.Error(),
 		},
+		{
+			name:             "validation error - labels invalid",
+			body:             validMetric,
+			valFunc:          func(m types.Metrics) error { return internalErrors.ErrMetricLabelsInvalid },
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: internalErrors.ErrMetricLabelsInvalid.Error(),
+		},
+		{
+			name:             "validation error - histogram invalid",
+			body:             validMetric,
+			valFunc:          func(m types.Metrics) error { return internalErrors.ErrMetricHistogramInvalid },
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: internalErrors.ErrMetricHistogramInvalid.Error(),
+		},
+		{
+			name:             "validation error - summary invalid",
+			body:             validMetric,
+			valFunc:          func(m types.Metrics) error { return internalErrors.ErrMetricSummaryInvalid },
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: internalErrors.ErrMetricSummaryInvalid.Error(),
+		},
 		{
 			name:    "service update returns error",
 			body:    validMetric,
@@ -73,6 +95,19 @@ func TestNewMetricUpdateBodyHandler(t *testing.T) {
 			wantStatus:       http.StatusInternalServerError,
 			wantBodyContains: internalErrors.ErrInternalServerError.Error(),
 		},
+		{
+			name:    "service update returns storage unavailable error",
+			body:    validMetric,
+			valFunc: func(m types.Metrics) error { return nil },
+			mockUpdateReturn: func(m *MockMetricUpdaterBody, metrics []*types.Metrics) {
+				m.EXPECT().
+					Update(gomock.Any(), gomock.Eq(metrics)).
+					Return(nil, fmt.Errorf("%w: connection reset", internalErrors.ErrStorageUnavailable)).
+					Times(1)
+			},
+			wantStatus:       http.StatusServiceUnavailable,
+			wantBodyContains: internalErrors.ErrStorageUnavailable.Error(),
+		},
 
 		{
 			name:    "success - valid metric",
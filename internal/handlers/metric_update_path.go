@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -60,9 +61,15 @@ func NewMetricUpdatePathHandler(
 // handleMetricUpdatePathError writes an appropriate HTTP error response
 // depending on the error type encountered during metric update via path parameters.
 //
-// Known errors map to specific HTTP status codes, and unknown errors
-// result in a 500 Internal Server Error response.
+// Known errors map to specific HTTP status codes, transient storage failures
+// result in a 503 Service Unavailable response so that clients may retry,
+// and unknown errors result in a 500 Internal Server Error response.
 func handleMetricUpdatePathError(w http.ResponseWriter, err error) {
+	if errors.Is(err, internalErrors.ErrStorageUnavailable) {
+		http.Error(w, internalErrors.ErrStorageUnavailable.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
 	switch err {
 	case internalErrors.ErrMetricNameMissing:
 		http.Error(w, err.Error(), http.StatusNotFound)
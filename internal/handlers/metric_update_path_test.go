@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -78,6 +79,21 @@ func TestNewMetricUpdatePathHandler(t *testing.T) {
 			wantStatus:       http.StatusInternalServerError,
 			wantBodyContains: internalErrors.ErrInternalServerError.Error(),
 		},
+		{
+			name: "service update returns storage unavailable error",
+			args: args{"gauge", "cpu", "123"},
+			valFunc: func(mt, mn, mv string) error {
+				return nil
+			},
+			mockUpdateReturn: func(m *MockMetricUpdaterPath) {
+				m.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("%w: connection reset", internalErrors.ErrStorageUnavailable)).
+					Times(1)
+			},
+			wantStatus:       http.StatusServiceUnavailable,
+			wantBodyContains: internalErrors.ErrStorageUnavailable.Error(),
+		},
 		{
 			name: "successful update",
 			args: args{"gauge", "cpu", "123"},
@@ -0,0 +1,35 @@
+// Package hashes provides helpers for signing and verifying data with a shared key.
+package hashes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Header is the HTTP header that carries the hex-encoded HMAC-SHA256 signature of the body.
+const Header = "HashSHA256"
+
+// MetadataKey is the gRPC metadata key that carries the hex-encoded HMAC-SHA256 signature
+// of the deterministically marshaled protobuf message.
+const MetadataKey = "hashsha256"
+
+// HashSHA256 returns the hex-encoded HMAC-SHA256 of data computed with the given key.
+func HashSHA256(data []byte, key string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifySHA256 reports whether hash is a valid hex-encoded HMAC-SHA256 of data for the given key.
+//
+// The comparison is done in constant time.
+func VerifySHA256(data []byte, key string, hash string) bool {
+	got, err := hex.DecodeString(hash)
+	if err != nil {
+		return false
+	}
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write(data)
+	return hmac.Equal(got, h.Sum(nil))
+}
@@ -0,0 +1,42 @@
+package hashes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashSHA256(t *testing.T) {
+	// Reference value: echo -n 'payload' | openssl dgst -sha256 -hmac 'secret'
+	assert.Equal(t,
+		"b82fcb791acec57859b989b430a826488ce2e479fdf92326bd0a2e8375a42ba4",
+		HashSHA256([]byte("payload"), "secret"),
+	)
+	assert.NotEqual(t, HashSHA256([]byte("payload"), "secret"), HashSHA256([]byte("payload"), "other"))
+}
+
+func TestVerifySHA256(t *testing.T) {
+	data := []byte(`[{"id":"a","type":"gauge","value":1}]`)
+	key := "secret"
+	valid := HashSHA256(data, key)
+
+	tests := []struct {
+		name string
+		data []byte
+		key  string
+		hash string
+		want bool
+	}{
+		{name: "valid signature", data: data, key: key, hash: valid, want: true},
+		{name: "tampered body", data: []byte(`[{"id":"a","type":"gauge","value":2}]`), key: key, hash: valid, want: false},
+		{name: "wrong key", data: data, key: "other", hash: valid, want: false},
+		{name: "not hex", data: data, key: key, hash: "zz", want: false},
+		{name: "empty hash", data: data, key: key, hash: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, VerifySHA256(tt.data, tt.key, tt.hash))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package hashes
+
+import (
+	"encoding/json"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricSHA256 returns the hex-encoded HMAC-SHA256 of the metric computed with the given key,
+// to be sent in its Hash field.
+//
+// The signed data is the JSON encoding of the metric without its hash, so the hash covers
+// the metric's identity and every value it carries.
+func MetricSHA256(m types.Metrics, key string) (string, error) {
+	m.Hash = ""
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return HashSHA256(data, key), nil
+}
+
+// VerifyMetricSHA256 reports whether the Hash field of the metric is its valid
+// HMAC-SHA256 for the given key.
+func VerifyMetricSHA256(m types.Metrics, key string) bool {
+	hash := m.Hash
+	m.Hash = ""
+	data, err := json.Marshal(m)
+	if err != nil {
+		return false
+	}
+	return VerifySHA256(data, key, hash)
+}
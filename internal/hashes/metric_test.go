@@ -0,0 +1,48 @@
+package hashes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestVerifyMetricSHA256(t *testing.T) {
+	const key = "secret"
+
+	v := 1.5
+	v2 := 2.5
+	metric := types.Metrics{ID: "g", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: &v}
+
+	hash, err := MetricSHA256(metric, key)
+	require.NoError(t, err)
+
+	// A hash already set on the metric is not part of the signed data
+	again, err := MetricSHA256(types.Metrics{ID: "g", Type: types.Gauge, Labels: metric.Labels, Value: &v, Hash: "old"}, key)
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	signed := metric
+	signed.Hash = hash
+
+	tests := []struct {
+		name   string
+		metric types.Metrics
+		key    string
+		want   bool
+	}{
+		{name: "valid hash", metric: signed, key: key, want: true},
+		{name: "tampered value", metric: types.Metrics{ID: "g", Type: types.Gauge, Labels: metric.Labels, Value: &v2, Hash: hash}, key: key, want: false},
+		{name: "tampered labels", metric: types.Metrics{ID: "g", Type: types.Gauge, Value: &v, Hash: hash}, key: key, want: false},
+		{name: "wrong key", metric: signed, key: "other", want: false},
+		{name: "no hash", metric: metric, key: key, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, VerifyMetricSHA256(tt.metric, tt.key))
+		})
+	}
+}
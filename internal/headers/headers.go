@@ -0,0 +1,8 @@
+// Package headers defines the HTTP headers and gRPC metadata keys shared by the agent and the server.
+package headers
+
+// RealIP is the HTTP header that carries the IP address of the client that originated the request.
+const RealIP = "X-Real-IP"
+
+// RealIPMetadataKey is the gRPC metadata key that carries the IP address of the client that originated the call.
+const RealIPMetadataKey = "x-real-ip"
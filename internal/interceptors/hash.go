@@ -0,0 +1,124 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+)
+
+// NewHashUnaryInterceptor returns a gRPC interceptor that checks and produces
+// HMAC-SHA256 signatures of unary calls using the given shared key.
+//
+// Non-empty request messages must carry a valid signature of their deterministic protobuf
+// encoding in the `hashsha256` metadata, otherwise the call fails with InvalidArgument.
+// Responses are signed the same way in the `hashsha256` header metadata.
+// If key is empty, the interceptor does nothing.
+func NewHashUnaryInterceptor(key string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if key == "" {
+			return handler(ctx, req)
+		}
+
+		data, err := marshalMessage(req)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid request message")
+		}
+		if len(data) > 0 && !hashes.VerifySHA256(data, key, metadataValue(ctx, hashes.MetadataKey)) {
+			return nil, status.Error(codes.InvalidArgument, "invalid request signature")
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = marshalMessage(resp)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "invalid response message")
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(hashes.MetadataKey, hashes.HashSHA256(data, key)))
+		return resp, nil
+	}
+}
+
+// NewHashStreamInterceptor returns a gRPC interceptor that checks and produces
+// HMAC-SHA256 signatures of streaming calls using the given shared key.
+//
+// The metadata of a stream cannot carry the signatures of the individual messages, so every
+// metric of a received message must carry its own signature in its hash field, computed
+// as by hashes.MetricSHA256, like metrics sent over HTTP may. A message with a metric
+// without a valid signature fails the call with InvalidArgument. Checked signatures are
+// removed, so that they are not stored along with the metrics. The response, which the
+// client-streaming calls of the service send once, is signed as the responses of unary calls.
+// If key is empty, the interceptor does nothing.
+func NewHashStreamInterceptor(key string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if key == "" {
+			return handler(srv, ss)
+		}
+		return handler(srv, &hashServerStream{ServerStream: ss, key: key})
+	}
+}
+
+// hashServerStream is a server stream checking the signatures of the received metrics
+// and signing the sent response.
+type hashServerStream struct {
+	grpc.ServerStream
+	key string
+}
+
+// RecvMsg receives a message and checks the signature of every metric it carries.
+func (s *hashServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	req, ok := m.(*pb.UpdateMetricsRequest)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", m)
+	}
+	for _, msg := range req.GetMetrics() {
+		if !hashes.VerifyMetricSHA256(*msg.ToMetrics(), s.key) {
+			return status.Errorf(codes.InvalidArgument, "invalid signature of metric %q", msg.GetId())
+		}
+		msg.Hash = ""
+	}
+	return nil
+}
+
+// SendMsg signs the response in the `hashsha256` header metadata and sends it.
+func (s *hashServerStream) SendMsg(m any) error {
+	data, err := marshalMessage(m)
+	if err != nil {
+		return status.Error(codes.Internal, "invalid response message")
+	}
+	if err := s.SetHeader(metadata.Pairs(hashes.MetadataKey, hashes.HashSHA256(data, s.key))); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// marshalMessage returns the deterministic protobuf encoding of the message signatures are computed on.
+func marshalMessage(msg any) ([]byte, error) {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "unexpected message type %T", msg)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+}
+
+// metadataValue returns the first value of the incoming metadata key, or an empty string.
+func metadataValue(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
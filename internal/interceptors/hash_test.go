@@ -0,0 +1,222 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// headerStream records the header metadata set by a unary interceptor.
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+// SetHeader records the header metadata.
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestHashUnaryInterceptor(t *testing.T) {
+	const key = "secret"
+	req := &pb.GetMetricRequest{Id: &pb.MetricID{Id: "Alloc", Type: "gauge"}}
+	resp := &pb.GetMetricResponse{Metric: &pb.Metric{Id: "Alloc", Type: "gauge"}}
+
+	sign := func(msg proto.Message) string {
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		require.NoError(t, err)
+		return hashes.HashSHA256(data, key)
+	}
+
+	tests := []struct {
+		name           string
+		key            string
+		req            proto.Message
+		hash           string
+		wantCode       codes.Code
+		wantCalled     bool
+		wantSignedResp bool
+	}{
+		{
+			name:       "no key skips verification",
+			req:        req,
+			hash:       "bogus",
+			wantCode:   codes.OK,
+			wantCalled: true,
+		},
+		{
+			name:           "valid signature",
+			key:            key,
+			req:            req,
+			hash:           sign(req),
+			wantCode:       codes.OK,
+			wantCalled:     true,
+			wantSignedResp: true,
+		},
+		{
+			name:     "invalid signature",
+			key:      key,
+			req:      req,
+			hash:     hashes.HashSHA256([]byte("other"), key),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing signature",
+			key:      key,
+			req:      req,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:           "empty request is not verified",
+			key:            key,
+			req:            &pb.ListMetricsRequest{},
+			wantCode:       codes.OK,
+			wantCalled:     true,
+			wantSignedResp: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &headerStream{}
+			ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+			if tt.hash != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(hashes.MetadataKey, tt.hash))
+			}
+
+			called := false
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				return resp, nil
+			}
+
+			got, err := NewHashUnaryInterceptor(tt.key)(ctx, tt.req, &grpc.UnaryServerInfo{}, handler)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCalled, called)
+			if tt.wantCalled {
+				assert.Equal(t, resp, got)
+			}
+			if tt.wantSignedResp {
+				assert.Equal(t, []string{sign(resp)}, stream.header.Get(hashes.MetadataKey))
+			} else {
+				assert.Empty(t, stream.header.Get(hashes.MetadataKey))
+			}
+		})
+	}
+}
+
+// recvStream is a server stream receiving the given requests and recording what is sent.
+type recvStream struct {
+	grpc.ServerStream
+	recv   []*pb.UpdateMetricsRequest
+	header metadata.MD
+	sent   []any
+}
+
+// RecvMsg receives the next request, or io.EOF once there are no more.
+func (s *recvStream) RecvMsg(m any) error {
+	if len(s.recv) == 0 {
+		return io.EOF
+	}
+	proto.Merge(m.(proto.Message), s.recv[0])
+	s.recv = s.recv[1:]
+	return nil
+}
+
+// SendMsg records the sent message.
+func (s *recvStream) SendMsg(m any) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+// SetHeader records the header metadata.
+func (s *recvStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestHashStreamInterceptor(t *testing.T) {
+	const key = "secret"
+
+	delta := int64(5)
+	metric := &types.Metrics{ID: "PollCount", Type: types.Counter, Delta: &delta}
+	hash, err := hashes.MetricSHA256(*metric, key)
+	require.NoError(t, err)
+	signed := &types.Metrics{ID: metric.ID, Type: metric.Type, Delta: metric.Delta, Hash: hash}
+	forged := &types.Metrics{ID: metric.ID, Type: metric.Type, Delta: metric.Delta, Hash: hashes.HashSHA256([]byte("other"), key)}
+
+	tests := []struct {
+		name     string
+		key      string
+		recv     []*types.Metrics
+		wantCode codes.Code
+	}{
+		{name: "no key", recv: []*types.Metrics{metric}, wantCode: codes.OK},
+		{name: "signed metrics", key: key, recv: []*types.Metrics{signed, signed}, wantCode: codes.OK},
+		{name: "unsigned metric", key: key, recv: []*types.Metrics{signed, metric}, wantCode: codes.InvalidArgument},
+		{name: "invalid signature", key: key, recv: []*types.Metrics{forged}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &recvStream{}
+			for _, m := range tt.recv {
+				stream.recv = append(stream.recv, &pb.UpdateMetricsRequest{Metrics: pb.NewMetrics([]*types.Metrics{m})})
+			}
+
+			// The handler receives every message, as UpdateMetricsStream does, then responds
+			var received []*pb.Metric
+			handler := func(srv any, ss grpc.ServerStream) error {
+				for {
+					req := &pb.UpdateMetricsRequest{}
+					err := ss.RecvMsg(req)
+					if errors.Is(err, io.EOF) {
+						return ss.SendMsg(&pb.UpdateMetricsStreamResponse{Updated: int64(len(received))})
+					}
+					if err != nil {
+						return err
+					}
+					received = append(received, req.GetMetrics()...)
+				}
+			}
+
+			err := NewHashStreamInterceptor(tt.key)(nil, stream, &grpc.StreamServerInfo{}, handler)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				assert.Empty(t, stream.sent)
+				return
+			}
+
+			require.Len(t, received, len(tt.recv))
+			for _, msg := range received {
+				// Checked signatures are not passed on to be stored
+				assert.Empty(t, msg.GetHash())
+			}
+
+			require.Len(t, stream.sent, 1)
+			if tt.key == "" {
+				assert.Empty(t, stream.header.Get(hashes.MetadataKey))
+				return
+			}
+			data, err := proto.MarshalOptions{Deterministic: true}.Marshal(stream.sent[0].(proto.Message))
+			require.NoError(t, err)
+			assert.Equal(t, []string{hashes.HashSHA256(data, key)}, stream.header.Get(hashes.MetadataKey))
+		})
+	}
+}
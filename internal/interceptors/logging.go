@@ -0,0 +1,53 @@
+// Package interceptors provides interceptors for gRPC servers,
+// the gRPC counterparts of the HTTP middlewares.
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+)
+
+// LoggingUnaryInterceptor is a gRPC interceptor that logs unary calls.
+//
+// For each call, it logs the full method name, the processing duration and the status code.
+func LoggingUnaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(info.FullMethod, time.Since(start), err)
+	return resp, err
+}
+
+// LoggingStreamInterceptor is a gRPC interceptor that logs streaming calls once they are finished.
+//
+// For each call, it logs the full method name, the duration of the stream and the status code.
+func LoggingStreamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(info.FullMethod, time.Since(start), err)
+	return err
+}
+
+// logCall logs a finished call using the zap logger from the internal logger package.
+func logCall(method string, duration time.Duration, err error) {
+	logger.Log.Desugar().Info("Call",
+		zap.String("method", method),
+		zap.Duration("duration", duration),
+		zap.String("code", status.Code(err).String()),
+	)
+}
@@ -0,0 +1,35 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoggingUnaryInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "response", status.Error(codes.NotFound, "metric not found")
+	}
+
+	resp, err := LoggingUnaryInterceptor(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: "/metrics.MetricService/GetMetric"}, handler)
+
+	assert.Equal(t, "response", resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestLoggingStreamInterceptor(t *testing.T) {
+	called := false
+	handler := func(srv any, ss grpc.ServerStream) error {
+		called = true
+		return nil
+	}
+
+	err := LoggingStreamInterceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/metrics.MetricService/UpdateMetricsStream"}, handler)
+
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
@@ -0,0 +1,57 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/headers"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+)
+
+// updateMethods are the methods that modify metrics and are restricted to the trusted subnet.
+var updateMethods = map[string]bool{
+	pb.MetricService_UpdateMetrics_FullMethodName:       true,
+	pb.MetricService_UpdateMetricsStream_FullMethodName: true,
+}
+
+// NewTrustedSubnetUnaryInterceptor returns a gRPC interceptor that only lets through update calls
+// whose `x-real-ip` metadata holds an address inside the given subnet.
+//
+// Update calls with missing or malformed metadata, or with an address outside the subnet,
+// fail with PermissionDenied. Queries are not restricted. If subnet is nil, the interceptor does nothing.
+func NewTrustedSubnetUnaryInterceptor(subnet *net.IPNet) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := checkTrustedSubnet(ctx, subnet, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewTrustedSubnetStreamInterceptor returns a gRPC interceptor that restricts streaming
+// update calls to the given subnet the same way NewTrustedSubnetUnaryInterceptor does.
+func NewTrustedSubnetStreamInterceptor(subnet *net.IPNet) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkTrustedSubnet(ss.Context(), subnet, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// checkTrustedSubnet returns a PermissionDenied error if the method is an update
+// and the real IP of the call is not inside the subnet.
+func checkTrustedSubnet(ctx context.Context, subnet *net.IPNet, method string) error {
+	if subnet == nil || !updateMethods[method] {
+		return nil
+	}
+	ip := net.ParseIP(metadataValue(ctx, headers.RealIPMetadataKey))
+	if ip == nil || !subnet.Contains(ip) {
+		return status.Error(codes.PermissionDenied, "call from an untrusted address")
+	}
+	return nil
+}
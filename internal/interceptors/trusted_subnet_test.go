@@ -0,0 +1,75 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/headers"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+)
+
+// contextStream is a server stream carrying the given context.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestTrustedSubnetInterceptors(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		subnet   *net.IPNet
+		method   string
+		realIP   string
+		wantCode codes.Code
+	}{
+		{name: "no subnet", method: pb.MetricService_UpdateMetrics_FullMethodName, realIP: "192.168.1.1", wantCode: codes.OK},
+		{name: "update inside subnet", subnet: subnet, method: pb.MetricService_UpdateMetrics_FullMethodName, realIP: "10.0.0.42", wantCode: codes.OK},
+		{name: "update outside subnet", subnet: subnet, method: pb.MetricService_UpdateMetrics_FullMethodName, realIP: "10.0.1.1", wantCode: codes.PermissionDenied},
+		{name: "stream update outside subnet", subnet: subnet, method: pb.MetricService_UpdateMetricsStream_FullMethodName, realIP: "10.0.1.1", wantCode: codes.PermissionDenied},
+		{name: "update without metadata", subnet: subnet, method: pb.MetricService_UpdateMetrics_FullMethodName, wantCode: codes.PermissionDenied},
+		{name: "query is not restricted", subnet: subnet, method: pb.MetricService_ListMetrics_FullMethodName, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.realIP != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(headers.RealIPMetadataKey, tt.realIP))
+			}
+
+			unaryCalled := false
+			_, err := NewTrustedSubnetUnaryInterceptor(tt.subnet)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, req any) (any, error) {
+					unaryCalled = true
+					return nil, nil
+				})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCode == codes.OK, unaryCalled)
+
+			streamCalled := false
+			err = NewTrustedSubnetStreamInterceptor(tt.subnet)(nil, &contextStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: tt.method},
+				func(srv any, ss grpc.ServerStream) error {
+					streamCalled = true
+					return nil
+				})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCode == codes.OK, streamCalled)
+		})
+	}
+}
@@ -10,6 +10,10 @@ import (
 // It is initialized as a no-op logger by default. Use Initialize to configure it properly.
 var Log *zap.SugaredLogger = zap.NewNop().Sugar()
 
+// atomicLevel is the level of the logger set up by Initialize. It is kept for the lifetime
+// of the process, so that the level can be changed at runtime.
+var atomicLevel = zap.NewAtomicLevel()
+
 // Initialize sets up the global Log instance with the given logging level.
 //
 // The `level` parameter should be a string compatible with zap's logging levels,
@@ -28,9 +32,17 @@ func Initialize(level string) error {
 	if err != nil {
 		return err
 	}
+	atomicLevel.SetLevel(lvl.Level())
 	cfg := zap.NewProductionConfig()
-	cfg.Level = lvl
+	cfg.Level = atomicLevel
 	baseLogger, _ := cfg.Build()
 	Log = baseLogger.Sugar()
 	return nil
 }
+
+// AtomicLevel returns the level of the logger set up by Initialize.
+//
+// Changing it, e.g. from an admin endpoint, changes the level of Log without rebuilding it.
+func AtomicLevel() zap.AtomicLevel {
+	return atomicLevel
+}
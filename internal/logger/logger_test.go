@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 )
 
 func TestInitialize_Initialize(t *testing.T) {
@@ -52,3 +53,16 @@ func TestInitialize_Initialize(t *testing.T) {
 func resetLogger() {
 	Log = nil
 }
+
+func TestAtomicLevel(t *testing.T) {
+	assert.NoError(t, Initialize("info"))
+	level := AtomicLevel()
+	assert.Equal(t, zap.InfoLevel, level.Level())
+
+	// The level handle outlives re-initialization
+	assert.NoError(t, Initialize("warn"))
+	assert.Equal(t, zap.WarnLevel, level.Level())
+
+	level.SetLevel(zap.DebugLevel)
+	assert.True(t, Log.Desugar().Core().Enabled(zap.DebugLevel))
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"io"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/cryptos"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+)
+
+// NewDecryptMiddleware returns an HTTP middleware that decrypts request bodies
+// encrypted with cryptos.Encrypt for the given private key.
+//
+// Requests with a non-empty body that cannot be decrypted are rejected with
+// HTTP status 400 (Bad Request). The body is encrypted after compression, so the
+// middleware must be registered before GzipMiddleware. If key is nil, the middleware does nothing.
+func NewDecryptMiddleware(key *rsa.PrivateKey) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if key == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			r.Body.Close()
+
+			if len(body) > 0 {
+				body, err = cryptos.Decrypt(key, body)
+				if err != nil {
+					http.Error(w, errors.ErrCiphertextInvalid.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/cryptos"
+)
+
+func TestDecryptMiddleware(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	body := []byte(`{"id":"a","type":"gauge","value":1}`)
+	encrypted, err := cryptos.Encrypt(&key.PublicKey, body)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		key        *rsa.PrivateKey
+		body       []byte
+		wantStatus int
+		wantBody   []byte
+	}{
+		{name: "no key passes body through", body: body, wantStatus: http.StatusOK, wantBody: body},
+		{name: "encrypted body", key: key, body: encrypted, wantStatus: http.StatusOK, wantBody: body},
+		{name: "empty body", key: key, body: nil, wantStatus: http.StatusOK, wantBody: []byte{}},
+		{name: "plaintext body", key: key, body: body, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/updates/", bytes.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			NewDecryptMiddleware(tt.key)(handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantBody, got)
+			} else {
+				assert.Contains(t, rec.Body.String(), "invalid ciphertext")
+			}
+		})
+	}
+}
+
+func TestDecryptMiddleware_BeforeGzip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	body := []byte(`[{"id":"a","type":"gauge","value":1}]`)
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err = gz.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	encrypted, err := cryptos.Encrypt(&key.PublicKey, compressed.Bytes())
+	require.NoError(t, err)
+
+	var got []byte
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/updates/", bytes.NewReader(encrypted))
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+
+	NewDecryptMiddleware(key)(GzipMiddleware(handler)).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, body, got)
+}
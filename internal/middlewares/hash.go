@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+)
+
+// NewHashMiddleware returns an HTTP middleware that checks and produces
+// HMAC-SHA256 signatures using the given shared key.
+//
+// Requests with a non-empty body must carry a valid signature of that body in the
+// `HashSHA256` header, otherwise the middleware responds with HTTP status 400 (Bad Request).
+// Requests without a body that are not reads, such as updates passed in the URL path,
+// must carry a signature of the request URI (path and query) instead; GET and HEAD
+// requests without a body need no signature.
+// Responses are buffered and signed the same way before being sent.
+//
+// The middleware expects the request body to be already decompressed, so it must be
+// registered after GzipMiddleware. If key is empty, the middleware does nothing.
+func NewHashMiddleware(key string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if key == "" {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if data := signedData(r, body); data != nil && !hashes.VerifySHA256(data, key, r.Header.Get(hashes.Header)) {
+				http.Error(w, "invalid request signature", http.StatusBadRequest)
+				return
+			}
+
+			hw := &hashResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(hw, r)
+
+			w.Header().Set(hashes.Header, hashes.HashSHA256(hw.body.Bytes(), key))
+			w.WriteHeader(hw.statusCode)
+			w.Write(hw.body.Bytes())
+		})
+	}
+}
+
+// signedData returns the data the signature of the request covers: its body or,
+// for a request without one that is not a read, its URI. Returns nil if the request
+// needs no signature.
+func signedData(r *http.Request, body []byte) []byte {
+	if len(body) > 0 {
+		return body
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return nil
+	}
+	return []byte(r.URL.RequestURI())
+}
+
+// hashResponseWriter buffers the response so it can be signed before the headers are sent.
+type hashResponseWriter struct {
+	http.ResponseWriter
+	statusCode int          // HTTP status code
+	body       bytes.Buffer // buffered response body
+}
+
+// WriteHeader captures the status code without sending it.
+func (w *hashResponseWriter) WriteHeader(code int) {
+	w.statusCode = code
+}
+
+// Write appends the data to the buffered response body.
+func (w *hashResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
@@ -0,0 +1,158 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+)
+
+func TestHashMiddleware(t *testing.T) {
+	const key = "secret"
+	body := []byte(`{"id":"a","type":"gauge","value":1}`)
+
+	tests := []struct {
+		name           string
+		key            string
+		method         string
+		target         string
+		body           []byte
+		hash           string
+		wantStatus     int
+		wantCalled     bool
+		wantSignedResp bool
+	}{
+		{
+			name:           "valid signature",
+			key:            key,
+			body:           body,
+			hash:           hashes.HashSHA256(body, key),
+			wantStatus:     http.StatusCreated,
+			wantCalled:     true,
+			wantSignedResp: true,
+		},
+		{
+			name:       "invalid signature",
+			key:        key,
+			body:       body,
+			hash:       hashes.HashSHA256(body, "other"),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing signature",
+			key:        key,
+			body:       body,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "read without body needs no signature",
+			key:            key,
+			method:         http.MethodGet,
+			target:         "/value/counter/x",
+			wantStatus:     http.StatusCreated,
+			wantCalled:     true,
+			wantSignedResp: true,
+		},
+		{
+			name:           "update in path with signed URI",
+			key:            key,
+			target:         "/update/counter/x/100",
+			hash:           hashes.HashSHA256([]byte("/update/counter/x/100"), key),
+			wantStatus:     http.StatusCreated,
+			wantCalled:     true,
+			wantSignedResp: true,
+		},
+		{
+			name:       "unsigned update in path",
+			key:        key,
+			target:     "/update/counter/x/100",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "update in path signed for another URI",
+			key:        key,
+			target:     "/update/counter/x/100",
+			hash:       hashes.HashSHA256([]byte("/update/counter/x/1"), key),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no key disables checks",
+			key:        "",
+			body:       body,
+			hash:       "garbage",
+			wantStatus: http.StatusCreated,
+			wantCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				got, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, string(tt.body), string(got), "handler must see the original body")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte("response"))
+			})
+
+			method, target := http.MethodPost, "/"
+			if tt.method != "" {
+				method = tt.method
+			}
+			if tt.target != "" {
+				target = tt.target
+			}
+
+			req := httptest.NewRequest(method, target, bytes.NewReader(tt.body))
+			if tt.hash != "" {
+				req.Header.Set(hashes.Header, tt.hash)
+			}
+			rr := httptest.NewRecorder()
+
+			NewHashMiddleware(tt.key)(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantCalled, called)
+			if tt.wantSignedResp {
+				assert.Equal(t, "response", rr.Body.String())
+				assert.True(t, hashes.VerifySHA256(rr.Body.Bytes(), tt.key, rr.Header().Get(hashes.Header)))
+			} else {
+				assert.Empty(t, rr.Header().Get(hashes.Header))
+			}
+		})
+	}
+}
+
+func TestHashMiddleware_AfterGzip(t *testing.T) {
+	const key = "secret"
+	body := []byte(`[{"id":"a","type":"gauge","value":1}]`)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	chain := GzipMiddleware(NewHashMiddleware(key)(handler))
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(body)
+	zw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set(hashes.Header, hashes.HashSHA256(body, key))
+	rr := httptest.NewRecorder()
+
+	chain.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, hashes.VerifySHA256([]byte("ok"), key, rr.Header().Get(hashes.Header)))
+}
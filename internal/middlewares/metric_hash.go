@@ -0,0 +1,97 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// NewMetricHashMiddleware returns an HTTP middleware that checks the Hash field of the metrics
+// in a JSON request body, either a single metric or an array of them, using the given shared key.
+//
+// If a metric carries a hash that is not its valid HMAC-SHA256, the middleware responds with
+// HTTP status 400 (Bad Request). Metrics without a hash are let through, as the body as a whole
+// is signed and checked by the middleware returned by NewHashMiddleware. Checked hashes are
+// removed from the body, so that they are not stored along with the metrics. Bodies that are
+// not valid JSON metrics are passed on unchanged for the handler to reject.
+//
+// If key is empty, the middleware does nothing.
+func NewMetricHashMiddleware(key string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if key == "" {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			r.Body.Close()
+
+			body, ok := verifyMetricHashes(body, key)
+			if !ok {
+				http.Error(w, "invalid metric hash", http.StatusBadRequest)
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verifyMetricHashes checks the hashes of the metrics in body. It returns the body
+// with the hashes removed and false if any of them is invalid.
+func verifyMetricHashes(body []byte, key string) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(body)
+	batch := len(trimmed) > 0 && trimmed[0] == '['
+
+	var metrics []types.Metrics
+	if batch {
+		if err := json.Unmarshal(body, &metrics); err != nil {
+			return body, true
+		}
+	} else {
+		var m types.Metrics
+		if err := json.Unmarshal(body, &m); err != nil {
+			return body, true
+		}
+		metrics = []types.Metrics{m}
+	}
+
+	hashed := false
+	for i, m := range metrics {
+		if m.Hash == "" {
+			continue
+		}
+		if !hashes.VerifyMetricSHA256(m, key) {
+			return nil, false
+		}
+		metrics[i].Hash = ""
+		hashed = true
+	}
+	if !hashed {
+		return body, true
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if batch {
+		data, err = json.Marshal(metrics)
+	} else {
+		data, err = json.Marshal(metrics[0])
+	}
+	if err != nil {
+		return body, true
+	}
+	return data, true
+}
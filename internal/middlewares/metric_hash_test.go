@@ -0,0 +1,111 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/hashes"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricHashMiddleware(t *testing.T) {
+	const key = "secret"
+
+	v := 1.5
+	v2 := 2.5
+	gauge := types.Metrics{ID: "g", Type: types.Gauge, Value: &v}
+	hash, err := hashes.MetricSHA256(gauge, key)
+	require.NoError(t, err)
+
+	signed := gauge
+	signed.Hash = hash
+	tampered := types.Metrics{ID: "g", Type: types.Gauge, Value: &v2, Hash: hash}
+
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	tests := []struct {
+		name       string
+		key        string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "valid hash is removed",
+			key:        key,
+			body:       encode(signed),
+			wantStatus: http.StatusOK,
+			wantBody:   encode(gauge),
+		},
+		{
+			name:       "valid hashes in batch are removed",
+			key:        key,
+			body:       encode([]types.Metrics{signed, gauge}),
+			wantStatus: http.StatusOK,
+			wantBody:   encode([]types.Metrics{gauge, gauge}),
+		},
+		{
+			name:       "invalid hash",
+			key:        key,
+			body:       encode(tampered),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid hash in batch",
+			key:        key,
+			body:       encode([]types.Metrics{signed, tampered}),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "metrics without hash pass unchanged",
+			key:        key,
+			body:       ` {"id":"g","type":"gauge","value":1.5}`,
+			wantStatus: http.StatusOK,
+			wantBody:   ` {"id":"g","type":"gauge","value":1.5}`,
+		},
+		{
+			name:       "invalid JSON is left to the handler",
+			key:        key,
+			body:       `{"id":`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":`,
+		},
+		{
+			name:       "no key disables checks",
+			key:        "",
+			body:       encode(tampered),
+			wantStatus: http.StatusOK,
+			wantBody:   encode(tampered),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				got = string(data)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/update/", bytes.NewReader([]byte(tt.body)))
+			rr := httptest.NewRecorder()
+
+			NewMetricHashMiddleware(tt.key)(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantBody, got)
+		})
+	}
+}
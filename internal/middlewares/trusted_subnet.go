@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/headers"
+)
+
+// NewTrustedSubnetMiddleware returns an HTTP middleware that only lets through requests
+// whose `X-Real-IP` header holds an address inside the given subnet.
+//
+// Requests with a missing or malformed header, or with an address outside the subnet,
+// are rejected with HTTP status 403 (Forbidden). If subnet is nil, the middleware does nothing.
+func NewTrustedSubnetMiddleware(subnet *net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if subnet == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := net.ParseIP(r.Header.Get(headers.RealIP))
+			if ip == nil || !subnet.Contains(ip) {
+				http.Error(w, "request from an untrusted address", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/headers"
+)
+
+func TestTrustedSubnetMiddleware(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		subnet     *net.IPNet
+		realIP     string
+		wantStatus int
+	}{
+		{name: "no subnet allows any address", realIP: "192.168.1.1", wantStatus: http.StatusOK},
+		{name: "no subnet allows missing header", wantStatus: http.StatusOK},
+		{name: "address inside subnet", subnet: subnet, realIP: "10.0.0.42", wantStatus: http.StatusOK},
+		{name: "address outside subnet", subnet: subnet, realIP: "10.0.1.1", wantStatus: http.StatusForbidden},
+		{name: "missing header", subnet: subnet, wantStatus: http.StatusForbidden},
+		{name: "malformed header", subnet: subnet, realIP: "10.0.0.x", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/updates/", nil)
+			if tt.realIP != "" {
+				req.Header.Set(headers.RealIP, tt.realIP)
+			}
+			rec := httptest.NewRecorder()
+
+			NewTrustedSubnetMiddleware(tt.subnet)(handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantStatus == http.StatusOK, called)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Package pb contains the protobuf messages and gRPC service of the metric API,
+// generated from api/proto/metrics.proto, along with converters to the internal types.
+package pb
+
+import (
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// NewMetric converts a metric to its protobuf message.
+func NewMetric(m *types.Metrics) *Metric {
+	msg := &Metric{
+		Id:     m.ID,
+		Type:   m.Type,
+		Labels: m.Labels.Map(),
+		Delta:  m.Delta,
+		Value:  m.Value,
+		Sum:    m.Sum,
+		Count:  m.Count,
+		Hash:   m.Hash,
+	}
+	for _, b := range m.Buckets {
+		msg.Buckets = append(msg.Buckets, &HistogramBucket{Le: b.UpperBound, Count: b.Count})
+	}
+	for _, q := range m.Quantiles {
+		msg.Quantiles = append(msg.Quantiles, &SummaryQuantile{Quantile: q.Quantile, Value: q.Value})
+	}
+	return msg
+}
+
+// NewMetrics converts metrics to their protobuf messages.
+func NewMetrics(metrics []*types.Metrics) []*Metric {
+	msgs := make([]*Metric, 0, len(metrics))
+	for _, m := range metrics {
+		msgs = append(msgs, NewMetric(m))
+	}
+	return msgs
+}
+
+// ToMetrics converts the protobuf message to a metric.
+func (x *Metric) ToMetrics() *types.Metrics {
+	m := &types.Metrics{
+		ID:     x.GetId(),
+		Type:   x.GetType(),
+		Labels: types.NewLabels(x.GetLabels()),
+		Delta:  x.Delta,
+		Value:  x.Value,
+		Sum:    x.Sum,
+		Count:  x.Count,
+		Hash:   x.GetHash(),
+	}
+	for _, b := range x.GetBuckets() {
+		m.Buckets = append(m.Buckets, types.HistogramBucket{UpperBound: b.GetLe(), Count: b.GetCount()})
+	}
+	for _, q := range x.GetQuantiles() {
+		m.Quantiles = append(m.Quantiles, types.SummaryQuantile{Quantile: q.GetQuantile(), Value: q.GetValue()})
+	}
+	return m
+}
+
+// NewMetricID converts a metric identifier to its protobuf message.
+func NewMetricID(id types.MetricID) *MetricID {
+	return &MetricID{Id: id.ID, Type: id.Type, Labels: id.Labels.Map()}
+}
+
+// ToMetricID converts the protobuf message to a metric identifier.
+func (x *MetricID) ToMetricID() types.MetricID {
+	return types.MetricID{ID: x.GetId(), Type: x.GetType(), Labels: types.NewLabels(x.GetLabels())}
+}
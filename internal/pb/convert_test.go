@@ -0,0 +1,72 @@
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/pb"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricConversion(t *testing.T) {
+	delta := int64(5)
+	value := 1.5
+	count := int64(3)
+
+	tests := []struct {
+		name   string
+		metric *types.Metrics
+	}{
+		{
+			name:   "counter",
+			metric: &types.Metrics{ID: "PollCount", Type: types.Counter, Delta: &delta},
+		},
+		{
+			name:   "signed counter",
+			metric: &types.Metrics{ID: "PollCount", Type: types.Counter, Delta: &delta, Hash: "abc123"},
+		},
+		{
+			name:   "gauge with labels",
+			metric: &types.Metrics{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: &value},
+		},
+		{
+			name: "histogram",
+			metric: &types.Metrics{
+				ID:      "PauseNs",
+				Type:    types.Histogram,
+				Sum:     &value,
+				Count:   &count,
+				Buckets: types.HistogramBuckets{{UpperBound: 1, Count: 2}, {UpperBound: 2, Count: 3}},
+			},
+		},
+		{
+			name: "summary",
+			metric: &types.Metrics{
+				ID:        "latency",
+				Type:      types.Summary,
+				Sum:       &value,
+				Count:     &count,
+				Quantiles: types.SummaryQuantiles{{Quantile: 0.5, Value: 0.2}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := pb.NewMetric(tt.metric)
+			assert.Equal(t, tt.metric.ID, msg.GetId())
+			assert.Equal(t, tt.metric.Labels.Map(), msg.GetLabels())
+			assert.Equal(t, tt.metric, msg.ToMetrics())
+		})
+	}
+}
+
+func TestMetricIDConversion(t *testing.T) {
+	id := types.MetricID{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"})}
+
+	msg := pb.NewMetricID(id)
+
+	assert.Equal(t, map[string]string{"host": "a", "env": "prod"}, msg.GetLabels())
+	assert.Equal(t, id, msg.ToMetricID())
+}
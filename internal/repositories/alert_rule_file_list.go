@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// alertRuleFile is the layout of an alert rules file.
+//
+// Example:
+//
+//	rules:
+//	  - name: HighHeap
+//	    expr: gauge HeapAlloc > 500MB for 2m
+//	  - name: AgentStalled
+//	    expr: counter PollCount rate == 0 for 1m
+type alertRuleFile struct {
+	Rules []struct {
+		Name string `yaml:"name"`
+		Expr string `yaml:"expr"`
+	} `yaml:"rules"`
+}
+
+// AlertRuleFileListRepository reads alert rules from a YAML or JSON file on disk.
+type AlertRuleFileListRepository struct {
+	path string
+}
+
+// NewAlertRuleFileListRepository creates and returns a new AlertRuleFileListRepository
+// that reads rules from the given file path.
+func NewAlertRuleFileListRepository(path string) *AlertRuleFileListRepository {
+	return &AlertRuleFileListRepository{path: path}
+}
+
+// List returns all alert rules defined in the file.
+//
+// The file is parsed as YAML, which also accepts JSON documents.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//
+// Returns:
+//   - A slice of parsed AlertRule in file order.
+//   - An error if the file cannot be read, is malformed, contains an invalid
+//     rule expression or defines the same rule name twice.
+func (repo *AlertRuleFileListRepository) List(ctx context.Context) ([]types.AlertRule, error) {
+	data, err := os.ReadFile(repo.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file alertRuleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse alert rules file %s: %w", repo.path, err)
+	}
+
+	rules := make([]types.AlertRule, 0, len(file.Rules))
+	names := make(map[string]struct{}, len(file.Rules))
+	for _, r := range file.Rules {
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("%w: duplicate rule name %q", internalErrors.ErrAlertRuleInvalid, r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		rule, err := types.NewAlertRule(r.Name, r.Expr)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestAlertRuleFileListRepository_List(t *testing.T) {
+	want := []types.AlertRule{
+		{
+			Name:      "HighHeap",
+			Expr:      "gauge HeapAlloc > 500MB for 2m",
+			MetricID:  types.MetricID{ID: "HeapAlloc", Type: types.Gauge},
+			Function:  types.AlertFunctionValue,
+			Operator:  ">",
+			Threshold: 500 << 20,
+			For:       2 * time.Minute,
+		},
+		{
+			Name:     "AgentStalled",
+			Expr:     "counter PollCount rate == 0 for 1m",
+			MetricID: types.MetricID{ID: "PollCount", Type: types.Counter},
+			Function: types.AlertFunctionRate,
+			Operator: "==",
+			For:      time.Minute,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []types.AlertRule
+		wantErr bool
+	}{
+		{
+			name: "YAML rules",
+			file: "rules.yaml",
+			content: `rules:
+  - name: HighHeap
+    expr: gauge HeapAlloc > 500MB for 2m
+  - name: AgentStalled
+    expr: counter PollCount rate == 0 for 1m
+`,
+			want: want,
+		},
+		{
+			name: "JSON rules",
+			file: "rules.json",
+			content: `{"rules": [
+				{"name": "HighHeap", "expr": "gauge HeapAlloc > 500MB for 2m"},
+				{"name": "AgentStalled", "expr": "counter PollCount rate == 0 for 1m"}
+			]}`,
+			want: want,
+		},
+		{
+			name:    "no rules",
+			file:    "rules.yaml",
+			content: "rules: []\n",
+			want:    []types.AlertRule{},
+		},
+		{
+			name:    "malformed file",
+			file:    "rules.yaml",
+			content: "rules: [",
+			wantErr: true,
+		},
+		{
+			name:    "invalid expression",
+			file:    "rules.yaml",
+			content: "rules:\n  - name: Broken\n    expr: gauge HeapAlloc >\n",
+			wantErr: true,
+		},
+		{
+			name: "duplicate names",
+			file: "rules.yaml",
+			content: `rules:
+  - name: Same
+    expr: gauge A > 1
+  - name: Same
+    expr: gauge B > 1
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			repo := NewAlertRuleFileListRepository(path)
+			got, err := repo.List(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAlertRuleFileListRepository_List_MissingFile(t *testing.T) {
+	repo := NewAlertRuleFileListRepository(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	_, err := repo.List(context.Background())
+	assert.Error(t, err)
+}
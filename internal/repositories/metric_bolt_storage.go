@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/databases"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricBoltStore is a MetricStore keeping metrics in an embedded bbolt database on disk,
+// so they survive restarts without an external database.
+//
+// Every metric is stored as JSON under the JSON encoding of its MetricID.
+// Operations run inside the transaction of a databases.BoltTransactor carried by the context,
+// if any, so a batch is committed, and synced to disk, once; otherwise each update is
+// committed on its own.
+type MetricBoltStore struct {
+	db *bbolt.DB
+}
+
+// NewMetricBoltStore creates and returns a new MetricBoltStore keeping metrics in the given database,
+// whose metrics bucket must exist; see databases.MigrateBolt.
+func NewMetricBoltStore(db *bbolt.DB) *MetricBoltStore {
+	return &MetricBoltStore{db: db}
+}
+
+// metricBoltKey returns the key the metric with the given ID is stored under.
+// The fields of a MetricID are always encoded in the same order, so equal IDs give equal keys.
+func metricBoltKey(id types.MetricID) ([]byte, error) {
+	return json.Marshal(id)
+}
+
+// view runs fn in the transaction carried by the context, if any, or in a read-only transaction of its own.
+func (s *MetricBoltStore) view(ctx context.Context, fn func(tx *bbolt.Tx) error) error {
+	if tx := databases.GetBoltTx(ctx, s.db); tx != nil {
+		return fn(tx)
+	}
+	return s.db.View(fn)
+}
+
+// update runs fn in the transaction carried by the context, if any, or in a read-write transaction of its own.
+func (s *MetricBoltStore) update(ctx context.Context, fn func(tx *bbolt.Tx) error) error {
+	if tx := databases.GetBoltTx(ctx, s.db); tx != nil {
+		return fn(tx)
+	}
+	return s.db.Update(fn)
+}
+
+// Get returns the metric with the given ID, or nil if there is none.
+func (s *MetricBoltStore) Get(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	key, err := metricBoltKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *types.Metrics
+	err = s.view(ctx, func(tx *bbolt.Tx) error {
+		data := tx.Bucket(databases.BoltMetricsBucket).Get(key)
+		if data == nil {
+			return nil
+		}
+		m = &types.Metrics{}
+		return json.Unmarshal(data, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Save stores the metric as is, keyed by its MetricID.
+func (s *MetricBoltStore) Save(ctx context.Context, m types.Metrics) error {
+	key, err := metricBoltKey(types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, func(tx *bbolt.Tx) error {
+		return tx.Bucket(databases.BoltMetricsBucket).Put(key, data)
+	})
+}
+
+// List returns all stored metrics, in no particular order.
+// The metrics are read in a single transaction, so they form a consistent snapshot.
+func (s *MetricBoltStore) List(ctx context.Context) ([]types.Metrics, error) {
+	var list []types.Metrics
+	err := s.view(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(databases.BoltMetricsBucket)
+		list = make([]types.Metrics, 0, bucket.Stats().KeyN)
+		return bucket.ForEach(func(_, data []byte) error {
+			var m types.Metrics
+			if err := json.Unmarshal(data, &m); err != nil {
+				return err
+			}
+			list = append(list, m)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListByName returns the stored metrics with the given name and type, whatever their labels.
+// The labels are encoded last in a key, so the metrics are found by a scan of the keys
+// starting with the encoded name and type.
+func (s *MetricBoltStore) ListByName(ctx context.Context, id, metricType string) ([]types.Metrics, error) {
+	key, err := metricBoltKey(types.MetricID{ID: id, Type: metricType})
+	if err != nil {
+		return nil, err
+	}
+	// The key of a metric without labels is the prefix followed by the closing brace
+	prefix := key[:len(key)-1]
+
+	var list []types.Metrics
+	err = s.view(ctx, func(tx *bbolt.Tx) error {
+		c := tx.Bucket(databases.BoltMetricsBucket).Cursor()
+		for k, data := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, data = c.Next() {
+			var m types.Metrics
+			if err := json.Unmarshal(data, &m); err != nil {
+				return err
+			}
+			list = append(list, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Increment atomically adds delta to the counter with the given ID, creating it if there
+// is none, and returns the resulting total. The counter is read and written in one transaction.
+func (s *MetricBoltStore) Increment(ctx context.Context, id types.MetricID, delta int64) (int64, error) {
+	key, err := metricBoltKey(id)
+	if err != nil {
+		return 0, err
+	}
+
+	total := delta
+	err = s.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(databases.BoltMetricsBucket)
+
+		if data := bucket.Get(key); data != nil {
+			var existing types.Metrics
+			if err := json.Unmarshal(data, &existing); err != nil {
+				return err
+			}
+			if existing.Delta != nil {
+				total += *existing.Delta
+			}
+		}
+
+		data, err := json.Marshal(types.Metrics{ID: id.ID, Type: id.Type, Labels: id.Labels, Delta: &total})
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// Upsert atomically replaces the metric with the given ID by the result of fn
+// and returns the result. The metric is read and written in one transaction.
+func (s *MetricBoltStore) Upsert(
+	ctx context.Context,
+	id types.MetricID,
+	fn func(existing *types.Metrics) types.Metrics,
+) (types.Metrics, error) {
+	key, err := metricBoltKey(id)
+	if err != nil {
+		return types.Metrics{}, err
+	}
+
+	var m types.Metrics
+	err = s.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(databases.BoltMetricsBucket)
+
+		var existing *types.Metrics
+		if data := bucket.Get(key); data != nil {
+			existing = &types.Metrics{}
+			if err := json.Unmarshal(data, existing); err != nil {
+				return err
+			}
+		}
+
+		m = fn(existing)
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+	if err != nil {
+		return types.Metrics{}, err
+	}
+	return m, nil
+}
+
+// Delete removes the metric with the given ID, if any.
+func (s *MetricBoltStore) Delete(ctx context.Context, id types.MetricID) error {
+	key, err := metricBoltKey(id)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, func(tx *bbolt.Tx) error {
+		return tx.Bucket(databases.BoltMetricsBucket).Delete(key)
+	})
+}
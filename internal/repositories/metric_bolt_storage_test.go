@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/databases"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// openBoltStore opens the embedded database in dir and returns a store over it.
+func openBoltStore(t *testing.T, dir string) (*MetricBoltStore, *bbolt.DB) {
+	t.Helper()
+
+	db, err := databases.NewBoltDB(dir)
+	require.NoError(t, err)
+	require.NoError(t, databases.MigrateBolt(db))
+	return NewMetricBoltStore(db), db
+}
+
+func TestMetricBoltStore(t *testing.T) {
+	ctx := context.Background()
+	store, db := openBoltStore(t, t.TempDir())
+	defer db.Close()
+
+	v := 1.5
+	d := int64(3)
+	gauge := types.Metrics{ID: "g", Type: types.Gauge, Value: &v}
+	counter := types.Metrics{ID: "c", Type: types.Counter, Delta: &d}
+	labeled := types.Metrics{ID: "g", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: &v}
+
+	got, err := store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	list, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	for _, m := range []types.Metrics{gauge, counter, labeled} {
+		require.NoError(t, store.Save(ctx, m))
+	}
+
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Equal(t, &gauge, got)
+
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge, Labels: labeled.Labels})
+	require.NoError(t, err)
+	assert.Equal(t, &labeled, got)
+
+	// The same name with another type is a different metric
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Counter})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	list, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []types.Metrics{gauge, counter, labeled}, list)
+
+	// Counters accumulate
+	total, err := store.Increment(ctx, types.MetricID{ID: "c", Type: types.Counter}, 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), total)
+
+	total, err = store.Increment(ctx, types.MetricID{ID: "new", Type: types.Counter}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+
+	// Delete removes only the metric with the exact ID
+	require.NoError(t, store.Delete(ctx, types.MetricID{ID: "g", Type: types.Gauge}))
+	require.NoError(t, store.Delete(ctx, types.MetricID{ID: "g", Type: types.Gauge}))
+
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge, Labels: labeled.Labels})
+	require.NoError(t, err)
+	assert.Equal(t, &labeled, got)
+}
+
+func TestMetricBoltStore_SurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	id := types.MetricID{ID: "hits", Type: types.Counter}
+
+	store, db := openBoltStore(t, dir)
+	_, err := store.Increment(ctx, id, 5)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, db = openBoltStore(t, dir)
+	defer db.Close()
+
+	total, err := store.Increment(ctx, id, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), total)
+}
+
+func TestMetricBoltStore_ConcurrentIncrement(t *testing.T) {
+	const n = 100
+
+	ctx := context.Background()
+	store, db := openBoltStore(t, t.TempDir())
+	defer db.Close()
+	id := types.MetricID{ID: "hits", Type: types.Counter}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Increment(ctx, id, 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(n), *got.Delta)
+}
+
+func TestMetricBoltStore_ConcurrentUpsert(t *testing.T) {
+	const n = 100
+
+	ctx := context.Background()
+	store, db := openBoltStore(t, t.TempDir())
+	defer db.Close()
+	id := types.MetricID{ID: "Latency", Type: types.Summary}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Upsert(ctx, id, addObservation(id))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(n), *got.Count)
+}
+
+func TestMetricBoltStore_Transaction(t *testing.T) {
+	errBatch := errors.New("batch failed")
+
+	tests := []struct {
+		name      string
+		err       error
+		wantTotal int64
+	}{
+		{name: "committed", wantTotal: 3},
+		{name: "rolled back", err: errBatch, wantTotal: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store, db := openBoltStore(t, t.TempDir())
+			defer db.Close()
+
+			counter := types.MetricID{ID: "c", Type: types.Counter}
+			gauge := types.MetricID{ID: "g", Type: types.Gauge}
+			_, err := store.Increment(ctx, counter, 1)
+			require.NoError(t, err)
+
+			v := 1.5
+			err = databases.NewBoltTransactor(db).Do(ctx, func(ctx context.Context) error {
+				if _, err := store.Increment(ctx, counter, 2); err != nil {
+					return err
+				}
+				if err := store.Save(ctx, types.Metrics{ID: gauge.ID, Type: gauge.Type, Value: &v}); err != nil {
+					return err
+				}
+
+				// The batch reads its own changes
+				got, err := store.Get(ctx, counter)
+				require.NoError(t, err)
+				assert.Equal(t, int64(3), *got.Delta)
+				list, err := store.List(ctx)
+				require.NoError(t, err)
+				assert.Len(t, list, 2)
+
+				return tt.err
+			})
+			assert.ErrorIs(t, err, tt.err)
+
+			got, err := store.Get(ctx, counter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, *got.Delta)
+			got, err = store.Get(ctx, gauge)
+			require.NoError(t, err)
+			assert.Equal(t, tt.err == nil, got != nil)
+		})
+	}
+}
+
+func TestMetricBoltStore_Closed(t *testing.T) {
+	ctx := context.Background()
+	store, db := openBoltStore(t, t.TempDir())
+	require.NoError(t, db.Close())
+
+	_, err := store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	assert.Error(t, err)
+	assert.Error(t, store.Save(ctx, types.Metrics{ID: "g", Type: types.Gauge}))
+	_, err = store.List(ctx)
+	assert.Error(t, err)
+	_, err = store.Increment(ctx, types.MetricID{ID: "c", Type: types.Counter}, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/databases"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricDBGetRepository provides PostgreSQL-backed retrieval of metrics.
+// Queries run inside the transaction carried by the context, if any.
+type MetricDBGetRepository struct {
+	db *sqlx.DB
+}
+
+// NewMetricDBGetRepository creates and returns a new MetricDBGetRepository instance.
+func NewMetricDBGetRepository(db *sqlx.DB) *MetricDBGetRepository {
+	return &MetricDBGetRepository{db: db}
+}
+
+// metricDBGetQuery selects a single metric by its name, type and labels.
+const metricDBGetQuery = `
+SELECT id, type, labels, delta, value, sum, count, buckets, quantiles
+FROM metrics
+WHERE id = $1 AND type = $2 AND labels = $3`
+
+// metricDBListByNameQuery selects the metrics of a name and type, whatever their labels;
+// the primary key starts with the name and type, so it serves the lookup.
+const metricDBListByNameQuery = `
+SELECT id, type, labels, delta, value, sum, count, buckets, quantiles
+FROM metrics
+WHERE id = $1 AND type = $2`
+
+// Get retrieves a metric by its ID from the database.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//   - id: The unique identifier of the metric to retrieve.
+//
+// Returns:
+//   - A pointer to the found metric, or nil if no metric with the given ID exists.
+//   - An error if the query fails, wrapping ErrStorageUnavailable if the failure is transient.
+func (repo *MetricDBGetRepository) Get(
+	ctx context.Context,
+	id types.MetricID,
+) (*types.Metrics, error) {
+	var m types.Metrics
+	err := sqlx.GetContext(ctx, databases.GetExecutor(ctx, repo.db), &m, metricDBGetQuery, id.ID, id.Type, string(id.Labels))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, databases.WrapRetriableError(err)
+	}
+	return &m, nil
+}
+
+// ListByName retrieves the metrics with the given name and type, whatever their labels.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//   - id: The name of the metrics to retrieve.
+//   - metricType: The type of the metrics to retrieve.
+//
+// Returns:
+//   - A slice of the found metrics, empty if there are none.
+//   - An error if the query fails, wrapping ErrStorageUnavailable if the failure is transient.
+func (repo *MetricDBGetRepository) ListByName(
+	ctx context.Context,
+	id string,
+	metricType string,
+) ([]types.Metrics, error) {
+	list := make([]types.Metrics, 0)
+	if err := sqlx.SelectContext(ctx, databases.GetExecutor(ctx, repo.db), &list, metricDBListByNameQuery, id, metricType); err != nil {
+		return nil, databases.WrapRetriableError(err)
+	}
+	return list, nil
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricDBGetRepository_Get(t *testing.T) {
+	ptrInt64 := func(i int64) *int64 { return &i }
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	columns := []string{"id", "type", "labels", "delta", "value", "sum", "count", "buckets", "quantiles"}
+
+	tests := []struct {
+		name    string
+		id      types.MetricID
+		setup   func(exp *sqlmock.ExpectedQuery)
+		want    *types.Metrics
+		wantErr bool
+	}{
+		{
+			name: "found existing metric",
+			id:   types.MetricID{ID: "metric1", Type: types.Counter},
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnRows(sqlmock.NewRows(columns).AddRow("metric1", types.Counter, "", int64(42), nil, nil, nil, nil, nil))
+			},
+			want: &types.Metrics{ID: "metric1", Type: types.Counter, Delta: ptrInt64(42)},
+		},
+		{
+			name: "found labeled metric",
+			id:   types.MetricID{ID: "metric1", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"})},
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnRows(sqlmock.NewRows(columns).AddRow("metric1", types.Gauge, `{"host":"a"}`, nil, 1.5, nil, nil, nil, nil))
+			},
+			want: &types.Metrics{ID: "metric1", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: ptrFloat64(1.5)},
+		},
+		{
+			name: "found histogram metric",
+			id:   types.MetricID{ID: "PauseNs", Type: types.Histogram},
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("PauseNs", types.Histogram, "", nil, nil, 1.5, int64(3), `[{"le":1,"count":2}]`, nil))
+			},
+			want: &types.Metrics{
+				ID:      "PauseNs",
+				Type:    types.Histogram,
+				Sum:     ptrFloat64(1.5),
+				Count:   ptrInt64(3),
+				Buckets: types.HistogramBuckets{{UpperBound: 1, Count: 2}},
+			},
+		},
+		{
+			name: "metric not found",
+			id:   types.MetricID{ID: "missing", Type: types.Gauge},
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnError(sql.ErrNoRows)
+			},
+			want: nil,
+		},
+		{
+			name: "query error",
+			id:   types.MetricID{ID: "metric1", Type: types.Gauge},
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnError(errors.New("connection reset"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			tt.setup(mock.ExpectQuery(regexp.QuoteMeta("SELECT id, type, labels, delta, value")).
+				WithArgs(tt.id.ID, tt.id.Type, string(tt.id.Labels)))
+
+			repo := NewMetricDBGetRepository(sqlx.NewDb(sqlDB, "pgx"))
+
+			got, err := repo.Get(context.Background(), tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMetricDBGetRepository_ListByName(t *testing.T) {
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	columns := []string{"id", "type", "labels", "delta", "value", "sum", "count", "buckets", "quantiles"}
+
+	tests := []struct {
+		name    string
+		setup   func(exp *sqlmock.ExpectedQuery)
+		want    []types.Metrics
+		wantErr bool
+	}{
+		{
+			name: "metrics of every label set",
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("Alloc", types.Gauge, "", nil, 1.5, nil, nil, nil, nil).
+					AddRow("Alloc", types.Gauge, `{"host":"a"}`, nil, 2.5, nil, nil, nil, nil))
+			},
+			want: []types.Metrics{
+				{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5)},
+				{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: ptrFloat64(2.5)},
+			},
+		},
+		{
+			name: "no metrics",
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnRows(sqlmock.NewRows(columns))
+			},
+			want: []types.Metrics{},
+		},
+		{
+			name: "query error",
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnError(errors.New("connection reset"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			tt.setup(mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1 AND type = $2")).
+				WithArgs("Alloc", types.Gauge))
+
+			repo := NewMetricDBGetRepository(sqlx.NewDb(sqlDB, "pgx"))
+
+			got, err := repo.ListByName(context.Background(), "Alloc", types.Gauge)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/databases"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricDBIncrementRepository provides a PostgreSQL-backed repository for incrementing counters.
+// Queries run inside the transaction carried by the context, if any.
+type MetricDBIncrementRepository struct {
+	db *sqlx.DB
+}
+
+// NewMetricDBIncrementRepository creates and returns a new MetricDBIncrementRepository instance.
+func NewMetricDBIncrementRepository(db *sqlx.DB) *MetricDBIncrementRepository {
+	return &MetricDBIncrementRepository{db: db}
+}
+
+// metricDBIncrementQuery inserts a counter or adds the delta to the stored one in a single statement,
+// so concurrent increments of the same counter are serialized by the row lock.
+const metricDBIncrementQuery = `
+INSERT INTO metrics (id, type, labels, delta)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (id, type, labels) DO UPDATE
+SET delta = COALESCE(metrics.delta, 0) + EXCLUDED.delta
+RETURNING delta`
+
+// Increment atomically adds delta to the counter with the given ID, creating it if there is none.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//   - id: The identifier of the counter.
+//   - delta: The value to add.
+//
+// Returns:
+//   - The resulting total of the counter.
+//   - An error if the query fails, wrapping ErrStorageUnavailable if the failure is transient.
+func (repo *MetricDBIncrementRepository) Increment(
+	ctx context.Context,
+	id types.MetricID,
+	delta int64,
+) (int64, error) {
+	var total int64
+	err := sqlx.GetContext(ctx, databases.GetExecutor(ctx, repo.db), &total, metricDBIncrementQuery,
+		id.ID, id.Type, string(id.Labels), delta)
+	if err != nil {
+		return 0, databases.WrapRetriableError(err)
+	}
+	return total, nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricDBIncrementRepository_Increment(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        types.MetricID
+		delta     int64
+		total     int64
+		queryErr  error
+		wantTotal int64
+		wantErr   bool
+	}{
+		{
+			name:      "increment counter",
+			id:        types.MetricID{ID: "hits", Type: types.Counter},
+			delta:     2,
+			total:     7,
+			wantTotal: 7,
+		},
+		{
+			name:      "increment labeled counter",
+			id:        types.MetricID{ID: "hits", Type: types.Counter, Labels: types.NewLabels(map[string]string{"host": "a"})},
+			delta:     1,
+			total:     1,
+			wantTotal: 1,
+		},
+		{
+			name:     "query error",
+			id:       types.MetricID{ID: "hits", Type: types.Counter},
+			delta:    1,
+			queryErr: errors.New("connection reset"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			exp := mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO metrics")).
+				WithArgs(tt.id.ID, tt.id.Type, string(tt.id.Labels), tt.delta)
+			if tt.queryErr != nil {
+				exp.WillReturnError(tt.queryErr)
+			} else {
+				exp.WillReturnRows(sqlmock.NewRows([]string{"delta"}).AddRow(tt.total))
+			}
+
+			repo := NewMetricDBIncrementRepository(sqlx.NewDb(sqlDB, "pgx"))
+
+			total, err := repo.Increment(context.Background(), tt.id, tt.delta)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantTotal, total)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
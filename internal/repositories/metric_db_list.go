@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/databases"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricDBListRepository provides PostgreSQL-backed listing of all stored metrics.
+// Queries run inside the transaction carried by the context, if any.
+type MetricDBListRepository struct {
+	db *sqlx.DB
+}
+
+// NewMetricDBListRepository creates and returns a new MetricDBListRepository instance.
+func NewMetricDBListRepository(db *sqlx.DB) *MetricDBListRepository {
+	return &MetricDBListRepository{db: db}
+}
+
+// metricDBListQuery selects all metrics ordered the same way as the in-memory repository.
+const metricDBListQuery = `
+SELECT id, type, labels, delta, value, sum, count, buckets, quantiles
+FROM metrics
+ORDER BY id, type, labels`
+
+// List returns all metrics stored in the database, sorted by their MetricID.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//
+// Returns:
+//   - A slice of Metrics sorted by their ID.
+//   - An error if the query fails, wrapping ErrStorageUnavailable if the failure is transient.
+func (repo *MetricDBListRepository) List(ctx context.Context) ([]types.Metrics, error) {
+	list := make([]types.Metrics, 0)
+	if err := sqlx.SelectContext(ctx, databases.GetExecutor(ctx, repo.db), &list, metricDBListQuery); err != nil {
+		return nil, databases.WrapRetriableError(err)
+	}
+	return list, nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricDBListRepository_List(t *testing.T) {
+	ptrInt64 := func(i int64) *int64 { return &i }
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	columns := []string{"id", "type", "labels", "delta", "value", "sum", "count", "buckets", "quantiles"}
+
+	tests := []struct {
+		name    string
+		setup   func(exp *sqlmock.ExpectedQuery)
+		want    []types.Metrics
+		wantErr bool
+	}{
+		{
+			name: "list all metrics",
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("metricA", types.Counter, "", int64(42), nil, nil, nil, nil, nil).
+					AddRow("metricB", types.Gauge, "", nil, 3.14, nil, nil, nil, nil).
+					AddRow("metricB", types.Gauge, `{"host":"a"}`, nil, 2.71, nil, nil, nil, nil).
+					AddRow("metricC", types.Summary, "", nil, nil, 1.5, int64(3), nil, `[{"quantile":0.5,"value":0.4}]`))
+			},
+			want: []types.Metrics{
+				{ID: "metricA", Type: types.Counter, Delta: ptrInt64(42)},
+				{ID: "metricB", Type: types.Gauge, Value: ptrFloat64(3.14)},
+				{ID: "metricB", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: ptrFloat64(2.71)},
+				{
+					ID:        "metricC",
+					Type:      types.Summary,
+					Sum:       ptrFloat64(1.5),
+					Count:     ptrInt64(3),
+					Quantiles: types.SummaryQuantiles{{Quantile: 0.5, Value: 0.4}},
+				},
+			},
+		},
+		{
+			name: "empty table",
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnRows(sqlmock.NewRows(columns))
+			},
+			want: []types.Metrics{},
+		},
+		{
+			name: "query error",
+			setup: func(exp *sqlmock.ExpectedQuery) {
+				exp.WillReturnError(errors.New("connection reset"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			tt.setup(mock.ExpectQuery(regexp.QuoteMeta("SELECT id, type, labels, delta, value")))
+
+			repo := NewMetricDBListRepository(sqlx.NewDb(sqlDB, "pgx"))
+
+			got, err := repo.List(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
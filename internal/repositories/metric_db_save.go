@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/databases"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricDBSaveRepository provides a PostgreSQL-backed repository for saving metrics.
+// Queries run inside the transaction carried by the context, if any.
+type MetricDBSaveRepository struct {
+	db *sqlx.DB
+}
+
+// NewMetricDBSaveRepository creates and returns a new MetricDBSaveRepository instance.
+func NewMetricDBSaveRepository(db *sqlx.DB) *MetricDBSaveRepository {
+	return &MetricDBSaveRepository{db: db}
+}
+
+// metricDBSaveQuery inserts a metric or overwrites the stored values of an existing one.
+const metricDBSaveQuery = `
+INSERT INTO metrics (id, type, labels, delta, value, sum, count, buckets, quantiles)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (id, type, labels) DO UPDATE
+SET delta = EXCLUDED.delta, value = EXCLUDED.value,
+	sum = EXCLUDED.sum, count = EXCLUDED.count,
+	buckets = EXCLUDED.buckets, quantiles = EXCLUDED.quantiles`
+
+// Save stores the given metric in the database, keyed by its MetricID.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//   - m: The Metrics value to save.
+//
+// Returns:
+//   - An error if the query fails, wrapping ErrStorageUnavailable if the failure is transient.
+func (repo *MetricDBSaveRepository) Save(
+	ctx context.Context,
+	m types.Metrics,
+) error {
+	_, err := databases.GetExecutor(ctx, repo.db).ExecContext(ctx, metricDBSaveQuery,
+		m.ID, m.Type, string(m.Labels), m.Delta, m.Value, m.Sum, m.Count, m.Buckets, m.Quantiles)
+	return databases.WrapRetriableError(err)
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricDBSaveRepository_Save(t *testing.T) {
+	ptrInt64 := func(i int64) *int64 { return &i }
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name    string
+		input   types.Metrics
+		execErr error
+		wantErr bool
+	}{
+		{
+			name:  "save counter metric",
+			input: types.Metrics{ID: "metric1", Type: types.Counter, Delta: ptrInt64(10)},
+		},
+		{
+			name:  "save gauge metric",
+			input: types.Metrics{ID: "metric2", Type: types.Gauge, Value: ptrFloat64(3.14)},
+		},
+		{
+			name:  "save labeled metric",
+			input: types.Metrics{ID: "metric2", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: ptrFloat64(1)},
+		},
+		{
+			name: "save histogram metric",
+			input: types.Metrics{
+				ID:      "PauseNs",
+				Type:    types.Histogram,
+				Sum:     ptrFloat64(1.5),
+				Count:   ptrInt64(3),
+				Buckets: types.HistogramBuckets{{UpperBound: 1, Count: 2}},
+			},
+		},
+		{
+			name: "save summary metric",
+			input: types.Metrics{
+				ID:        "latency",
+				Type:      types.Summary,
+				Sum:       ptrFloat64(1.5),
+				Count:     ptrInt64(3),
+				Quantiles: types.SummaryQuantiles{{Quantile: 0.5, Value: 0.4}},
+			},
+		},
+		{
+			name:    "exec error",
+			input:   types.Metrics{ID: "metric3", Type: types.Gauge, Value: ptrFloat64(1)},
+			execErr: errors.New("connection reset"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			exp := mock.ExpectExec(regexp.QuoteMeta("INSERT INTO metrics")).
+				WithArgs(tt.input.ID, tt.input.Type, string(tt.input.Labels), tt.input.Delta, tt.input.Value,
+					tt.input.Sum, tt.input.Count, tt.input.Buckets, tt.input.Quantiles)
+			if tt.execErr != nil {
+				exp.WillReturnError(tt.execErr)
+			} else {
+				exp.WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			repo := NewMetricDBSaveRepository(sqlx.NewDb(sqlDB, "pgx"))
+
+			err = repo.Save(context.Background(), tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/databases"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricDBUpsertRepository provides a PostgreSQL-backed repository for read-modify-write updates of metrics.
+// Queries run inside the transaction carried by the context, if any, or in a transaction of their own.
+type MetricDBUpsertRepository struct {
+	db *sqlx.DB
+	tx *databases.Transactor
+}
+
+// NewMetricDBUpsertRepository creates and returns a new MetricDBUpsertRepository instance.
+func NewMetricDBUpsertRepository(db *sqlx.DB) *MetricDBUpsertRepository {
+	return &MetricDBUpsertRepository{db: db, tx: databases.NewTransactor(db)}
+}
+
+// metricDBUpsertSelectQuery selects a metric and locks its row until the end of the transaction,
+// so concurrent upserts of the same metric are serialized.
+const metricDBUpsertSelectQuery = metricDBGetQuery + `
+FOR UPDATE`
+
+// metricDBUpsertInsertQuery inserts a metric unless a concurrent transaction has inserted it first.
+const metricDBUpsertInsertQuery = `
+INSERT INTO metrics (id, type, labels, delta, value, sum, count, buckets, quantiles)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (id, type, labels) DO NOTHING`
+
+// Upsert atomically replaces the metric with the given ID by the result of fn.
+//
+// The stored metric is read with its row locked. A metric that does not exist yet is inserted;
+// if a concurrent transaction inserts it first, the metric is read again and fn is called
+// once more with it, so fn may be called several times.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//   - id: The identifier of the metric.
+//   - fn: Computes the new metric from the stored one, or from nil if there is none.
+//
+// Returns:
+//   - The stored result of fn.
+//   - An error if a query fails, wrapping ErrStorageUnavailable if the failure is transient.
+func (repo *MetricDBUpsertRepository) Upsert(
+	ctx context.Context,
+	id types.MetricID,
+	fn func(existing *types.Metrics) types.Metrics,
+) (types.Metrics, error) {
+	var m types.Metrics
+	err := repo.tx.Do(ctx, func(ctx context.Context) error {
+		executor := databases.GetExecutor(ctx, repo.db)
+		for {
+			var existing types.Metrics
+			err := sqlx.GetContext(ctx, executor, &existing, metricDBUpsertSelectQuery, id.ID, id.Type, string(id.Labels))
+			if err == nil {
+				m = fn(&existing)
+				_, err = executor.ExecContext(ctx, metricDBSaveQuery,
+					id.ID, id.Type, string(id.Labels), m.Delta, m.Value, m.Sum, m.Count, m.Buckets, m.Quantiles)
+				return databases.WrapRetriableError(err)
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return databases.WrapRetriableError(err)
+			}
+
+			m = fn(nil)
+			res, err := executor.ExecContext(ctx, metricDBUpsertInsertQuery,
+				id.ID, id.Type, string(id.Labels), m.Delta, m.Value, m.Sum, m.Count, m.Buckets, m.Quantiles)
+			if err != nil {
+				return databases.WrapRetriableError(err)
+			}
+			if n, err := res.RowsAffected(); err != nil || n > 0 {
+				return databases.WrapRetriableError(err)
+			}
+		}
+	})
+	if err != nil {
+		return types.Metrics{}, err
+	}
+	return m, nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricDBUpsertRepository_Upsert(t *testing.T) {
+	ptrInt64 := func(i int64) *int64 { return &i }
+
+	columns := []string{"id", "type", "labels", "delta", "value", "sum", "count", "buckets", "quantiles"}
+	id := types.MetricID{ID: "Latency", Type: types.Summary}
+
+	// fn adds one observation to the stored count
+	fn := func(existing *types.Metrics) types.Metrics {
+		count := int64(1)
+		if existing != nil && existing.Count != nil {
+			count += *existing.Count
+		}
+		return types.Metrics{ID: id.ID, Type: id.Type, Count: &count}
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		want    types.Metrics
+		wantErr bool
+	}{
+		{
+			name: "update existing metric",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+					WithArgs(id.ID, id.Type, "").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(id.ID, id.Type, "", nil, nil, nil, int64(4), nil, nil))
+				mock.ExpectExec(regexp.QuoteMeta("DO UPDATE")).
+					WithArgs(id.ID, id.Type, "", nil, nil, nil, ptrInt64(5), nil, nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: types.Metrics{ID: id.ID, Type: id.Type, Count: ptrInt64(5)},
+		},
+		{
+			name: "insert new metric",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectExec(regexp.QuoteMeta("DO NOTHING")).
+					WithArgs(id.ID, id.Type, "", nil, nil, nil, ptrInt64(1), nil, nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: types.Metrics{ID: id.ID, Type: id.Type, Count: ptrInt64(1)},
+		},
+		{
+			name: "metric inserted concurrently is read again",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectExec(regexp.QuoteMeta("DO NOTHING")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(id.ID, id.Type, "", nil, nil, nil, int64(1), nil, nil))
+				mock.ExpectExec(regexp.QuoteMeta("DO UPDATE")).
+					WithArgs(id.ID, id.Type, "", nil, nil, nil, ptrInt64(2), nil, nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: types.Metrics{ID: id.ID, Type: id.Type, Count: ptrInt64(2)},
+		},
+		{
+			name: "query error rolls back",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).WillReturnError(errors.New("connection reset"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			tt.setup(mock)
+			repo := NewMetricDBUpsertRepository(sqlx.NewDb(sqlDB, "pgx"))
+
+			got, err := repo.Upsert(context.Background(), id, fn)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricFileListRepository reads metric snapshots from a file on disk.
+type MetricFileListRepository struct {
+	path string
+}
+
+// NewMetricFileListRepository creates and returns a new MetricFileListRepository
+// that reads snapshots from the given file path.
+func NewMetricFileListRepository(path string) *MetricFileListRepository {
+	return &MetricFileListRepository{path: path}
+}
+
+// List returns all metrics stored in the snapshot file.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//
+// Returns:
+//   - A slice of Metrics read from the file, or an empty slice if the file does not exist or is empty.
+//   - An error if the file cannot be read or contains invalid JSON.
+func (repo *MetricFileListRepository) List(ctx context.Context) ([]types.Metrics, error) {
+	data, err := os.ReadFile(repo.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []types.Metrics{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return []types.Metrics{}, nil
+	}
+
+	var list []types.Metrics
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
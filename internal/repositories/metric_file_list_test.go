@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricFileListRepository_List(t *testing.T) {
+	ptrInt64 := func(i int64) *int64 { return &i }
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name    string
+		content *string
+		want    []types.Metrics
+		wantErr bool
+	}{
+		{
+			name:    "file does not exist",
+			content: nil,
+			want:    []types.Metrics{},
+		},
+		{
+			name:    "empty file",
+			content: func() *string { s := ""; return &s }(),
+			want:    []types.Metrics{},
+		},
+		{
+			name: "valid snapshot",
+			content: func() *string {
+				s := `[{"id":"c","type":"counter","delta":5},{"id":"g","type":"gauge","value":1.5}]`
+				return &s
+			}(),
+			want: []types.Metrics{
+				{ID: "c", Type: types.Counter, Delta: ptrInt64(5)},
+				{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+			},
+		},
+		{
+			name:    "invalid JSON",
+			content: func() *string { s := "{broken"; return &s }(),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "metrics.json")
+			if tt.content != nil {
+				assert.NoError(t, os.WriteFile(path, []byte(*tt.content), 0o644))
+			}
+
+			repo := NewMetricFileListRepository(path)
+
+			got, err := repo.List(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
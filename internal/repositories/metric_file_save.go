@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricFileSaveRepository writes snapshots of metrics to a file on disk.
+type MetricFileSaveRepository struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewMetricFileSaveRepository creates and returns a new MetricFileSaveRepository
+// that writes snapshots to the given file path.
+func NewMetricFileSaveRepository(path string) *MetricFileSaveRepository {
+	return &MetricFileSaveRepository{path: path}
+}
+
+// Save replaces the contents of the snapshot file with the given metrics encoded as JSON.
+//
+// The snapshot is first written to a temporary file in the same directory and then
+// renamed over the target, so a crash during the write never leaves a truncated file.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//   - metrics: The full set of metrics to persist.
+//
+// Returns:
+//   - An error if the file cannot be written or renamed.
+func (repo *MetricFileSaveRepository) Save(
+	ctx context.Context,
+	metrics []types.Metrics,
+) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(repo.path), filepath.Base(repo.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(metrics); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), repo.path)
+}
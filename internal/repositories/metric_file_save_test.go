@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestMetricFileSaveRepository_Save(t *testing.T) {
+	ptrInt64 := func(i int64) *int64 { return &i }
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name    string
+		path    func(dir string) string
+		input   []types.Metrics
+		wantErr bool
+	}{
+		{
+			name: "save snapshot",
+			path: func(dir string) string { return filepath.Join(dir, "metrics.json") },
+			input: []types.Metrics{
+				{ID: "metric1", Type: types.Counter, Delta: ptrInt64(10)},
+				{ID: "metric2", Type: types.Gauge, Value: ptrFloat64(3.14)},
+			},
+		},
+		{
+			name:  "save empty snapshot",
+			path:  func(dir string) string { return filepath.Join(dir, "metrics.json") },
+			input: []types.Metrics{},
+		},
+		{
+			name:    "directory does not exist",
+			path:    func(dir string) string { return filepath.Join(dir, "missing", "metrics.json") },
+			input:   []types.Metrics{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path(t.TempDir())
+			repo := NewMetricFileSaveRepository(path)
+
+			err := repo.Save(context.Background(), tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			var got []types.Metrics
+			require.NoError(t, json.Unmarshal(data, &got))
+			assert.Equal(t, tt.input, got)
+		})
+	}
+}
+
+func TestMetricFileSaveRepository_SaveOverwrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	repo := NewMetricFileSaveRepository(path)
+	ctx := context.Background()
+
+	v1, v2 := 1.0, 2.0
+	require.NoError(t, repo.Save(ctx, []types.Metrics{{ID: "a", Type: types.Gauge, Value: &v1}}))
+	require.NoError(t, repo.Save(ctx, []types.Metrics{{ID: "b", Type: types.Gauge, Value: &v2}}))
+
+	got, err := NewMetricFileListRepository(path).List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []types.Metrics{{ID: "b", Type: types.Gauge, Value: &v2}}, got)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files should be cleaned up")
+}
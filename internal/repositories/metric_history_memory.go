@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricHistoryMemoryRepository keeps the time series of every metric in memory.
+//
+// Points older than the retention period are dropped, so memory use is bounded
+// by the update rate times the retention period. The series of metrics that stopped
+// being updated are swept once per retention period, and removed once they are empty.
+// Nothing is persisted: the history starts empty on every start, whatever store keeps the metrics.
+type MetricHistoryMemoryRepository struct {
+	retention time.Duration
+
+	mu      sync.RWMutex
+	series  map[types.MetricID][]types.MetricPoint
+	names   metricNameIndex // IDs of the series by metric name and type
+	sweptAt time.Time       // time of the last sweep of every series
+}
+
+// NewMetricHistoryMemoryRepository creates and returns a new MetricHistoryMemoryRepository
+// keeping points for the given retention period. A non-positive retention keeps points forever.
+func NewMetricHistoryMemoryRepository(retention time.Duration) *MetricHistoryMemoryRepository {
+	return &MetricHistoryMemoryRepository{
+		retention: retention,
+		series:    make(map[types.MetricID][]types.MetricPoint),
+		names:     make(metricNameIndex),
+	}
+}
+
+// Append adds a point with the current value of every given metric, taken at the given time,
+// to the metric's time series, and drops the points of those series that fell out of retention.
+// Once per retention period, the points of every other series are dropped as well.
+//
+// Metrics without a value are skipped.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//   - metrics: The metrics whose values are recorded.
+//   - at: The time the values were recorded at.
+//
+// Returns:
+//   - An error if appending fails (currently always nil).
+func (repo *MetricHistoryMemoryRepository) Append(
+	ctx context.Context,
+	metrics []types.Metrics,
+	at time.Time,
+) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, m := range metrics {
+		point, ok := types.NewMetricPoint(m, at)
+		if !ok {
+			continue
+		}
+
+		id := types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels}
+		points, ok := repo.series[id]
+		if !ok {
+			repo.names.add(id)
+		}
+
+		// Concurrent updates may arrive slightly out of order, keep the series sorted
+		idx := sort.Search(len(points), func(i int) bool {
+			return points[i].Timestamp.After(at)
+		})
+		points = append(points, types.MetricPoint{})
+		copy(points[idx+1:], points[idx:])
+		points[idx] = point
+
+		repo.series[id] = repo.expire(points, at)
+	}
+
+	if repo.retention > 0 && at.Sub(repo.sweptAt) >= repo.retention {
+		repo.sweep(at)
+	}
+
+	return nil
+}
+
+// List returns the points of the metric's time series with timestamps
+// within [from, to], sorted by timestamp.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//   - id: The metric whose time series is returned.
+//   - from, to: Inclusive bounds of the time range.
+//
+// Returns:
+//   - The points in the range; empty if the metric has none.
+//   - An error if listing fails (currently always nil).
+func (repo *MetricHistoryMemoryRepository) List(
+	ctx context.Context,
+	id types.MetricID,
+	from time.Time,
+	to time.Time,
+) ([]types.MetricPoint, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	points := repo.series[id]
+	if repo.retention > 0 {
+		if cutoff := time.Now().Add(-repo.retention); from.Before(cutoff) {
+			from = cutoff
+		}
+	}
+
+	start := sort.Search(len(points), func(i int) bool {
+		return !points[i].Timestamp.Before(from)
+	})
+	end := sort.Search(len(points), func(i int) bool {
+		return points[i].Timestamp.After(to)
+	})
+
+	result := make([]types.MetricPoint, 0, max(end-start, 0))
+	if start < end {
+		result = append(result, points[start:end]...)
+	}
+	return result, nil
+}
+
+// ListIDsByName returns the IDs of the time series of the metrics with the given name and type,
+// whatever their labels, in no particular order.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//   - id: The name of the metrics.
+//   - metricType: The type of the metrics.
+//
+// Returns:
+//   - The IDs of the series; empty if there are none.
+//   - An error if listing fails (currently always nil).
+func (repo *MetricHistoryMemoryRepository) ListIDsByName(
+	ctx context.Context,
+	id string,
+	metricType string,
+) ([]types.MetricID, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	indexed := repo.names[metricName{id: id, metricType: metricType}]
+	ids := make([]types.MetricID, 0, len(indexed))
+	for metricID := range indexed {
+		ids = append(ids, metricID)
+	}
+	return ids, nil
+}
+
+// sweep drops the points of every series that fell out of retention as of the given time,
+// and removes the series left empty.
+func (repo *MetricHistoryMemoryRepository) sweep(now time.Time) {
+	for id, points := range repo.series {
+		points = repo.expire(points, now)
+		if len(points) == 0 {
+			delete(repo.series, id)
+			repo.names.remove(id)
+			continue
+		}
+		repo.series[id] = points
+	}
+	repo.sweptAt = now
+}
+
+// expire drops the points that fell out of retention as of the given time.
+func (repo *MetricHistoryMemoryRepository) expire(points []types.MetricPoint, now time.Time) []types.MetricPoint {
+	if repo.retention <= 0 {
+		return points
+	}
+
+	cutoff := now.Add(-repo.retention)
+	idx := sort.Search(len(points), func(i int) bool {
+		return !points[i].Timestamp.Before(cutoff)
+	})
+	// The dropped points are released once append outgrows the backing array
+	return points[idx:]
+}
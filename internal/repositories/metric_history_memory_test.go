@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricHistoryMemoryRepository_AppendAndList(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	ptrFloat64 := func(f float64) *float64 {
+		return &f
+	}
+	ptrInt64 := func(i int64) *int64 {
+		return &i
+	}
+
+	heap := types.MetricID{ID: "HeapAlloc", Type: types.Gauge}
+	polls := types.MetricID{ID: "PollCount", Type: types.Counter}
+
+	repo := NewMetricHistoryMemoryRepository(time.Hour)
+
+	require.NoError(t, repo.Append(ctx, []types.Metrics{
+		{ID: "HeapAlloc", Type: types.Gauge, Value: ptrFloat64(1)},
+		{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(5)},
+		{ID: "Empty", Type: types.Gauge},
+	}, now.Add(-3*time.Minute)))
+	require.NoError(t, repo.Append(ctx, []types.Metrics{
+		{ID: "HeapAlloc", Type: types.Gauge, Value: ptrFloat64(3)},
+	}, now.Add(-time.Minute)))
+	// Out of order append is kept sorted
+	require.NoError(t, repo.Append(ctx, []types.Metrics{
+		{ID: "HeapAlloc", Type: types.Gauge, Value: ptrFloat64(2)},
+	}, now.Add(-2*time.Minute)))
+
+	tests := []struct {
+		name     string
+		id       types.MetricID
+		from, to time.Time
+		want     []types.MetricPoint
+	}{
+		{
+			name: "whole range",
+			id:   heap,
+			from: now.Add(-time.Hour),
+			to:   now,
+			want: []types.MetricPoint{
+				{Timestamp: now.Add(-3 * time.Minute), Value: 1},
+				{Timestamp: now.Add(-2 * time.Minute), Value: 2},
+				{Timestamp: now.Add(-time.Minute), Value: 3},
+			},
+		},
+		{
+			name: "bounds are inclusive",
+			id:   heap,
+			from: now.Add(-2 * time.Minute),
+			to:   now.Add(-time.Minute),
+			want: []types.MetricPoint{
+				{Timestamp: now.Add(-2 * time.Minute), Value: 2},
+				{Timestamp: now.Add(-time.Minute), Value: 3},
+			},
+		},
+		{
+			name: "counter",
+			id:   polls,
+			from: now.Add(-time.Hour),
+			to:   now,
+			want: []types.MetricPoint{{Timestamp: now.Add(-3 * time.Minute), Value: 5}},
+		},
+		{
+			name: "empty range",
+			id:   heap,
+			from: now.Add(-30 * time.Second),
+			to:   now,
+			want: []types.MetricPoint{},
+		},
+		{
+			name: "unknown metric",
+			id:   types.MetricID{ID: "Empty", Type: types.Gauge},
+			from: now.Add(-time.Hour),
+			to:   now,
+			want: []types.MetricPoint{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.List(ctx, tt.id, tt.from, tt.to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMetricHistoryMemoryRepository_Retention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	value := 1.0
+	gauge := types.Metrics{ID: "HeapAlloc", Type: types.Gauge, Value: &value}
+	id := types.MetricID{ID: "HeapAlloc", Type: types.Gauge}
+
+	repo := NewMetricHistoryMemoryRepository(time.Minute)
+
+	require.NoError(t, repo.Append(ctx, []types.Metrics{gauge}, now.Add(-2*time.Minute)))
+	require.NoError(t, repo.Append(ctx, []types.Metrics{gauge}, now.Add(-30*time.Second)))
+
+	got, err := repo.List(ctx, id, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, []types.MetricPoint{{Timestamp: now.Add(-30 * time.Second), Value: 1}}, got)
+
+	// Expired points are dropped from the series on append
+	repo.mu.RLock()
+	assert.Len(t, repo.series[id], 1)
+	repo.mu.RUnlock()
+}
+
+func TestMetricHistoryMemoryRepository_SweepsStaleSeries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	value := 1.0
+	stale := types.Metrics{ID: "Stale", Type: types.Gauge, Value: &value}
+	active := types.Metrics{ID: "Active", Type: types.Gauge, Value: &value}
+
+	repo := NewMetricHistoryMemoryRepository(time.Minute)
+
+	require.NoError(t, repo.Append(ctx, []types.Metrics{stale, active}, now))
+
+	// Until a retention period passes, the series that stopped reporting is kept as is
+	require.NoError(t, repo.Append(ctx, []types.Metrics{active}, now.Add(30*time.Second)))
+	repo.mu.RLock()
+	assert.Len(t, repo.series, 2)
+	repo.mu.RUnlock()
+
+	// Then its expired points are swept, and the empty series is removed
+	require.NoError(t, repo.Append(ctx, []types.Metrics{active}, now.Add(2*time.Minute)))
+	repo.mu.RLock()
+	assert.NotContains(t, repo.series, types.MetricID{ID: "Stale", Type: types.Gauge})
+	assert.Len(t, repo.series[types.MetricID{ID: "Active", Type: types.Gauge}], 1)
+	repo.mu.RUnlock()
+
+	ids, err := repo.ListIDsByName(ctx, "Stale", types.Gauge)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestMetricHistoryMemoryRepository_ListIDsByName(t *testing.T) {
+	ctx := context.Background()
+	value := 1.0
+	hostA := types.MetricID{ID: "HeapAlloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"})}
+	hostB := types.MetricID{ID: "HeapAlloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "b"})}
+
+	repo := NewMetricHistoryMemoryRepository(time.Hour)
+	require.NoError(t, repo.Append(ctx, []types.Metrics{
+		{ID: hostA.ID, Type: hostA.Type, Labels: hostA.Labels, Value: &value},
+		{ID: hostB.ID, Type: hostB.Type, Labels: hostB.Labels, Value: &value},
+		{ID: "HeapAlloc", Type: types.Counter, Delta: new(int64)},
+		{ID: "HeapSys", Type: types.Gauge, Value: &value},
+	}, time.Now()))
+
+	ids, err := repo.ListIDsByName(ctx, "HeapAlloc", types.Gauge)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []types.MetricID{hostA, hostB}, ids)
+}
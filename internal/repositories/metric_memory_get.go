@@ -7,32 +7,46 @@ import (
 )
 
 // MetricMemoryGetRepository provides in-memory retrieval of metrics.
-type MetricMemoryGetRepository struct{}
+type MetricMemoryGetRepository struct {
+	store MetricStore
+}
 
-// NewMetricMemoryGetRepository creates and returns a new MetricMemoryGetRepository instance.
-func NewMetricMemoryGetRepository() *MetricMemoryGetRepository {
-	return &MetricMemoryGetRepository{}
+// NewMetricMemoryGetRepository creates and returns a new MetricMemoryGetRepository instance
+// reading metrics from the given store.
+func NewMetricMemoryGetRepository(store MetricStore) *MetricMemoryGetRepository {
+	return &MetricMemoryGetRepository{store: store}
 }
 
-// Get retrieves a metric by its ID from the in-memory storage.
+// Get retrieves a metric by its ID from the store.
 //
 // Parameters:
-//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//   - ctx: Context for cancellation and deadlines.
 //   - id: The unique identifier of the metric to retrieve.
 //
 // Returns:
 //   - A pointer to the found metric, or nil if no metric with the given ID exists.
-//   - An error if retrieval fails (currently always nil as no error handling is implemented).
+//   - An error if the store fails.
 func (repo *MetricMemoryGetRepository) Get(
 	ctx context.Context,
 	id types.MetricID,
 ) (*types.Metrics, error) {
-	mu.RLock()
-	defer mu.RUnlock()
+	return repo.store.Get(ctx, id)
+}
 
-	value, ok := metrics[id]
-	if !ok {
-		return nil, nil
-	}
-	return &value, nil
+// ListByName retrieves the metrics with the given name and type, whatever their labels, from the store.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//   - id: The name of the metrics to retrieve.
+//   - metricType: The type of the metrics to retrieve.
+//
+// Returns:
+//   - A slice of the found metrics, empty if there are none.
+//   - An error if the store fails.
+func (repo *MetricMemoryGetRepository) ListByName(
+	ctx context.Context,
+	id string,
+	metricType string,
+) ([]types.Metrics, error) {
+	return repo.store.ListByName(ctx, id, metricType)
 }
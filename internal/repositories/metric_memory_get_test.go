@@ -6,15 +6,12 @@ import (
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMetricMemoryGetRepository_Get(t *testing.T) {
-	// Очищаем глобальное хранилище перед началом теста
-	mu.Lock()
-	metrics = make(map[types.MetricID]types.Metrics)
-	mu.Unlock()
-
-	repo := NewMetricMemoryGetRepository()
+	store := NewMetricMemoryStore()
+	repo := NewMetricMemoryGetRepository(store)
 	ctx := context.Background()
 
 	ptrInt64 := func(i int64) *int64 {
@@ -29,9 +26,7 @@ func TestMetricMemoryGetRepository_Get(t *testing.T) {
 	}
 	key := types.MetricID{ID: existingMetric.ID, Type: existingMetric.Type}
 
-	mu.Lock()
-	metrics[key] = existingMetric
-	mu.Unlock()
+	require.NoError(t, store.Save(ctx, existingMetric))
 
 	tests := []struct {
 		name    string
@@ -64,3 +59,18 @@ func TestMetricMemoryGetRepository_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricMemoryGetRepository_ListByName(t *testing.T) {
+	store := NewMetricMemoryStore()
+	repo := NewMetricMemoryGetRepository(store)
+	ctx := context.Background()
+
+	v := 1.5
+	labeled := types.Metrics{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: &v}
+	require.NoError(t, store.Save(ctx, labeled))
+	require.NoError(t, store.Save(ctx, types.Metrics{ID: "Other", Type: types.Gauge, Value: &v}))
+
+	got, err := repo.ListByName(ctx, "Alloc", types.Gauge)
+	require.NoError(t, err)
+	assert.Equal(t, []types.Metrics{labeled}, got)
+}
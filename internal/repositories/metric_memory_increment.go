@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricMemoryIncrementRepository provides an in-memory repository for incrementing counters.
+type MetricMemoryIncrementRepository struct {
+	store MetricStore
+}
+
+// NewMetricMemoryIncrementRepository creates and returns a new MetricMemoryIncrementRepository instance
+// incrementing counters in the given store.
+func NewMetricMemoryIncrementRepository(store MetricStore) *MetricMemoryIncrementRepository {
+	return &MetricMemoryIncrementRepository{store: store}
+}
+
+// Increment atomically adds delta to the counter with the given ID, creating it if there is none.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//   - id: The identifier of the counter.
+//   - delta: The value to add.
+//
+// Returns:
+//   - The resulting total of the counter.
+//   - An error if the store fails.
+func (repo *MetricMemoryIncrementRepository) Increment(
+	ctx context.Context,
+	id types.MetricID,
+	delta int64,
+) (int64, error) {
+	return repo.store.Increment(ctx, id, delta)
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricMemoryIncrementRepository_Increment(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricMemoryStore()
+	repo := NewMetricMemoryIncrementRepository(store)
+
+	id := types.MetricID{ID: "hits", Type: types.Counter}
+
+	total, err := repo.Increment(ctx, id, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+
+	total, err = repo.Increment(ctx, id, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), total)
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.NotNil(t, got.Delta)
+	assert.Equal(t, int64(8), *got.Delta)
+
+	// Counters with other labels are separate
+	labeled := types.MetricID{ID: "hits", Type: types.Counter, Labels: types.NewLabels(map[string]string{"host": "a"})}
+	total, err = repo.Increment(ctx, labeled, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	got, err = store.Get(ctx, labeled)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, labeled.Labels, got.Labels)
+}
+
+func TestMetricMemoryIncrementRepository_Concurrent(t *testing.T) {
+	const n = 1000
+
+	ctx := context.Background()
+	store := NewMetricMemoryStore()
+	repo := NewMetricMemoryIncrementRepository(store)
+	id := types.MetricID{ID: "hits", Type: types.Counter}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Increment(ctx, id, 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(n), *got.Delta)
+}
@@ -8,32 +8,35 @@ import (
 )
 
 // MetricMemoryListRepository provides in-memory listing of all stored metrics.
-type MetricMemoryListRepository struct{}
+type MetricMemoryListRepository struct {
+	store MetricStore
+}
 
-// NewMetricMemoryListRepository creates and returns a new MetricMemoryListRepository instance.
-func NewMetricMemoryListRepository() *MetricMemoryListRepository {
-	return &MetricMemoryListRepository{}
+// NewMetricMemoryListRepository creates and returns a new MetricMemoryListRepository instance
+// listing the metrics of the given store.
+func NewMetricMemoryListRepository(store MetricStore) *MetricMemoryListRepository {
+	return &MetricMemoryListRepository{store: store}
 }
 
-// List returns all metrics currently stored in memory, sorted by their MetricID.
+// List returns all metrics currently in the store, sorted by their MetricID.
 //
 // Parameters:
-//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//   - ctx: Context for cancellation and deadlines.
 //
 // Returns:
 //   - A slice of Metrics sorted by their ID.
-//   - An error if the operation fails (currently always nil as no error handling is implemented).
+//   - An error if the store fails.
 func (repo *MetricMemoryListRepository) List(ctx context.Context) ([]types.Metrics, error) {
-	mu.RLock()
-	defer mu.RUnlock()
-
-	list := make([]types.Metrics, 0, len(metrics))
-	for _, m := range metrics {
-		list = append(list, m)
+	list, err := repo.store.List(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	sort.Slice(list, func(i, j int) bool {
-		return list[i].ID < list[j].ID
+		if list[i].ID != list[j].ID {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].Labels < list[j].Labels
 	})
 
 	return list, nil
@@ -7,15 +7,12 @@ import (
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMetricMemoryListRepository_List(t *testing.T) {
-	// Очищаем глобальное хранилище
-	mu.Lock()
-	metrics = make(map[types.MetricID]types.Metrics)
-	mu.Unlock()
-
-	repo := NewMetricMemoryListRepository()
+	store := NewMetricMemoryStore()
+	repo := NewMetricMemoryListRepository(store)
 	ctx := context.Background()
 
 	ptrFloat64 := func(f float64) *float64 {
@@ -29,11 +26,9 @@ func TestMetricMemoryListRepository_List(t *testing.T) {
 	metric2 := types.Metrics{ID: "metricA", Type: types.Counter, Delta: ptrInt64(42)}
 	metric3 := types.Metrics{ID: "metricC", Type: types.Gauge, Value: ptrFloat64(2.71)}
 
-	mu.Lock()
-	metrics[types.MetricID{ID: metric1.ID, Type: metric1.Type}] = metric1
-	metrics[types.MetricID{ID: metric2.ID, Type: metric2.Type}] = metric2
-	metrics[types.MetricID{ID: metric3.ID, Type: metric3.Type}] = metric3
-	mu.Unlock()
+	for _, m := range []types.Metrics{metric1, metric2, metric3} {
+		require.NoError(t, store.Save(ctx, m))
+	}
 
 	tests := []struct {
 		name    string
@@ -7,28 +7,27 @@ import (
 )
 
 // MetricMemorySaveRepository provides an in-memory repository for saving metrics.
-type MetricMemorySaveRepository struct{}
+type MetricMemorySaveRepository struct {
+	store MetricStore
+}
 
-// NewMetricMemorySaveRepository creates and returns a new MetricMemorySaveRepository instance.
-func NewMetricMemorySaveRepository() *MetricMemorySaveRepository {
-	return &MetricMemorySaveRepository{}
+// NewMetricMemorySaveRepository creates and returns a new MetricMemorySaveRepository instance
+// saving metrics to the given store.
+func NewMetricMemorySaveRepository(store MetricStore) *MetricMemorySaveRepository {
+	return &MetricMemorySaveRepository{store: store}
 }
 
-// Save stores the given metric in memory, keyed by its MetricID.
+// Save stores the given metric in the store, keyed by its MetricID.
 //
 // Parameters:
-//   - ctx: Context for cancellation and deadlines (not used in current implementation).
+//   - ctx: Context for cancellation and deadlines.
 //   - m: The Metrics value to save.
 //
 // Returns:
-//   - An error if saving fails (currently always nil as no error handling is implemented).
+//   - An error if the store fails.
 func (repo *MetricMemorySaveRepository) Save(
 	ctx context.Context,
 	m types.Metrics,
 ) error {
-	mu.Lock()
-	defer mu.Unlock()
-
-	metrics[types.MetricID{ID: m.ID, Type: m.Type}] = m
-	return nil
+	return repo.store.Save(ctx, m)
 }
@@ -10,12 +10,8 @@ import (
 )
 
 func TestMetricMemorySaveRepository_Save(t *testing.T) {
-	// Очищаем глобальное хранилище перед запуском теста
-	mu.Lock()
-	metrics = make(map[types.MetricID]types.Metrics)
-	mu.Unlock()
-
-	repo := NewMetricMemorySaveRepository()
+	store := NewMetricMemoryStore()
+	repo := NewMetricMemorySaveRepository(store)
 
 	ptrInt64 := func(i int64) *int64 { return &i }
 	ptrFloat64 := func(f float64) *float64 { return &f }
@@ -58,12 +54,11 @@ func TestMetricMemorySaveRepository_Save(t *testing.T) {
 
 			key := types.MetricID{ID: tt.input.ID, Type: tt.input.Type}
 
-			mu.RLock()
-			savedMetric, ok := metrics[key]
-			mu.RUnlock()
-
-			assert.True(t, ok, "metric should be saved in global metrics")
-			assert.Equal(t, tt.input, savedMetric)
+			savedMetric, err := store.Get(ctx, key)
+			assert.NoError(t, err)
+			if assert.NotNil(t, savedMetric, "metric should be saved in the store") {
+				assert.Equal(t, tt.input, *savedMetric)
+			}
 		})
 	}
 }
@@ -1,10 +1,179 @@
 package repositories
 
 import (
+	"context"
 	"sync"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
-var metrics map[types.MetricID]types.Metrics = make(map[types.MetricID]types.Metrics)
-var mu sync.RWMutex
+// MetricStore defines the storage the memory repositories keep metrics in.
+//
+// Every store is independent, so several servers, or tests, can run in one process.
+// Implementations must be safe for concurrent use; other backends can be used with
+// the memory repositories by implementing this interface.
+type MetricStore interface {
+	// Get returns the metric with the given ID, or nil if there is none.
+	Get(ctx context.Context, id types.MetricID) (*types.Metrics, error)
+
+	// Save stores the metric as is, replacing the one with the same ID, if any.
+	Save(ctx context.Context, m types.Metrics) error
+
+	// List returns all stored metrics, in no particular order. The slice belongs to the caller.
+	List(ctx context.Context) ([]types.Metrics, error)
+
+	// ListByName returns the stored metrics with the given name and type, whatever their labels,
+	// in no particular order. It is served by an index, not by a scan of all metrics.
+	// The slice belongs to the caller.
+	ListByName(ctx context.Context, id, metricType string) ([]types.Metrics, error)
+
+	// Increment atomically adds delta to the counter with the given ID, creating it if there
+	// is none, and returns the resulting total.
+	Increment(ctx context.Context, id types.MetricID, delta int64) (int64, error)
+
+	// Upsert atomically replaces the metric with the given ID by the result of fn, which is
+	// called with the stored metric, or nil if there is none, and returns the stored result.
+	// No other update of the metric happens between the call of fn and the store of its result.
+	// fn must not use the store.
+	Upsert(ctx context.Context, id types.MetricID, fn func(existing *types.Metrics) types.Metrics) (types.Metrics, error)
+
+	// Delete removes the metric with the given ID, if any.
+	Delete(ctx context.Context, id types.MetricID) error
+}
+
+// metricName identifies the metrics of one name and type, whatever their labels.
+type metricName struct {
+	id         string
+	metricType string
+}
+
+// metricNameIndex maps the name and type of the stored metrics to their IDs.
+type metricNameIndex map[metricName]map[types.MetricID]struct{}
+
+// add records the metric with the given ID as stored.
+func (idx metricNameIndex) add(id types.MetricID) {
+	name := metricName{id: id.ID, metricType: id.Type}
+	ids, ok := idx[name]
+	if !ok {
+		ids = make(map[types.MetricID]struct{})
+		idx[name] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// remove records the metric with the given ID as no longer stored.
+func (idx metricNameIndex) remove(id types.MetricID) {
+	name := metricName{id: id.ID, metricType: id.Type}
+	delete(idx[name], id)
+	if len(idx[name]) == 0 {
+		delete(idx, name)
+	}
+}
+
+// MetricMemoryStore is a MetricStore keeping metrics in a map guarded by a mutex.
+type MetricMemoryStore struct {
+	mu      sync.RWMutex
+	metrics map[types.MetricID]types.Metrics
+	names   metricNameIndex
+}
+
+// NewMetricMemoryStore creates and returns a new, empty MetricMemoryStore.
+func NewMetricMemoryStore() *MetricMemoryStore {
+	return &MetricMemoryStore{
+		metrics: make(map[types.MetricID]types.Metrics),
+		names:   make(metricNameIndex),
+	}
+}
+
+// Get returns the metric with the given ID, or nil if there is none.
+func (s *MetricMemoryStore) Get(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.metrics[id]
+	if !ok {
+		return nil, nil
+	}
+	return &value, nil
+}
+
+// Save stores the metric as is, keyed by its MetricID.
+func (s *MetricMemoryStore) Save(ctx context.Context, m types.Metrics) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels}
+	s.metrics[id] = m
+	s.names.add(id)
+	return nil
+}
+
+// List returns all stored metrics, in no particular order.
+func (s *MetricMemoryStore) List(ctx context.Context) ([]types.Metrics, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]types.Metrics, 0, len(s.metrics))
+	for _, m := range s.metrics {
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+// ListByName returns the stored metrics with the given name and type, whatever their labels.
+func (s *MetricMemoryStore) ListByName(ctx context.Context, id, metricType string) ([]types.Metrics, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := s.names[metricName{id: id, metricType: metricType}]
+	list := make([]types.Metrics, 0, len(ids))
+	for metricID := range ids {
+		list = append(list, s.metrics[metricID])
+	}
+	return list, nil
+}
+
+// Increment atomically adds delta to the counter with the given ID, creating it if there
+// is none, and returns the resulting total.
+func (s *MetricMemoryStore) Increment(ctx context.Context, id types.MetricID, delta int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := delta
+	if existing, ok := s.metrics[id]; ok && existing.Delta != nil {
+		total += *existing.Delta
+	}
+	s.metrics[id] = types.Metrics{ID: id.ID, Type: id.Type, Labels: id.Labels, Delta: &total}
+	s.names.add(id)
+	return total, nil
+}
+
+// Upsert atomically replaces the metric with the given ID by the result of fn
+// and returns the result.
+func (s *MetricMemoryStore) Upsert(
+	ctx context.Context,
+	id types.MetricID,
+	fn func(existing *types.Metrics) types.Metrics,
+) (types.Metrics, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var existing *types.Metrics
+	if value, ok := s.metrics[id]; ok {
+		existing = &value
+	}
+	m := fn(existing)
+	s.metrics[id] = m
+	s.names.add(id)
+	return m, nil
+}
+
+// Delete removes the metric with the given ID, if any.
+func (s *MetricMemoryStore) Delete(ctx context.Context, id types.MetricID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.metrics, id)
+	s.names.remove(id)
+	return nil
+}
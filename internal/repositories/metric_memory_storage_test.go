@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricMemoryStore()
+
+	v := 1.5
+	d := int64(3)
+	gauge := types.Metrics{ID: "g", Type: types.Gauge, Value: &v}
+	counter := types.Metrics{ID: "c", Type: types.Counter, Delta: &d}
+
+	got, err := store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, store.Save(ctx, gauge))
+	require.NoError(t, store.Save(ctx, counter))
+
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Equal(t, &gauge, got)
+
+	// The same name with another type is a different metric
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Counter})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	list, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []types.Metrics{gauge, counter}, list)
+
+	// Save replaces the stored metric
+	v2 := 2.5
+	updated := types.Metrics{ID: "g", Type: types.Gauge, Value: &v2}
+	require.NoError(t, store.Save(ctx, updated))
+
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Equal(t, &updated, got)
+
+	// Delete removes the metric, and deleting a missing one is not an error
+	require.NoError(t, store.Delete(ctx, types.MetricID{ID: "g", Type: types.Gauge}))
+	require.NoError(t, store.Delete(ctx, types.MetricID{ID: "g", Type: types.Gauge}))
+
+	got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestMetricMemoryStore_Independent(t *testing.T) {
+	ctx := context.Background()
+	first := NewMetricMemoryStore()
+	second := NewMetricMemoryStore()
+
+	v := 1.0
+	require.NoError(t, first.Save(ctx, types.Metrics{ID: "g", Type: types.Gauge, Value: &v}))
+
+	got, err := second.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	list, err := second.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestMetricStore_ListByName(t *testing.T) {
+	ctx := context.Background()
+	boltStore, db := openBoltStore(t, t.TempDir())
+	defer db.Close()
+
+	v := 1.5
+	hostA := types.Metrics{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: &v}
+	hostB := types.Metrics{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "b"}), Value: &v}
+	unlabeled := types.Metrics{ID: "Alloc", Type: types.Gauge, Value: &v}
+	otherType := types.Metrics{ID: "Alloc", Type: types.Counter, Delta: new(int64)}
+	otherName := types.Metrics{ID: "AllocTotal", Type: types.Gauge, Value: &v}
+
+	for name, store := range map[string]MetricStore{
+		"memory":  NewMetricMemoryStore(),
+		"sharded": NewMetricShardedStore(4),
+		"tx":      NewMetricTxStore(NewMetricShardedStore(4)),
+		"bolt":    boltStore,
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, m := range []types.Metrics{hostA, hostB, unlabeled, otherType, otherName} {
+				require.NoError(t, store.Save(ctx, m))
+			}
+
+			list, err := store.ListByName(ctx, "Alloc", types.Gauge)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []types.Metrics{hostA, hostB, unlabeled}, list)
+
+			// Deleted metrics leave the index
+			require.NoError(t, store.Delete(ctx, types.MetricID{ID: hostB.ID, Type: hostB.Type, Labels: hostB.Labels}))
+			list, err = store.ListByName(ctx, "Alloc", types.Gauge)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []types.Metrics{hostA, unlabeled}, list)
+
+			list, err = store.ListByName(ctx, "Missing", types.Gauge)
+			require.NoError(t, err)
+			assert.Empty(t, list)
+		})
+	}
+}
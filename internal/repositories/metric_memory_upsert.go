@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricMemoryUpsertRepository provides an in-memory repository for read-modify-write updates of metrics.
+type MetricMemoryUpsertRepository struct {
+	store MetricStore
+}
+
+// NewMetricMemoryUpsertRepository creates and returns a new MetricMemoryUpsertRepository instance
+// updating metrics in the given store.
+func NewMetricMemoryUpsertRepository(store MetricStore) *MetricMemoryUpsertRepository {
+	return &MetricMemoryUpsertRepository{store: store}
+}
+
+// Upsert atomically replaces the metric with the given ID by the result of fn.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines.
+//   - id: The identifier of the metric.
+//   - fn: Computes the new metric from the stored one, or from nil if there is none.
+//
+// Returns:
+//   - The stored result of fn.
+//   - An error if the store fails.
+func (repo *MetricMemoryUpsertRepository) Upsert(
+	ctx context.Context,
+	id types.MetricID,
+	fn func(existing *types.Metrics) types.Metrics,
+) (types.Metrics, error) {
+	return repo.store.Upsert(ctx, id, fn)
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addObservation returns an upsert function adding one observation to the count of a summary.
+func addObservation(id types.MetricID) func(existing *types.Metrics) types.Metrics {
+	return func(existing *types.Metrics) types.Metrics {
+		count := int64(1)
+		if existing != nil && existing.Count != nil {
+			count += *existing.Count
+		}
+		return types.Metrics{ID: id.ID, Type: id.Type, Labels: id.Labels, Count: &count}
+	}
+}
+
+func TestMetricMemoryUpsertRepository_Upsert(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricMemoryStore()
+	repo := NewMetricMemoryUpsertRepository(store)
+
+	id := types.MetricID{ID: "Latency", Type: types.Summary}
+
+	m, err := repo.Upsert(ctx, id, addObservation(id))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), *m.Count)
+
+	m, err = repo.Upsert(ctx, id, addObservation(id))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), *m.Count)
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, m, *got)
+}
+
+func TestMetricMemoryUpsertRepository_Concurrent(t *testing.T) {
+	const n = 1000
+
+	ctx := context.Background()
+	for name, store := range map[string]MetricStore{
+		"memory":  NewMetricMemoryStore(),
+		"sharded": NewMetricShardedStore(4),
+		"tx":      NewMetricTxStore(NewMetricMemoryStore()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := NewMetricMemoryUpsertRepository(store)
+			id := types.MetricID{ID: "Latency", Type: types.Summary}
+
+			var wg sync.WaitGroup
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := repo.Upsert(ctx, id, addObservation(id))
+					assert.NoError(t, err)
+				}()
+			}
+			wg.Wait()
+
+			got, err := store.Get(ctx, id)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, int64(n), *got.Count)
+		})
+	}
+}
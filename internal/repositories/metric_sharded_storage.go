@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// DefaultMetricStoreShards is the number of shards used by NewMetricShardedStore
+// when a non-positive number is given.
+const DefaultMetricStoreShards = 32
+
+// MetricShardedStore is a MetricStore splitting metrics between shards, each guarded by its own mutex,
+// so that writes of different metrics rarely wait for each other. The shard of a metric is chosen by
+// a hash of its MetricID.
+//
+// The metrics of every name and type are indexed, whatever shards they are in. The index is
+// guarded by a lock of its own, taken only when a metric is created or deleted, and always
+// after the lock of the metric's shard.
+type MetricShardedStore struct {
+	seed   maphash.Seed
+	shards []metricShard
+
+	namesMu sync.RWMutex
+	names   metricNameIndex
+}
+
+// metricShard is a part of the metrics of a MetricShardedStore with its own lock.
+type metricShard struct {
+	mu      sync.RWMutex
+	metrics map[types.MetricID]types.Metrics
+}
+
+// NewMetricShardedStore creates and returns a new, empty MetricShardedStore with the given number of shards.
+// A non-positive number selects DefaultMetricStoreShards.
+func NewMetricShardedStore(shards int) *MetricShardedStore {
+	if shards <= 0 {
+		shards = DefaultMetricStoreShards
+	}
+
+	s := &MetricShardedStore{
+		seed:   maphash.MakeSeed(),
+		shards: make([]metricShard, shards),
+		names:  make(metricNameIndex),
+	}
+	for i := range s.shards {
+		s.shards[i].metrics = make(map[types.MetricID]types.Metrics)
+	}
+	return s
+}
+
+// shard returns the shard holding the metric with the given ID.
+func (s *MetricShardedStore) shard(id types.MetricID) *metricShard {
+	var h maphash.Hash
+	h.SetSeed(s.seed)
+	h.WriteString(id.ID)
+	h.WriteByte(0)
+	h.WriteString(id.Type)
+	h.WriteByte(0)
+	h.WriteString(string(id.Labels))
+	return &s.shards[h.Sum64()%uint64(len(s.shards))]
+}
+
+// put stores the metric under the given ID in its locked shard, indexing the ID if it is new.
+func (s *MetricShardedStore) put(shard *metricShard, id types.MetricID, m types.Metrics) {
+	if _, ok := shard.metrics[id]; !ok {
+		s.namesMu.Lock()
+		s.names.add(id)
+		s.namesMu.Unlock()
+	}
+	shard.metrics[id] = m
+}
+
+// Get returns the metric with the given ID, or nil if there is none.
+func (s *MetricShardedStore) Get(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	shard := s.shard(id)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	value, ok := shard.metrics[id]
+	if !ok {
+		return nil, nil
+	}
+	return &value, nil
+}
+
+// Save stores the metric as is, keyed by its MetricID.
+func (s *MetricShardedStore) Save(ctx context.Context, m types.Metrics) error {
+	id := types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels}
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	s.put(shard, id, m)
+	return nil
+}
+
+// List returns all stored metrics, in no particular order.
+//
+// The result is a consistent snapshot: every shard is read-locked before any is copied,
+// so no update is seen partially. Writers wait only for the copy, not for the caller's
+// processing of the result.
+func (s *MetricShardedStore) List(ctx context.Context) ([]types.Metrics, error) {
+	// Shards are always locked in the same order, so List calls cannot deadlock each other
+	for i := range s.shards {
+		s.shards[i].mu.RLock()
+	}
+	defer func() {
+		for i := range s.shards {
+			s.shards[i].mu.RUnlock()
+		}
+	}()
+
+	n := 0
+	for i := range s.shards {
+		n += len(s.shards[i].metrics)
+	}
+
+	list := make([]types.Metrics, 0, n)
+	for i := range s.shards {
+		for _, m := range s.shards[i].metrics {
+			list = append(list, m)
+		}
+	}
+	return list, nil
+}
+
+// ListByName returns the stored metrics with the given name and type, whatever their labels.
+// Every metric is read under the lock of its shard only, as by Get.
+func (s *MetricShardedStore) ListByName(ctx context.Context, id, metricType string) ([]types.Metrics, error) {
+	s.namesMu.RLock()
+	indexed := s.names[metricName{id: id, metricType: metricType}]
+	ids := make([]types.MetricID, 0, len(indexed))
+	for metricID := range indexed {
+		ids = append(ids, metricID)
+	}
+	s.namesMu.RUnlock()
+
+	list := make([]types.Metrics, 0, len(ids))
+	for _, metricID := range ids {
+		// A metric deleted since the index was read is skipped
+		m, err := s.Get(ctx, metricID)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			list = append(list, *m)
+		}
+	}
+	return list, nil
+}
+
+// Increment atomically adds delta to the counter with the given ID, creating it if there
+// is none, and returns the resulting total.
+func (s *MetricShardedStore) Increment(ctx context.Context, id types.MetricID, delta int64) (int64, error) {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	total := delta
+	if existing, ok := shard.metrics[id]; ok && existing.Delta != nil {
+		total += *existing.Delta
+	}
+	s.put(shard, id, types.Metrics{ID: id.ID, Type: id.Type, Labels: id.Labels, Delta: &total})
+	return total, nil
+}
+
+// Upsert atomically replaces the metric with the given ID by the result of fn
+// and returns the result. Only the shard of the metric is locked meanwhile.
+func (s *MetricShardedStore) Upsert(
+	ctx context.Context,
+	id types.MetricID,
+	fn func(existing *types.Metrics) types.Metrics,
+) (types.Metrics, error) {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	var existing *types.Metrics
+	if value, ok := shard.metrics[id]; ok {
+		existing = &value
+	}
+	m := fn(existing)
+	s.put(shard, id, m)
+	return m, nil
+}
+
+// Delete removes the metric with the given ID, if any.
+func (s *MetricShardedStore) Delete(ctx context.Context, id types.MetricID) error {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if _, ok := shard.metrics[id]; ok {
+		delete(shard.metrics, id)
+		s.namesMu.Lock()
+		s.names.remove(id)
+		s.namesMu.Unlock()
+	}
+	return nil
+}
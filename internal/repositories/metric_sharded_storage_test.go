@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricShardedStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricShardedStore(4)
+
+	var saved []types.Metrics
+	for i := 0; i < 100; i++ {
+		v := float64(i)
+		m := types.Metrics{ID: "g" + strconv.Itoa(i), Type: types.Gauge, Value: &v}
+		require.NoError(t, store.Save(ctx, m))
+		saved = append(saved, m)
+	}
+
+	for _, m := range saved {
+		got, err := store.Get(ctx, types.MetricID{ID: m.ID, Type: m.Type})
+		require.NoError(t, err)
+		assert.Equal(t, &m, got)
+	}
+
+	got, err := store.Get(ctx, types.MetricID{ID: "g0", Type: types.Counter})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	list, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, saved, list)
+
+	total, err := store.Increment(ctx, types.MetricID{ID: "c", Type: types.Counter}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	total, err = store.Increment(ctx, types.MetricID{ID: "c", Type: types.Counter}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+
+	require.NoError(t, store.Delete(ctx, types.MetricID{ID: "g0", Type: types.Gauge}))
+	got, err = store.Get(ctx, types.MetricID{ID: "g0", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestNewMetricShardedStore_DefaultShards(t *testing.T) {
+	assert.Len(t, NewMetricShardedStore(0).shards, DefaultMetricStoreShards)
+	assert.Len(t, NewMetricShardedStore(-1).shards, DefaultMetricStoreShards)
+	assert.Len(t, NewMetricShardedStore(8).shards, 8)
+}
+
+func TestMetricShardedStore_ConcurrentIncrement(t *testing.T) {
+	const n = 1000
+
+	ctx := context.Background()
+	store := NewMetricShardedStore(0)
+	id := types.MetricID{ID: "hits", Type: types.Counter}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Increment(ctx, id, 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(n), *got.Delta)
+}
+
+func TestMetricShardedStore_ListSnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricShardedStore(2)
+
+	// Pick two counters kept in different shards
+	first := types.MetricID{ID: "first", Type: types.Counter}
+	var second types.MetricID
+	for i := 0; ; i++ {
+		second = types.MetricID{ID: "second" + strconv.Itoa(i), Type: types.Counter}
+		if store.shard(second) != store.shard(first) {
+			break
+		}
+	}
+
+	// The writer always increments first before second, so any consistent snapshot
+	// has first equal to second or ahead of it by one
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10000; i++ {
+			store.Increment(ctx, first, 1)
+			store.Increment(ctx, second, 1)
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		list, err := store.List(ctx)
+		require.NoError(t, err)
+
+		var a, b int64
+		for _, m := range list {
+			switch m.ID {
+			case first.ID:
+				a = *m.Delta
+			case second.ID:
+				b = *m.Delta
+			}
+		}
+		diff := a - b
+		require.True(t, diff == 0 || diff == 1, "inconsistent snapshot: %s=%d, %s=%d", first.ID, a, second.ID, b)
+	}
+}
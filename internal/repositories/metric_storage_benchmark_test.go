@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// benchmarkMetrics is the number of distinct metrics the benchmark stores are filled with.
+const benchmarkMetrics = 1000
+
+// BenchmarkMetricStore_MixedLoad compares the stores under concurrent mixed load.
+// Out of every 100 operations, list is the number of sorted listings, as made by the HTML page,
+// get the number of reads and the rest are writes, split evenly between saves and increments.
+func BenchmarkMetricStore_MixedLoad(b *testing.B) {
+	stores := []struct {
+		name string
+		new  func() MetricStore
+	}{
+		{name: "mutex", new: func() MetricStore { return NewMetricMemoryStore() }},
+		{name: "sharded", new: func() MetricStore { return NewMetricShardedStore(DefaultMetricStoreShards) }},
+	}
+	mixes := []struct {
+		name string
+		list int
+		get  int
+	}{
+		{name: "write-only", list: 0, get: 0},
+		{name: "read-write", list: 0, get: 50},
+		{name: "write-heavy", list: 1, get: 9},
+		{name: "read-heavy", list: 1, get: 89},
+		{name: "list-heavy", list: 10, get: 40},
+	}
+
+	for _, mix := range mixes {
+		for _, st := range stores {
+			b.Run(mix.name+"/"+st.name, func(b *testing.B) {
+				benchmarkMixedLoad(b, st.new(), mix.list, mix.get)
+			})
+		}
+	}
+}
+
+func benchmarkMixedLoad(b *testing.B, store MetricStore, list, get int) {
+	ctx := context.Background()
+
+	ids := make([]types.MetricID, benchmarkMetrics)
+	for i := range ids {
+		ids[i] = types.MetricID{ID: "metric" + strconv.Itoa(i), Type: types.Counter}
+		if _, err := store.Increment(ctx, ids[i], 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+	lister := NewMetricMemoryListRepository(store)
+
+	var worker atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		// Workers start at different metrics, so they do not all contend for the same one
+		i := int(worker.Add(1)) * 7919
+		for pb.Next() {
+			i++
+			id := ids[i%len(ids)]
+
+			var err error
+			switch op := i % 100; {
+			case op < list:
+				_, err = lister.List(ctx)
+			case op < list+get:
+				_, err = store.Get(ctx, id)
+			case op%2 == 0:
+				delta := int64(1)
+				err = store.Save(ctx, types.Metrics{ID: id.ID, Type: id.Type, Delta: &delta})
+			default:
+				_, err = store.Increment(ctx, id, 1)
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,292 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// ErrMetricTxOrder is returned when a batch of a MetricTxStore touches a metric
+// out of the ascending MetricID order.
+var ErrMetricTxOrder = errors.New("metrics of a batch must be touched in ascending order")
+
+// metricTxBatchKey is the context key under which the current batch of a MetricTxStore is stored.
+type metricTxBatchKey struct{}
+
+// metricTxLock is the lock of a single metric of a MetricTxStore.
+type metricTxLock struct {
+	mu   sync.RWMutex
+	refs int // goroutines holding or waiting for the lock, guarded by MetricTxStore.locksMu
+}
+
+// metricTxBatch records the metrics locked by a batch and the values the metrics it changed
+// had before it, so that the batch can be rolled back.
+type metricTxBatch struct {
+	store   *MetricTxStore
+	unlocks map[types.MetricID]func()
+	last    types.MetricID // greatest metric locked so far
+	undo    map[types.MetricID]*types.Metrics
+}
+
+// MetricTxStore is a MetricStore decorator that applies batches of changes atomically.
+//
+// A batch run by Do locks every metric it touches until it ends, so readers of those metrics
+// never see it half applied, and is rolled back if it fails. Batches touching different metrics,
+// and reads and updates of other metrics, run concurrently, as they do on the wrapped store.
+// List waits for the running batches, so that it returns a consistent snapshot.
+type MetricTxStore struct {
+	store MetricStore
+
+	gate    sync.RWMutex // held shared by batches and exclusively by List
+	locksMu sync.Mutex
+	locks   map[types.MetricID]*metricTxLock // locks of the metrics in use, dropped once unused
+}
+
+// NewMetricTxStore creates and returns a new MetricTxStore wrapping the given store.
+func NewMetricTxStore(store MetricStore) *MetricTxStore {
+	return &MetricTxStore{store: store, locks: make(map[types.MetricID]*metricTxLock)}
+}
+
+// Do runs fn as a single batch: either all of the changes fn makes through the store
+// with the context passed to it are applied, or, if fn returns an error, none of them.
+// The changes are made in place and undone on failure, as the wrapped store has no
+// transactions of its own; fn must not use the context from several goroutines.
+//
+// fn must touch metrics in ascending order, see types.MetricID.Compare, so that batches
+// locking the same metrics cannot deadlock; touching a metric the batch has not locked yet
+// that comes before one it has fails with ErrMetricTxOrder.
+//
+// A Do called with the context of a running batch of the same store joins that batch.
+func (s *MetricTxStore) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.batch(ctx) != nil {
+		return fn(ctx)
+	}
+
+	s.gate.RLock()
+	defer s.gate.RUnlock()
+
+	batch := &metricTxBatch{
+		store:   s,
+		unlocks: make(map[types.MetricID]func()),
+		undo:    make(map[types.MetricID]*types.Metrics),
+	}
+	defer batch.unlock()
+
+	if err := fn(context.WithValue(ctx, metricTxBatchKey{}, batch)); err != nil {
+		return errors.Join(err, s.rollback(context.WithoutCancel(ctx), batch))
+	}
+	return nil
+}
+
+// Get returns the metric with the given ID, or nil if there is none.
+func (s *MetricTxStore) Get(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	if batch := s.batch(ctx); batch != nil {
+		if err := batch.acquire(id); err != nil {
+			return nil, err
+		}
+	} else {
+		defer s.rlock(id)()
+	}
+	return s.store.Get(ctx, id)
+}
+
+// Save stores the metric as is, replacing the one with the same ID, if any.
+func (s *MetricTxStore) Save(ctx context.Context, m types.Metrics) error {
+	id := types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels}
+	if batch := s.batch(ctx); batch != nil {
+		if err := s.remember(ctx, batch, id); err != nil {
+			return err
+		}
+	} else {
+		defer s.lock(id)()
+	}
+	return s.store.Save(ctx, m)
+}
+
+// List returns all stored metrics, in no particular order. The slice belongs to the caller.
+// Outside a batch, it waits for the running batches to end.
+func (s *MetricTxStore) List(ctx context.Context) ([]types.Metrics, error) {
+	if s.batch(ctx) == nil {
+		s.gate.Lock()
+		defer s.gate.Unlock()
+	}
+	return s.store.List(ctx)
+}
+
+// ListByName returns the stored metrics with the given name and type, whatever their labels.
+// Outside a batch, every metric is read again under its lock, as by Get, so none is seen
+// in the middle of a batch.
+func (s *MetricTxStore) ListByName(ctx context.Context, id, metricType string) ([]types.Metrics, error) {
+	list, err := s.store.ListByName(ctx, id, metricType)
+	if err != nil || s.batch(ctx) != nil {
+		return list, err
+	}
+
+	found := list[:0]
+	for _, m := range list {
+		stored, err := s.Get(ctx, types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels})
+		if err != nil {
+			return nil, err
+		}
+		if stored != nil {
+			found = append(found, *stored)
+		}
+	}
+	return found, nil
+}
+
+// Increment atomically adds delta to the counter with the given ID, creating it if there
+// is none, and returns the resulting total.
+func (s *MetricTxStore) Increment(ctx context.Context, id types.MetricID, delta int64) (int64, error) {
+	if batch := s.batch(ctx); batch != nil {
+		if err := s.remember(ctx, batch, id); err != nil {
+			return 0, err
+		}
+	} else {
+		defer s.lock(id)()
+	}
+	return s.store.Increment(ctx, id, delta)
+}
+
+// Upsert atomically replaces the metric with the given ID by the result of fn
+// and returns the result.
+func (s *MetricTxStore) Upsert(
+	ctx context.Context,
+	id types.MetricID,
+	fn func(existing *types.Metrics) types.Metrics,
+) (types.Metrics, error) {
+	if batch := s.batch(ctx); batch != nil {
+		if err := s.remember(ctx, batch, id); err != nil {
+			return types.Metrics{}, err
+		}
+	} else {
+		defer s.lock(id)()
+	}
+	return s.store.Upsert(ctx, id, fn)
+}
+
+// Delete removes the metric with the given ID, if any.
+func (s *MetricTxStore) Delete(ctx context.Context, id types.MetricID) error {
+	if batch := s.batch(ctx); batch != nil {
+		if err := s.remember(ctx, batch, id); err != nil {
+			return err
+		}
+	} else {
+		defer s.lock(id)()
+	}
+	return s.store.Delete(ctx, id)
+}
+
+// batch returns the batch of this store running in ctx, or nil if there is none.
+func (s *MetricTxStore) batch(ctx context.Context) *metricTxBatch {
+	batch, ok := ctx.Value(metricTxBatchKey{}).(*metricTxBatch)
+	if !ok || batch.store != s {
+		return nil
+	}
+	return batch
+}
+
+// ref returns the lock of the metric with the given ID, counting the caller as its user.
+func (s *MetricTxStore) ref(id types.MetricID) *metricTxLock {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+
+	l, ok := s.locks[id]
+	if !ok {
+		l = &metricTxLock{}
+		s.locks[id] = l
+	}
+	l.refs++
+	return l
+}
+
+// unref drops the caller's use of the lock, removing the lock once nobody uses it.
+func (s *MetricTxStore) unref(id types.MetricID, l *metricTxLock) {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+
+	l.refs--
+	if l.refs == 0 {
+		delete(s.locks, id)
+	}
+}
+
+// lock locks the metric with the given ID for writing and returns the function unlocking it.
+func (s *MetricTxStore) lock(id types.MetricID) func() {
+	l := s.ref(id)
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		s.unref(id, l)
+	}
+}
+
+// rlock locks the metric with the given ID for reading and returns the function unlocking it.
+func (s *MetricTxStore) rlock(id types.MetricID) func() {
+	l := s.ref(id)
+	l.mu.RLock()
+	return func() {
+		l.mu.RUnlock()
+		s.unref(id, l)
+	}
+}
+
+// acquire locks the metric with the given ID until the end of the batch, unless the batch
+// already holds it.
+func (b *metricTxBatch) acquire(id types.MetricID) error {
+	if _, ok := b.unlocks[id]; ok {
+		return nil
+	}
+	if len(b.unlocks) > 0 && id.Compare(b.last) < 0 {
+		return fmt.Errorf("%w: %s %s after %s %s", ErrMetricTxOrder, id.Type, id.ID, b.last.Type, b.last.ID)
+	}
+
+	b.unlocks[id] = b.store.lock(id)
+	b.last = id
+	return nil
+}
+
+// unlock releases every metric locked by the batch.
+func (b *metricTxBatch) unlock() {
+	for _, unlock := range b.unlocks {
+		unlock()
+	}
+}
+
+// remember locks the metric for the batch and records the value it had before the batch
+// first changed it.
+func (s *MetricTxStore) remember(ctx context.Context, batch *metricTxBatch, id types.MetricID) error {
+	if err := batch.acquire(id); err != nil {
+		return err
+	}
+	if _, ok := batch.undo[id]; ok {
+		return nil
+	}
+
+	prev, err := s.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	batch.undo[id] = prev
+	return nil
+}
+
+// rollback restores every metric changed by the batch to its value before the batch.
+func (s *MetricTxStore) rollback(ctx context.Context, batch *metricTxBatch) error {
+	var errs []error
+	for id, prev := range batch.undo {
+		var err error
+		if prev == nil {
+			err = s.store.Delete(ctx, id)
+		} else {
+			err = s.store.Save(ctx, *prev)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
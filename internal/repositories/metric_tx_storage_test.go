@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricTxStore_Do(t *testing.T) {
+	ctx := context.Background()
+
+	v := 1.5
+	v2 := 2.5
+	d := int64(3)
+	total := int64(5)
+	observations := int64(1)
+	gauge := types.Metrics{ID: "g", Type: types.Gauge, Value: &v}
+	counter := types.Metrics{ID: "c", Type: types.Counter, Delta: &d}
+	errBatch := errors.New("batch failed")
+
+	tests := []struct {
+		name    string
+		err     error
+		want    []types.Metrics
+		wantErr error
+	}{
+		{
+			name: "applied",
+			want: []types.Metrics{
+				{ID: "g", Type: types.Gauge, Value: &v2},
+				{ID: "c", Type: types.Counter, Delta: &total},
+				{ID: "new", Type: types.Gauge, Value: &v2},
+				{ID: "s", Type: types.Summary, Count: &observations},
+			},
+		},
+		{
+			name:    "rolled back",
+			err:     errBatch,
+			want:    []types.Metrics{gauge, counter},
+			wantErr: errBatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMetricTxStore(NewMetricMemoryStore())
+			require.NoError(t, store.Save(ctx, gauge))
+			require.NoError(t, store.Save(ctx, counter))
+
+			err := store.Do(ctx, func(ctx context.Context) error {
+				// Metrics are touched in ascending order
+				if _, err := store.Increment(ctx, types.MetricID{ID: "c", Type: types.Counter}, 2); err != nil {
+					return err
+				}
+				if err := store.Save(ctx, types.Metrics{ID: "g", Type: types.Gauge, Value: &v2}); err != nil {
+					return err
+				}
+				if err := store.Save(ctx, types.Metrics{ID: "new", Type: types.Gauge, Value: &v2}); err != nil {
+					return err
+				}
+				summary := types.MetricID{ID: "s", Type: types.Summary}
+				if _, err := store.Upsert(ctx, summary, addObservation(summary)); err != nil {
+					return err
+				}
+				// A nested batch joins the running one
+				return store.Do(ctx, func(ctx context.Context) error {
+					return tt.err
+				})
+			})
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			list, err := store.List(ctx)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, list)
+		})
+	}
+}
+
+func TestMetricTxStore_ReadersWaitForBatch(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricTxStore(NewMetricMemoryStore())
+	id := types.MetricID{ID: "c", Type: types.Counter}
+
+	started := make(chan struct{})
+	read := make(chan *types.Metrics)
+
+	err := store.Do(ctx, func(ctx context.Context) error {
+		if _, err := store.Increment(ctx, id, 1); err != nil {
+			return err
+		}
+
+		go func() {
+			close(started)
+			got, err := store.Get(context.Background(), id)
+			assert.NoError(t, err)
+			read <- got
+		}()
+		<-started
+
+		select {
+		case <-read:
+			t.Error("read the metric while the batch was running")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		_, err := store.Increment(ctx, id, 1)
+		return err
+	})
+	require.NoError(t, err)
+
+	got := <-read
+	require.NotNil(t, got)
+	assert.Equal(t, int64(2), *got.Delta)
+}
+
+func TestMetricTxStore_OtherMetricsDuringBatch(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricTxStore(NewMetricShardedStore(0))
+	inBatch := types.MetricID{ID: "a", Type: types.Counter}
+	other := types.MetricID{ID: "b", Type: types.Counter}
+
+	err := store.Do(ctx, func(ctx context.Context) error {
+		if _, err := store.Increment(ctx, inBatch, 1); err != nil {
+			return err
+		}
+
+		// Metrics the batch does not touch are read and updated without waiting for it
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := store.Increment(context.Background(), other, 1)
+			assert.NoError(t, err)
+			got, err := store.Get(context.Background(), other)
+			assert.NoError(t, err)
+			assert.NotNil(t, got)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("an update of another metric waited for the batch")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMetricTxStore_Order(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricTxStore(NewMetricMemoryStore())
+	v := 1.0
+
+	err := store.Do(ctx, func(ctx context.Context) error {
+		if err := store.Save(ctx, types.Metrics{ID: "b", Type: types.Gauge, Value: &v}); err != nil {
+			return err
+		}
+		// Metrics already locked by the batch can be touched again in any order
+		if err := store.Save(ctx, types.Metrics{ID: "b", Type: types.Gauge, Value: &v}); err != nil {
+			return err
+		}
+		return store.Save(ctx, types.Metrics{ID: "a", Type: types.Gauge, Value: &v})
+	})
+	assert.ErrorIs(t, err, ErrMetricTxOrder)
+
+	// The batch was rolled back
+	list, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	assert.Empty(t, store.locks)
+}
+
+func TestMetricTxStore_ConcurrentBatches(t *testing.T) {
+	const n = 200
+
+	ctx := context.Background()
+	store := NewMetricTxStore(NewMetricShardedStore(0))
+	a := types.MetricID{ID: "a", Type: types.Counter}
+	b := types.MetricID{ID: "b", Type: types.Counter}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			err := store.Do(ctx, func(ctx context.Context) error {
+				if _, err := store.Increment(ctx, a, 1); err != nil {
+					return err
+				}
+				_, err := store.Increment(ctx, b, 1)
+				return err
+			})
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			// Every snapshot sees whole batches only
+			list, err := store.List(ctx)
+			assert.NoError(t, err)
+			if len(list) == 2 {
+				assert.Equal(t, *list[0].Delta, *list[1].Delta)
+			}
+		}()
+	}
+	wg.Wait()
+
+	list, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(n), *list[0].Delta)
+	assert.Equal(t, int64(n), *list[1].Delta)
+	assert.Empty(t, store.locks)
+}
@@ -0,0 +1,511 @@
+package repositories
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/maphash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// walHeaderSize is the size of the header preceding every WAL record:
+// the length of the payload and its CRC-32C checksum, both big-endian.
+const walHeaderSize = 8
+
+// walLockStripes is the number of locks serializing the updates of the metrics of a MetricWALStore.
+const walLockStripes = 64
+
+// walTable is the CRC-32C (Castagnoli) table used for record checksums.
+var walTable = crc32.MakeTable(crc32.Castagnoli)
+
+// walRecord is the payload of a WAL record: the resulting value of an updated metric,
+// or the ID of a deleted one.
+type walRecord struct {
+	Metric  types.Metrics `json:"metric"`
+	Deleted bool          `json:"deleted,omitempty"`
+}
+
+// MetricWALStore is a MetricStore recording every update in an append-only write-ahead log
+// before applying it to the wrapped store, so that updates accepted since the last snapshot
+// survive a crash.
+//
+// Every record holds the resulting value of a metric, so replaying the log over a snapshot
+// that already includes some of its records gives the same result. Records are written
+// to the file as they are made; whether they are also flushed to disk right away depends
+// on the sync interval.
+//
+// Updates of the same metric are serialized, so its records are in the log in the order
+// the updates are applied to the store. Updates of different metrics only wait for each
+// other while their records are written.
+//
+// The wrapped store must not be updated other than through the MetricWALStore.
+type MetricWALStore struct {
+	store MetricStore
+	path  string
+
+	seed  maphash.Seed
+	locks [walLockStripes]sync.Mutex // a metric's updates hold the lock chosen by a hash of its ID
+
+	mu      sync.Mutex // guards the file; held only to write a record, not to update the store
+	file    *os.File
+	size    int64 // length of the valid records in file
+	syncErr error // error of the last background sync, reported by the next update
+
+	syncInterval time.Duration
+	done         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewMetricWALStore opens or creates the log at the given path and returns a MetricWALStore
+// recording updates of store in it.
+//
+// A zero syncInterval flushes the log to disk on every update before it returns. A positive one
+// flushes it in the background at that interval instead, so updates made since the last flush
+// are lost if the machine, rather than the process, goes down.
+//
+// The log is not replayed; see Replay. Close must be called once the store is no longer used.
+func NewMetricWALStore(store MetricStore, path string, syncInterval time.Duration) (*MetricWALStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	s := &MetricWALStore{
+		store:        store,
+		path:         path,
+		seed:         maphash.MakeSeed(),
+		file:         file,
+		size:         info.Size(),
+		syncInterval: syncInterval,
+		done:         make(chan struct{}),
+	}
+	if syncInterval > 0 {
+		s.wg.Add(1)
+		go s.syncLoop()
+	}
+	return s, nil
+}
+
+// syncLoop flushes the log to disk every sync interval until the store is closed.
+func (s *MetricWALStore) syncLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.sync(); err != nil {
+				s.mu.Lock()
+				s.syncErr = err
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// sync flushes the log to disk. The file is synced without holding the lock,
+// so updates are not blocked by the flush.
+func (s *MetricWALStore) sync() error {
+	s.mu.Lock()
+	file := s.file
+	s.mu.Unlock()
+
+	err := file.Sync()
+	// The file was replaced by Truncate, which flushed the records it kept
+	if errors.Is(err, os.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// Get returns the metric with the given ID, or nil if there is none.
+func (s *MetricWALStore) Get(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	return s.store.Get(ctx, id)
+}
+
+// List returns all stored metrics, in no particular order.
+func (s *MetricWALStore) List(ctx context.Context) ([]types.Metrics, error) {
+	return s.store.List(ctx)
+}
+
+// ListByName returns the stored metrics with the given name and type, whatever their labels.
+func (s *MetricWALStore) ListByName(ctx context.Context, id, metricType string) ([]types.Metrics, error) {
+	return s.store.ListByName(ctx, id, metricType)
+}
+
+// lock locks the updates of the metric with the given ID and returns the function unlocking them.
+func (s *MetricWALStore) lock(id types.MetricID) func() {
+	var h maphash.Hash
+	h.SetSeed(s.seed)
+	h.WriteString(id.ID)
+	h.WriteByte(0)
+	h.WriteString(id.Type)
+	h.WriteByte(0)
+	h.WriteString(string(id.Labels))
+
+	mu := &s.locks[h.Sum64()%walLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
+
+// lockAll locks the updates of every metric and returns the function unlocking them.
+func (s *MetricWALStore) lockAll() func() {
+	// Stripes are always locked in the same order and updates hold a single one,
+	// so lockAll cannot deadlock
+	for i := range s.locks {
+		s.locks[i].Lock()
+	}
+	return func() {
+		for i := range s.locks {
+			s.locks[i].Unlock()
+		}
+	}
+}
+
+// Save records the metric in the log and then stores it as is.
+func (s *MetricWALStore) Save(ctx context.Context, m types.Metrics) error {
+	unlock := s.lock(types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels})
+	err := s.appendRecord(walRecord{Metric: m})
+	if err == nil {
+		err = s.store.Save(ctx, m)
+	}
+	unlock()
+
+	if err != nil {
+		return err
+	}
+	return s.syncUpdate()
+}
+
+// Increment atomically adds delta to the counter with the given ID, creating it if there
+// is none, records the resulting counter in the log and returns its total.
+func (s *MetricWALStore) Increment(ctx context.Context, id types.MetricID, delta int64) (int64, error) {
+	unlock := s.lock(id)
+	total, err := s.incrementLocked(ctx, id, delta)
+	unlock()
+
+	if err != nil {
+		return 0, err
+	}
+	return total, s.syncUpdate()
+}
+
+// incrementLocked increments the counter in the store and records the result.
+// If the record cannot be written, the counter is restored, which the lock of the
+// metric makes safe: the counter cannot change in between.
+func (s *MetricWALStore) incrementLocked(ctx context.Context, id types.MetricID, delta int64) (int64, error) {
+	prev, err := s.store.Get(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	total, err := s.store.Increment(ctx, id, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	m := types.Metrics{ID: id.ID, Type: id.Type, Labels: id.Labels, Delta: &total}
+	if err := s.appendRecord(walRecord{Metric: m}); err != nil {
+		return 0, errors.Join(err, s.restore(ctx, id, prev))
+	}
+	return total, nil
+}
+
+// Upsert atomically replaces the metric with the given ID by the result of fn,
+// records the result in the log and returns it.
+func (s *MetricWALStore) Upsert(
+	ctx context.Context,
+	id types.MetricID,
+	fn func(existing *types.Metrics) types.Metrics,
+) (types.Metrics, error) {
+	unlock := s.lock(id)
+	m, err := s.upsertLocked(ctx, id, fn)
+	unlock()
+
+	if err != nil {
+		return types.Metrics{}, err
+	}
+	return m, s.syncUpdate()
+}
+
+// upsertLocked updates the metric in the store and records the result.
+// If the record cannot be written, the metric is restored, as in incrementLocked.
+func (s *MetricWALStore) upsertLocked(
+	ctx context.Context,
+	id types.MetricID,
+	fn func(existing *types.Metrics) types.Metrics,
+) (types.Metrics, error) {
+	var prev *types.Metrics
+	m, err := s.store.Upsert(ctx, id, func(existing *types.Metrics) types.Metrics {
+		prev = existing
+		return fn(existing)
+	})
+	if err != nil {
+		return types.Metrics{}, err
+	}
+
+	if err := s.appendRecord(walRecord{Metric: m}); err != nil {
+		return types.Metrics{}, errors.Join(err, s.restore(ctx, id, prev))
+	}
+	return m, nil
+}
+
+// restore puts back the value the metric had before an update that could not be recorded.
+func (s *MetricWALStore) restore(ctx context.Context, id types.MetricID, prev *types.Metrics) error {
+	if prev == nil {
+		return s.store.Delete(ctx, id)
+	}
+	return s.store.Save(ctx, *prev)
+}
+
+// Delete records the deletion in the log and then removes the metric with the given ID, if any.
+func (s *MetricWALStore) Delete(ctx context.Context, id types.MetricID) error {
+	unlock := s.lock(id)
+	err := s.appendRecord(walRecord{Metric: types.Metrics{ID: id.ID, Type: id.Type, Labels: id.Labels}, Deleted: true})
+	if err == nil {
+		err = s.store.Delete(ctx, id)
+	}
+	unlock()
+
+	if err != nil {
+		return err
+	}
+	return s.syncUpdate()
+}
+
+// syncUpdate makes a recorded update durable according to the sync interval: it flushes the log
+// when every update is synced, or reports the failure of the last background sync otherwise.
+func (s *MetricWALStore) syncUpdate() error {
+	if s.syncInterval <= 0 {
+		return s.sync()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.syncErr
+	s.syncErr = nil
+	return err
+}
+
+// appendRecord writes the record at the end of the log. If the write fails,
+// the partly written record is cut off, so that later records stay readable.
+func (s *MetricWALStore) appendRecord(record walRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, walHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(data[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(data[4:8], crc32.Checksum(payload, walTable))
+	copy(data[walHeaderSize:], payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		s.file.Truncate(s.size)
+		return err
+	}
+	s.size += int64(len(data))
+	return nil
+}
+
+// Replay applies the records of the log to the wrapped store, in the order they were made,
+// without recording them again. It is meant to be called once, on start, after the snapshot
+// the log continues has been restored.
+//
+// Reading stops at the first record that is incomplete or fails its checksum, as left
+// by a crash in the middle of a write. The rest of the log is cut off, so new records
+// follow the last valid one.
+//
+// Returns:
+//   - The number of records applied.
+//   - The number of bytes skipped at the end of the log.
+//   - An error if the log cannot be read or truncated, or the store fails.
+func (s *MetricWALStore) Replay(ctx context.Context) (int, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := make([]byte, s.size)
+	if _, err := s.file.ReadAt(data, 0); err != nil && !errors.Is(err, io.EOF) {
+		return 0, 0, err
+	}
+
+	var (
+		offset  int64
+		applied int
+	)
+	for offset < int64(len(data)) {
+		record, n, ok := decodeWALRecord(data[offset:])
+		if !ok {
+			break
+		}
+		if err := s.applyRecord(ctx, record); err != nil {
+			return applied, 0, err
+		}
+		offset += n
+		applied++
+	}
+
+	skipped := s.size - offset
+	if skipped > 0 {
+		if err := s.file.Truncate(offset); err != nil {
+			return applied, 0, err
+		}
+		s.size = offset
+	}
+	return applied, skipped, nil
+}
+
+// applyRecord applies a replayed record to the wrapped store.
+func (s *MetricWALStore) applyRecord(ctx context.Context, record walRecord) error {
+	if record.Deleted {
+		m := record.Metric
+		return s.store.Delete(ctx, types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels})
+	}
+	return s.store.Save(ctx, record.Metric)
+}
+
+// decodeWALRecord decodes the record at the start of data. It returns the record,
+// its length and whether it is complete and valid.
+func decodeWALRecord(data []byte) (walRecord, int64, bool) {
+	var record walRecord
+	if len(data) < walHeaderSize {
+		return record, 0, false
+	}
+
+	length := int64(binary.BigEndian.Uint32(data[0:4]))
+	if int64(len(data)-walHeaderSize) < length {
+		return record, 0, false
+	}
+	payload := data[walHeaderSize : walHeaderSize+length]
+	if crc32.Checksum(payload, walTable) != binary.BigEndian.Uint32(data[4:8]) {
+		return record, 0, false
+	}
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, 0, false
+	}
+	return record, walHeaderSize + length, true
+}
+
+// Checkpoint returns all stored metrics together with the position in the log they include,
+// for a snapshot after which the log can be truncated up to that position.
+//
+// Updates are held off meanwhile, so none of them is recorded but not yet applied, or the other way round.
+func (s *MetricWALStore) Checkpoint(ctx context.Context) ([]types.Metrics, int64, error) {
+	unlock := s.lockAll()
+	defer unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics, err := s.store.List(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return metrics, s.size, nil
+}
+
+// Truncate drops the records before the given position, as returned by Checkpoint,
+// once the metrics they hold are safely in a snapshot. Records made after the checkpoint are kept.
+func (s *MetricWALStore) Truncate(ctx context.Context, position int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if position < 0 || position > s.size {
+		return fmt.Errorf("WAL position %d out of range [0, %d]", position, s.size)
+	}
+	if position == 0 {
+		return nil
+	}
+
+	// Nothing was recorded since the checkpoint, so the log can simply be emptied
+	if position == s.size {
+		if err := s.file.Truncate(0); err != nil {
+			return err
+		}
+		s.size = 0
+		return s.file.Sync()
+	}
+
+	// Otherwise the records made since the checkpoint are moved to a new log,
+	// which replaces the old one at once
+	rest := make([]byte, s.size-position)
+	if _, err := s.file.ReadAt(rest, position); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(rest); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	s.file.Close()
+	s.file = file
+	s.size = int64(len(rest))
+	return nil
+}
+
+// Reset drops every record of the log, for a start that does not restore the previous metrics.
+func (s *MetricWALStore) Reset(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	s.size = 0
+	return s.file.Sync()
+}
+
+// Close stops the background sync, flushes the log to disk and closes it.
+func (s *MetricWALStore) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
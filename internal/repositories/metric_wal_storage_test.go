@@ -0,0 +1,330 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayWAL replays the log at path into a new store and returns the store
+// with the results of the replay.
+func replayWAL(t *testing.T, path string) (*MetricMemoryStore, int, int64) {
+	t.Helper()
+
+	store := NewMetricMemoryStore()
+	wal, err := NewMetricWALStore(store, path, 0)
+	require.NoError(t, err)
+	defer wal.Close()
+
+	applied, skipped, err := wal.Replay(context.Background())
+	require.NoError(t, err)
+	return store, applied, skipped
+}
+
+func TestMetricWALStore_Replay(t *testing.T) {
+	for _, syncInterval := range []time.Duration{0, 10 * time.Millisecond} {
+		t.Run(syncInterval.String(), func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "metrics.wal")
+
+			wal, err := NewMetricWALStore(NewMetricMemoryStore(), path, syncInterval)
+			require.NoError(t, err)
+
+			v := 1.5
+			gauge := types.Metrics{ID: "g", Type: types.Gauge, Value: &v}
+			require.NoError(t, wal.Save(ctx, gauge))
+
+			id := types.MetricID{ID: "c", Type: types.Counter}
+			_, err = wal.Increment(ctx, id, 2)
+			require.NoError(t, err)
+			total, err := wal.Increment(ctx, id, 3)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), total)
+			require.NoError(t, wal.Close())
+
+			store, applied, skipped := replayWAL(t, path)
+			assert.Equal(t, 3, applied)
+			assert.Zero(t, skipped)
+
+			got, err := store.Get(ctx, id)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, int64(5), *got.Delta)
+
+			got, err = store.Get(ctx, types.MetricID{ID: "g", Type: types.Gauge})
+			require.NoError(t, err)
+			assert.Equal(t, &gauge, got)
+		})
+	}
+}
+
+func TestMetricWALStore_ReplayDelete(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.wal")
+
+	wal, err := NewMetricWALStore(NewMetricMemoryStore(), path, 0)
+	require.NoError(t, err)
+
+	id := types.MetricID{ID: "c", Type: types.Counter}
+	_, err = wal.Increment(ctx, id, 2)
+	require.NoError(t, err)
+	require.NoError(t, wal.Delete(ctx, id))
+
+	got, err := wal.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	require.NoError(t, wal.Close())
+
+	store, applied, skipped := replayWAL(t, path)
+	assert.Equal(t, 2, applied)
+	assert.Zero(t, skipped)
+
+	got, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestMetricWALStore_ReplayUpsert(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.wal")
+
+	wal, err := NewMetricWALStore(NewMetricMemoryStore(), path, 0)
+	require.NoError(t, err)
+
+	id := types.MetricID{ID: "Latency", Type: types.Summary}
+	_, err = wal.Upsert(ctx, id, addObservation(id))
+	require.NoError(t, err)
+	m, err := wal.Upsert(ctx, id, addObservation(id))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), *m.Count)
+	require.NoError(t, wal.Close())
+
+	store, applied, skipped := replayWAL(t, path)
+	assert.Equal(t, 2, applied)
+	assert.Zero(t, skipped)
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, &m, got)
+}
+
+func TestMetricWALStore_ReplayCorruptedTail(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+	}{
+		{
+			name:    "incomplete header",
+			corrupt: func(data []byte) []byte { return append(data, 0, 0, 0) },
+		},
+		{
+			name:    "incomplete record",
+			corrupt: func(data []byte) []byte { return data[:len(data)-3] },
+		},
+		{
+			name: "checksum mismatch",
+			corrupt: func(data []byte) []byte {
+				data[len(data)-2] ^= 0xff
+				return data
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "metrics.wal")
+
+			wal, err := NewMetricWALStore(NewMetricMemoryStore(), path, 0)
+			require.NoError(t, err)
+			id := types.MetricID{ID: "c", Type: types.Counter}
+			for i := 0; i < 2; i++ {
+				_, err = wal.Increment(ctx, id, 1)
+				require.NoError(t, err)
+			}
+			require.NoError(t, wal.Close())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, tt.corrupt(data), 0o644))
+
+			store := NewMetricMemoryStore()
+			wal, err = NewMetricWALStore(store, path, 0)
+			require.NoError(t, err)
+
+			applied, skipped, err := wal.Replay(ctx)
+			require.NoError(t, err)
+			assert.Positive(t, skipped)
+
+			got, err := store.Get(ctx, id)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, int64(applied), *got.Delta)
+
+			// New records follow the last valid one, so they are replayed as well
+			_, err = wal.Increment(ctx, id, 10)
+			require.NoError(t, err)
+			require.NoError(t, wal.Close())
+
+			store, _, skipped = replayWAL(t, path)
+			assert.Zero(t, skipped)
+			got, err = store.Get(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, int64(applied+10), *got.Delta)
+		})
+	}
+}
+
+func TestMetricWALStore_CheckpointAndTruncate(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.wal")
+
+	wal, err := NewMetricWALStore(NewMetricShardedStore(0), path, 0)
+	require.NoError(t, err)
+
+	before := types.MetricID{ID: "before", Type: types.Counter}
+	after := types.MetricID{ID: "after", Type: types.Counter}
+
+	_, err = wal.Increment(ctx, before, 1)
+	require.NoError(t, err)
+
+	metrics, position, err := wal.Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Positive(t, position)
+
+	// An update made after the checkpoint is not in the snapshot and must stay in the log
+	_, err = wal.Increment(ctx, after, 2)
+	require.NoError(t, err)
+
+	assert.Error(t, wal.Truncate(ctx, position+1000))
+	require.NoError(t, wal.Truncate(ctx, position))
+
+	// The log keeps working after it was replaced
+	_, err = wal.Increment(ctx, after, 3)
+	require.NoError(t, err)
+	require.NoError(t, wal.Close())
+
+	store, applied, _ := replayWAL(t, path)
+	assert.Equal(t, 2, applied)
+
+	got, err := store.Get(ctx, before)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = store.Get(ctx, after)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(5), *got.Delta)
+}
+
+func TestMetricWALStore_TruncateAll(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.wal")
+
+	wal, err := NewMetricWALStore(NewMetricMemoryStore(), path, 0)
+	require.NoError(t, err)
+
+	_, err = wal.Increment(ctx, types.MetricID{ID: "c", Type: types.Counter}, 1)
+	require.NoError(t, err)
+
+	_, position, err := wal.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.NoError(t, wal.Truncate(ctx, position))
+	require.NoError(t, wal.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+func TestMetricWALStore_Reset(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.wal")
+
+	wal, err := NewMetricWALStore(NewMetricMemoryStore(), path, 0)
+	require.NoError(t, err)
+
+	_, err = wal.Increment(ctx, types.MetricID{ID: "c", Type: types.Counter}, 1)
+	require.NoError(t, err)
+	require.NoError(t, wal.Reset(ctx))
+	require.NoError(t, wal.Close())
+
+	_, applied, skipped := replayWAL(t, path)
+	assert.Zero(t, applied)
+	assert.Zero(t, skipped)
+}
+
+func TestMetricWALStore_FailedAppendRestoresMetric(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricMemoryStore()
+
+	wal, err := NewMetricWALStore(store, filepath.Join(t.TempDir(), "metrics.wal"), 0)
+	require.NoError(t, err)
+
+	counter := types.MetricID{ID: "c", Type: types.Counter}
+	_, err = wal.Increment(ctx, counter, 2)
+	require.NoError(t, err)
+
+	// Records can no longer be written
+	require.NoError(t, wal.file.Close())
+
+	_, err = wal.Increment(ctx, counter, 3)
+	assert.Error(t, err)
+	got, err := store.Get(ctx, counter)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(2), *got.Delta)
+
+	summary := types.MetricID{ID: "s", Type: types.Summary}
+	_, err = wal.Upsert(ctx, summary, addObservation(summary))
+	assert.Error(t, err)
+	got, err = store.Get(ctx, summary)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestMetricWALStore_ConcurrentIncrement(t *testing.T) {
+	const n = 200
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.wal")
+
+	wal, err := NewMetricWALStore(NewMetricShardedStore(0), path, 10*time.Millisecond)
+	require.NoError(t, err)
+	id := types.MetricID{ID: "hits", Type: types.Counter}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := wal.Increment(ctx, id, 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, wal.Close())
+
+	// Every record holds the total at the time, so the last replayed one is the final total
+	store, applied, _ := replayWAL(t, path)
+	assert.Equal(t, n, applied)
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(n), *got.Delta)
+}
+
+func TestNewMetricWALStore_InvalidPath(t *testing.T) {
+	_, err := NewMetricWALStore(NewMetricMemoryStore(), filepath.Join(t.TempDir(), "missing", "metrics.wal"), 0)
+	assert.Error(t, err)
+}
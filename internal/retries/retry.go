@@ -0,0 +1,55 @@
+// Package retries provides a helper for repeating operations that fail with transient errors.
+package retries
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+)
+
+// Do calls fn and, while it returns an error accepted by retriable, calls it again
+// after each of the given intervals in turn.
+//
+// The total number of attempts is len(intervals)+1. Waiting between attempts is
+// interrupted when the context is done, in which case the context error is returned.
+// When every attempt fails, the exhaustion is logged and the last error is returned.
+//
+// Parameters:
+//   - ctx: Context for cancelling the wait between attempts.
+//   - intervals: Delays before the second, third, ... attempts.
+//   - retriable: Reports whether an error is worth another attempt.
+//   - fn: The operation to perform.
+//
+// Returns:
+//   - nil if an attempt succeeds, otherwise the last error from fn or the context error.
+func Do(
+	ctx context.Context,
+	intervals []time.Duration,
+	retriable func(error) bool,
+	fn func() error,
+) error {
+	err := fn()
+	for i, interval := range intervals {
+		if err == nil || !retriable(err) {
+			return err
+		}
+
+		logger.Log.Warnf("Attempt %d failed, retrying in %s: %v", i+1, interval, err)
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		err = fn()
+	}
+
+	if err != nil && len(intervals) > 0 && retriable(err) {
+		logger.Log.Errorf("Giving up after %d attempts: %v", len(intervals)+1, err)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package retries
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errFatal     = errors.New("fatal")
+)
+
+func isTransient(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func TestDo(t *testing.T) {
+	intervals := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		results      []error
+		intervals    []time.Duration
+		wantErr      error
+		wantAttempts int
+	}{
+		{
+			name:         "succeeds on first attempt",
+			results:      []error{nil},
+			intervals:    intervals,
+			wantAttempts: 1,
+		},
+		{
+			name:         "succeeds after transient failures",
+			results:      []error{errTransient, errTransient, nil},
+			intervals:    intervals,
+			wantAttempts: 3,
+		},
+		{
+			name:         "does not retry non-retriable error",
+			results:      []error{errFatal},
+			intervals:    intervals,
+			wantErr:      errFatal,
+			wantAttempts: 1,
+		},
+		{
+			name:         "stops on non-retriable error after retry",
+			results:      []error{errTransient, errFatal},
+			intervals:    intervals,
+			wantErr:      errFatal,
+			wantAttempts: 2,
+		},
+		{
+			name:         "gives up when retries are exhausted",
+			results:      []error{errTransient, errTransient, errTransient, errTransient},
+			intervals:    intervals,
+			wantErr:      errTransient,
+			wantAttempts: 4,
+		},
+		{
+			name:         "no intervals means a single attempt",
+			results:      []error{errTransient},
+			intervals:    nil,
+			wantErr:      errTransient,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := Do(context.Background(), tt.intervals, isTransient, func() error {
+				err := tt.results[attempts]
+				attempts++
+				return err
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+func TestDo_ContextCanceledDuringWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	start := time.Now()
+	err := Do(ctx, []time.Duration{time.Hour}, isTransient, func() error {
+		attempts++
+		cancel()
+		return errTransient
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, attempts)
+	assert.Less(t, time.Since(start), time.Second)
+}
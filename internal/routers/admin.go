@@ -0,0 +1,32 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewAdminRouter creates and returns a new HTTP router configured with routes
+// for runtime administration, along with optional middleware.
+//
+// Parameters:
+//   - logLevelGetHandler: Handler for reading the current log level.
+//   - logLevelSetHandler: Handler for changing the log level at runtime.
+//   - middlewares: Optional variadic middleware functions applied to all routes.
+//
+// Returns:
+//   - An http.Handler that routes requests to the appropriate admin handlers.
+func NewAdminRouter(
+	logLevelGetHandler http.HandlerFunc,
+	logLevelSetHandler http.HandlerFunc,
+	middlewares ...func(http.Handler) http.Handler,
+) http.Handler {
+	router := chi.NewRouter()
+
+	router.Use(middlewares...)
+
+	router.Get("/admin/loglevel", logLevelGetHandler)
+	router.Put("/admin/loglevel", logLevelSetHandler)
+
+	return router
+}
@@ -0,0 +1,54 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAdminRouter(t *testing.T) {
+	// Dummy handlers that respond with their name
+	logLevelGetHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("logLevelGet"))
+	})
+	logLevelSetHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("logLevelSet"))
+	})
+
+	// Middleware that adds a test header
+	testMiddleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Test-Middleware", "true")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	router := NewAdminRouter(logLevelGetHandler, logLevelSetHandler, testMiddleware)
+
+	tests := []struct {
+		method       string
+		route        string
+		expectedCode int
+		expectedBody string
+	}{
+		{"GET", "/admin/loglevel", http.StatusOK, "logLevelGet"},
+		{"PUT", "/admin/loglevel", http.StatusOK, "logLevelSet"},
+		{"POST", "/admin/loglevel", http.StatusMethodNotAllowed, ""},
+		{"GET", "/metrics", http.StatusNotFound, "404 page not found\n"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.route, nil)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.expectedCode, rec.Code)
+		assert.Equal(t, "true", rec.Header().Get("X-Test-Middleware"))
+		assert.Equal(t, tt.expectedBody, rec.Body.String())
+	}
+}
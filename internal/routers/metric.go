@@ -7,14 +7,21 @@ import (
 )
 
 // NewMetricRouter creates and returns a new HTTP router configured with routes
-// for metric updates, retrievals, and listing, along with optional middleware.
+// for metric updates, retrievals, listing, range queries, alert states and log level administration,
+// along with optional middleware.
 //
 // Parameters:
 //   - metricUpdatePathHandler: Handler for metric updates via URL path parameters.
 //   - metricUpdateBodyHandler: Handler for metric updates via JSON body.
+//   - metricUpdateBatchBodyHandler: Handler for batch metric updates via JSON array body.
 //   - metricGetPathHandler: Handler for metric retrieval via URL path parameters.
 //   - metricGetBodyHandler: Handler for metric retrieval via JSON body.
 //   - metricListHTMLHandler: Handler for listing all metrics as HTML.
+//   - metricListPrometheusHandler: Handler for exposing all metrics in the Prometheus text format.
+//   - metricQueryRangeHandler: Handler for querying the time series of a metric as JSON.
+//   - alertListHandler: Handler for listing the state of alert rules as JSON.
+//   - logLevelGetHandler: Handler for reading the current log level.
+//   - logLevelSetHandler: Handler for changing the log level at runtime; if nil, the route is not registered.
 //   - middlewares: Optional variadic middleware functions applied to all routes.
 //
 // Returns:
@@ -22,9 +29,15 @@ import (
 func NewMetricRouter(
 	metricUpdatePathHandler http.HandlerFunc,
 	metricUpdateBodyHandler http.HandlerFunc,
+	metricUpdateBatchBodyHandler http.HandlerFunc,
 	metricGetPathHandler http.HandlerFunc,
 	metricGetBodyHandler http.HandlerFunc,
 	metricListHTMLHandler http.HandlerFunc,
+	metricListPrometheusHandler http.HandlerFunc,
+	metricQueryRangeHandler http.HandlerFunc,
+	alertListHandler http.HandlerFunc,
+	logLevelGetHandler http.HandlerFunc,
+	logLevelSetHandler http.HandlerFunc,
 	middlewares ...func(http.Handler) http.Handler,
 ) http.Handler {
 	router := chi.NewRouter()
@@ -33,11 +46,21 @@ func NewMetricRouter(
 
 	router.Post("/update/{type}/{name}/{value}", metricUpdatePathHandler)
 	router.Post("/update/", metricUpdateBodyHandler)
+	router.Post("/updates/", metricUpdateBatchBodyHandler)
 
 	router.Get("/value/{type}/{name}", metricGetPathHandler)
 	router.Post("/value/", metricGetBodyHandler)
 
 	router.Get("/", metricListHTMLHandler)
+	router.Get("/metrics", metricListPrometheusHandler)
+	router.Get("/api/v1/query_range", metricQueryRangeHandler)
+
+	router.Get("/alerts", alertListHandler)
+
+	router.Get("/admin/loglevel", logLevelGetHandler)
+	if logLevelSetHandler != nil {
+		router.Put("/admin/loglevel", logLevelSetHandler)
+	}
 
 	return router
 }
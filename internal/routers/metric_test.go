@@ -18,6 +18,10 @@ func TestNewMetricRouter(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("updateBody"))
 	})
+	updateBatchBodyHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("updateBatchBody"))
+	})
 	getPathHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("getPath"))
@@ -30,6 +34,26 @@ func TestNewMetricRouter(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("listHTML"))
 	})
+	listPrometheusHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("listPrometheus"))
+	})
+	queryRangeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("queryRange"))
+	})
+	alertListHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("alertList"))
+	})
+	logLevelGetHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("logLevelGet"))
+	})
+	logLevelSetHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("logLevelSet"))
+	})
 
 	// Middleware that adds a test header
 	testMiddleware := func(next http.Handler) http.Handler {
@@ -42,9 +66,15 @@ func TestNewMetricRouter(t *testing.T) {
 	router := NewMetricRouter(
 		updatePathHandler,
 		updateBodyHandler,
+		updateBatchBodyHandler,
 		getPathHandler,
 		getBodyHandler,
 		listHTMLHandler,
+		listPrometheusHandler,
+		queryRangeHandler,
+		alertListHandler,
+		logLevelGetHandler,
+		logLevelSetHandler,
 		testMiddleware,
 	)
 
@@ -55,9 +85,15 @@ func TestNewMetricRouter(t *testing.T) {
 	}{
 		{"POST", "/update/gauge/temp/42", "updatePath"},
 		{"POST", "/update/", "updateBody"},
+		{"POST", "/updates/", "updateBatchBody"},
 		{"GET", "/value/counter/hits", "getPath"},
 		{"POST", "/value/", "getBody"},
 		{"GET", "/", "listHTML"},
+		{"GET", "/metrics", "listPrometheus"},
+		{"GET", "/api/v1/query_range?type=gauge&name=temp", "queryRange"},
+		{"GET", "/alerts", "alertList"},
+		{"GET", "/admin/loglevel", "logLevelGet"},
+		{"PUT", "/admin/loglevel", "logLevelSet"},
 	}
 
 	for _, tt := range tests {
@@ -71,3 +107,16 @@ func TestNewMetricRouter(t *testing.T) {
 		assert.Equal(t, tt.expectedBody, rec.Body.String())
 	}
 }
+
+func TestNewMetricRouter_WithoutLogLevelSetHandler(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router := NewMetricRouter(
+		handler, handler, handler, handler, handler, handler, handler, handler, handler, handler,
+		nil,
+	)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/loglevel", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
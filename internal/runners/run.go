@@ -3,10 +3,14 @@ package runners
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
 )
 
 // Runnable defines an interface for components that have a start/stop lifecycle,
@@ -21,33 +25,47 @@ type Runnable interface {
 	Stop(ctx context.Context) error
 }
 
-// Run executes a Runnable component and manages its lifecycle.
+// Reloadable defines an interface for Runnable components that can apply a new
+// configuration without restarting, such as servers reloading their settings.
+type Reloadable interface {
+	Runnable
+
+	// Reload re-reads the component's configuration and applies it while the component
+	// keeps running. An error leaves the component running with its previous configuration.
+	Reload(ctx context.Context) error
+}
+
+// Run executes one or more Runnable components and manages their lifecycle.
 //
 // It listens for termination signals (SIGINT, SIGTERM, SIGQUIT) and cancels
 // the context when such a signal is received. Upon cancellation, Run attempts
-// a graceful shutdown by calling Stop on the Runnable with a 5-second timeout.
+// a graceful shutdown by calling Stop on every Runnable, in reverse order,
+// with a shared 5-second timeout.
+//
+// Run also captures errors returned by Start. If any Start returns an error other
+// than http.ErrServerClosed, Run stops the remaining components and returns that error.
 //
-// Run also captures errors returned by Start. If Start returns an error other
-// than http.ErrServerClosed, Run returns that error immediately.
+// On SIGHUP, Run calls Reload on every Runnable that implements Reloadable, in start
+// order, and keeps running. Reload errors are logged and do not stop the components.
 //
-// Run blocks until either the Runnable completes or a termination signal is received.
+// Run blocks until either a Runnable fails or a termination signal is received.
 //
 // Parameters:
 //   - ctx: The parent context to control the lifecycle.
-//   - runnable: The Runnable instance to start and stop.
+//   - runnables: The Runnable instances to start and stop.
 //
 // Returns:
-//   - An error if the Runnable fails to start or stops with an error (other than http.ErrServerClosed).
+//   - An error if a Runnable fails to start or stops with an error (other than http.ErrServerClosed).
 //
 // Usage example:
 //
-//	err := runners.Run(ctx, myApp)
+//	err := runners.Run(ctx, myApp, myNotifier)
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
 func Run(
 	ctx context.Context,
-	runnable Runnable,
+	runnables ...Runnable,
 ) error {
 	ctx, cancel := signal.NotifyContext(
 		ctx,
@@ -57,23 +75,80 @@ func Run(
 	)
 	defer cancel()
 
-	errChan := make(chan error, 1)
-	defer close(errChan)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	type startError struct {
+		index int
+		err   error
+	}
+
+	// Buffered so that late failures never block after Run has returned
+	errChan := make(chan startError, len(runnables))
+
+	for i, runnable := range runnables {
+		go func() {
+			err := runnable.Start(ctx)
+			if err != nil && err != http.ErrServerClosed {
+				errChan <- startError{index: i, err: err}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return stopAll(runnables, -1)
+
+		case failed := <-errChan:
+			cancel()
+			if err := stopAll(runnables, failed.index); err != nil {
+				return errors.Join(failed.err, err)
+			}
+			return failed.err
 
-	go func() {
-		err := runnable.Start(ctx)
-		if err != nil && err != http.ErrServerClosed {
-			errChan <- err
+		case <-reload:
+			reloadAll(ctx, runnables)
 		}
-	}()
+	}
+}
+
+// reloadAll calls Reload on every Reloadable, in start order, logging the errors.
+func reloadAll(ctx context.Context, runnables []Runnable) {
+	for _, runnable := range runnables {
+		reloadable, ok := runnable.(Reloadable)
+		if !ok {
+			continue
+		}
+		if err := reloadable.Reload(ctx); err != nil {
+			logger.Log.Errorf("Failed to reload configuration: %v", err)
+		}
+	}
+}
+
+// stopAll stops every Runnable except the one at index skip, in reverse start order.
+// It returns the single Stop error as is, or all of them joined if there are several.
+func stopAll(runnables []Runnable, skip int) error {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	select {
-	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		return runnable.Stop(shutdownCtx)
+	var errs []error
+	for i := len(runnables) - 1; i >= 0; i-- {
+		if i == skip {
+			continue
+		}
+		if err := runnables[i].Stop(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-	case err := <-errChan:
-		return err
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
 	}
 }
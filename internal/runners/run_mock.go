@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: /home/sergey/Go/yandex-go-advanced/internal/runners/run.go
+// Source: internal/runners/run.go
 
 // Package runners is a generated GoMock package.
 package runners
@@ -61,3 +61,68 @@ func (mr *MockRunnableMockRecorder) Stop(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockRunnable)(nil).Stop), ctx)
 }
+
+// MockReloadable is a mock of Reloadable interface.
+type MockReloadable struct {
+	ctrl     *gomock.Controller
+	recorder *MockReloadableMockRecorder
+}
+
+// MockReloadableMockRecorder is the mock recorder for MockReloadable.
+type MockReloadableMockRecorder struct {
+	mock *MockReloadable
+}
+
+// NewMockReloadable creates a new mock instance.
+func NewMockReloadable(ctrl *gomock.Controller) *MockReloadable {
+	mock := &MockReloadable{ctrl: ctrl}
+	mock.recorder = &MockReloadableMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReloadable) EXPECT() *MockReloadableMockRecorder {
+	return m.recorder
+}
+
+// Reload mocks base method.
+func (m *MockReloadable) Reload(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reload", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reload indicates an expected call of Reload.
+func (mr *MockReloadableMockRecorder) Reload(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reload", reflect.TypeOf((*MockReloadable)(nil).Reload), ctx)
+}
+
+// Start mocks base method.
+func (m *MockReloadable) Start(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockReloadableMockRecorder) Start(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockReloadable)(nil).Start), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockReloadable) Stop(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockReloadableMockRecorder) Stop(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockReloadable)(nil).Stop), ctx)
+}
@@ -3,11 +3,14 @@ package runners_test
 import (
 	"context"
 	"errors"
+	"os"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/runners"
 )
@@ -80,3 +83,101 @@ func TestRun_StopReturnsError(t *testing.T) {
 	err := runners.Run(ctx, mockRunnable)
 	assert.Equal(t, wantErr, err)
 }
+
+func TestRun_MultipleRunnablesStopInReverseOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	first := runners.NewMockRunnable(ctrl)
+	second := runners.NewMockRunnable(ctrl)
+
+	blockUntilDone := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+	first.EXPECT().Start(gomock.Any()).DoAndReturn(blockUntilDone).Times(1)
+	second.EXPECT().Start(gomock.Any()).DoAndReturn(blockUntilDone).Times(1)
+
+	gomock.InOrder(
+		second.EXPECT().Stop(gomock.Any()).Return(nil).Times(1),
+		first.EXPECT().Stop(gomock.Any()).Return(nil).Times(1),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	err := runners.Run(ctx, first, second)
+	assert.NoError(t, err)
+}
+
+func TestRun_StartErrorStopsOthers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	healthy := runners.NewMockRunnable(ctrl)
+	failing := runners.NewMockRunnable(ctrl)
+
+	wantErr := errors.New("start error")
+	stopErr := errors.New("stop error")
+
+	// The healthy component may not have been scheduled before Run returns
+	healthy.EXPECT().Start(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}).MaxTimes(1)
+	failing.EXPECT().Start(gomock.Any()).Return(wantErr).Times(1)
+
+	// Only the healthy component is stopped; the failed one already exited
+	healthy.EXPECT().Stop(gomock.Any()).Return(stopErr).Times(1)
+
+	err := runners.Run(context.Background(), healthy, failing)
+	assert.ErrorIs(t, err, wantErr)
+	assert.ErrorIs(t, err, stopErr)
+}
+
+func TestRun_SIGHUPReloadsReloadables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	plain := runners.NewMockRunnable(ctrl)
+	reloadable := runners.NewMockReloadable(ctrl)
+
+	started := make(chan struct{})
+	plain.EXPECT().Start(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}).Times(1)
+	reloadable.EXPECT().Start(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	}).Times(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A failed reload keeps the components running; the next SIGHUP reloads again
+	reloaded := make(chan struct{}, 2)
+	reloadable.EXPECT().Reload(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+		reloaded <- struct{}{}
+		return errors.New("reload error")
+	}).Times(2)
+
+	reloadable.EXPECT().Stop(gomock.Any()).Return(nil).Times(1)
+	plain.EXPECT().Stop(gomock.Any()).Return(nil).Times(1)
+
+	go func() {
+		<-started
+		for i := 0; i < 2; i++ {
+			require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+			<-reloaded
+		}
+		cancel()
+	}()
+
+	err := runners.Run(ctx, plain, reloadable)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// AlertMetricLister defines the interface for listing the metrics alert rules are evaluated against.
+type AlertMetricLister interface {
+	// List returns a slice of all available metrics or an error if something goes wrong.
+	List(ctx context.Context) ([]types.Metrics, error)
+}
+
+// alertState holds the evaluation state of a single rule between evaluations.
+type alertState struct {
+	alert     types.Alert
+	prevValue *float64 // Previous counter value, used to compute rates
+	prevAt    time.Time
+}
+
+// AlertService evaluates alert rules against stored metrics and tracks
+// the pending/firing/resolved state of every rule.
+type AlertService struct {
+	lister AlertMetricLister
+
+	mu     sync.RWMutex
+	rules  []types.AlertRule
+	states map[string]*alertState
+}
+
+// NewAlertService creates a new AlertService evaluating the given rules
+// against the metrics returned by the provided AlertMetricLister.
+func NewAlertService(
+	lister AlertMetricLister,
+	rules []types.AlertRule,
+) *AlertService {
+	svc := &AlertService{lister: lister}
+	svc.SetRules(rules)
+	return svc
+}
+
+// SetRules replaces the evaluated rules, e.g. after the rules file is reloaded.
+//
+// Rules that are kept unchanged keep their state, so that reloading does not reset
+// pending or firing alerts; new and changed rules start inactive.
+func (svc *AlertService) SetRules(rules []types.AlertRule) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	previous := make(map[string]types.AlertRule, len(svc.rules))
+	for _, rule := range svc.rules {
+		previous[rule.Name] = rule
+	}
+
+	states := make(map[string]*alertState, len(rules))
+	for _, rule := range rules {
+		if prev, ok := previous[rule.Name]; ok && prev == rule {
+			states[rule.Name] = svc.states[rule.Name]
+			continue
+		}
+		states[rule.Name] = &alertState{
+			alert: types.Alert{
+				Rule:     rule.Name,
+				Expr:     rule.Expr,
+				MetricID: rule.MetricID,
+				State:    types.AlertInactive,
+			},
+		}
+	}
+	svc.rules = rules
+	svc.states = states
+}
+
+// Evaluate checks every rule against the current metrics at the given time
+// and advances its state.
+//
+// A rule whose condition holds becomes pending, and firing once the condition
+// has held for the rule's duration. A firing rule whose condition no longer holds
+// becomes resolved; a pending one goes back to inactive. Rules over missing metrics,
+// and rate rules without a previous sample, are treated as not holding.
+//
+// A rule is evaluated against the metric with the rule's exact ID or, if there is none,
+// against the only metric of the same name and type whose labels contain the rule's,
+// so rules without labels apply to metrics reported with a host label. A rule matching
+// several metrics is treated as not holding, and a warning is logged.
+//
+// Returns the alerts whose state changed during this evaluation, or an error
+// if the metrics cannot be listed, in which case no state is changed.
+// Without rules, metrics are not listed at all.
+func (svc *AlertService) Evaluate(
+	ctx context.Context,
+	now time.Time,
+) ([]types.Alert, error) {
+	svc.mu.RLock()
+	noRules := len(svc.rules) == 0
+	svc.mu.RUnlock()
+	if noRules {
+		return nil, nil
+	}
+
+	metrics, err := svc.lister.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	var changed []types.Alert
+	for _, rule := range svc.rules {
+		state := svc.states[rule.Name]
+		prev := state.alert.State
+
+		value, ok := evaluateAlertRule(&rule, state, metrics, now)
+		if ok {
+			state.alert.Value = &value
+		} else {
+			state.alert.Value = nil
+		}
+		advanceAlertState(&rule, &state.alert, ok && rule.Holds(value), now)
+
+		if state.alert.State != prev {
+			changed = append(changed, copyAlert(state.alert))
+		}
+	}
+
+	return changed, nil
+}
+
+// List returns the current state of every rule, in rule order.
+func (svc *AlertService) List(
+	ctx context.Context,
+) ([]types.Alert, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+
+	alerts := make([]types.Alert, 0, len(svc.rules))
+	for _, rule := range svc.rules {
+		alerts = append(alerts, copyAlert(svc.states[rule.Name].alert))
+	}
+	return alerts, nil
+}
+
+// evaluateAlertRule computes the value a rule is compared against.
+// It returns false if the value is not available.
+func evaluateAlertRule(
+	rule *types.AlertRule,
+	state *alertState,
+	metrics []types.Metrics,
+	now time.Time,
+) (float64, bool) {
+	m, err := findMetric(metrics, rule.MetricID)
+	if err != nil {
+		logger.Log.Warnf("Alert rule %s matches several metrics; add labels to tell them apart", rule.Name)
+		return 0, false
+	}
+	if m == nil {
+		return 0, false
+	}
+	state.alert.MetricID = types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels}
+
+	var value float64
+	switch {
+	case m.Type == types.Gauge && m.Value != nil:
+		value = *m.Value
+	case m.Type == types.Counter && m.Delta != nil:
+		value = float64(*m.Delta)
+	default:
+		return 0, false
+	}
+
+	if rule.Function != types.AlertFunctionRate {
+		return value, true
+	}
+
+	prevValue, prevAt := state.prevValue, state.prevAt
+	state.prevValue, state.prevAt = &value, now
+
+	elapsed := now.Sub(prevAt).Seconds()
+	// A counter that went down was reset, so its previous sample is meaningless
+	if prevValue == nil || elapsed <= 0 || value < *prevValue {
+		return 0, false
+	}
+	return (value - *prevValue) / elapsed, true
+}
+
+// advanceAlertState moves an alert to its next state given whether the rule condition holds.
+func advanceAlertState(rule *types.AlertRule, alert *types.Alert, holds bool, now time.Time) {
+	if !holds {
+		switch alert.State {
+		case types.AlertFiring:
+			alert.State = types.AlertResolved
+			alert.ResolvedAt = &now
+		case types.AlertPending:
+			alert.State = types.AlertInactive
+		}
+		alert.ActiveSince = nil
+		return
+	}
+
+	if alert.State != types.AlertPending && alert.State != types.AlertFiring {
+		alert.State = types.AlertPending
+		alert.ActiveSince = &now
+		alert.FiredAt = nil
+		alert.ResolvedAt = nil
+	}
+	if alert.State == types.AlertPending && now.Sub(*alert.ActiveSince) >= rule.For {
+		alert.State = types.AlertFiring
+		alert.FiredAt = &now
+	}
+}
+
+// copyAlert returns a copy of the alert that does not share pointers with the original.
+func copyAlert(alert types.Alert) types.Alert {
+	copyTime := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		v := *t
+		return &v
+	}
+	if alert.Value != nil {
+		v := *alert.Value
+		alert.Value = &v
+	}
+	alert.ActiveSince = copyTime(alert.ActiveSince)
+	alert.FiredAt = copyTime(alert.FiredAt)
+	alert.ResolvedAt = copyTime(alert.ResolvedAt)
+	return alert
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/alert_evaluate.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockAlertMetricLister is a mock of AlertMetricLister interface.
+type MockAlertMetricLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertMetricListerMockRecorder
+}
+
+// MockAlertMetricListerMockRecorder is the mock recorder for MockAlertMetricLister.
+type MockAlertMetricListerMockRecorder struct {
+	mock *MockAlertMetricLister
+}
+
+// NewMockAlertMetricLister creates a new mock instance.
+func NewMockAlertMetricLister(ctrl *gomock.Controller) *MockAlertMetricLister {
+	mock := &MockAlertMetricLister{ctrl: ctrl}
+	mock.recorder = &MockAlertMetricListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertMetricLister) EXPECT() *MockAlertMetricListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAlertMetricLister) List(ctx context.Context) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAlertMetricListerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAlertMetricLister)(nil).List), ctx)
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustAlertRule(t *testing.T, name, expr string) types.AlertRule {
+	t.Helper()
+	rule, err := types.NewAlertRule(name, expr)
+	require.NoError(t, err)
+	return *rule
+}
+
+func gaugeMetric(id string, v float64) types.Metrics {
+	return types.Metrics{ID: id, Type: types.Gauge, Value: &v}
+}
+
+func counterMetric(id string, d int64) types.Metrics {
+	return types.Metrics{ID: id, Type: types.Counter, Delta: &d}
+}
+
+func TestAlertService_Evaluate_Transitions(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type step struct {
+		after     time.Duration
+		metrics   []types.Metrics
+		wantState string
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		steps []step
+	}{
+		{
+			name: "threshold with duration goes pending, firing and resolved",
+			expr: "gauge HeapAlloc > 500MB for 2m",
+			steps: []step{
+				{after: 0, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 100<<20)}, wantState: types.AlertInactive},
+				{after: time.Minute, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 600<<20)}, wantState: types.AlertPending},
+				{after: 2 * time.Minute, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 600<<20)}, wantState: types.AlertPending},
+				{after: 3 * time.Minute, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 700<<20)}, wantState: types.AlertFiring},
+				{after: 4 * time.Minute, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 100<<20)}, wantState: types.AlertResolved},
+				{after: 5 * time.Minute, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 100<<20)}, wantState: types.AlertResolved},
+				{after: 6 * time.Minute, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 600<<20)}, wantState: types.AlertPending},
+			},
+		},
+		{
+			name: "pending goes back to inactive when condition stops holding",
+			expr: "gauge HeapAlloc > 500MB for 2m",
+			steps: []step{
+				{after: 0, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 600<<20)}, wantState: types.AlertPending},
+				{after: time.Minute, metrics: []types.Metrics{gaugeMetric("HeapAlloc", 100<<20)}, wantState: types.AlertInactive},
+			},
+		},
+		{
+			name: "rule without duration fires immediately",
+			expr: "gauge Load > 1",
+			steps: []step{
+				{after: 0, metrics: []types.Metrics{gaugeMetric("Load", 2)}, wantState: types.AlertFiring},
+			},
+		},
+		{
+			name: "missing metric does not hold",
+			expr: "gauge Load > 1",
+			steps: []step{
+				{after: 0, metrics: []types.Metrics{gaugeMetric("Load", 2)}, wantState: types.AlertFiring},
+				{after: time.Minute, metrics: nil, wantState: types.AlertResolved},
+			},
+		},
+		{
+			name: "counter rate needs a previous sample",
+			expr: "counter PollCount rate == 0 for 1m",
+			steps: []step{
+				{after: 0, metrics: []types.Metrics{counterMetric("PollCount", 10)}, wantState: types.AlertInactive},
+				{after: 30 * time.Second, metrics: []types.Metrics{counterMetric("PollCount", 10)}, wantState: types.AlertPending},
+				{after: 90 * time.Second, metrics: []types.Metrics{counterMetric("PollCount", 10)}, wantState: types.AlertFiring},
+				{after: 120 * time.Second, metrics: []types.Metrics{counterMetric("PollCount", 40)}, wantState: types.AlertResolved},
+			},
+		},
+		{
+			name: "counter reset does not produce a rate",
+			expr: "counter PollCount rate > 0",
+			steps: []step{
+				{after: 0, metrics: []types.Metrics{counterMetric("PollCount", 10)}, wantState: types.AlertInactive},
+				{after: time.Second, metrics: []types.Metrics{counterMetric("PollCount", 20)}, wantState: types.AlertFiring},
+				{after: 2 * time.Second, metrics: []types.Metrics{counterMetric("PollCount", 5)}, wantState: types.AlertResolved},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			lister := NewMockAlertMetricLister(ctrl)
+			svc := NewAlertService(lister, []types.AlertRule{mustAlertRule(t, "rule", tt.expr)})
+
+			for i, s := range tt.steps {
+				lister.EXPECT().List(gomock.Any()).Return(s.metrics, nil)
+
+				_, err := svc.Evaluate(context.Background(), start.Add(s.after))
+				require.NoError(t, err)
+
+				alerts, err := svc.List(context.Background())
+				require.NoError(t, err)
+				require.Len(t, alerts, 1)
+				assert.Equal(t, s.wantState, alerts[0].State, "step %d", i)
+			}
+		})
+	}
+}
+
+func TestAlertService_Evaluate_ReturnsChangedAlerts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	lister := NewMockAlertMetricLister(ctrl)
+	svc := NewAlertService(lister, []types.AlertRule{
+		mustAlertRule(t, "high", "gauge Load > 1"),
+		mustAlertRule(t, "low", "gauge Load < 1"),
+	})
+
+	lister.EXPECT().List(gomock.Any()).Return([]types.Metrics{gaugeMetric("Load", 2)}, nil)
+
+	changed, err := svc.Evaluate(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, changed, 1)
+	assert.Equal(t, "high", changed[0].Rule)
+	assert.Equal(t, types.AlertFiring, changed[0].State)
+	require.NotNil(t, changed[0].Value)
+	assert.Equal(t, 2.0, *changed[0].Value)
+	require.NotNil(t, changed[0].FiredAt)
+	assert.Equal(t, now, *changed[0].FiredAt)
+
+	// Nothing changes on an identical evaluation
+	lister.EXPECT().List(gomock.Any()).Return([]types.Metrics{gaugeMetric("Load", 2)}, nil)
+
+	changed, err = svc.Evaluate(context.Background(), now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+}
+
+func TestAlertService_Evaluate_MatchesLabels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	labeled := func(id string, v float64, pairs map[string]string) types.Metrics {
+		m := gaugeMetric(id, v)
+		m.Labels = types.NewLabels(pairs)
+		return m
+	}
+
+	lister := NewMockAlertMetricLister(ctrl)
+	svc := NewAlertService(lister, []types.AlertRule{
+		mustAlertRule(t, "unlabeled", "gauge HeapAlloc > 500MB"),
+		mustAlertRule(t, "subset", "gauge Load{env=prod} > 1"),
+		mustAlertRule(t, "ambiguous", "gauge Load > 1"),
+	})
+
+	// Rules without some of the labels apply to the only metric having the rest of them
+	lister.EXPECT().List(gomock.Any()).Return([]types.Metrics{
+		labeled("HeapAlloc", 600<<20, map[string]string{"host": "a"}),
+		labeled("Load", 2, map[string]string{"host": "a", "env": "prod"}),
+		labeled("Load", 2, map[string]string{"host": "b", "env": "dev"}),
+	}, nil)
+
+	changed, err := svc.Evaluate(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, changed, 2)
+
+	assert.Equal(t, "unlabeled", changed[0].Rule)
+	assert.Equal(t, types.AlertFiring, changed[0].State)
+	assert.Equal(t, types.MetricID{ID: "HeapAlloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"})}, changed[0].MetricID)
+
+	assert.Equal(t, "subset", changed[1].Rule)
+	assert.Equal(t, types.AlertFiring, changed[1].State)
+
+	// A rule matching several metrics is not evaluated
+	alerts, err := svc.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, types.AlertInactive, alerts[2].State)
+}
+
+func TestAlertService_Evaluate_ListerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lister := NewMockAlertMetricLister(ctrl)
+	svc := NewAlertService(lister, []types.AlertRule{mustAlertRule(t, "high", "gauge Load > 1")})
+
+	lister.EXPECT().List(gomock.Any()).Return(nil, errors.New("list error"))
+
+	changed, err := svc.Evaluate(context.Background(), time.Now())
+	assert.Error(t, err)
+	assert.Nil(t, changed)
+
+	alerts, err := svc.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []types.Alert{{
+		Rule:     "high",
+		Expr:     "gauge Load > 1",
+		MetricID: types.MetricID{ID: "Load", Type: types.Gauge},
+		State:    types.AlertInactive,
+	}}, alerts)
+}
+
+func TestAlertService_List_NoRules(t *testing.T) {
+	svc := NewAlertService(nil, nil)
+
+	alerts, err := svc.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+}
+
+func TestAlertService_Evaluate_NoRulesSkipsListing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The mock fails the test if the metrics are listed
+	svc := NewAlertService(NewMockAlertMetricLister(ctrl), nil)
+
+	changed, err := svc.Evaluate(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+}
+
+func TestAlertService_SetRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lister := NewMockAlertMetricLister(ctrl)
+	lister.EXPECT().List(gomock.Any()).Return([]types.Metrics{gaugeMetric("Load", 5), gaugeMetric("Temp", 90)}, nil)
+
+	svc := NewAlertService(lister, []types.AlertRule{
+		mustAlertRule(t, "load", "gauge Load > 1"),
+		mustAlertRule(t, "temp", "gauge Temp > 80"),
+		mustAlertRule(t, "gone", "gauge Load > 2"),
+	})
+	_, err := svc.Evaluate(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	svc.SetRules([]types.AlertRule{
+		mustAlertRule(t, "load", "gauge Load > 1"),
+		mustAlertRule(t, "temp", "gauge Temp > 100"),
+		mustAlertRule(t, "new", "gauge Load > 3"),
+	})
+
+	alerts, err := svc.List(context.Background())
+	require.NoError(t, err)
+	states := make(map[string]string, len(alerts))
+	for _, alert := range alerts {
+		states[alert.Rule] = alert.State
+	}
+	assert.Equal(t, map[string]string{
+		"load": types.AlertFiring,   // unchanged rules keep their state
+		"temp": types.AlertInactive, // changed rules start over
+		"new":  types.AlertInactive,
+	}, states)
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
@@ -13,19 +14,35 @@ type MetricGetGetter interface {
 	Get(ctx context.Context, id types.MetricID) (*types.Metrics, error)
 }
 
+// MetricGetLister defines the interface for listing metrics that are looked up by their labels.
+type MetricGetLister interface {
+	// ListByName returns the metrics with the given name and type, whatever their labels,
+	// or an error if something goes wrong.
+	ListByName(ctx context.Context, id string, metricType string) ([]types.Metrics, error)
+}
+
 // MetricGetService provides metric retrieval services by delegating to a MetricGetGetter.
 type MetricGetService struct {
 	getter MetricGetGetter
+	lister MetricGetLister
 }
 
 // NewMetricGetService creates a new MetricGetService with the provided MetricGetGetter.
+// lister provides the metrics of the requested name and type that are matched by their labels
+// when no metric has the exact ID.
 func NewMetricGetService(
 	getter MetricGetGetter,
+	lister MetricGetLister,
 ) *MetricGetService {
-	return &MetricGetService{getter: getter}
+	return &MetricGetService{getter: getter, lister: lister}
 }
 
 // Get retrieves a metric by its MetricID using the underlying MetricGetGetter.
+//
+// If there is no metric with the exact ID, the metric of the same name and type whose
+// labels contain all of the requested ones is returned, so that, for example, a metric
+// reported with a host label can be read without it. ErrMetricLabelsAmbiguous is returned
+// if several metrics match. Returns nil if no metric matches.
 func (svc *MetricGetService) Get(
 	ctx context.Context,
 	id types.MetricID,
@@ -34,5 +51,46 @@ func (svc *MetricGetService) Get(
 	if err != nil {
 		return nil, err
 	}
-	return metric, nil
+	if metric != nil {
+		return metric, nil
+	}
+
+	metrics, err := svc.lister.ListByName(ctx, id.ID, id.Type)
+	if err != nil {
+		return nil, err
+	}
+	return findMetric(metrics, id)
+}
+
+// findMetric returns the metric with the given ID or, if there is none, the only metric
+// of the same name and type whose labels contain all of the ID's labels.
+// It returns ErrMetricLabelsAmbiguous if several metrics match, and nil if none does.
+func findMetric(metrics []types.Metrics, id types.MetricID) (*types.Metrics, error) {
+	return findByLabels(metrics, id, metricIDOf)
+}
+
+// findByLabels returns the item whose ID, given by idOf, is the given one or, if there is none,
+// the only item of the same name and type whose labels contain all of the ID's labels.
+// It returns ErrMetricLabelsAmbiguous if several items match, and nil if none does.
+func findByLabels[T any](items []T, id types.MetricID, idOf func(*T) types.MetricID) (*T, error) {
+	var found *T
+	ambiguous := false
+	for i := range items {
+		itemID := idOf(&items[i])
+		if itemID.ID != id.ID || itemID.Type != id.Type || !itemID.Labels.Matches(id.Labels) {
+			continue
+		}
+		if itemID.Labels == id.Labels {
+			return &items[i], nil
+		}
+		if found != nil {
+			ambiguous = true
+		}
+		found = &items[i]
+	}
+
+	if ambiguous {
+		return nil, errors.ErrMetricLabelsAmbiguous
+	}
+	return found, nil
 }
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: /home/sergey/Go/yandex-go-advanced/internal/services/metric_get.go
+// Source: internal/services/metric_get.go
 
 // Package services is a generated GoMock package.
 package services
@@ -49,3 +49,41 @@ func (mr *MockMetricGetGetterMockRecorder) Get(ctx, id interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMetricGetGetter)(nil).Get), ctx, id)
 }
+
+// MockMetricGetLister is a mock of MetricGetLister interface.
+type MockMetricGetLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricGetListerMockRecorder
+}
+
+// MockMetricGetListerMockRecorder is the mock recorder for MockMetricGetLister.
+type MockMetricGetListerMockRecorder struct {
+	mock *MockMetricGetLister
+}
+
+// NewMockMetricGetLister creates a new mock instance.
+func NewMockMetricGetLister(ctrl *gomock.Controller) *MockMetricGetLister {
+	mock := &MockMetricGetLister{ctrl: ctrl}
+	mock.recorder = &MockMetricGetListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricGetLister) EXPECT() *MockMetricGetListerMockRecorder {
+	return m.recorder
+}
+
+// ListByName mocks base method.
+func (m *MockMetricGetLister) ListByName(ctx context.Context, id, metricType string) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByName", ctx, id, metricType)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByName indicates an expected call of ListByName.
+func (mr *MockMetricGetListerMockRecorder) ListByName(ctx, id, metricType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByName", reflect.TypeOf((*MockMetricGetLister)(nil).ListByName), ctx, id, metricType)
+}
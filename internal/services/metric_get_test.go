@@ -16,6 +16,7 @@ func TestMetricgetService_Get_Table(t *testing.T) {
 
 	type fields struct {
 		getter *MockMetricGetGetter
+		lister *MockMetricGetLister
 	}
 
 	type args struct {
@@ -39,6 +40,7 @@ func TestMetricgetService_Get_Table(t *testing.T) {
 			name: "successfully get metric",
 			fields: fields{
 				getter: NewMockMetricGetGetter(ctrl),
+				lister: NewMockMetricGetLister(ctrl),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -58,6 +60,7 @@ func TestMetricgetService_Get_Table(t *testing.T) {
 			name: "getter returns error",
 			fields: fields{
 				getter: NewMockMetricGetGetter(ctrl),
+				lister: NewMockMetricGetLister(ctrl),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -77,6 +80,7 @@ func TestMetricgetService_Get_Table(t *testing.T) {
 			name: "metric not found returns nil",
 			fields: fields{
 				getter: NewMockMetricGetGetter(ctrl),
+				lister: NewMockMetricGetLister(ctrl),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -90,6 +94,70 @@ func TestMetricgetService_Get_Table(t *testing.T) {
 				f.getter.EXPECT().
 					Get(args.ctx, args.id).
 					Return(nil, nil)
+				f.lister.EXPECT().
+					ListByName(args.ctx, args.id.ID, args.id.Type).
+					Return([]types.Metrics{}, nil)
+			},
+		},
+		{
+			name: "unlabeled ID matches the only labeled metric",
+			fields: fields{
+				getter: NewMockMetricGetGetter(ctrl),
+				lister: NewMockMetricGetLister(ctrl),
+			},
+			args: args{
+				ctx: context.Background(),
+				id:  types.MetricID{ID: "Alloc", Type: types.Gauge},
+			},
+			want: want{
+				metric: &types.Metrics{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"})},
+			},
+			setup: func(f fields, args args) {
+				f.getter.EXPECT().Get(args.ctx, args.id).Return(nil, nil)
+				f.lister.EXPECT().ListByName(args.ctx, args.id.ID, args.id.Type).Return([]types.Metrics{
+					{ID: "Alloc", Type: types.Counter},
+					{ID: "Other", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"})},
+					{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"})},
+				}, nil)
+			},
+		},
+		{
+			name: "labels matching several metrics are ambiguous",
+			fields: fields{
+				getter: NewMockMetricGetGetter(ctrl),
+				lister: NewMockMetricGetLister(ctrl),
+			},
+			args: args{
+				ctx: context.Background(),
+				id:  types.MetricID{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"env": "prod"})},
+			},
+			want: want{
+				err: true,
+			},
+			setup: func(f fields, args args) {
+				f.getter.EXPECT().Get(args.ctx, args.id).Return(nil, nil)
+				f.lister.EXPECT().ListByName(args.ctx, args.id.ID, args.id.Type).Return([]types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"})},
+					{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "b", "env": "prod"})},
+				}, nil)
+			},
+		},
+		{
+			name: "lister returns error",
+			fields: fields{
+				getter: NewMockMetricGetGetter(ctrl),
+				lister: NewMockMetricGetLister(ctrl),
+			},
+			args: args{
+				ctx: context.Background(),
+				id:  types.MetricID{ID: "Alloc", Type: types.Gauge},
+			},
+			want: want{
+				err: true,
+			},
+			setup: func(f fields, args args) {
+				f.getter.EXPECT().Get(args.ctx, args.id).Return(nil, nil)
+				f.lister.EXPECT().ListByName(args.ctx, args.id.ID, args.id.Type).Return(nil, errors.New("lister error"))
 			},
 		},
 	}
@@ -100,7 +168,7 @@ func TestMetricgetService_Get_Table(t *testing.T) {
 				tt.setup(tt.fields, tt.args)
 			}
 
-			service := NewMetricGetService(tt.fields.getter)
+			service := NewMetricGetService(tt.fields.getter, tt.fields.lister)
 			gotMetric, err := service.Get(tt.args.ctx, tt.args.id)
 
 			if tt.want.err {
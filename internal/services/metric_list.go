@@ -24,14 +24,27 @@ func NewMetricListService(
 	return &MetricListService{lister: lister}
 }
 
-// List fetches the list of metrics by delegating to the underlying MetricListLister.
+// List fetches the list of metrics by delegating to the underlying MetricListLister
+// and keeps the metrics whose labels contain every pair of the filter.
+// An empty filter keeps all metrics.
 // It returns the slice of metrics or an error if the operation fails.
 func (svc *MetricListService) List(
 	ctx context.Context,
+	filter types.Labels,
 ) ([]types.Metrics, error) {
 	metrics, err := svc.lister.List(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return metrics, nil
+	if filter == "" {
+		return metrics, nil
+	}
+
+	filtered := make([]types.Metrics, 0, len(metrics))
+	for _, m := range metrics {
+		if m.Labels.Matches(filter) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered, nil
 }
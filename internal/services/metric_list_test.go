@@ -19,7 +19,8 @@ func TestMetricListService_List_Table(t *testing.T) {
 	}
 
 	type args struct {
-		ctx context.Context
+		ctx    context.Context
+		filter types.Labels
 	}
 
 	type want struct {
@@ -58,6 +59,31 @@ func TestMetricListService_List_Table(t *testing.T) {
 					}, nil)
 			},
 		},
+		{
+			name: "filter keeps metrics with matching labels",
+			fields: fields{
+				lister: NewMockMetricListLister(ctrl),
+			},
+			args: args{
+				ctx:    context.Background(),
+				filter: types.NewLabels(map[string]string{"host": "a"}),
+			},
+			want: want{
+				metrics: []types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"})},
+				},
+				err: false,
+			},
+			setup: func(f fields, args args) {
+				f.lister.EXPECT().
+					List(args.ctx).
+					Return([]types.Metrics{
+						{ID: "Alloc", Type: types.Gauge},
+						{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"})},
+						{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "b"})},
+					}, nil)
+			},
+		},
 		{
 			name: "lister returns error",
 			fields: fields{
@@ -103,7 +129,7 @@ func TestMetricListService_List_Table(t *testing.T) {
 			}
 
 			service := NewMetricListService(tt.fields.lister)
-			gotMetrics, err := service.List(tt.args.ctx)
+			gotMetrics, err := service.List(tt.args.ctx, tt.args.filter)
 
 			if tt.want.err {
 				assert.Error(t, err)
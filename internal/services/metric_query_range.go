@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// maxMetricRangePoints limits the number of points a single range query may produce.
+const maxMetricRangePoints = 11000
+
+// MetricQueryRangeLister defines the interface for listing the time series of a metric.
+type MetricQueryRangeLister interface {
+	// List returns the points of the metric's time series within [from, to], sorted by timestamp.
+	List(ctx context.Context, id types.MetricID, from time.Time, to time.Time) ([]types.MetricPoint, error)
+
+	// ListIDsByName returns the IDs of the time series of the metrics with the given name
+	// and type, whatever their labels.
+	ListIDsByName(ctx context.Context, id string, metricType string) ([]types.MetricID, error)
+}
+
+// MetricQueryRangeService provides range queries over metric time series.
+type MetricQueryRangeService struct {
+	lister MetricQueryRangeLister
+}
+
+// NewMetricQueryRangeService creates a new MetricQueryRangeService with the provided MetricQueryRangeLister.
+func NewMetricQueryRangeService(
+	lister MetricQueryRangeLister,
+) *MetricQueryRangeService {
+	return &MetricQueryRangeService{lister: lister}
+}
+
+// QueryRange returns the time series of a metric within [from, to].
+//
+// The series is selected as by MetricGetService.Get: if there is no series with the exact ID,
+// the series of the same name and type whose labels contain all of the requested ones is
+// returned, and ErrMetricLabelsAmbiguous if several do. The returned series carries the ID
+// of the selected series, or the requested one, without points, if none matches.
+//
+// With a zero step every recorded point in the range is returned. With a positive
+// step the series is resampled at from, from+step, ... up to to: each resulting point
+// carries the last value recorded within the step ending at its timestamp, and
+// steps without recorded values are left out.
+//
+// Returns an error wrapping ErrMetricRangeInvalid if from is after to, the step is
+// negative, or the step would produce more than maxMetricRangePoints points.
+func (svc *MetricQueryRangeService) QueryRange(
+	ctx context.Context,
+	id types.MetricID,
+	from time.Time,
+	to time.Time,
+	step time.Duration,
+) (types.MetricSeries, error) {
+	if from.After(to) || step < 0 {
+		return types.MetricSeries{}, errors.ErrMetricRangeInvalid
+	}
+	if step > 0 && to.Sub(from)/step >= maxMetricRangePoints {
+		return types.MetricSeries{}, errors.ErrMetricRangeInvalid
+	}
+
+	ids, err := svc.lister.ListIDsByName(ctx, id.ID, id.Type)
+	if err != nil {
+		return types.MetricSeries{}, err
+	}
+	found, err := findByLabels(ids, id, func(id *types.MetricID) types.MetricID { return *id })
+	if err != nil {
+		return types.MetricSeries{}, err
+	}
+	if found != nil {
+		id = *found
+	}
+
+	series := types.MetricSeries{ID: id.ID, Type: id.Type, Labels: id.Labels}
+	if step == 0 {
+		series.Points, err = svc.lister.List(ctx, id, from, to)
+		if err != nil {
+			return types.MetricSeries{}, err
+		}
+		return series, nil
+	}
+
+	points, err := svc.lister.List(ctx, id, from.Add(-step), to)
+	if err != nil {
+		return types.MetricSeries{}, err
+	}
+	series.Points = resamplePoints(points, from, to, step)
+	return series, nil
+}
+
+// resamplePoints resamples the sorted points at from, from+step, ... up to to, taking the
+// last value recorded within the step ending at each timestamp and leaving out empty steps.
+func resamplePoints(points []types.MetricPoint, from time.Time, to time.Time, step time.Duration) []types.MetricPoint {
+	resampled := make([]types.MetricPoint, 0)
+	idx := 0
+	for t := from; !t.After(to); t = t.Add(step) {
+		// Advance to the last point at or before t
+		for idx < len(points) && !points[idx].Timestamp.After(t) {
+			idx++
+		}
+		if idx == 0 {
+			continue
+		}
+		if last := points[idx-1]; last.Timestamp.After(t.Add(-step)) {
+			resampled = append(resampled, types.MetricPoint{Timestamp: t, Value: last.Value})
+		}
+	}
+	return resampled
+}
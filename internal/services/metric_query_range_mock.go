@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/metric_query_range.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockMetricQueryRangeLister is a mock of MetricQueryRangeLister interface.
+type MockMetricQueryRangeLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricQueryRangeListerMockRecorder
+}
+
+// MockMetricQueryRangeListerMockRecorder is the mock recorder for MockMetricQueryRangeLister.
+type MockMetricQueryRangeListerMockRecorder struct {
+	mock *MockMetricQueryRangeLister
+}
+
+// NewMockMetricQueryRangeLister creates a new mock instance.
+func NewMockMetricQueryRangeLister(ctrl *gomock.Controller) *MockMetricQueryRangeLister {
+	mock := &MockMetricQueryRangeLister{ctrl: ctrl}
+	mock.recorder = &MockMetricQueryRangeListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricQueryRangeLister) EXPECT() *MockMetricQueryRangeListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMetricQueryRangeLister) List(ctx context.Context, id types.MetricID, from, to time.Time) ([]types.MetricPoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, id, from, to)
+	ret0, _ := ret[0].([]types.MetricPoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMetricQueryRangeListerMockRecorder) List(ctx, id, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricQueryRangeLister)(nil).List), ctx, id, from, to)
+}
+
+// ListIDsByName mocks base method.
+func (m *MockMetricQueryRangeLister) ListIDsByName(ctx context.Context, id, metricType string) ([]types.MetricID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIDsByName", ctx, id, metricType)
+	ret0, _ := ret[0].([]types.MetricID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIDsByName indicates an expected call of ListIDsByName.
+func (mr *MockMetricQueryRangeListerMockRecorder) ListIDsByName(ctx, id, metricType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIDsByName", reflect.TypeOf((*MockMetricQueryRangeLister)(nil).ListIDsByName), ctx, id, metricType)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricQueryRangeService_QueryRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+	id := types.MetricID{ID: "HeapAlloc", Type: types.Gauge}
+	hostA := types.MetricID{ID: "HeapAlloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"})}
+	hostB := types.MetricID{ID: "HeapAlloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "b", "env": "prod"})}
+
+	points := []types.MetricPoint{
+		{Timestamp: at(-5), Value: 1},
+		{Timestamp: at(3), Value: 2},
+		{Timestamp: at(7), Value: 3},
+		{Timestamp: at(35), Value: 4},
+	}
+
+	tests := []struct {
+		name     string
+		id       types.MetricID
+		from, to time.Time
+		step     time.Duration
+		setup    func(lister *MockMetricQueryRangeLister)
+		want     types.MetricSeries
+		wantErr  error
+	}{
+		{
+			name: "zero step returns raw points",
+			id:   id,
+			from: at(0),
+			to:   at(40),
+			setup: func(lister *MockMetricQueryRangeLister) {
+				lister.EXPECT().ListIDsByName(gomock.Any(), id.ID, id.Type).Return([]types.MetricID{id}, nil)
+				lister.EXPECT().List(gomock.Any(), id, at(0), at(40)).Return(points[1:], nil)
+			},
+			want: types.MetricSeries{ID: id.ID, Type: id.Type, Points: points[1:]},
+		},
+		{
+			name: "step resamples to the last value in each step",
+			id:   id,
+			from: at(0),
+			to:   at(40),
+			step: 10 * time.Second,
+			setup: func(lister *MockMetricQueryRangeLister) {
+				lister.EXPECT().ListIDsByName(gomock.Any(), id.ID, id.Type).Return([]types.MetricID{id}, nil)
+				lister.EXPECT().List(gomock.Any(), id, at(-10), at(40)).Return(points, nil)
+			},
+			want: types.MetricSeries{ID: id.ID, Type: id.Type, Points: []types.MetricPoint{
+				{Timestamp: at(0), Value: 1},
+				{Timestamp: at(10), Value: 3},
+				{Timestamp: at(40), Value: 4},
+			}},
+		},
+		{
+			name: "no points",
+			id:   id,
+			from: at(0),
+			to:   at(40),
+			step: 10 * time.Second,
+			setup: func(lister *MockMetricQueryRangeLister) {
+				lister.EXPECT().ListIDsByName(gomock.Any(), id.ID, id.Type).Return([]types.MetricID{}, nil)
+				lister.EXPECT().List(gomock.Any(), id, at(-10), at(40)).Return([]types.MetricPoint{}, nil)
+			},
+			want: types.MetricSeries{ID: id.ID, Type: id.Type, Points: []types.MetricPoint{}},
+		},
+		{
+			name: "labels select the only series containing them",
+			id:   types.MetricID{ID: "HeapAlloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"})},
+			from: at(0),
+			to:   at(40),
+			setup: func(lister *MockMetricQueryRangeLister) {
+				lister.EXPECT().ListIDsByName(gomock.Any(), id.ID, id.Type).Return([]types.MetricID{hostA, hostB}, nil)
+				lister.EXPECT().List(gomock.Any(), hostA, at(0), at(40)).Return(points[1:], nil)
+			},
+			want: types.MetricSeries{ID: hostA.ID, Type: hostA.Type, Labels: hostA.Labels, Points: points[1:]},
+		},
+		{
+			name: "labels matching several series are ambiguous",
+			id:   types.MetricID{ID: "HeapAlloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"env": "prod"})},
+			from: at(0),
+			to:   at(40),
+			setup: func(lister *MockMetricQueryRangeLister) {
+				lister.EXPECT().ListIDsByName(gomock.Any(), id.ID, id.Type).Return([]types.MetricID{hostA, hostB}, nil)
+			},
+			wantErr: internalErrors.ErrMetricLabelsAmbiguous,
+		},
+		{
+			name:    "from after to",
+			id:      id,
+			from:    at(40),
+			to:      at(0),
+			wantErr: internalErrors.ErrMetricRangeInvalid,
+		},
+		{
+			name:    "negative step",
+			id:      id,
+			from:    at(0),
+			to:      at(40),
+			step:    -time.Second,
+			wantErr: internalErrors.ErrMetricRangeInvalid,
+		},
+		{
+			name:    "too many points",
+			id:      id,
+			from:    at(0),
+			to:      at(0).Add(24 * time.Hour),
+			step:    time.Second,
+			wantErr: internalErrors.ErrMetricRangeInvalid,
+		},
+		{
+			name: "series lister error",
+			id:   id,
+			from: at(0),
+			to:   at(40),
+			setup: func(lister *MockMetricQueryRangeLister) {
+				lister.EXPECT().ListIDsByName(gomock.Any(), id.ID, id.Type).Return(nil, errors.New("list error"))
+			},
+			wantErr: errors.New("list error"),
+		},
+		{
+			name: "lister error",
+			id:   id,
+			from: at(0),
+			to:   at(40),
+			step: 10 * time.Second,
+			setup: func(lister *MockMetricQueryRangeLister) {
+				lister.EXPECT().ListIDsByName(gomock.Any(), id.ID, id.Type).Return([]types.MetricID{id}, nil)
+				lister.EXPECT().List(gomock.Any(), id, at(-10), at(40)).Return(nil, errors.New("list error"))
+			},
+			wantErr: errors.New("list error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister := NewMockMetricQueryRangeLister(ctrl)
+			if tt.setup != nil {
+				tt.setup(lister)
+			}
+
+			svc := NewMetricQueryRangeService(lister)
+			got, err := svc.QueryRange(context.Background(), tt.id, tt.from, tt.to, tt.step)
+
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				assert.Equal(t, types.MetricSeries{}, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
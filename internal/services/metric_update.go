@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"sort"
+	"time"
 
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
@@ -12,66 +15,222 @@ type MetricUpdateSaver interface {
 	Save(ctx context.Context, metrics types.Metrics) error
 }
 
-// MetricUpdateGetter defines an interface for retrieving metrics by ID.
-type MetricUpdateGetter interface {
-	// Get retrieves a metric by its ID. Returns nil if not found.
-	Get(ctx context.Context, id types.MetricID) (*types.Metrics, error)
+// MetricUpdateUpserter defines an interface for atomic read-modify-write updates of metrics.
+type MetricUpdateUpserter interface {
+	// Upsert atomically replaces the metric with the given ID by the result of fn, which is
+	// called with the stored metric, or nil if there is none, and returns the stored result.
+	// fn may be called more than once.
+	Upsert(ctx context.Context, id types.MetricID, fn func(existing *types.Metrics) types.Metrics) (types.Metrics, error)
+}
+
+// MetricUpdateIncrementer defines an interface for atomically incrementing counters.
+type MetricUpdateIncrementer interface {
+	// Increment atomically adds delta to the counter with the given ID, creating it if there
+	// is none, and returns the resulting total.
+	Increment(ctx context.Context, id types.MetricID, delta int64) (int64, error)
+}
+
+// MetricUpdateTransactor defines an interface for running a function atomically.
+type MetricUpdateTransactor interface {
+	// Do runs fn so that either all of its changes are applied or none of them.
+	// Storage operations made by fn must use the context passed to it and touch metrics
+	// in ascending order, see types.MetricID.Compare, so that concurrent batches lock
+	// the same metrics in the same order.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// MetricUpdateHistoryAppender defines an interface for recording metric values in their time series.
+type MetricUpdateHistoryAppender interface {
+	// Append records the current value of every given metric at the given time.
+	Append(ctx context.Context, metrics []types.Metrics, at time.Time) error
 }
 
 // MetricUpdateService provides methods for updating metrics,
 // combining retrieving and saving functionality.
 type MetricUpdateService struct {
-	saver  MetricUpdateSaver
-	getter MetricUpdateGetter
+	saver       MetricUpdateSaver
+	upserter    MetricUpdateUpserter
+	incrementer MetricUpdateIncrementer
+	tx          MetricUpdateTransactor
+	history     MetricUpdateHistoryAppender
 }
 
-// NewMetricUpdateService creates a new MetricUpdateService with the provided saver and getter.
+// NewMetricUpdateService creates a new MetricUpdateService with the provided saver and upserter.
+//
+// incrementer updates counters and upserter histograms and summaries, so that concurrent
+// updates of the same metric are not lost.
+// tx is used to apply each batch of several metrics atomically; a single metric is applied
+// by one storage operation and needs no transaction. tx may be nil, in which case a batch
+// failing part way stays partly applied.
+// history records every applied batch in the metrics' time series; it may be nil
+// to disable the history.
 func NewMetricUpdateService(
 	saver MetricUpdateSaver,
-	getter MetricUpdateGetter,
+	upserter MetricUpdateUpserter,
+	incrementer MetricUpdateIncrementer,
+	tx MetricUpdateTransactor,
+	history MetricUpdateHistoryAppender,
 ) *MetricUpdateService {
-	return &MetricUpdateService{saver: saver, getter: getter}
+	return &MetricUpdateService{saver: saver, upserter: upserter, incrementer: incrementer, tx: tx, history: history}
 }
 
-// Update processes and saves a slice of metrics.
-// For counter-type metrics, it sums the existing delta with the new one before saving.
-// Returns the updated slice of metrics or an error.
+// Update processes and saves a slice of metrics as a single batch.
+// For counter-type metrics, the new delta is atomically added to the existing one,
+// and the metric is returned with the resulting total.
+// Histograms and summaries carry the observations made since the previous update:
+// their sums and counts are added to the stored ones, as are histogram bucket counts,
+// while summary quantiles are replaced since quantiles cannot be combined.
+// Once the batch is applied, the updated values are appended to the metrics' history.
+// A failure to record the history is logged and does not fail the update, which is already applied.
+// Returns the updated slice of metrics or an error. A failed batch is applied as a whole or not at all
+// only when a transactor is set; without one, the metrics applied before the failure stay applied.
+// A single metric is applied by one storage operation, so it never stays partly applied.
 func (svc *MetricUpdateService) Update(
 	ctx context.Context,
 	metrics []*types.Metrics,
 ) ([]*types.Metrics, error) {
-	for idx, m := range metrics {
-		switch m.Type {
-		case types.Counter:
-			if err := updateCounterMetric(ctx, svc.getter, m); err != nil {
-				return nil, err
+	// Metrics are applied in ascending order of their IDs, as the transactor requires;
+	// the sort is stable, so updates of the same metric keep their order
+	order := make([]int, len(metrics))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return metricIDOf(metrics[order[i]]).Compare(metricIDOf(metrics[order[j]])) < 0
+	})
+
+	update := func(ctx context.Context) error {
+		for _, idx := range order {
+			m := metrics[idx]
+			switch m.Type {
+			case types.Counter:
+				if err := updateCounterMetric(ctx, svc.incrementer, m); err != nil {
+					return err
+				}
+				metrics[idx] = m
+				continue
+			case types.Histogram, types.Summary:
+				if err := updateDistributionMetric(ctx, svc.upserter, m); err != nil {
+					return err
+				}
+				metrics[idx] = m
+				continue
+			}
+
+			err := svc.saver.Save(ctx, *m)
+			if err != nil {
+				return err
 			}
+			metrics[idx] = m
 		}
+		return nil
+	}
+
+	var err error
+	if svc.tx != nil && len(metrics) > 1 {
+		err = svc.tx.Do(ctx, update)
+	} else {
+		err = update(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		err := svc.saver.Save(ctx, *m)
-		if err != nil {
-			return nil, err
+	if svc.history != nil {
+		updated := make([]types.Metrics, 0, len(metrics))
+		for _, m := range metrics {
+			updated = append(updated, *m)
+		}
+		if err := svc.history.Append(ctx, updated, time.Now()); err != nil {
+			logger.Log.Warnf("Failed to record metric history: %v", err)
 		}
-		metrics[idx] = m
 	}
 
 	return metrics, nil
 }
 
-// updateCounterMetric retrieves the existing counter metric and sums its delta value with the incoming one.
+// metricIDOf returns the ID of the metric.
+func metricIDOf(m *types.Metrics) types.MetricID {
+	return types.MetricID{ID: m.ID, Type: m.Type, Labels: m.Labels}
+}
+
+// updateCounterMetric atomically adds the incoming delta to the stored counter
+// and replaces the incoming delta with the resulting total.
 func updateCounterMetric(
 	ctx context.Context,
-	getter MetricUpdateGetter,
+	incrementer MetricUpdateIncrementer,
 	metric *types.Metrics,
 ) error {
-	existing, err := getter.Get(ctx, types.MetricID{ID: metric.ID, Type: metric.Type})
+	var delta int64
+	if metric.Delta != nil {
+		delta = *metric.Delta
+	}
+
+	total, err := incrementer.Increment(ctx, types.MetricID{ID: metric.ID, Type: metric.Type, Labels: metric.Labels}, delta)
 	if err != nil {
 		return err
 	}
 
-	if existing != nil && existing.Delta != nil && metric.Delta != nil {
-		*metric.Delta += *existing.Delta
+	metric.Delta = &total
+	return nil
+}
+
+// updateDistributionMetric atomically adds the sum, count and, for histograms, bucket counts
+// of the incoming histogram or summary to the stored ones and replaces the incoming metric
+// with the result.
+func updateDistributionMetric(
+	ctx context.Context,
+	upserter MetricUpdateUpserter,
+	metric *types.Metrics,
+) error {
+	merged, err := upserter.Upsert(ctx, types.MetricID{ID: metric.ID, Type: metric.Type, Labels: metric.Labels},
+		func(existing *types.Metrics) types.Metrics {
+			return mergeDistributionMetric(existing, *metric)
+		})
+	if err != nil {
+		return err
 	}
 
+	*metric = merged
 	return nil
 }
+
+// mergeDistributionMetric returns the incoming histogram or summary with the stored one added to it.
+// The result shares no memory with either of them.
+//
+// A histogram whose bucket bounds changed, for example because the client was reconfigured,
+// starts over with the incoming buckets, as counts of different buckets cannot be added;
+// the reset is logged, since the observations recorded so far are dropped.
+func mergeDistributionMetric(existing *types.Metrics, incoming types.Metrics) types.Metrics {
+	merged := incoming
+	if incoming.Sum != nil {
+		sum := *incoming.Sum
+		merged.Sum = &sum
+	}
+	if incoming.Count != nil {
+		count := *incoming.Count
+		merged.Count = &count
+	}
+	merged.Buckets = append(types.HistogramBuckets(nil), incoming.Buckets...)
+	merged.Quantiles = append(types.SummaryQuantiles(nil), incoming.Quantiles...)
+
+	if existing == nil || existing.Sum == nil || existing.Count == nil || merged.Sum == nil || merged.Count == nil {
+		return merged
+	}
+	if incoming.Type == types.Histogram && !incoming.Buckets.SameBounds(existing.Buckets) {
+		logger.Log.Warnf("Bucket bounds of histogram %s changed from %v to %v; its %d recorded observations are dropped",
+			incoming.ID, existing.Buckets, incoming.Buckets, *existing.Count)
+		return merged
+	}
+
+	*merged.Sum += *existing.Sum
+	*merged.Count += *existing.Count
+
+	if incoming.Type == types.Histogram {
+		for i := range merged.Buckets {
+			merged.Buckets[i].Count += existing.Buckets[i].Count
+		}
+	}
+
+	return merged
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: /home/sergey/Go/yandex-go-advanced/internal/services/metric_update.go
+// Source: internal/services/metric_update.go
 
 // Package services is a generated GoMock package.
 package services
@@ -7,6 +7,7 @@ package services
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
@@ -49,40 +50,152 @@ func (mr *MockMetricUpdateSaverMockRecorder) Save(ctx, metrics interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockMetricUpdateSaver)(nil).Save), ctx, metrics)
 }
 
-// MockMetricUpdateGetter is a mock of MetricUpdateGetter interface.
-type MockMetricUpdateGetter struct {
+// MockMetricUpdateUpserter is a mock of MetricUpdateUpserter interface.
+type MockMetricUpdateUpserter struct {
 	ctrl     *gomock.Controller
-	recorder *MockMetricUpdateGetterMockRecorder
+	recorder *MockMetricUpdateUpserterMockRecorder
 }
 
-// MockMetricUpdateGetterMockRecorder is the mock recorder for MockMetricUpdateGetter.
-type MockMetricUpdateGetterMockRecorder struct {
-	mock *MockMetricUpdateGetter
+// MockMetricUpdateUpserterMockRecorder is the mock recorder for MockMetricUpdateUpserter.
+type MockMetricUpdateUpserterMockRecorder struct {
+	mock *MockMetricUpdateUpserter
 }
 
-// NewMockMetricUpdateGetter creates a new mock instance.
-func NewMockMetricUpdateGetter(ctrl *gomock.Controller) *MockMetricUpdateGetter {
-	mock := &MockMetricUpdateGetter{ctrl: ctrl}
-	mock.recorder = &MockMetricUpdateGetterMockRecorder{mock}
+// NewMockMetricUpdateUpserter creates a new mock instance.
+func NewMockMetricUpdateUpserter(ctrl *gomock.Controller) *MockMetricUpdateUpserter {
+	mock := &MockMetricUpdateUpserter{ctrl: ctrl}
+	mock.recorder = &MockMetricUpdateUpserterMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockMetricUpdateGetter) EXPECT() *MockMetricUpdateGetterMockRecorder {
+func (m *MockMetricUpdateUpserter) EXPECT() *MockMetricUpdateUpserterMockRecorder {
 	return m.recorder
 }
 
-// Get mocks base method.
-func (m *MockMetricUpdateGetter) Get(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+// Upsert mocks base method.
+func (m *MockMetricUpdateUpserter) Upsert(ctx context.Context, id types.MetricID, fn func(*types.Metrics) types.Metrics) (types.Metrics, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, id)
-	ret0, _ := ret[0].(*types.Metrics)
+	ret := m.ctrl.Call(m, "Upsert", ctx, id, fn)
+	ret0, _ := ret[0].(types.Metrics)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Get indicates an expected call of Get.
-func (mr *MockMetricUpdateGetterMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+// Upsert indicates an expected call of Upsert.
+func (mr *MockMetricUpdateUpserterMockRecorder) Upsert(ctx, id, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMetricUpdateGetter)(nil).Get), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockMetricUpdateUpserter)(nil).Upsert), ctx, id, fn)
+}
+
+// MockMetricUpdateIncrementer is a mock of MetricUpdateIncrementer interface.
+type MockMetricUpdateIncrementer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricUpdateIncrementerMockRecorder
+}
+
+// MockMetricUpdateIncrementerMockRecorder is the mock recorder for MockMetricUpdateIncrementer.
+type MockMetricUpdateIncrementerMockRecorder struct {
+	mock *MockMetricUpdateIncrementer
+}
+
+// NewMockMetricUpdateIncrementer creates a new mock instance.
+func NewMockMetricUpdateIncrementer(ctrl *gomock.Controller) *MockMetricUpdateIncrementer {
+	mock := &MockMetricUpdateIncrementer{ctrl: ctrl}
+	mock.recorder = &MockMetricUpdateIncrementerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricUpdateIncrementer) EXPECT() *MockMetricUpdateIncrementerMockRecorder {
+	return m.recorder
+}
+
+// Increment mocks base method.
+func (m *MockMetricUpdateIncrementer) Increment(ctx context.Context, id types.MetricID, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Increment", ctx, id, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Increment indicates an expected call of Increment.
+func (mr *MockMetricUpdateIncrementerMockRecorder) Increment(ctx, id, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockMetricUpdateIncrementer)(nil).Increment), ctx, id, delta)
+}
+
+// MockMetricUpdateTransactor is a mock of MetricUpdateTransactor interface.
+type MockMetricUpdateTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricUpdateTransactorMockRecorder
+}
+
+// MockMetricUpdateTransactorMockRecorder is the mock recorder for MockMetricUpdateTransactor.
+type MockMetricUpdateTransactorMockRecorder struct {
+	mock *MockMetricUpdateTransactor
+}
+
+// NewMockMetricUpdateTransactor creates a new mock instance.
+func NewMockMetricUpdateTransactor(ctrl *gomock.Controller) *MockMetricUpdateTransactor {
+	mock := &MockMetricUpdateTransactor{ctrl: ctrl}
+	mock.recorder = &MockMetricUpdateTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricUpdateTransactor) EXPECT() *MockMetricUpdateTransactorMockRecorder {
+	return m.recorder
+}
+
+// Do mocks base method.
+func (m *MockMetricUpdateTransactor) Do(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Do", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Do indicates an expected call of Do.
+func (mr *MockMetricUpdateTransactorMockRecorder) Do(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockMetricUpdateTransactor)(nil).Do), ctx, fn)
+}
+
+// MockMetricUpdateHistoryAppender is a mock of MetricUpdateHistoryAppender interface.
+type MockMetricUpdateHistoryAppender struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricUpdateHistoryAppenderMockRecorder
+}
+
+// MockMetricUpdateHistoryAppenderMockRecorder is the mock recorder for MockMetricUpdateHistoryAppender.
+type MockMetricUpdateHistoryAppenderMockRecorder struct {
+	mock *MockMetricUpdateHistoryAppender
+}
+
+// NewMockMetricUpdateHistoryAppender creates a new mock instance.
+func NewMockMetricUpdateHistoryAppender(ctrl *gomock.Controller) *MockMetricUpdateHistoryAppender {
+	mock := &MockMetricUpdateHistoryAppender{ctrl: ctrl}
+	mock.recorder = &MockMetricUpdateHistoryAppenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricUpdateHistoryAppender) EXPECT() *MockMetricUpdateHistoryAppenderMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockMetricUpdateHistoryAppender) Append(ctx context.Context, metrics []types.Metrics, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, metrics, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockMetricUpdateHistoryAppenderMockRecorder) Append(ctx, metrics, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockMetricUpdateHistoryAppender)(nil).Append), ctx, metrics, at)
 }
@@ -23,8 +23,9 @@ func TestMetricUpdateService_Update_Table(t *testing.T) {
 	}
 
 	type fields struct {
-		saver  *MockMetricUpdateSaver
-		getter *MockMetricUpdateGetter
+		saver       *MockMetricUpdateSaver
+		upserter    *MockMetricUpdateUpserter
+		incrementer *MockMetricUpdateIncrementer
 	}
 	type args struct {
 		metrics []*types.Metrics
@@ -34,17 +35,19 @@ func TestMetricUpdateService_Update_Table(t *testing.T) {
 	}
 
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   want
-		setup  func(f fields, args args)
+		name        string
+		fields      fields
+		args        args
+		want        want
+		setup       func(f fields, args args)
+		wantMetrics []*types.Metrics
 	}{
 		{
 			name: "counter metric with existing value",
 			fields: fields{
-				saver:  NewMockMetricUpdateSaver(ctrl),
-				getter: NewMockMetricUpdateGetter(ctrl),
+				saver:       NewMockMetricUpdateSaver(ctrl),
+				upserter:    NewMockMetricUpdateUpserter(ctrl),
+				incrementer: NewMockMetricUpdateIncrementer(ctrl),
 			},
 			args: args{
 				metrics: []*types.Metrics{
@@ -57,28 +60,24 @@ func TestMetricUpdateService_Update_Table(t *testing.T) {
 			},
 			want: want{err: false},
 			setup: func(f fields, args args) {
-				existing := &types.Metrics{
+				f.incrementer.EXPECT().
+					Increment(gomock.Any(), types.MetricID{ID: "metric1", Type: types.Counter}, int64(10)).
+					Return(int64(15), nil) // 10 + 5
+			},
+			wantMetrics: []*types.Metrics{
+				{
 					ID:    "metric1",
 					Type:  types.Counter,
-					Delta: ptrInt64(5),
-				}
-				f.getter.EXPECT().
-					Get(gomock.Any(), types.MetricID{ID: "metric1", Type: types.Counter}).
-					Return(existing, nil)
-				f.saver.EXPECT().
-					Save(gomock.Any(), types.Metrics{
-						ID:    "metric1",
-						Type:  types.Counter,
-						Delta: ptrInt64(15), // 10 + 5
-					}).
-					Return(nil)
+					Delta: ptrInt64(15),
+				},
 			},
 		},
 		{
 			name: "gauge metric saves as is",
 			fields: fields{
-				saver:  NewMockMetricUpdateSaver(ctrl),
-				getter: NewMockMetricUpdateGetter(ctrl),
+				saver:       NewMockMetricUpdateSaver(ctrl),
+				upserter:    NewMockMetricUpdateUpserter(ctrl),
+				incrementer: NewMockMetricUpdateIncrementer(ctrl),
 			},
 			args: args{
 				metrics: []*types.Metrics{
@@ -97,10 +96,137 @@ func TestMetricUpdateService_Update_Table(t *testing.T) {
 			},
 		},
 		{
-			name: "getter returns error",
+			name: "histogram adds sum, count and buckets",
+			fields: fields{
+				saver:       NewMockMetricUpdateSaver(ctrl),
+				upserter:    NewMockMetricUpdateUpserter(ctrl),
+				incrementer: NewMockMetricUpdateIncrementer(ctrl),
+			},
+			args: args{
+				metrics: []*types.Metrics{
+					{
+						ID:      "PauseNs",
+						Type:    types.Histogram,
+						Sum:     ptrFloat64(1.5),
+						Count:   ptrInt64(3),
+						Buckets: types.HistogramBuckets{{UpperBound: 0.5, Count: 1}, {UpperBound: 1, Count: 2}},
+					},
+				},
+			},
+			want: want{err: false},
+			setup: func(f fields, args args) {
+				f.upserter.EXPECT().
+					Upsert(gomock.Any(), types.MetricID{ID: "PauseNs", Type: types.Histogram}, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id types.MetricID, fn func(*types.Metrics) types.Metrics) (types.Metrics, error) {
+						return fn(&types.Metrics{
+							ID:      "PauseNs",
+							Type:    types.Histogram,
+							Sum:     ptrFloat64(2),
+							Count:   ptrInt64(4),
+							Buckets: types.HistogramBuckets{{UpperBound: 0.5, Count: 2}, {UpperBound: 1, Count: 3}},
+						}), nil
+					})
+			},
+			wantMetrics: []*types.Metrics{
+				{
+					ID:      "PauseNs",
+					Type:    types.Histogram,
+					Sum:     ptrFloat64(3.5),
+					Count:   ptrInt64(7),
+					Buckets: types.HistogramBuckets{{UpperBound: 0.5, Count: 3}, {UpperBound: 1, Count: 5}},
+				},
+			},
+		},
+		{
+			name: "histogram with changed buckets starts over",
 			fields: fields{
-				saver:  NewMockMetricUpdateSaver(ctrl),
-				getter: NewMockMetricUpdateGetter(ctrl),
+				saver:       NewMockMetricUpdateSaver(ctrl),
+				upserter:    NewMockMetricUpdateUpserter(ctrl),
+				incrementer: NewMockMetricUpdateIncrementer(ctrl),
+			},
+			args: args{
+				metrics: []*types.Metrics{
+					{
+						ID:      "PauseNs",
+						Type:    types.Histogram,
+						Sum:     ptrFloat64(1.5),
+						Count:   ptrInt64(3),
+						Buckets: types.HistogramBuckets{{UpperBound: 1, Count: 2}},
+					},
+				},
+			},
+			want: want{err: false},
+			setup: func(f fields, args args) {
+				f.upserter.EXPECT().
+					Upsert(gomock.Any(), types.MetricID{ID: "PauseNs", Type: types.Histogram}, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id types.MetricID, fn func(*types.Metrics) types.Metrics) (types.Metrics, error) {
+						return fn(&types.Metrics{
+							ID:      "PauseNs",
+							Type:    types.Histogram,
+							Sum:     ptrFloat64(2),
+							Count:   ptrInt64(4),
+							Buckets: types.HistogramBuckets{{UpperBound: 0.5, Count: 2}, {UpperBound: 1, Count: 3}},
+						}), nil
+					})
+			},
+			wantMetrics: []*types.Metrics{
+				{
+					ID:      "PauseNs",
+					Type:    types.Histogram,
+					Sum:     ptrFloat64(1.5),
+					Count:   ptrInt64(3),
+					Buckets: types.HistogramBuckets{{UpperBound: 1, Count: 2}},
+				},
+			},
+		},
+		{
+			name: "summary adds sum and count and replaces quantiles",
+			fields: fields{
+				saver:       NewMockMetricUpdateSaver(ctrl),
+				upserter:    NewMockMetricUpdateUpserter(ctrl),
+				incrementer: NewMockMetricUpdateIncrementer(ctrl),
+			},
+			args: args{
+				metrics: []*types.Metrics{
+					{
+						ID:        "Latency",
+						Type:      types.Summary,
+						Sum:       ptrFloat64(1),
+						Count:     ptrInt64(2),
+						Quantiles: types.SummaryQuantiles{{Quantile: 0.5, Value: 0.4}},
+					},
+				},
+			},
+			want: want{err: false},
+			setup: func(f fields, args args) {
+				f.upserter.EXPECT().
+					Upsert(gomock.Any(), types.MetricID{ID: "Latency", Type: types.Summary}, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id types.MetricID, fn func(*types.Metrics) types.Metrics) (types.Metrics, error) {
+						return fn(&types.Metrics{
+							ID:        "Latency",
+							Type:      types.Summary,
+							Sum:       ptrFloat64(3),
+							Count:     ptrInt64(5),
+							Quantiles: types.SummaryQuantiles{{Quantile: 0.5, Value: 0.7}},
+						}), nil
+					})
+			},
+			wantMetrics: []*types.Metrics{
+				{
+					ID:        "Latency",
+					Type:      types.Summary,
+					Sum:       ptrFloat64(4),
+					Count:     ptrInt64(7),
+					Quantiles: types.SummaryQuantiles{{Quantile: 0.5, Value: 0.4}},
+				},
+			},
+		},
+		{
+			name: "incrementer returns error",
+			fields: fields{
+				saver:       NewMockMetricUpdateSaver(ctrl),
+				upserter:    NewMockMetricUpdateUpserter(ctrl),
+				incrementer: NewMockMetricUpdateIncrementer(ctrl),
 			},
 			args: args{
 				metrics: []*types.Metrics{
@@ -113,16 +239,41 @@ func TestMetricUpdateService_Update_Table(t *testing.T) {
 			},
 			want: want{err: true},
 			setup: func(f fields, args args) {
-				f.getter.EXPECT().
-					Get(gomock.Any(), types.MetricID{ID: "metric3", Type: types.Counter}).
-					Return(nil, errors.New("getter error"))
+				f.incrementer.EXPECT().
+					Increment(gomock.Any(), types.MetricID{ID: "metric3", Type: types.Counter}, int64(1)).
+					Return(int64(0), errors.New("incrementer error"))
+			},
+		},
+		{
+			name: "upserter returns error",
+			fields: fields{
+				saver:       NewMockMetricUpdateSaver(ctrl),
+				upserter:    NewMockMetricUpdateUpserter(ctrl),
+				incrementer: NewMockMetricUpdateIncrementer(ctrl),
+			},
+			args: args{
+				metrics: []*types.Metrics{
+					{
+						ID:    "Latency",
+						Type:  types.Summary,
+						Sum:   ptrFloat64(1),
+						Count: ptrInt64(2),
+					},
+				},
+			},
+			want: want{err: true},
+			setup: func(f fields, args args) {
+				f.upserter.EXPECT().
+					Upsert(gomock.Any(), types.MetricID{ID: "Latency", Type: types.Summary}, gomock.Any()).
+					Return(types.Metrics{}, errors.New("upserter error"))
 			},
 		},
 		{
 			name: "saver returns error",
 			fields: fields{
-				saver:  NewMockMetricUpdateSaver(ctrl),
-				getter: NewMockMetricUpdateGetter(ctrl),
+				saver:       NewMockMetricUpdateSaver(ctrl),
+				upserter:    NewMockMetricUpdateUpserter(ctrl),
+				incrementer: NewMockMetricUpdateIncrementer(ctrl),
 			},
 			args: args{
 				metrics: []*types.Metrics{
@@ -147,7 +298,7 @@ func TestMetricUpdateService_Update_Table(t *testing.T) {
 			if tt.setup != nil {
 				tt.setup(tt.fields, tt.args)
 			}
-			svc := NewMetricUpdateService(tt.fields.saver, tt.fields.getter)
+			svc := NewMetricUpdateService(tt.fields.saver, tt.fields.upserter, tt.fields.incrementer, nil, nil)
 
 			res, err := svc.Update(context.Background(), tt.args.metrics)
 			if tt.want.err {
@@ -157,6 +308,204 @@ func TestMetricUpdateService_Update_Table(t *testing.T) {
 				assert.NoError(t, err)
 				assert.NotNil(t, res)
 			}
+			if tt.wantMetrics != nil {
+				assert.Equal(t, tt.wantMetrics, res)
+			}
+		})
+	}
+}
+
+func TestMetricUpdateService_Update_Transactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrInt64 := func(i int64) *int64 {
+		return &i
+	}
+
+	ptrFloat64 := func(f float64) *float64 {
+		return &f
+	}
+
+	tests := []struct {
+		name    string
+		metrics []*types.Metrics
+		setup   func(saver *MockMetricUpdateSaver, incrementer *MockMetricUpdateIncrementer, tx *MockMetricUpdateTransactor)
+		wantErr bool
+	}{
+		{
+			name: "batch runs inside transaction",
+			metrics: []*types.Metrics{
+				{ID: "c", Type: types.Counter, Delta: ptrInt64(1)},
+				{ID: "c", Type: types.Counter, Delta: ptrInt64(2)},
+				{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+			},
+			setup: func(saver *MockMetricUpdateSaver, incrementer *MockMetricUpdateIncrementer, tx *MockMetricUpdateTransactor) {
+				tx.EXPECT().Do(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					})
+				gomock.InOrder(
+					incrementer.EXPECT().Increment(gomock.Any(), types.MetricID{ID: "c", Type: types.Counter}, int64(1)).
+						Return(int64(1), nil),
+					incrementer.EXPECT().Increment(gomock.Any(), types.MetricID{ID: "c", Type: types.Counter}, int64(2)).
+						Return(int64(3), nil),
+					saver.EXPECT().Save(gomock.Any(), types.Metrics{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)}).Return(nil),
+				)
+			},
+		},
+		{
+			name: "batch is applied in ascending order of metric IDs",
+			metrics: []*types.Metrics{
+				{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+				{ID: "c", Type: types.Counter, Delta: ptrInt64(1)},
+				{ID: "g", Type: types.Gauge, Value: ptrFloat64(2.5)},
+			},
+			setup: func(saver *MockMetricUpdateSaver, incrementer *MockMetricUpdateIncrementer, tx *MockMetricUpdateTransactor) {
+				tx.EXPECT().Do(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					})
+				gomock.InOrder(
+					incrementer.EXPECT().Increment(gomock.Any(), types.MetricID{ID: "c", Type: types.Counter}, int64(1)).
+						Return(int64(1), nil),
+					saver.EXPECT().Save(gomock.Any(), types.Metrics{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)}).Return(nil),
+					saver.EXPECT().Save(gomock.Any(), types.Metrics{ID: "g", Type: types.Gauge, Value: ptrFloat64(2.5)}).Return(nil),
+				)
+			},
+		},
+		{
+			name: "single metric runs without transaction",
+			metrics: []*types.Metrics{
+				{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+			},
+			setup: func(saver *MockMetricUpdateSaver, incrementer *MockMetricUpdateIncrementer, tx *MockMetricUpdateTransactor) {
+				saver.EXPECT().Save(gomock.Any(), types.Metrics{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)}).Return(nil)
+			},
+		},
+		{
+			name: "error inside transaction is returned",
+			metrics: []*types.Metrics{
+				{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+				{ID: "h", Type: types.Gauge, Value: ptrFloat64(2.5)},
+			},
+			setup: func(saver *MockMetricUpdateSaver, incrementer *MockMetricUpdateIncrementer, tx *MockMetricUpdateTransactor) {
+				tx.EXPECT().Do(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					})
+				gomock.InOrder(
+					saver.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil),
+					saver.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("save error")),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "transaction commit error",
+			metrics: []*types.Metrics{
+				{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+				{ID: "h", Type: types.Gauge, Value: ptrFloat64(2.5)},
+			},
+			setup: func(saver *MockMetricUpdateSaver, incrementer *MockMetricUpdateIncrementer, tx *MockMetricUpdateTransactor) {
+				tx.EXPECT().Do(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						if err := fn(ctx); err != nil {
+							return err
+						}
+						return errors.New("commit error")
+					})
+				saver.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saver := NewMockMetricUpdateSaver(ctrl)
+			upserter := NewMockMetricUpdateUpserter(ctrl)
+			incrementer := NewMockMetricUpdateIncrementer(ctrl)
+			tx := NewMockMetricUpdateTransactor(ctrl)
+			tt.setup(saver, incrementer, tx)
+
+			svc := NewMetricUpdateService(saver, upserter, incrementer, tx, nil)
+
+			res, err := svc.Update(context.Background(), tt.metrics)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, res, len(tt.metrics))
+			}
+		})
+	}
+}
+
+func TestMetricUpdateService_Update_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrInt64 := func(i int64) *int64 {
+		return &i
+	}
+
+	ptrFloat64 := func(f float64) *float64 {
+		return &f
+	}
+
+	tests := []struct {
+		name    string
+		saveErr error
+		histErr error
+		wantErr bool
+		wantApp bool
+	}{
+		{
+			name:    "applied batch is appended to history",
+			wantApp: true,
+		},
+		{
+			name:    "history error does not fail the update",
+			histErr: errors.New("history error"),
+			wantApp: true,
+		},
+		{
+			name:    "failed batch is not appended to history",
+			saveErr: errors.New("save error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saver := NewMockMetricUpdateSaver(ctrl)
+			upserter := NewMockMetricUpdateUpserter(ctrl)
+			incrementer := NewMockMetricUpdateIncrementer(ctrl)
+			history := NewMockMetricUpdateHistoryAppender(ctrl)
+
+			incrementer.EXPECT().Increment(gomock.Any(), types.MetricID{ID: "c", Type: types.Counter}, int64(1)).
+				Return(int64(6), nil)
+			saver.EXPECT().Save(gomock.Any(), gomock.Any()).Return(tt.saveErr)
+			if tt.wantApp {
+				history.EXPECT().Append(gomock.Any(), []types.Metrics{
+					{ID: "c", Type: types.Counter, Delta: ptrInt64(6)},
+					{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+				}, gomock.Any()).Return(tt.histErr)
+			}
+
+			svc := NewMetricUpdateService(saver, upserter, incrementer, nil, history)
+
+			_, err := svc.Update(context.Background(), []*types.Metrics{
+				{ID: "c", Type: types.Counter, Delta: ptrInt64(1)},
+				{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
 		})
 	}
 }
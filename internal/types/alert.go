@@ -0,0 +1,189 @@
+package types
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+)
+
+const (
+	// AlertInactive means the rule condition is not met.
+	AlertInactive = "inactive"
+	// AlertPending means the rule condition is met but has not held for the required duration yet.
+	AlertPending = "pending"
+	// AlertFiring means the rule condition has held for at least the required duration.
+	AlertFiring = "firing"
+	// AlertResolved means the rule was firing and its condition is no longer met.
+	AlertResolved = "resolved"
+)
+
+const (
+	// AlertFunctionValue evaluates the stored metric value as is.
+	AlertFunctionValue = "value"
+	// AlertFunctionRate evaluates the per-second rate of change of a counter.
+	AlertFunctionRate = "rate"
+)
+
+// AlertRule describes a threshold condition over a single stored metric.
+//
+// Rules are written as expressions of the form
+//
+//	<type> <name>[{<labels>}] [rate] <operator> <threshold>[unit] [for <duration>]
+//
+// for example `gauge HeapAlloc > 500MB for 2m`, `counter PollCount rate == 0 for 1m`
+// or `gauge HeapAlloc{host=a,env=prod} > 500MB`.
+type AlertRule struct {
+	Name      string        `json:"name"`      // Unique rule name
+	Expr      string        `json:"expr"`      // Original rule expression
+	MetricID  MetricID      `json:"metric"`    // Metric the rule is evaluated against
+	Function  string        `json:"function"`  // AlertFunctionValue or AlertFunctionRate
+	Operator  string        `json:"operator"`  // Comparison operator
+	Threshold float64       `json:"threshold"` // Threshold the metric is compared with
+	For       time.Duration `json:"for"`       // How long the condition must hold before firing
+}
+
+// Alert is the current evaluation state of an alert rule.
+type Alert struct {
+	Rule        string     `json:"rule"`                   // Rule name
+	Expr        string     `json:"expr"`                   // Rule expression
+	MetricID    MetricID   `json:"metric"`                 // Metric the rule is evaluated against
+	State       string     `json:"state"`                  // One of the Alert* states
+	Value       *float64   `json:"value,omitempty"`        // Last evaluated value, if known
+	ActiveSince *time.Time `json:"active_since,omitempty"` // When the condition started to hold
+	FiredAt     *time.Time `json:"fired_at,omitempty"`     // When the alert started firing
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`  // When the firing alert was resolved
+}
+
+// alertUnits maps threshold suffixes to their multipliers. Byte sizes are binary multiples.
+var alertUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"TB", 1 << 40},
+	{"B", 1},
+}
+
+// NewAlertRule parses a rule expression into an AlertRule with the given name.
+//
+// The metric type must be gauge or counter, and the rate function is only
+// allowed for counters. Supported operators are >, >=, <, <=, == and !=.
+// Thresholds may carry a byte size suffix (B, KB, MB, GB, TB).
+// The metric name may be followed by labels selecting the series, written as
+// comma-separated key=value pairs in braces.
+// Returns an error wrapping ErrAlertRuleInvalid if the expression is malformed.
+func NewAlertRule(name string, expr string) (*AlertRule, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("%w: name is required", internalErrors.ErrAlertRuleInvalid)
+	}
+
+	fields := strings.Fields(expr)
+	rule := &AlertRule{Name: name, Expr: strings.Join(fields, " "), Function: AlertFunctionValue}
+
+	if n := len(fields); n >= 2 && fields[n-2] == "for" {
+		d, err := time.ParseDuration(fields[n-1])
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%w: %s: invalid duration %q", internalErrors.ErrAlertRuleInvalid, name, fields[n-1])
+		}
+		rule.For = d
+		fields = fields[:n-2]
+	}
+
+	if len(fields) == 5 && fields[2] == AlertFunctionRate {
+		rule.Function = AlertFunctionRate
+		fields = append(fields[:2], fields[3:]...)
+	}
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("%w: %s: expected \"<type> <name> [rate] <operator> <threshold> [for <duration>]\"", internalErrors.ErrAlertRuleInvalid, name)
+	}
+
+	rule.MetricID = MetricID{Type: fields[0], ID: fields[1]}
+	if id, selector, ok := strings.Cut(fields[1], "{"); ok {
+		labels, valid := ParseLabels(strings.TrimSuffix(selector, "}"))
+		if !valid || !strings.HasSuffix(selector, "}") || id == "" {
+			return nil, fmt.Errorf("%w: %s: invalid labels %q", internalErrors.ErrAlertRuleInvalid, name, fields[1])
+		}
+		rule.MetricID.ID, rule.MetricID.Labels = id, labels
+	}
+	switch rule.MetricID.Type {
+	case Gauge:
+		if rule.Function == AlertFunctionRate {
+			return nil, fmt.Errorf("%w: %s: rate is only supported for counters", internalErrors.ErrAlertRuleInvalid, name)
+		}
+	case Counter:
+	default:
+		return nil, fmt.Errorf("%w: %s: unknown metric type %q", internalErrors.ErrAlertRuleInvalid, name, rule.MetricID.Type)
+	}
+
+	switch fields[2] {
+	case ">", ">=", "<", "<=", "==", "!=":
+		rule.Operator = fields[2]
+	default:
+		return nil, fmt.Errorf("%w: %s: unknown operator %q", internalErrors.ErrAlertRuleInvalid, name, fields[2])
+	}
+
+	threshold, err := parseAlertThreshold(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: invalid threshold %q", internalErrors.ErrAlertRuleInvalid, name, fields[3])
+	}
+	rule.Threshold = threshold
+
+	return rule, nil
+}
+
+// parseAlertThreshold parses a number with an optional byte size suffix.
+func parseAlertThreshold(s string) (float64, error) {
+	multiplier := 1.0
+	for _, unit := range alertUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSuffix(s, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return v * multiplier, nil
+}
+
+// Holds reports whether the given value satisfies the rule condition.
+func (r *AlertRule) Holds(value float64) bool {
+	switch r.Operator {
+	case ">":
+		return value > r.Threshold
+	case ">=":
+		return value >= r.Threshold
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	case "==":
+		return value == r.Threshold
+	case "!=":
+		return value != r.Threshold
+	}
+	return false
+}
+
+// newAlertsHTML generates an HTML section listing the provided alerts.
+// Each alert is escaped properly to prevent HTML injection.
+func newAlertsHTML(alerts []Alert) string {
+	htmlStr := "<h1>Alerts</h1>"
+	htmlStr += "<ul>"
+
+	for _, alert := range alerts {
+		htmlStr += "<li>" + html.EscapeString(alert.Rule) + " [" + html.EscapeString(alert.State) + "]: " +
+			html.EscapeString(alert.Expr) + "</li>"
+	}
+
+	htmlStr += "</ul>"
+	return htmlStr
+}
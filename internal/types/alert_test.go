@@ -0,0 +1,128 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAlertRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		expr    string
+		want    *types.AlertRule
+		wantErr bool
+	}{
+		{
+			name: "gauge threshold with unit and duration",
+			rule: "HighHeap",
+			expr: "gauge HeapAlloc > 500MB for 2m",
+			want: &types.AlertRule{
+				Name:      "HighHeap",
+				Expr:      "gauge HeapAlloc > 500MB for 2m",
+				MetricID:  types.MetricID{ID: "HeapAlloc", Type: types.Gauge},
+				Function:  types.AlertFunctionValue,
+				Operator:  ">",
+				Threshold: 500 << 20,
+				For:       2 * time.Minute,
+			},
+		},
+		{
+			name: "counter rate",
+			rule: "Stalled",
+			expr: "counter  PollCount rate == 0 for 1m",
+			want: &types.AlertRule{
+				Name:      "Stalled",
+				Expr:      "counter PollCount rate == 0 for 1m",
+				MetricID:  types.MetricID{ID: "PollCount", Type: types.Counter},
+				Function:  types.AlertFunctionRate,
+				Operator:  "==",
+				Threshold: 0,
+				For:       time.Minute,
+			},
+		},
+		{
+			name: "without duration",
+			rule: "LowMemory",
+			expr: "gauge FreeMemory <= 1.5GB",
+			want: &types.AlertRule{
+				Name:      "LowMemory",
+				Expr:      "gauge FreeMemory <= 1.5GB",
+				MetricID:  types.MetricID{ID: "FreeMemory", Type: types.Gauge},
+				Function:  types.AlertFunctionValue,
+				Operator:  "<=",
+				Threshold: 1.5 * (1 << 30),
+			},
+		},
+		{
+			name: "labels select the series",
+			rule: "HighMemoryA",
+			expr: "gauge HeapAlloc{host=a,env=prod} > 1KB",
+			want: &types.AlertRule{
+				Name: "HighMemoryA",
+				Expr: "gauge HeapAlloc{host=a,env=prod} > 1KB",
+				MetricID: types.MetricID{
+					ID:     "HeapAlloc",
+					Type:   types.Gauge,
+					Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"}),
+				},
+				Function:  types.AlertFunctionValue,
+				Operator:  ">",
+				Threshold: 1 << 10,
+			},
+		},
+		{name: "missing name", rule: "", expr: "gauge A > 1", wantErr: true},
+		{name: "malformed labels", rule: "r", expr: "gauge A{host} > 1", wantErr: true},
+		{name: "unclosed labels", rule: "r", expr: "gauge A{host=a > 1", wantErr: true},
+		{name: "unknown type", rule: "r", expr: "histogram A > 1", wantErr: true},
+		{name: "rate on gauge", rule: "r", expr: "gauge A rate > 1", wantErr: true},
+		{name: "unknown operator", rule: "r", expr: "gauge A => 1", wantErr: true},
+		{name: "invalid threshold", rule: "r", expr: "gauge A > lots", wantErr: true},
+		{name: "invalid duration", rule: "r", expr: "gauge A > 1 for ever", wantErr: true},
+		{name: "too few fields", rule: "r", expr: "gauge A >", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := types.NewAlertRule(tt.rule, tt.expr)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, internalErrors.ErrAlertRuleInvalid)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAlertRule_Holds(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    float64
+		want     bool
+	}{
+		{">", 2, true},
+		{">", 1, false},
+		{">=", 1, true},
+		{"<", 0, true},
+		{"<", 1, false},
+		{"<=", 1, true},
+		{"==", 1, true},
+		{"==", 2, false},
+		{"!=", 2, true},
+		{"!=", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operator, func(t *testing.T) {
+			rule := types.AlertRule{Operator: tt.operator, Threshold: 1}
+			assert.Equal(t, tt.want, rule.Holds(tt.value))
+		})
+	}
+}
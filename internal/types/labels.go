@@ -0,0 +1,132 @@
+package types
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// labelValueEscaper escapes label values the way the Prometheus text format does.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Labels is a set of key/value pairs, such as host or env, that is part of a metric's identity.
+//
+// Labels are kept in a canonical form, the JSON object with keys in sorted order,
+// so equal sets compare equal and MetricID can be used as a map key.
+// The zero value is the empty set.
+type Labels string
+
+// NewLabels creates Labels from the given key/value pairs.
+// An empty or nil map gives the empty set.
+func NewLabels(pairs map[string]string) Labels {
+	if len(pairs) == 0 {
+		return ""
+	}
+	// encoding/json writes map keys in sorted order, which makes the result canonical
+	data, _ := json.Marshal(pairs)
+	return Labels(data)
+}
+
+// ParseLabels parses labels written as comma-separated key=value pairs, such as "env=prod,service=api".
+// Surrounding whitespace is trimmed and empty items are skipped. Later pairs override earlier ones.
+// Returns false if an item is not a key=value pair.
+func ParseLabels(s string) (Labels, bool) {
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return "", false
+		}
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return NewLabels(pairs), true
+}
+
+// Map returns the key/value pairs of the labels. The map is nil for the empty set.
+func (l Labels) Map() map[string]string {
+	if l == "" {
+		return nil
+	}
+	var pairs map[string]string
+	json.Unmarshal([]byte(l), &pairs)
+	return pairs
+}
+
+// With returns the labels with the given pairs added, replacing existing values of the same keys.
+func (l Labels) With(pairs map[string]string) Labels {
+	merged := l.Map()
+	if merged == nil {
+		merged = make(map[string]string, len(pairs))
+	}
+	for k, v := range pairs {
+		merged[k] = v
+	}
+	return NewLabels(merged)
+}
+
+// Matches reports whether the labels contain every pair of the given filter.
+// The empty filter matches any labels.
+func (l Labels) Matches(filter Labels) bool {
+	if filter == "" || filter == l {
+		return true
+	}
+	pairs := l.Map()
+	for k, v := range filter.Map() {
+		if got, ok := pairs[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the labels as in the Prometheus text format, such as `{env="prod",host="a"}`.
+// The empty set is formatted as an empty string.
+func (l Labels) String() string {
+	pairs := l.Map()
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteByte('"')
+		sb.WriteString(labelValueEscaper.Replace(pairs[k]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// MarshalJSON encodes the labels as a JSON object.
+func (l Labels) MarshalJSON() ([]byte, error) {
+	if l == "" {
+		return []byte("{}"), nil
+	}
+	return []byte(l), nil
+}
+
+// UnmarshalJSON decodes the labels from a JSON object of string values, or null.
+func (l *Labels) UnmarshalJSON(data []byte) error {
+	var pairs map[string]string
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return err
+	}
+	*l = NewLabels(pairs)
+	return nil
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLabels(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs map[string]string
+		want  Labels
+	}{
+		{name: "nil", pairs: nil, want: ""},
+		{name: "empty", pairs: map[string]string{}, want: ""},
+		{name: "keys are sorted", pairs: map[string]string{"host": "a", "env": "prod"}, want: `{"env":"prod","host":"a"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewLabels(tt.pairs)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.pairs), len(got.Map()))
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   Labels
+		wantOk bool
+	}{
+		{name: "empty", in: "", want: "", wantOk: true},
+		{name: "pairs", in: " service=api , env=prod,", want: NewLabels(map[string]string{"service": "api", "env": "prod"}), wantOk: true},
+		{name: "later pair wins", in: "env=dev,env=prod", want: NewLabels(map[string]string{"env": "prod"}), wantOk: true},
+		{name: "value with equals sign", in: "query=a=b", want: NewLabels(map[string]string{"query": "a=b"}), wantOk: true},
+		{name: "not a pair", in: "env", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseLabels(tt.in)
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLabels_With(t *testing.T) {
+	base := NewLabels(map[string]string{"env": "prod", "host": "a"})
+
+	assert.Equal(t, NewLabels(map[string]string{"env": "prod", "host": "b", "zone": "1"}),
+		base.With(map[string]string{"host": "b", "zone": "1"}))
+	assert.Equal(t, NewLabels(map[string]string{"host": "a"}), Labels("").With(map[string]string{"host": "a"}))
+	assert.Equal(t, Labels(""), Labels("").With(nil))
+}
+
+func TestLabels_Matches(t *testing.T) {
+	labels := NewLabels(map[string]string{"env": "prod", "host": "a"})
+
+	tests := []struct {
+		name   string
+		filter Labels
+		want   bool
+	}{
+		{name: "empty filter", filter: "", want: true},
+		{name: "same labels", filter: labels, want: true},
+		{name: "subset", filter: NewLabels(map[string]string{"host": "a"}), want: true},
+		{name: "different value", filter: NewLabels(map[string]string{"host": "b"}), want: false},
+		{name: "missing key", filter: NewLabels(map[string]string{"zone": "1"}), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, labels.Matches(tt.filter))
+		})
+	}
+
+	assert.False(t, Labels("").Matches(NewLabels(map[string]string{"host": "a"})))
+}
+
+func TestLabels_String(t *testing.T) {
+	assert.Equal(t, "", Labels("").String())
+	assert.Equal(t, `{env="prod",host="a"}`, NewLabels(map[string]string{"host": "a", "env": "prod"}).String())
+	assert.Equal(t, `{path="C:\\dir \"x\"\n"}`, NewLabels(map[string]string{"path": "C:\\dir \"x\"\n"}).String())
+}
+
+func TestLabels_JSON(t *testing.T) {
+	m := Metrics{ID: "Alloc", Type: Gauge, Labels: NewLabels(map[string]string{"host": "a", "env": "prod"})}
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"Alloc","type":"gauge","labels":{"env":"prod","host":"a"}}`, string(data))
+
+	var decoded Metrics
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"Alloc","type":"gauge","labels":{"host":"a","env":"prod"}}`), &decoded))
+	assert.Equal(t, m, decoded)
+
+	// Metrics without labels keep their previous encoding
+	data, err = json.Marshal(Metrics{ID: "Alloc", Type: Gauge})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"Alloc","type":"gauge"}`, string(data))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"Alloc","type":"gauge","labels":null}`), &decoded))
+	assert.Equal(t, Labels(""), decoded.Labels)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"id":"Alloc","type":"gauge","labels":{"host":1}}`), &decoded))
+}
@@ -0,0 +1,6 @@
+package types
+
+// LogLevel is the log level served and accepted by the log level admin endpoint.
+type LogLevel struct {
+	Level string `json:"level"` // One of zap's levels, e.g. debug, info, warn or error
+}
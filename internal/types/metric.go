@@ -2,7 +2,10 @@ package types
 
 import (
 	"html"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -10,12 +13,17 @@ const (
 	Counter = "counter"
 	// Gauge represents a metric type for floating-point gauges.
 	Gauge = "gauge"
+	// Histogram represents a metric type counting observations in configurable buckets.
+	Histogram = "histogram"
+	// Summary represents a metric type with quantiles of observations computed by the client.
+	Summary = "summary"
 )
 
-// MetricID uniquely identifies a metric by its type and name.
+// MetricID uniquely identifies a metric by its type, name and labels.
 type MetricID struct {
-	ID   string `json:"id"`   // Metric name or identifier
-	Type string `json:"type"` // Metric type, e.g. "counter" or "gauge"
+	ID     string `json:"id"`               // Metric name or identifier
+	Type   string `json:"type"`             // Metric type, e.g. "counter" or "gauge"
+	Labels Labels `json:"labels,omitempty"` // Optional labels, e.g. host or env
 }
 
 // NewMetricID creates a new MetricID given a metric type and name.
@@ -29,16 +37,38 @@ func NewMetricID(
 	}
 }
 
-// Metrics represents a metric with its ID, type, and value(s).
+// Compare orders metric IDs by name, then type, then labels. It returns a negative number
+// if id comes before other, a positive one if it comes after it and zero if they are equal.
+//
+// Storages locking several metrics at once lock them in this order, so they cannot deadlock.
+func (id MetricID) Compare(other MetricID) int {
+	if c := strings.Compare(id.ID, other.ID); c != 0 {
+		return c
+	}
+	if c := strings.Compare(id.Type, other.Type); c != 0 {
+		return c
+	}
+	return strings.Compare(string(id.Labels), string(other.Labels))
+}
+
+// Metrics represents a metric with its ID, type, labels and value(s).
+// Metrics with the same ID and type but different labels are distinct.
 // For counter metrics, Delta holds an integer count.
 // For gauge metrics, Value holds a floating-point measurement.
+// For histogram and summary metrics, Sum and Count hold the sum and the number of observations,
+// and Buckets or Quantiles hold their distribution.
 // Hash can be used for data integrity or verification.
 type Metrics struct {
-	ID    string   `json:"id"`
-	Type  string   `json:"type"`
-	Delta *int64   `json:"delta,omitempty"`
-	Value *float64 `json:"value,omitempty"`
-	Hash  string   `json:"hash,omitempty"`
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	Labels    Labels           `json:"labels,omitempty"`
+	Delta     *int64           `json:"delta,omitempty"`
+	Value     *float64         `json:"value,omitempty"`
+	Sum       *float64         `json:"sum,omitempty"`
+	Count     *int64           `json:"count,omitempty"`
+	Buckets   HistogramBuckets `json:"buckets,omitempty"`
+	Quantiles SummaryQuantiles `json:"quantiles,omitempty"`
+	Hash      string           `json:"hash,omitempty"`
 }
 
 // NewMetric constructs a new Metrics instance from the provided type, name, and value string.
@@ -67,31 +97,18 @@ func NewMetric(
 	return m
 }
 
-// NewMetricsHTML generates an HTML page listing the provided metrics.
-// Each metric is escaped properly to prevent HTML injection.
-func NewMetricsHTML(metrics []Metrics) string {
+// NewMetricsHTML generates an HTML page listing the provided metrics,
+// followed by the state of the provided alerts, if any.
+// Each metric and alert is escaped properly to prevent HTML injection.
+func NewMetricsHTML(metrics []Metrics, alerts []Alert) string {
 	htmlStr := "<html><head><title>Metrics List</title></head><body>"
 	htmlStr += "<h1>Metrics</h1>"
 	htmlStr += "<ul>"
 
 	for _, metric := range metrics {
-		name := html.EscapeString(metric.ID)
-		var value string
-
-		switch metric.Type {
-		case Gauge:
-			if metric.Value == nil {
-				value = "N/A"
-			} else {
-				value = strconv.FormatFloat(*metric.Value, 'f', -1, 64)
-			}
-		case Counter:
-			if metric.Delta == nil {
-				value = "N/A"
-			} else {
-				value = strconv.FormatInt(*metric.Delta, 10)
-			}
-		default:
+		name := html.EscapeString(metric.ID + metric.Labels.String())
+		value := GetMetricsStringValue(&metric)
+		if value == "" {
 			value = "N/A"
 		}
 
@@ -99,11 +116,156 @@ func NewMetricsHTML(metrics []Metrics) string {
 		htmlStr += "<li>" + name + ": " + value + "</li>"
 	}
 
-	htmlStr += "</ul></body></html>"
+	htmlStr += "</ul>"
+
+	if len(alerts) > 0 {
+		htmlStr += newAlertsHTML(alerts)
+	}
+
+	htmlStr += "</body></html>"
 	return htmlStr
 }
 
+// NewMetricsPrometheus renders the provided metrics in the Prometheus text exposition format.
+//
+// Counters are exposed as `counter` and gauges as `gauge`. Histograms are exposed with
+// their `_bucket`, `_sum` and `_count` series, and summaries with a series per quantile
+// followed by `_sum` and `_count`. Series sharing a name are
+// grouped under a single `# TYPE` line and told apart by their labels.
+// Metric names are sanitized to the Prometheus charset; if a sanitized name is already
+// taken by a metric of another type, the type is appended to keep the names distinct.
+// Metrics of unknown type or without a value are skipped, as are later metrics whose
+// sanitized name, type and labels duplicate an earlier one. Output is sorted by name.
+func NewMetricsPrometheus(metrics []Metrics) string {
+	sorted := make([]Metrics, len(metrics))
+	copy(sorted, metrics)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ID != sorted[j].ID {
+			return sorted[i].ID < sorted[j].ID
+		}
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type < sorted[j].Type
+		}
+		return sorted[i].Labels < sorted[j].Labels
+	})
+
+	seen := make(map[string]string) // sanitized name -> metric type
+	series := make(map[string]bool) // sanitized name and labels of written samples
+	current := ""                   // name of the group being written
+	var sb strings.Builder
+
+	for _, metric := range sorted {
+		if !hasPrometheusValue(metric) {
+			continue
+		}
+
+		name := sanitizePrometheusName(metric.ID)
+		if t, ok := seen[name]; ok && t != metric.Type {
+			name += "_" + metric.Type
+		}
+		sample := name + metric.Labels.String()
+
+		if _, ok := seen[name]; ok && (name != current || series[sample]) {
+			// Samples of a name must not be split across groups
+			continue
+		}
+		if name != current {
+			seen[name] = metric.Type
+			current = name
+			sb.WriteString("# TYPE " + name + " " + metric.Type + "\n")
+		}
+		series[sample] = true
+
+		writePrometheusSamples(&sb, name, metric)
+	}
+
+	return sb.String()
+}
+
+// hasPrometheusValue reports whether the metric is of a known type and has a value to expose.
+func hasPrometheusValue(metric Metrics) bool {
+	switch metric.Type {
+	case Gauge:
+		return metric.Value != nil
+	case Counter:
+		return metric.Delta != nil
+	case Histogram, Summary:
+		return metric.Sum != nil && metric.Count != nil
+	}
+	return false
+}
+
+// writePrometheusSamples writes the samples of the metric under the given sanitized name.
+func writePrometheusSamples(sb *strings.Builder, name string, metric Metrics) {
+	writeSample := func(name string, labels Labels, value string) {
+		sb.WriteString(name + labels.String() + " " + value + "\n")
+	}
+
+	switch metric.Type {
+	case Gauge:
+		writeSample(name, metric.Labels, formatPrometheusFloat(*metric.Value))
+	case Counter:
+		writeSample(name, metric.Labels, strconv.FormatInt(*metric.Delta, 10))
+	case Histogram:
+		for _, b := range metric.Buckets {
+			le := metric.Labels.With(map[string]string{"le": formatPrometheusFloat(b.UpperBound)})
+			writeSample(name+"_bucket", le, strconv.FormatInt(b.Count, 10))
+		}
+		inf := metric.Labels.With(map[string]string{"le": "+Inf"})
+		writeSample(name+"_bucket", inf, strconv.FormatInt(*metric.Count, 10))
+		writeSample(name+"_sum", metric.Labels, formatPrometheusFloat(*metric.Sum))
+		writeSample(name+"_count", metric.Labels, strconv.FormatInt(*metric.Count, 10))
+	case Summary:
+		for _, q := range metric.Quantiles {
+			quantile := metric.Labels.With(map[string]string{"quantile": formatPrometheusFloat(q.Quantile)})
+			writeSample(name, quantile, formatPrometheusFloat(q.Value))
+		}
+		writeSample(name+"_sum", metric.Labels, formatPrometheusFloat(*metric.Sum))
+		writeSample(name+"_count", metric.Labels, strconv.FormatInt(*metric.Count, 10))
+	}
+}
+
+// sanitizePrometheusName replaces every character outside the Prometheus metric name
+// charset [a-zA-Z_:][a-zA-Z0-9_:]* with an underscore.
+func sanitizePrometheusName(name string) string {
+	if name == "" {
+		return "_"
+	}
+
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// formatPrometheusFloat formats a float value, using the Prometheus spelling for special values.
+func formatPrometheusFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
 // GetMetricsStringValue returns the string representation of a metric's value.
+// Histograms are formatted as "count=3 sum=1.5 buckets=0.1:1,1:2" and summaries as
+// "count=3 sum=1.5 quantiles=0.5:0.2,0.99:1.2", with buckets and quantiles written as
+// upper bound:cumulative count and quantile:value pairs.
 // Returns an empty string if the value is not set or the metric type is unknown.
 func GetMetricsStringValue(metric *Metrics) string {
 	switch metric.Type {
@@ -115,6 +277,19 @@ func GetMetricsStringValue(metric *Metrics) string {
 		if metric.Delta != nil {
 			return strconv.FormatInt(*metric.Delta, 10)
 		}
+	case Histogram:
+		if metric.Sum != nil && metric.Count != nil {
+			return formatDistribution(*metric.Count, *metric.Sum) + " buckets=" + metric.Buckets.String()
+		}
+	case Summary:
+		if metric.Sum != nil && metric.Count != nil {
+			return formatDistribution(*metric.Count, *metric.Sum) + " quantiles=" + metric.Quantiles.String()
+		}
 	}
 	return ""
 }
+
+// formatDistribution formats the number and the sum of observations of a histogram or summary.
+func formatDistribution(count int64, sum float64) string {
+	return "count=" + strconv.FormatInt(count, 10) + " sum=" + strconv.FormatFloat(sum, 'f', -1, 64)
+}
@@ -0,0 +1,148 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HistogramBucket is a single bucket of a histogram.
+// Count is cumulative: the number of observations less than or equal to UpperBound.
+// The implicit +Inf bucket is not stored, its count is the histogram count.
+type HistogramBucket struct {
+	UpperBound float64 `json:"le"`
+	Count      int64   `json:"count"`
+}
+
+// HistogramBuckets are the buckets of a histogram, sorted by upper bound.
+//
+// They are stored in SQL databases as a JSON array.
+type HistogramBuckets []HistogramBucket
+
+// SummaryQuantile is a single quantile of a summary, such as the 0.99 quantile of request durations.
+type SummaryQuantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+// SummaryQuantiles are the quantiles of a summary, sorted by quantile.
+//
+// They are stored in SQL databases as a JSON array.
+type SummaryQuantiles []SummaryQuantile
+
+// NewHistogramMetric creates a histogram metric with the given bucket upper bounds,
+// filled with the given observations. The bounds are sorted and deduplicated.
+func NewHistogramMetric(name string, bounds []float64, observations []float64) *Metrics {
+	sorted := make([]float64, len(bounds))
+	copy(sorted, bounds)
+	sort.Float64s(sorted)
+
+	buckets := make(HistogramBuckets, 0, len(sorted))
+	for i, b := range sorted {
+		if i > 0 && b == sorted[i-1] {
+			continue
+		}
+		buckets = append(buckets, HistogramBucket{UpperBound: b})
+	}
+
+	var sum float64
+	for _, v := range observations {
+		sum += v
+		// Buckets are cumulative, so the observation counts in every bucket it fits in
+		idx := sort.Search(len(buckets), func(i int) bool {
+			return v <= buckets[i].UpperBound
+		})
+		for i := idx; i < len(buckets); i++ {
+			buckets[i].Count++
+		}
+	}
+	count := int64(len(observations))
+
+	return &Metrics{ID: name, Type: Histogram, Sum: &sum, Count: &count, Buckets: buckets}
+}
+
+// SameBounds reports whether both histograms have the same bucket upper bounds.
+func (b HistogramBuckets) SameBounds(other HistogramBuckets) bool {
+	if len(b) != len(other) {
+		return false
+	}
+	for i := range b {
+		if b[i].UpperBound != other[i].UpperBound {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the buckets as comma-separated upper bound:count pairs, such as "0.1:2,1:5".
+func (b HistogramBuckets) String() string {
+	parts := make([]string, 0, len(b))
+	for _, bucket := range b {
+		parts = append(parts, strconv.FormatFloat(bucket.UpperBound, 'f', -1, 64)+":"+strconv.FormatInt(bucket.Count, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Value encodes the buckets for storing in a SQL database. Empty buckets are stored as NULL.
+func (b HistogramBuckets) Value() (driver.Value, error) {
+	return marshalSQLJSON(len(b), b)
+}
+
+// Scan decodes the buckets read from a SQL database.
+func (b *HistogramBuckets) Scan(src any) error {
+	return unmarshalSQLJSON(src, b)
+}
+
+// String formats the quantiles as comma-separated quantile:value pairs, such as "0.5:0.2,0.99:1.5".
+func (q SummaryQuantiles) String() string {
+	parts := make([]string, 0, len(q))
+	for _, quantile := range q {
+		parts = append(parts, strconv.FormatFloat(quantile.Quantile, 'f', -1, 64)+":"+strconv.FormatFloat(quantile.Value, 'f', -1, 64))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Value encodes the quantiles for storing in a SQL database. Empty quantiles are stored as NULL.
+func (q SummaryQuantiles) Value() (driver.Value, error) {
+	return marshalSQLJSON(len(q), q)
+}
+
+// Scan decodes the quantiles read from a SQL database.
+func (q *SummaryQuantiles) Scan(src any) error {
+	return unmarshalSQLJSON(src, q)
+}
+
+// marshalSQLJSON encodes v as a JSON string, or as NULL if it has no elements.
+func marshalSQLJSON(n int, v any) (driver.Value, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// unmarshalSQLJSON decodes a JSON string or byte slice read from a SQL database into dest.
+// NULL and empty values leave dest untouched.
+func unmarshalSQLJSON(src any, dest any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, dest)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, dest)
+}
@@ -0,0 +1,74 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestNewHistogramMetric(t *testing.T) {
+	m := types.NewHistogramMetric("PauseNs", []float64{100, 10, 1000, 10}, []float64{5, 10, 50, 5000})
+
+	assert.Equal(t, "PauseNs", m.ID)
+	assert.Equal(t, types.Histogram, m.Type)
+	require.NotNil(t, m.Sum)
+	require.NotNil(t, m.Count)
+	assert.Equal(t, float64(5065), *m.Sum)
+	assert.Equal(t, int64(4), *m.Count)
+	assert.Equal(t, types.HistogramBuckets{
+		{UpperBound: 10, Count: 2},
+		{UpperBound: 100, Count: 3},
+		{UpperBound: 1000, Count: 3},
+	}, m.Buckets)
+}
+
+func TestNewHistogramMetric_NoObservations(t *testing.T) {
+	m := types.NewHistogramMetric("PauseNs", []float64{1}, nil)
+
+	assert.Equal(t, float64(0), *m.Sum)
+	assert.Equal(t, int64(0), *m.Count)
+	assert.Equal(t, types.HistogramBuckets{{UpperBound: 1}}, m.Buckets)
+}
+
+func TestHistogramBuckets_SameBounds(t *testing.T) {
+	a := types.HistogramBuckets{{UpperBound: 1, Count: 1}, {UpperBound: 2, Count: 3}}
+
+	assert.True(t, a.SameBounds(types.HistogramBuckets{{UpperBound: 1}, {UpperBound: 2}}))
+	assert.False(t, a.SameBounds(types.HistogramBuckets{{UpperBound: 1}}))
+	assert.False(t, a.SameBounds(types.HistogramBuckets{{UpperBound: 1}, {UpperBound: 3}}))
+}
+
+func TestHistogramBuckets_SQL(t *testing.T) {
+	buckets := types.HistogramBuckets{{UpperBound: 0.5, Count: 1}, {UpperBound: 1, Count: 2}}
+
+	v, err := buckets.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `[{"le":0.5,"count":1},{"le":1,"count":2}]`, v)
+
+	var scanned types.HistogramBuckets
+	require.NoError(t, scanned.Scan([]byte(v.(string))))
+	assert.Equal(t, buckets, scanned)
+
+	v, err = types.HistogramBuckets(nil).Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	var empty types.HistogramBuckets
+	require.NoError(t, empty.Scan(nil))
+	assert.Nil(t, empty)
+	assert.Error(t, empty.Scan(42))
+}
+
+func TestSummaryQuantiles_SQL(t *testing.T) {
+	quantiles := types.SummaryQuantiles{{Quantile: 0.5, Value: 0.2}, {Quantile: 0.99, Value: 1.5}}
+
+	v, err := quantiles.Value()
+	require.NoError(t, err)
+
+	var scanned types.SummaryQuantiles
+	require.NoError(t, scanned.Scan(v))
+	assert.Equal(t, quantiles, scanned)
+}
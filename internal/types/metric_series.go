@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// MetricPoint is a single sample of a metric time series.
+// Counter samples hold the accumulated counter value at the time of the update.
+type MetricPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// MetricSeries is the time series of a single metric over a time range.
+type MetricSeries struct {
+	ID     string        `json:"id"`
+	Type   string        `json:"type"`
+	Labels Labels        `json:"labels,omitempty"`
+	Points []MetricPoint `json:"points"`
+}
+
+// NewMetricPoint converts a metric value into a time series sample taken at the given time.
+// It returns false if the metric is of an unknown type or has no value.
+func NewMetricPoint(m Metrics, at time.Time) (MetricPoint, bool) {
+	switch {
+	case m.Type == Gauge && m.Value != nil:
+		return MetricPoint{Timestamp: at, Value: *m.Value}, true
+	case m.Type == Counter && m.Delta != nil:
+		return MetricPoint{Timestamp: at, Value: float64(*m.Delta)}, true
+	}
+	return MetricPoint{}, false
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetricPoint(t *testing.T) {
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	value := 1.5
+	delta := int64(42)
+
+	tests := []struct {
+		name   string
+		metric Metrics
+		want   MetricPoint
+		wantOk bool
+	}{
+		{
+			name:   "gauge",
+			metric: Metrics{ID: "g", Type: Gauge, Value: &value},
+			want:   MetricPoint{Timestamp: at, Value: 1.5},
+			wantOk: true,
+		},
+		{
+			name:   "counter",
+			metric: Metrics{ID: "c", Type: Counter, Delta: &delta},
+			want:   MetricPoint{Timestamp: at, Value: 42},
+			wantOk: true,
+		},
+		{
+			name:   "gauge without value",
+			metric: Metrics{ID: "g", Type: Gauge},
+		},
+		{
+			name:   "unknown type",
+			metric: Metrics{ID: "x", Type: "histogram", Value: &value},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewMetricPoint(tt.metric, at)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
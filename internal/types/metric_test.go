@@ -27,6 +27,18 @@ func TestNewMetricID(t *testing.T) {
 	}
 }
 
+func TestMetricID_Compare(t *testing.T) {
+	a := types.MetricID{ID: "a", Type: types.Gauge}
+	b := types.MetricID{ID: "b", Type: types.Counter}
+	labeled := types.MetricID{ID: "a", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "x"})}
+
+	assert.Zero(t, a.Compare(a))
+	assert.Negative(t, a.Compare(b))
+	assert.Positive(t, b.Compare(a))
+	assert.Negative(t, types.MetricID{ID: "a", Type: types.Counter}.Compare(a))
+	assert.Negative(t, a.Compare(labeled))
+}
+
 func TestNewMetric(t *testing.T) {
 	tests := []struct {
 		metricType  string
@@ -105,8 +117,14 @@ func TestNewMetricsHTML(t *testing.T) {
 				{ID: "unknown", Type: "unknown"},
 				{ID: "gauge_nil", Type: types.Gauge},
 				{ID: "counter_nil", Type: types.Counter},
+				{ID: "gauge1", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a"}), Value: &gv},
+				{ID: "hist1", Type: types.Histogram, Sum: &gv, Count: &cv, Buckets: types.HistogramBuckets{{UpperBound: 1, Count: 5}}},
+				{ID: "hist_nil", Type: types.Histogram},
 			},
 			expected: []string{
+				"<li>hist1: count=56 sum=12.34 buckets=1:5</li>",
+				"<li>hist_nil: N/A</li>",
+				"<li>gauge1{host=&#34;a&#34;}: 12.34</li>",
 				"<li>gauge1: 12.34</li>",
 				"<li>counter1: 56</li>",
 				"<li>unknown: N/A</li>",
@@ -118,16 +136,143 @@ func TestNewMetricsHTML(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			html := types.NewMetricsHTML(tt.metrics)
+			html := types.NewMetricsHTML(tt.metrics, nil)
 			assert.Contains(t, html, "<html>")
 			for _, exp := range tt.expected {
 				assert.Contains(t, html, exp)
 			}
+			assert.NotContains(t, html, "<h1>Alerts</h1>")
 			assert.Contains(t, html, "</html>")
 		})
 	}
 }
 
+func TestNewMetricsHTML_Alerts(t *testing.T) {
+	alerts := []types.Alert{
+		{Rule: "HighHeap", Expr: "gauge HeapAlloc > 500MB for 2m", State: types.AlertFiring},
+		{Rule: "<script>", Expr: "gauge A > 1", State: types.AlertInactive},
+	}
+
+	html := types.NewMetricsHTML(nil, alerts)
+
+	assert.Contains(t, html, "<h1>Alerts</h1>")
+	assert.Contains(t, html, "<li>HighHeap [firing]: gauge HeapAlloc &gt; 500MB for 2m</li>")
+	assert.Contains(t, html, "<li>&lt;script&gt; [inactive]: gauge A &gt; 1</li>")
+	assert.Contains(t, html, "</html>")
+}
+
+func TestNewMetricsPrometheus(t *testing.T) {
+	gv := 12.5
+	cv := int64(56)
+	big := 1e21
+
+	tests := []struct {
+		name     string
+		metrics  []types.Metrics
+		expected string
+	}{
+		{
+			name:     "empty",
+			metrics:  nil,
+			expected: "",
+		},
+		{
+			name: "counters and gauges sorted by name",
+			metrics: []types.Metrics{
+				{ID: "PollCount", Type: types.Counter, Delta: &cv},
+				{ID: "Alloc", Type: types.Gauge, Value: &gv},
+			},
+			expected: "# TYPE Alloc gauge\nAlloc 12.5\n" +
+				"# TYPE PollCount counter\nPollCount 56\n",
+		},
+		{
+			name: "names are sanitized",
+			metrics: []types.Metrics{
+				{ID: "cpu.usage-1", Type: types.Gauge, Value: &gv},
+				{ID: "9lives", Type: types.Counter, Delta: &cv},
+				{ID: "", Type: types.Gauge, Value: &big},
+			},
+			expected: "# TYPE _ gauge\n_ 1e+21\n" +
+				"# TYPE _9lives counter\n_9lives 56\n" +
+				"# TYPE cpu_usage_1 gauge\ncpu_usage_1 12.5\n",
+		},
+		{
+			name: "same name with different types stays distinct",
+			metrics: []types.Metrics{
+				{ID: "requests", Type: types.Gauge, Value: &gv},
+				{ID: "requests", Type: types.Counter, Delta: &cv},
+			},
+			expected: "# TYPE requests counter\nrequests 56\n" +
+				"# TYPE requests_gauge gauge\nrequests_gauge 12.5\n",
+		},
+		{
+			name: "unknown types, missing values and duplicates are skipped",
+			metrics: []types.Metrics{
+				{ID: "a.b", Type: types.Gauge, Value: &gv},
+				{ID: "a_b", Type: types.Gauge, Value: &big},
+				{ID: "unknown", Type: "unknown"},
+				{ID: "gauge_nil", Type: types.Gauge},
+				{ID: "counter_nil", Type: types.Counter},
+			},
+			expected: "# TYPE a_b gauge\na_b 12.5\n",
+		},
+		{
+			name: "series with labels are grouped under one name",
+			metrics: []types.Metrics{
+				{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "b"}), Value: &big},
+				{ID: "Alloc", Type: types.Gauge, Value: &gv},
+				{ID: "Alloc", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"}), Value: &gv},
+			},
+			expected: "# TYPE Alloc gauge\nAlloc 12.5\n" +
+				"Alloc{env=\"prod\",host=\"a\"} 12.5\n" +
+				"Alloc{host=\"b\"} 1e+21\n",
+		},
+		{
+			name: "histograms expose buckets, sum and count",
+			metrics: []types.Metrics{
+				{
+					ID:      "PauseNs",
+					Type:    types.Histogram,
+					Labels:  types.NewLabels(map[string]string{"host": "a"}),
+					Sum:     &gv,
+					Count:   &cv,
+					Buckets: types.HistogramBuckets{{UpperBound: 0.5, Count: 10}, {UpperBound: 1e6, Count: 50}},
+				},
+				{ID: "hist_nil", Type: types.Histogram},
+			},
+			expected: "# TYPE PauseNs histogram\n" +
+				"PauseNs_bucket{host=\"a\",le=\"0.5\"} 10\n" +
+				"PauseNs_bucket{host=\"a\",le=\"1e+06\"} 50\n" +
+				"PauseNs_bucket{host=\"a\",le=\"+Inf\"} 56\n" +
+				"PauseNs_sum{host=\"a\"} 12.5\n" +
+				"PauseNs_count{host=\"a\"} 56\n",
+		},
+		{
+			name: "summaries expose quantiles, sum and count",
+			metrics: []types.Metrics{
+				{
+					ID:        "latency",
+					Type:      types.Summary,
+					Sum:       &gv,
+					Count:     &cv,
+					Quantiles: types.SummaryQuantiles{{Quantile: 0.5, Value: 0.2}, {Quantile: 0.99, Value: 1.5}},
+				},
+			},
+			expected: "# TYPE latency summary\n" +
+				"latency{quantile=\"0.5\"} 0.2\n" +
+				"latency{quantile=\"0.99\"} 1.5\n" +
+				"latency_sum 12.5\n" +
+				"latency_count 56\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, types.NewMetricsPrometheus(tt.metrics))
+		})
+	}
+}
+
 func TestGetMetricsStringValue(t *testing.T) {
 	gv := 78.9
 	cv := int64(123)
@@ -142,6 +287,17 @@ func TestGetMetricsStringValue(t *testing.T) {
 		{"Gauge with nil value", &types.Metrics{Type: types.Gauge}, ""},
 		{"Counter with nil delta", &types.Metrics{Type: types.Counter}, ""},
 		{"Unknown type", &types.Metrics{Type: "unknown"}, ""},
+		{
+			"Histogram",
+			&types.Metrics{Type: types.Histogram, Sum: &gv, Count: &cv, Buckets: types.HistogramBuckets{{UpperBound: 0.5, Count: 1}, {UpperBound: 1, Count: 2}}},
+			"count=123 sum=78.9 buckets=0.5:1,1:2",
+		},
+		{
+			"Summary",
+			&types.Metrics{Type: types.Summary, Sum: &gv, Count: &cv, Quantiles: types.SummaryQuantiles{{Quantile: 0.99, Value: 1.5}}},
+			"count=123 sum=78.9 quantiles=0.99:1.5",
+		},
+		{"Histogram without count", &types.Metrics{Type: types.Histogram, Sum: &gv}, ""},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+)
+
+const (
+	// StatsDCounter is the StatsD type of counters, whose values are added up.
+	StatsDCounter = "c"
+	// StatsDGauge is the StatsD type of gauges, whose last value is kept.
+	StatsDGauge = "g"
+	// StatsDTimer is the StatsD type of timings in milliseconds.
+	StatsDTimer = "ms"
+	// StatsDHistogram is the StatsD type of arbitrary observations, handled like timings.
+	StatsDHistogram = "h"
+)
+
+// StatsDSample is a single sample parsed from a StatsD line.
+type StatsDSample struct {
+	Name       string  // Metric name
+	Type       string  // One of the StatsD* types
+	Value      float64 // Sample value
+	SampleRate float64 // Fraction of the events that were sampled, in (0, 1]
+	Relative   bool    // For gauges, whether Value is added to the current value instead of replacing it
+}
+
+// ParseStatsDLine parses a StatsD line of the form
+//
+//	<name>:<value>|<type>[|@<sample rate>]
+//
+// for example `api.requests:1|c|@0.1`, `queue.size:42|g`, `queue.size:-3|g` or `db.query:120|ms`.
+// A gauge value with an explicit sign is relative to the current value. Sets are not supported,
+// and further sections, such as DogStatsD tags, are ignored.
+// Returns an error wrapping ErrStatsDLineInvalid if the line is malformed or of an unsupported type.
+func ParseStatsDLine(line string) (*StatsDSample, error) {
+	name, rest, ok := strings.Cut(strings.TrimSpace(line), ":")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("%w: %q: expected \"<name>:<value>|<type>\"", internalErrors.ErrStatsDLineInvalid, line)
+	}
+
+	sections := strings.Split(rest, "|")
+	if len(sections) < 2 {
+		return nil, fmt.Errorf("%w: %q: missing type", internalErrors.ErrStatsDLineInvalid, line)
+	}
+
+	sample := &StatsDSample{Name: name, Type: sections[1], SampleRate: 1}
+	switch sample.Type {
+	case StatsDCounter, StatsDGauge, StatsDTimer, StatsDHistogram:
+	default:
+		return nil, fmt.Errorf("%w: %q: unsupported type %q", internalErrors.ErrStatsDLineInvalid, line, sample.Type)
+	}
+
+	value, err := strconv.ParseFloat(sections[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: invalid value %q", internalErrors.ErrStatsDLineInvalid, line, sections[0])
+	}
+	sample.Value = value
+	sample.Relative = sample.Type == StatsDGauge && strings.ContainsAny(sections[0][:1], "+-")
+
+	for _, section := range sections[2:] {
+		if !strings.HasPrefix(section, "@") {
+			continue
+		}
+		rate, err := strconv.ParseFloat(section[1:], 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return nil, fmt.Errorf("%w: %q: invalid sample rate %q", internalErrors.ErrStatsDLineInvalid, line, section)
+		}
+		sample.SampleRate = rate
+	}
+
+	return sample, nil
+}
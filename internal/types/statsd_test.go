@@ -0,0 +1,74 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+func TestParseStatsDLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    *types.StatsDSample
+		wantErr bool
+	}{
+		{
+			name: "counter",
+			line: "api.requests:1|c",
+			want: &types.StatsDSample{Name: "api.requests", Type: types.StatsDCounter, Value: 1, SampleRate: 1},
+		},
+		{
+			name: "counter with sample rate",
+			line: "api.requests:2|c|@0.1",
+			want: &types.StatsDSample{Name: "api.requests", Type: types.StatsDCounter, Value: 2, SampleRate: 0.1},
+		},
+		{
+			name: "gauge",
+			line: "load:3.2|g",
+			want: &types.StatsDSample{Name: "load", Type: types.StatsDGauge, Value: 3.2, SampleRate: 1},
+		},
+		{
+			name: "relative gauge",
+			line: "queue.size:-3|g",
+			want: &types.StatsDSample{Name: "queue.size", Type: types.StatsDGauge, Value: -3, SampleRate: 1, Relative: true},
+		},
+		{
+			name: "signed counter is not relative",
+			line: "balance:+5|c",
+			want: &types.StatsDSample{Name: "balance", Type: types.StatsDCounter, Value: 5, SampleRate: 1},
+		},
+		{
+			name: "timer with tags",
+			line: " db.query:120|ms|@0.5|#env:prod \r",
+			want: &types.StatsDSample{Name: "db.query", Type: types.StatsDTimer, Value: 120, SampleRate: 0.5},
+		},
+		{
+			name: "histogram",
+			line: "payload.size:512|h",
+			want: &types.StatsDSample{Name: "payload.size", Type: types.StatsDHistogram, Value: 512, SampleRate: 1},
+		},
+		{name: "missing value", line: "api.requests", wantErr: true},
+		{name: "missing name", line: ":1|c", wantErr: true},
+		{name: "missing type", line: "api.requests:1", wantErr: true},
+		{name: "unsupported set", line: "users:42|s", wantErr: true},
+		{name: "invalid value", line: "api.requests:one|c", wantErr: true},
+		{name: "empty value", line: "api.requests:|c", wantErr: true},
+		{name: "invalid sample rate", line: "api.requests:1|c|@2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := types.ParseStatsDLine(tt.line)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, internalErrors.ErrStatsDLineInvalid)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,30 +1,53 @@
 package validators
 
 import (
+	"math"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
+const (
+	// maxMetricLabels is the maximum number of labels a metric may have.
+	maxMetricLabels = 16
+	// maxMetricLabelValueLen is the maximum length of a label value in bytes.
+	maxMetricLabelValueLen = 256
+)
+
+// labelNamePattern matches valid label names. Names starting with "__" are reserved.
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 // ValidateMetricIDAttributes checks if the provided metricType and metricName
 // are valid. Returns an error if the metricName is empty or the metricType
-// is not one of the recognized types (Counter or Gauge).
+// is not one of the recognized types (Counter, Gauge, Histogram or Summary).
 func ValidateMetricIDAttributes(metricType, metricName string) error {
 	if metricName == "" {
 		return errors.ErrMetricNameMissing
 	}
 
-	if metricType != types.Counter && metricType != types.Gauge {
+	if !isMetricType(metricType) {
 		return errors.ErrMetricTypeInvalid
 	}
 
 	return nil
 }
 
+// isMetricType reports whether metricType is one of the recognized metric types.
+func isMetricType(metricType string) bool {
+	switch metricType {
+	case types.Counter, types.Gauge, types.Histogram, types.Summary:
+		return true
+	}
+	return false
+}
+
 // ValidateMetricAttributes validates the metricType, metricName, and metricValue.
 // It first validates the metric ID attributes, then checks if the metricValue
 // can be properly parsed according to the metricType.
+// Histograms and summaries cannot be written as a single value, so their values are always invalid.
 // Returns an error if any validation fails.
 func ValidateMetricAttributes(metricType, metricName, metricValue string) error {
 	err := ValidateMetricIDAttributes(metricType, metricName)
@@ -43,30 +66,59 @@ func ValidateMetricAttributes(metricType, metricName, metricValue string) error
 		if err != nil {
 			return errors.ErrMetricValueInvalid
 		}
+	case types.Histogram, types.Summary:
+		return errors.ErrMetricValueInvalid
 	}
 
 	return nil
 }
 
-// ValidateMetricID validates a MetricID struct, ensuring its ID and Type are valid.
-// Returns an error if ID is empty or Type is not recognized.
+// ValidateMetricID validates a MetricID struct, ensuring its ID, Type and Labels are valid.
+// Returns an error if ID is empty, Type is not recognized or Labels are invalid.
 func ValidateMetricID(id types.MetricID) error {
 	if id.ID == "" {
 		return errors.ErrMetricIDInvalid
 	}
 
-	if id.Type != types.Counter && id.Type != types.Gauge {
+	if !isMetricType(id.Type) {
 		return errors.ErrMetricTypeInvalid
 	}
 
+	return ValidateMetricLabels(id.Labels)
+}
+
+// ValidateMetricLabels validates a set of metric labels.
+// Label names must start with a letter or an underscore, contain only letters, digits
+// and underscores, and must not start with the reserved "__" prefix. Label values must
+// be non-empty and at most maxMetricLabelValueLen bytes long, and a metric may have
+// at most maxMetricLabels labels.
+// Returns ErrMetricLabelsInvalid if any of these rules is violated.
+func ValidateMetricLabels(labels types.Labels) error {
+	pairs := labels.Map()
+	if len(pairs) > maxMetricLabels {
+		return errors.ErrMetricLabelsInvalid
+	}
+
+	for name, value := range pairs {
+		if !labelNamePattern.MatchString(name) || strings.HasPrefix(name, "__") {
+			return errors.ErrMetricLabelsInvalid
+		}
+		if value == "" || len(value) > maxMetricLabelValueLen {
+			return errors.ErrMetricLabelsInvalid
+		}
+	}
+
 	return nil
 }
 
 // ValidateMetric validates a Metrics struct, ensuring its ID and type are valid
-// and that the corresponding value field is set (Delta for Counter, Value for Gauge).
+// and that the corresponding value field is set (Delta for Counter, Value for Gauge,
+// Sum and Count for Histogram and Summary).
+// Histogram buckets must have finite, increasing upper bounds and non-decreasing cumulative
+// counts not exceeding the total count. Summary quantiles must be increasing and within [0, 1].
 // Returns an error if any validation fails.
 func ValidateMetric(metric types.Metrics) error {
-	err := ValidateMetricID(types.MetricID{ID: metric.ID, Type: metric.Type})
+	err := ValidateMetricID(types.MetricID{ID: metric.ID, Type: metric.Type, Labels: metric.Labels})
 	if err != nil {
 		return err
 	}
@@ -80,6 +132,46 @@ func ValidateMetric(metric types.Metrics) error {
 		if metric.Value == nil {
 			return errors.ErrMetricValueInvalid
 		}
+	case types.Histogram:
+		return validateHistogram(metric)
+	case types.Summary:
+		return validateSummary(metric)
+	}
+
+	return nil
+}
+
+// validateHistogram validates the sum, count and buckets of a histogram metric.
+func validateHistogram(metric types.Metrics) error {
+	if metric.Sum == nil || metric.Count == nil || *metric.Count < 0 {
+		return errors.ErrMetricHistogramInvalid
+	}
+
+	for i, b := range metric.Buckets {
+		if math.IsNaN(b.UpperBound) || math.IsInf(b.UpperBound, 0) || b.Count < 0 || b.Count > *metric.Count {
+			return errors.ErrMetricHistogramInvalid
+		}
+		if i > 0 && (b.UpperBound <= metric.Buckets[i-1].UpperBound || b.Count < metric.Buckets[i-1].Count) {
+			return errors.ErrMetricHistogramInvalid
+		}
+	}
+
+	return nil
+}
+
+// validateSummary validates the sum, count and quantiles of a summary metric.
+func validateSummary(metric types.Metrics) error {
+	if metric.Sum == nil || metric.Count == nil || *metric.Count < 0 {
+		return errors.ErrMetricSummaryInvalid
+	}
+
+	for i, q := range metric.Quantiles {
+		if math.IsNaN(q.Quantile) || q.Quantile < 0 || q.Quantile > 1 {
+			return errors.ErrMetricSummaryInvalid
+		}
+		if i > 0 && q.Quantile <= metric.Quantiles[i-1].Quantile {
+			return errors.ErrMetricSummaryInvalid
+		}
 	}
 
 	return nil
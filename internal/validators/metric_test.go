@@ -1,6 +1,8 @@
 package validators_test
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/errors"
@@ -20,6 +22,8 @@ func TestValidateMetricIDAttributes(t *testing.T) {
 		{"invalid type", "unknown", "test_metric", errors.ErrMetricTypeInvalid},
 		{"valid gauge", string(types.Gauge), "temperature", nil},
 		{"valid counter", string(types.Counter), "requests", nil},
+		{"valid histogram", string(types.Histogram), "PauseNs", nil},
+		{"valid summary", string(types.Summary), "latency", nil},
 	}
 
 	for _, tt := range tests {
@@ -44,6 +48,8 @@ func TestValidateMetricAttributes(t *testing.T) {
 		{"invalid gauge value", string(types.Gauge), "load", "NaN%", errors.ErrMetricValueInvalid},
 		{"missing name", string(types.Gauge), "", "1.5", errors.ErrMetricNameMissing},
 		{"invalid type", "unknown", "metric", "42", errors.ErrMetricTypeInvalid},
+		{"histogram value", string(types.Histogram), "PauseNs", "42", errors.ErrMetricValueInvalid},
+		{"summary value", string(types.Summary), "latency", "42", errors.ErrMetricValueInvalid},
 	}
 
 	for _, tt := range tests {
@@ -80,6 +86,16 @@ func TestValidateMetricID(t *testing.T) {
 			metricID:  types.MetricID{ID: "metric3", Type: "invalid"},
 			wantError: errors.ErrMetricTypeInvalid,
 		},
+		{
+			name:      "valid labels",
+			metricID:  types.MetricID{ID: "metric4", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"host": "a", "env": "prod"})},
+			wantError: nil,
+		},
+		{
+			name:      "invalid labels",
+			metricID:  types.MetricID{ID: "metric5", Type: types.Gauge, Labels: types.NewLabels(map[string]string{"1host": "a"})},
+			wantError: errors.ErrMetricLabelsInvalid,
+		},
 	}
 
 	for _, tt := range tests {
@@ -93,6 +109,8 @@ func TestValidateMetricID(t *testing.T) {
 func TestValidateMetric(t *testing.T) {
 	delta := int64(10)
 	value := float64(3.14)
+	count := int64(3)
+	negative := int64(-1)
 
 	tests := []struct {
 		name      string
@@ -149,6 +167,98 @@ func TestValidateMetric(t *testing.T) {
 			},
 			wantError: errors.ErrMetricTypeInvalid,
 		},
+		{
+			name: "valid histogram metric",
+			metric: types.Metrics{
+				ID:      "metric6",
+				Type:    types.Histogram,
+				Sum:     &value,
+				Count:   &count,
+				Buckets: types.HistogramBuckets{{UpperBound: 0.5, Count: 1}, {UpperBound: 1, Count: 3}},
+			},
+			wantError: nil,
+		},
+		{
+			name:      "histogram metric without count",
+			metric:    types.Metrics{ID: "metric7", Type: types.Histogram, Sum: &value},
+			wantError: errors.ErrMetricHistogramInvalid,
+		},
+		{
+			name:      "histogram metric with negative count",
+			metric:    types.Metrics{ID: "metric7", Type: types.Histogram, Sum: &value, Count: &negative},
+			wantError: errors.ErrMetricHistogramInvalid,
+		},
+		{
+			name: "histogram metric with unsorted buckets",
+			metric: types.Metrics{
+				ID:      "metric8",
+				Type:    types.Histogram,
+				Sum:     &value,
+				Count:   &count,
+				Buckets: types.HistogramBuckets{{UpperBound: 1, Count: 1}, {UpperBound: 0.5, Count: 1}},
+			},
+			wantError: errors.ErrMetricHistogramInvalid,
+		},
+		{
+			name: "histogram metric with decreasing counts",
+			metric: types.Metrics{
+				ID:      "metric9",
+				Type:    types.Histogram,
+				Sum:     &value,
+				Count:   &count,
+				Buckets: types.HistogramBuckets{{UpperBound: 0.5, Count: 2}, {UpperBound: 1, Count: 1}},
+			},
+			wantError: errors.ErrMetricHistogramInvalid,
+		},
+		{
+			name: "histogram metric with bucket count above total",
+			metric: types.Metrics{
+				ID:      "metric10",
+				Type:    types.Histogram,
+				Sum:     &value,
+				Count:   &count,
+				Buckets: types.HistogramBuckets{{UpperBound: 1, Count: 4}},
+			},
+			wantError: errors.ErrMetricHistogramInvalid,
+		},
+		{
+			name: "valid summary metric",
+			metric: types.Metrics{
+				ID:        "metric11",
+				Type:      types.Summary,
+				Sum:       &value,
+				Count:     &count,
+				Quantiles: types.SummaryQuantiles{{Quantile: 0.5, Value: 1}, {Quantile: 0.99, Value: 2}},
+			},
+			wantError: nil,
+		},
+		{
+			name:      "summary metric without sum",
+			metric:    types.Metrics{ID: "metric12", Type: types.Summary, Count: &count},
+			wantError: errors.ErrMetricSummaryInvalid,
+		},
+		{
+			name: "summary metric with quantile out of range",
+			metric: types.Metrics{
+				ID:        "metric13",
+				Type:      types.Summary,
+				Sum:       &value,
+				Count:     &count,
+				Quantiles: types.SummaryQuantiles{{Quantile: 1.5, Value: 1}},
+			},
+			wantError: errors.ErrMetricSummaryInvalid,
+		},
+		{
+			name: "summary metric with unsorted quantiles",
+			metric: types.Metrics{
+				ID:        "metric14",
+				Type:      types.Summary,
+				Sum:       &value,
+				Count:     &count,
+				Quantiles: types.SummaryQuantiles{{Quantile: 0.9, Value: 2}, {Quantile: 0.5, Value: 1}},
+			},
+			wantError: errors.ErrMetricSummaryInvalid,
+		},
 	}
 
 	for _, tt := range tests {
@@ -158,3 +268,37 @@ func TestValidateMetric(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMetricLabels(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= 16; i++ {
+		tooMany["label_"+strconv.Itoa(i)] = "v"
+	}
+
+	tests := []struct {
+		name      string
+		labels    map[string]string
+		wantError error
+	}{
+		{name: "no labels", labels: nil},
+		{name: "valid labels", labels: map[string]string{"host": "a", "service": "api", "_env": "prod", "zone_2": "b"}},
+		{name: "name starts with digit", labels: map[string]string{"2zone": "b"}, wantError: errors.ErrMetricLabelsInvalid},
+		{name: "name with dash", labels: map[string]string{"host-name": "a"}, wantError: errors.ErrMetricLabelsInvalid},
+		{name: "reserved name", labels: map[string]string{"__name__": "a"}, wantError: errors.ErrMetricLabelsInvalid},
+		{name: "empty name", labels: map[string]string{"": "a"}, wantError: errors.ErrMetricLabelsInvalid},
+		{name: "empty value", labels: map[string]string{"host": ""}, wantError: errors.ErrMetricLabelsInvalid},
+		{name: "too long value", labels: map[string]string{"host": strings.Repeat("a", 257)}, wantError: errors.ErrMetricLabelsInvalid},
+		{name: "too many labels", labels: tooMany, wantError: errors.ErrMetricLabelsInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validators.ValidateMetricLabels(types.NewLabels(tt.labels))
+			if tt.wantError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantError)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// AlertEvaluator defines the interface to evaluate alert rules.
+type AlertEvaluator interface {
+	// Evaluate checks every rule at the given time and returns the alerts whose state changed.
+	Evaluate(ctx context.Context, now time.Time) ([]types.Alert, error)
+}
+
+// AlertEvaluateWorker periodically evaluates alert rules, logs state changes
+// and publishes them to the notifications.
+type AlertEvaluateWorker struct {
+	evaluator        AlertEvaluator
+	evaluateInterval int
+	changes          chan<- types.Alert
+}
+
+// NewAlertEvaluateWorker creates a new AlertEvaluateWorker.
+//
+// evaluateInterval specifies the frequency (in seconds) of evaluating rules.
+// A non-positive value disables evaluation.
+// changes, if not nil, receives every alert whose state changed, in order, so that
+// no change is missed however briefly the alert stays in a state. The worker never waits
+// for room in the channel, so that a slow reader cannot hold up evaluation: a change
+// that does not fit is logged and dropped, and only the periodic checks of the
+// notifications see the alert's state then.
+func NewAlertEvaluateWorker(
+	evaluator AlertEvaluator,
+	evaluateInterval int,
+	changes chan<- types.Alert,
+) *AlertEvaluateWorker {
+	return &AlertEvaluateWorker{
+		evaluator:        evaluator,
+		evaluateInterval: evaluateInterval,
+		changes:          changes,
+	}
+}
+
+// Start evaluates rules every evaluateInterval seconds until the context is done.
+//
+// Errors are logged and do not stop the loop. If evaluateInterval is not positive,
+// Start returns immediately.
+func (w *AlertEvaluateWorker) Start(ctx context.Context) {
+	if w.evaluateInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(w.evaluateInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			changed, err := w.evaluator.Evaluate(ctx, now)
+			if err != nil {
+				logger.Log.Error("alert evaluation error: ", err)
+				continue
+			}
+			for _, alert := range changed {
+				logger.Log.Infof("Alert %s is %s: %s", alert.Rule, alert.State, alert.Expr)
+				if w.changes == nil {
+					continue
+				}
+				select {
+				case w.changes <- alert:
+				default:
+					logger.Log.Warnf("Alert changes are not read fast enough; dropping change of %s to %s", alert.Rule, alert.State)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/workers/alert_evaluate.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockAlertEvaluator is a mock of AlertEvaluator interface.
+type MockAlertEvaluator struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertEvaluatorMockRecorder
+}
+
+// MockAlertEvaluatorMockRecorder is the mock recorder for MockAlertEvaluator.
+type MockAlertEvaluatorMockRecorder struct {
+	mock *MockAlertEvaluator
+}
+
+// NewMockAlertEvaluator creates a new mock instance.
+func NewMockAlertEvaluator(ctrl *gomock.Controller) *MockAlertEvaluator {
+	mock := &MockAlertEvaluator{ctrl: ctrl}
+	mock.recorder = &MockAlertEvaluatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertEvaluator) EXPECT() *MockAlertEvaluatorMockRecorder {
+	return m.recorder
+}
+
+// Evaluate mocks base method.
+func (m *MockAlertEvaluator) Evaluate(ctx context.Context, now time.Time) ([]types.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Evaluate", ctx, now)
+	ret0, _ := ret[0].([]types.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Evaluate indicates an expected call of Evaluate.
+func (mr *MockAlertEvaluatorMockRecorder) Evaluate(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockAlertEvaluator)(nil).Evaluate), ctx, now)
+}
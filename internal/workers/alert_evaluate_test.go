@@ -0,0 +1,127 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertEvaluateWorker_StartEvaluatesPeriodically(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	evaluator := NewMockAlertEvaluator(ctrl)
+
+	gomock.InOrder(
+		evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+			Return([]types.Alert{{Rule: "high", State: types.AlertFiring}}, nil),
+		evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("list error")).AnyTimes(),
+	)
+
+	w := NewAlertEvaluateWorker(evaluator, 1, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(4 * time.Second):
+		t.Fatal("worker did not stop in time")
+	}
+}
+
+func TestAlertEvaluateWorker_StartPublishesChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	evaluator := NewMockAlertEvaluator(ctrl)
+
+	fired := types.Alert{Rule: "high", State: types.AlertFiring}
+	resolved := types.Alert{Rule: "high", State: types.AlertResolved}
+	gomock.InOrder(
+		evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return([]types.Alert{fired, resolved}, nil),
+		evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes(),
+	)
+
+	changes := make(chan types.Alert, 2)
+	w := NewAlertEvaluateWorker(evaluator, 1, changes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	for _, want := range []types.Alert{fired, resolved} {
+		select {
+		case got := <-changes:
+			assert.Equal(t, want, got)
+		case <-time.After(2 * time.Second):
+			t.Fatal("change was not published")
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop in time")
+	}
+}
+
+func TestAlertEvaluateWorker_DropsChangesThatDoNotFit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	evaluator := NewMockAlertEvaluator(ctrl)
+	evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return([]types.Alert{{Rule: "high", State: types.AlertFiring}}, nil).MinTimes(2)
+
+	// Nobody reads the changes, yet the worker keeps evaluating and stops with its context
+	w := NewAlertEvaluateWorker(evaluator, 1, make(chan types.Alert))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("worker did not stop in time")
+	}
+}
+
+func TestAlertEvaluateWorker_StartDisabled(t *testing.T) {
+	w := NewAlertEvaluateWorker(nil, 0, nil)
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("worker with disabled interval should return immediately")
+	}
+}
@@ -0,0 +1,234 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// AlertNotifyLister defines the interface to list the current state of alert rules.
+type AlertNotifyLister interface {
+	// List returns the current state of every alert rule.
+	List(ctx context.Context) ([]types.Alert, error)
+}
+
+// AlertNotifier defines the interface to deliver a notification about an alert.
+type AlertNotifier interface {
+	// Notify delivers the alert, returning an error if it could not be delivered.
+	Notify(ctx context.Context, alert types.Alert) error
+}
+
+// alertNotifyQueueSize is the number of received alert changes waiting for delivery
+// beyond which the oldest ones are dropped.
+const alertNotifyQueueSize = 1024
+
+// alertNotification records the last notification delivered for a rule.
+type alertNotification struct {
+	state  string
+	since  time.Time // FiredAt or ResolvedAt of the notified alert
+	sentAt time.Time
+}
+
+// AlertNotifyWorker delivers notifications for firing and resolved alerts.
+//
+// Alerts are delivered as their state changes, and the current states are also checked
+// periodically to repeat alerts and retry failed deliveries.
+// Every firing or resolved alert is delivered once. An alert that keeps firing
+// is delivered again every repeatInterval. Alerts in other states are never delivered.
+//
+// Deliveries run on their own goroutine, so a slow or unreachable notifier only delays
+// other notifications and never the reading of changes.
+type AlertNotifyWorker struct {
+	lister         AlertNotifyLister
+	changes        <-chan types.Alert
+	notifier       AlertNotifier
+	notifyInterval int
+	repeatInterval int
+	sent           map[string]alertNotification // used by the delivering goroutine only
+
+	mu      sync.Mutex
+	pending []types.Alert // changes received but not delivered yet
+	checkAt time.Time     // time of the periodic check due, if not zero
+	wake    chan struct{}
+}
+
+// NewAlertNotifyWorker creates a new AlertNotifyWorker.
+//
+// changes delivers the alerts whose state changed, as published by AlertEvaluateWorker;
+// it may be nil, in which case only the periodic checks deliver alerts, and a change
+// undone between two checks is missed.
+// notifyInterval specifies the frequency (in seconds) of checking alerts;
+// a non-positive value disables notifications. repeatInterval specifies how
+// often (in seconds) a still-firing alert is delivered again; a non-positive
+// value disables repeats.
+func NewAlertNotifyWorker(
+	lister AlertNotifyLister,
+	changes <-chan types.Alert,
+	notifier AlertNotifier,
+	notifyInterval int,
+	repeatInterval int,
+) *AlertNotifyWorker {
+	return &AlertNotifyWorker{
+		lister:         lister,
+		changes:        changes,
+		notifier:       notifier,
+		notifyInterval: notifyInterval,
+		repeatInterval: repeatInterval,
+		sent:           make(map[string]alertNotification),
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// Start delivers changed alerts as they arrive and checks alerts every notifyInterval
+// seconds until the context is done.
+//
+// Changes and checks are queued and handled in order by a delivering goroutine.
+// At most alertNotifyQueueSize changes wait for delivery; older ones are logged and
+// dropped, and checks due while one is already waiting are merged into it.
+//
+// Errors are logged and do not stop the loop. If notifyInterval is not positive,
+// Start returns immediately. Otherwise it returns once the context is done and the
+// delivering goroutine has stopped.
+func (w *AlertNotifyWorker) Start(ctx context.Context) {
+	if w.notifyInterval <= 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.deliverQueued(ctx)
+	}()
+	defer wg.Wait()
+
+	ticker := time.NewTicker(time.Duration(w.notifyInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alert, ok := <-w.changes:
+			if !ok {
+				w.changes = nil
+				continue
+			}
+			w.enqueue(func() {
+				if len(w.pending) >= alertNotifyQueueSize {
+					logger.Log.Warnf("Alert notifications are delivered too slowly; dropping change of %s to %s",
+						w.pending[0].Rule, w.pending[0].State)
+					w.pending = w.pending[1:]
+				}
+				w.pending = append(w.pending, alert)
+			})
+		case now := <-ticker.C:
+			w.enqueue(func() {
+				w.checkAt = now
+			})
+		}
+	}
+}
+
+// enqueue updates the queue with fn under the lock and wakes the delivering goroutine.
+func (w *AlertNotifyWorker) enqueue(fn func()) {
+	w.mu.Lock()
+	fn()
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverQueued delivers the queued changes, then runs the queued check, whenever
+// it is woken, until the context is done.
+func (w *AlertNotifyWorker) deliverQueued(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.wake:
+		}
+
+		w.mu.Lock()
+		pending, checkAt := w.pending, w.checkAt
+		w.pending, w.checkAt = nil, time.Time{}
+		w.mu.Unlock()
+
+		for _, alert := range pending {
+			w.deliver(ctx, alert, time.Now())
+		}
+		if !checkAt.IsZero() {
+			if err := w.Notify(ctx, checkAt); err != nil {
+				logger.Log.Error("alert notification error: ", err)
+			}
+		}
+	}
+}
+
+// Notify delivers the alerts that are due for a notification at the given time.
+//
+// An alert is due when it is firing or resolved and has not been delivered
+// in that state since it last changed, or when it is still firing and was last
+// delivered at least repeatInterval ago. Alerts that fail to be delivered are
+// not recorded, so they are retried on the next call.
+//
+// Returns an error if the alerts cannot be listed.
+func (w *AlertNotifyWorker) Notify(ctx context.Context, now time.Time) error {
+	alerts, err := w.lister.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		w.deliver(ctx, alert, now)
+	}
+
+	return nil
+}
+
+// deliver delivers the alert if it is due for a notification at the given time.
+// Delivery errors are logged, and the alert is not recorded, so it is retried later.
+func (w *AlertNotifyWorker) deliver(ctx context.Context, alert types.Alert, now time.Time) {
+	since, ok := alertNotifySince(alert)
+	if !ok {
+		return
+	}
+
+	last, notified := w.sent[alert.Rule]
+	if notified && last.state == alert.State && last.since.Equal(since) && !w.repeatDue(last, now) {
+		return
+	}
+
+	if err := w.notifier.Notify(ctx, alert); err != nil {
+		logger.Log.Errorf("Failed to notify about alert %s: %v", alert.Rule, err)
+		return
+	}
+	logger.Log.Infof("Notified about alert %s: %s", alert.Rule, alert.State)
+
+	w.sent[alert.Rule] = alertNotification{state: alert.State, since: since, sentAt: now}
+}
+
+// repeatDue reports whether a firing alert delivered earlier should be delivered again.
+func (w *AlertNotifyWorker) repeatDue(last alertNotification, now time.Time) bool {
+	return last.state == types.AlertFiring &&
+		w.repeatInterval > 0 &&
+		now.Sub(last.sentAt) >= time.Duration(w.repeatInterval)*time.Second
+}
+
+// alertNotifySince returns the time a firing or resolved alert entered its state.
+// It returns false for alerts in other states.
+func alertNotifySince(alert types.Alert) (time.Time, bool) {
+	switch {
+	case alert.State == types.AlertFiring && alert.FiredAt != nil:
+		return *alert.FiredAt, true
+	case alert.State == types.AlertResolved && alert.ResolvedAt != nil:
+		return *alert.ResolvedAt, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,88 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/workers/alert_notify.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockAlertNotifyLister is a mock of AlertNotifyLister interface.
+type MockAlertNotifyLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertNotifyListerMockRecorder
+}
+
+// MockAlertNotifyListerMockRecorder is the mock recorder for MockAlertNotifyLister.
+type MockAlertNotifyListerMockRecorder struct {
+	mock *MockAlertNotifyLister
+}
+
+// NewMockAlertNotifyLister creates a new mock instance.
+func NewMockAlertNotifyLister(ctrl *gomock.Controller) *MockAlertNotifyLister {
+	mock := &MockAlertNotifyLister{ctrl: ctrl}
+	mock.recorder = &MockAlertNotifyListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertNotifyLister) EXPECT() *MockAlertNotifyListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAlertNotifyLister) List(ctx context.Context) ([]types.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]types.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAlertNotifyListerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAlertNotifyLister)(nil).List), ctx)
+}
+
+// MockAlertNotifier is a mock of AlertNotifier interface.
+type MockAlertNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertNotifierMockRecorder
+}
+
+// MockAlertNotifierMockRecorder is the mock recorder for MockAlertNotifier.
+type MockAlertNotifierMockRecorder struct {
+	mock *MockAlertNotifier
+}
+
+// NewMockAlertNotifier creates a new mock instance.
+func NewMockAlertNotifier(ctrl *gomock.Controller) *MockAlertNotifier {
+	mock := &MockAlertNotifier{ctrl: ctrl}
+	mock.recorder = &MockAlertNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertNotifier) EXPECT() *MockAlertNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *MockAlertNotifier) Notify(ctx context.Context, alert types.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockAlertNotifierMockRecorder) Notify(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockAlertNotifier)(nil).Notify), ctx, alert)
+}
@@ -0,0 +1,259 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertNotifyWorker_Notify(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	firedAt := start
+	refiredAt := start.Add(30 * time.Minute)
+	resolvedAt := start.Add(10 * time.Minute)
+
+	firing := types.Alert{Rule: "high", State: types.AlertFiring, FiredAt: &firedAt}
+	refiring := types.Alert{Rule: "high", State: types.AlertFiring, FiredAt: &refiredAt}
+	resolved := types.Alert{Rule: "high", State: types.AlertResolved, FiredAt: &firedAt, ResolvedAt: &resolvedAt}
+	pending := types.Alert{Rule: "high", State: types.AlertPending, ActiveSince: &firedAt}
+	inactive := types.Alert{Rule: "high", State: types.AlertInactive}
+
+	type step struct {
+		after      time.Duration
+		alerts     []types.Alert
+		notifyErr  error
+		wantNotify bool
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "firing and resolved are delivered once",
+			steps: []step{
+				{after: 0, alerts: []types.Alert{firing}, wantNotify: true},
+				{after: time.Minute, alerts: []types.Alert{firing}},
+				{after: 10 * time.Minute, alerts: []types.Alert{resolved}, wantNotify: true},
+				{after: 11 * time.Minute, alerts: []types.Alert{resolved}},
+			},
+		},
+		{
+			name: "pending and inactive are not delivered",
+			steps: []step{
+				{after: 0, alerts: []types.Alert{inactive}},
+				{after: time.Minute, alerts: []types.Alert{pending}},
+			},
+		},
+		{
+			name: "still firing alert is repeated after the repeat interval",
+			steps: []step{
+				{after: 0, alerts: []types.Alert{firing}, wantNotify: true},
+				{after: 59 * time.Minute, alerts: []types.Alert{firing}},
+				{after: time.Hour, alerts: []types.Alert{firing}, wantNotify: true},
+				{after: time.Hour + time.Minute, alerts: []types.Alert{firing}},
+			},
+		},
+		{
+			name: "alert firing again after resolving is delivered",
+			steps: []step{
+				{after: 0, alerts: []types.Alert{firing}, wantNotify: true},
+				{after: 10 * time.Minute, alerts: []types.Alert{resolved}, wantNotify: true},
+				{after: 30 * time.Minute, alerts: []types.Alert{refiring}, wantNotify: true},
+			},
+		},
+		{
+			name: "failed delivery is retried on the next call",
+			steps: []step{
+				{after: 0, alerts: []types.Alert{firing}, notifyErr: errors.New("webhook down"), wantNotify: true},
+				{after: time.Minute, alerts: []types.Alert{firing}, wantNotify: true},
+				{after: 2 * time.Minute, alerts: []types.Alert{firing}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			lister := NewMockAlertNotifyLister(ctrl)
+			notifier := NewMockAlertNotifier(ctrl)
+			w := NewAlertNotifyWorker(lister, nil, notifier, 1, 3600)
+
+			for _, s := range tt.steps {
+				lister.EXPECT().List(gomock.Any()).Return(s.alerts, nil)
+				if s.wantNotify {
+					notifier.EXPECT().Notify(gomock.Any(), s.alerts[0]).Return(s.notifyErr)
+				}
+
+				require.NoError(t, w.Notify(context.Background(), start.Add(s.after)))
+			}
+		})
+	}
+}
+
+func TestAlertNotifyWorker_Notify_ListerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lister := NewMockAlertNotifyLister(ctrl)
+	notifier := NewMockAlertNotifier(ctrl)
+	w := NewAlertNotifyWorker(lister, nil, notifier, 1, 0)
+
+	lister.EXPECT().List(gomock.Any()).Return(nil, errors.New("list error"))
+
+	require.Error(t, w.Notify(context.Background(), time.Now()))
+}
+
+func TestAlertNotifyWorker_StartNotifiesPeriodically(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	firedAt := time.Now()
+	alert := types.Alert{Rule: "high", State: types.AlertFiring, FiredAt: &firedAt}
+
+	lister := NewMockAlertNotifyLister(ctrl)
+	notifier := NewMockAlertNotifier(ctrl)
+
+	lister.EXPECT().List(gomock.Any()).Return([]types.Alert{alert}, nil).MinTimes(1)
+	notifier.EXPECT().Notify(gomock.Any(), alert).Return(nil).Times(1)
+
+	w := NewAlertNotifyWorker(lister, nil, notifier, 1, 3600)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(4 * time.Second):
+		t.Fatal("worker did not stop in time")
+	}
+}
+
+func TestAlertNotifyWorker_StartDeliversChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	firedAt := time.Now()
+	resolvedAt := firedAt.Add(time.Second)
+	firing := types.Alert{Rule: "flapping", State: types.AlertFiring, FiredAt: &firedAt}
+	resolved := types.Alert{Rule: "flapping", State: types.AlertResolved, FiredAt: &firedAt, ResolvedAt: &resolvedAt}
+
+	lister := NewMockAlertNotifyLister(ctrl)
+	notifier := NewMockAlertNotifier(ctrl)
+
+	// The alert fires and resolves between two checks, which only ever see it resolved;
+	// both changes are delivered, in order, and the check delivers nothing more
+	delivered := make(chan struct{})
+	gomock.InOrder(
+		notifier.EXPECT().Notify(gomock.Any(), firing).Return(nil),
+		notifier.EXPECT().Notify(gomock.Any(), resolved).DoAndReturn(func(context.Context, types.Alert) error {
+			close(delivered)
+			return nil
+		}),
+	)
+	lister.EXPECT().List(gomock.Any()).Return([]types.Alert{resolved}, nil).AnyTimes()
+
+	changes := make(chan types.Alert, 2)
+	changes <- firing
+	changes <- resolved
+	close(changes)
+
+	w := NewAlertNotifyWorker(lister, changes, notifier, 1, 3600)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("changes were not delivered")
+	}
+	<-done
+}
+
+func TestAlertNotifyWorker_StartReadsChangesWhileDelivering(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lister := NewMockAlertNotifyLister(ctrl)
+	notifier := NewMockAlertNotifier(ctrl)
+
+	// The first delivery hangs until the test ends, yet the worker keeps reading changes
+	delivering := make(chan struct{})
+	release := make(chan struct{})
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, types.Alert) error {
+		close(delivering)
+		<-release
+		return nil
+	}).Times(1)
+	lister.EXPECT().List(gomock.Any()).Return(nil, nil).AnyTimes()
+
+	changes := make(chan types.Alert)
+	w := NewAlertNotifyWorker(lister, changes, notifier, 1, 3600)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	firedAt := time.Now()
+	changes <- types.Alert{Rule: "high", State: types.AlertFiring, FiredAt: &firedAt}
+	select {
+	case <-delivering:
+	case <-time.After(time.Second):
+		t.Fatal("change was not delivered")
+	}
+
+	for i := 0; i < 10; i++ {
+		select {
+		case changes <- types.Alert{Rule: "high", State: types.AlertFiring, FiredAt: &firedAt}:
+		case <-time.After(time.Second):
+			t.Fatal("changes are not read while a notification is delivered")
+		}
+	}
+
+	cancel()
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop in time")
+	}
+}
+
+func TestAlertNotifyWorker_StartDisabled(t *testing.T) {
+	w := NewAlertNotifyWorker(nil, nil, nil, 0, 0)
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("worker with disabled interval should return immediately")
+	}
+}
@@ -4,47 +4,73 @@ import (
 	"context"
 	"math/rand"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
 )
 
+// reportFlushTimeout bounds the time the reports still queued or in flight when
+// the agent is stopped are given to be sent.
+const reportFlushTimeout = 3 * time.Second
+
 // MetricUpdater defines the interface to update metrics.
 type MetricUpdater interface {
 	// Update processes a batch of metrics and returns an error if any.
 	Update(ctx context.Context, metrics []*types.Metrics) error
 }
 
-// NewMetricAgentWorker creates a worker function that collects runtime metrics,
+// NewMetricAgentWorker creates a worker function that collects runtime and host system metrics,
 // periodically reports them using the given MetricUpdater, and logs any errors.
 //
-// pollInterval specifies the frequency (in seconds) of collecting runtime metrics.
+// pollInterval specifies the frequency (in seconds) of collecting metrics.
 // reportInterval specifies the frequency (in seconds) of sending collected metrics to the updater.
+// numWorkers caps the number of reports sent to the updater concurrently.
+// labels are attached to every collected metric.
+//
+// The worker runs until the context is done. The metrics collected since the last report
+// are then reported once more, and the worker returns once every report is sent or
+// reportFlushTimeout has passed.
 func NewMetricAgentWorker(
 	updater MetricUpdater,
 	pollInterval int,
 	reportInterval int,
+	numWorkers int,
+	labels types.Labels,
 ) func(ctx context.Context) {
 	return func(ctx context.Context) {
-		startMetricAgentWorker(ctx, updater, pollInterval, reportInterval)
+		startMetricAgentWorker(ctx, updater, pollInterval, reportInterval, numWorkers, labels)
 	}
 }
 
 // startMetricAgentWorker runs the metric collection, reporting, and error logging loops.
+//
+// Runtime and system metrics are collected concurrently and merged into a single report pipeline.
+// It returns once the pipeline is drained.
 func startMetricAgentWorker(
 	ctx context.Context,
 	updater MetricUpdater,
 	pollInterval int,
 	reportInterval int,
+	numWorkers int,
+	labels types.Labels,
 ) {
-	pollCh := collectRuntimeMetrics(ctx, pollInterval)
-	reportCh := updateMetrics(ctx, reportInterval, updater, pollCh)
-	logErrors(ctx, reportCh)
+	pollCh := labelMetrics(ctx, labels, mergeMetrics(ctx,
+		collectRuntimeMetrics(ctx, pollInterval),
+		collectSystemMetrics(ctx, pollInterval, procDir),
+	))
+	reportCh := updateMetrics(ctx, reportInterval, numWorkers, updater, pollCh)
+	logErrors(reportCh)
 }
 
+// gcPauseBuckets are the upper bounds, in nanoseconds, of the PauseNs histogram buckets.
+var gcPauseBuckets = []float64{1e4, 5e4, 1e5, 5e5, 1e6, 5e6, 1e7, 5e7, 1e8}
+
 // collectRuntimeMetrics collects various runtime memory statistics and other metrics
 // at the given poll interval. It returns a channel that emits these metrics until the context is done.
+//
+// GC pauses are reported as the PauseNs histogram of the pauses since the previous poll.
 func collectRuntimeMetrics(ctx context.Context, pollInterval int) <-chan *types.Metrics {
 	out := make(chan *types.Metrics)
 
@@ -53,6 +79,8 @@ func collectRuntimeMetrics(ctx context.Context, pollInterval int) <-chan *types.
 		ticker := time.NewTicker(time.Duration(pollInterval) * time.Second)
 		defer ticker.Stop()
 
+		var lastNumGC uint32
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -94,6 +122,10 @@ func collectRuntimeMetrics(ctx context.Context, pollInterval int) <-chan *types.
 				sendGauge("Sys", float64(ms.Sys))
 				sendGauge("TotalAlloc", float64(ms.TotalAlloc))
 
+				// Send the GC pauses since the previous poll as a histogram.
+				out <- types.NewHistogramMetric("PauseNs", gcPauseBuckets, gcPausesSince(ms, lastNumGC))
+				lastNumGC = ms.NumGC
+
 				// Send a counter metric for PollCount.
 				c := int64(1)
 				out <- &types.Metrics{ID: "PollCount", Type: "counter", Delta: &c}
@@ -108,20 +140,93 @@ func collectRuntimeMetrics(ctx context.Context, pollInterval int) <-chan *types.
 	return out
 }
 
+// labelMetrics sets the given labels on every metric from the input channel.
+// The input channel is returned as is if there are no labels.
+func labelMetrics(ctx context.Context, labels types.Labels, in <-chan *types.Metrics) <-chan *types.Metrics {
+	if labels == "" {
+		return in
+	}
+
+	out := make(chan *types.Metrics)
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-in:
+				if !ok {
+					return
+				}
+				m.Labels = labels
+				select {
+				case out <- m:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// gcPausesSince returns the durations, in nanoseconds, of the GC pauses that ended after
+// the first lastNumGC collections. The runtime keeps only the most recent len(ms.PauseNs)
+// pauses, so older ones are lost if the collections outpace polling.
+func gcPausesSince(ms *runtime.MemStats, lastNumGC uint32) []float64 {
+	n := ms.NumGC - lastNumGC
+	if n > uint32(len(ms.PauseNs)) {
+		n = uint32(len(ms.PauseNs))
+	}
+
+	pauses := make([]float64, 0, n)
+	for gc := ms.NumGC - n + 1; gc <= ms.NumGC && gc > 0; gc++ {
+		// The pause of the k-th collection is stored at PauseNs[(k-1)%256]
+		pauses = append(pauses, float64(ms.PauseNs[(gc-1)%uint32(len(ms.PauseNs))]))
+	}
+	return pauses
+}
+
 // updateMetrics receives metrics from the input channel, buffers them,
 // and periodically sends them to the provided MetricUpdater according to reportInterval.
-// It returns a channel for any errors encountered during update.
+//
+// Reports are queued and sent by a pool of numWorkers senders, so at most numWorkers
+// requests are in flight at once and a slow updater does not block metric collection
+// until the queue is full. It returns a channel for any errors encountered during update.
 func updateMetrics(
 	ctx context.Context,
 	reportInterval int,
+	numWorkers int,
 	updater MetricUpdater,
 	in <-chan *types.Metrics,
 ) <-chan error {
-	errCh := make(chan error)
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	jobs := batchMetrics(ctx, reportInterval, numWorkers, in)
+	return sendMetrics(ctx, numWorkers, updater, jobs)
+}
+
+// batchMetrics buffers metrics from the input channel and emits the buffer as a single
+// batch every reportInterval seconds, as well as once more when the context is done
+// or the input channel is closed. The returned job queue holds up to queueSize batches.
+//
+// The final batch is queued regardless of the context: the senders drain the queue
+// until it is closed, and on shutdown they give up within reportFlushTimeout,
+// so queuing it cannot block forever.
+func batchMetrics(
+	ctx context.Context,
+	reportInterval int,
+	queueSize int,
+	in <-chan *types.Metrics,
+) <-chan []*types.Metrics {
+	jobs := make(chan []*types.Metrics, queueSize)
 	ticker := time.NewTicker(time.Duration(reportInterval) * time.Second)
 
 	go func() {
-		defer close(errCh)
+		defer close(jobs)
 		defer ticker.Stop()
 
 		var buffer []*types.Metrics
@@ -130,18 +235,14 @@ func updateMetrics(
 			select {
 			case <-ctx.Done():
 				if len(buffer) > 0 {
-					if err := updater.Update(ctx, buffer); err != nil {
-						errCh <- err
-					}
+					jobs <- buffer
 				}
 				return
 
 			case m, ok := <-in:
 				if !ok {
 					if len(buffer) > 0 {
-						if err := updater.Update(ctx, buffer); err != nil {
-							errCh <- err
-						}
+						jobs <- buffer
 					}
 					return
 				}
@@ -149,33 +250,79 @@ func updateMetrics(
 
 			case <-ticker.C:
 				if len(buffer) > 0 {
-					if err := updater.Update(ctx, buffer); err != nil {
-						errCh <- err
+					// The batch is handed over to a sender, so start a fresh buffer
+					// instead of reusing the backing array.
+					select {
+					case jobs <- buffer:
+					case <-ctx.Done():
+						jobs <- buffer
+						return
 					}
-					buffer = buffer[:0]
+					buffer = nil
 				}
 			}
 		}
 	}()
 
-	return errCh
+	return jobs
 }
 
-// logErrors listens on the error channel and logs errors until the context is canceled.
-func logErrors(ctx context.Context, errCh <-chan error) {
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case err, ok := <-errCh:
-				if !ok {
-					return
-				}
-				if err != nil {
-					logger.Log.Error("update error: ", err)
+// sendMetrics starts numWorkers senders that take batches from the job queue and pass them
+// to the updater. Errors are forwarded to the returned channel, which is closed once the
+// job queue is drained and every sender has finished; it must be read until then.
+//
+// Batches are sent with a context that outlives ctx by reportFlushTimeout, so that
+// the batches queued when ctx is done, including the final one, are still sent.
+func sendMetrics(
+	ctx context.Context,
+	numWorkers int,
+	updater MetricUpdater,
+	jobs <-chan []*types.Metrics,
+) <-chan error {
+	errCh := make(chan error)
+	sendCtx, cancel := withFlushTimeout(ctx, reportFlushTimeout)
+
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				if err := updater.Update(sendCtx, batch); err != nil {
+					errCh <- err
 				}
 			}
-		}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(errCh)
 	}()
+
+	return errCh
+}
+
+// withFlushTimeout returns a context that is canceled timeout after ctx is done,
+// or when the returned cancel function is called.
+func withFlushTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	flushCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(timeout, cancel)
+	})
+	return flushCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// logErrors logs the errors from the error channel until it is closed.
+func logErrors(errCh <-chan error) {
+	for err := range errCh {
+		if err != nil {
+			logger.Log.Error("update error: ", err)
+		}
+	}
 }
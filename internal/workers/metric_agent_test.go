@@ -3,12 +3,15 @@ package workers
 import (
 	"context"
 	"errors"
+	"runtime"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,6 +33,10 @@ loop:
 			}
 			require.NotNil(t, m)
 			require.NotEmpty(t, m.ID)
+			if m.Type == types.Histogram {
+				assert.Equal(t, "PauseNs", m.ID)
+				require.NotNil(t, m.Count)
+			}
 			count++
 		case <-time.After(4 * time.Second):
 			t.Fatal("timeout waiting for metrics")
@@ -39,6 +46,23 @@ loop:
 	require.Greater(t, count, 0, "Expected some metrics emitted")
 }
 
+func TestGCPausesSince(t *testing.T) {
+	ms := &runtime.MemStats{NumGC: 3}
+	ms.PauseNs[0], ms.PauseNs[1], ms.PauseNs[2] = 100, 200, 300
+
+	assert.Equal(t, []float64{100, 200, 300}, gcPausesSince(ms, 0))
+	assert.Equal(t, []float64{300}, gcPausesSince(ms, 2))
+	assert.Empty(t, gcPausesSince(ms, 3))
+
+	// Wrapped around the circular buffer, only the most recent pauses are kept
+	ms = &runtime.MemStats{NumGC: 258}
+	ms.PauseNs[255], ms.PauseNs[0], ms.PauseNs[1] = 256, 257, 258
+	pauses := gcPausesSince(ms, 0)
+	require.Len(t, pauses, len(ms.PauseNs))
+	assert.Equal(t, []float64{256, 257, 258}, pauses[len(pauses)-3:])
+	assert.Equal(t, []float64{258}, gcPausesSince(ms, 257))
+}
+
 func float64Ptr(f float64) *float64 {
 	return &f
 }
@@ -60,7 +84,7 @@ func TestUpdateMetrics_BufferingAndPeriodicFlush(t *testing.T) {
 		Return(nil).
 		MinTimes(1)
 
-	errCh := updateMetrics(ctx, 1, mockUpdater, metricsIn) // 1 second flush interval
+	errCh := updateMetrics(ctx, 1, 1, mockUpdater, metricsIn) // 1 second flush interval
 
 	go func() {
 		for i := 0; i < 3; i++ {
@@ -95,7 +119,7 @@ func TestUpdateMetrics_FlushOnInputChannelClose(t *testing.T) {
 		Return(nil).
 		Times(1)
 
-	errCh := updateMetrics(ctx, 10, mockUpdater, metricsIn) // long flush interval, so flush only on close
+	errCh := updateMetrics(ctx, 10, 1, mockUpdater, metricsIn) // long flush interval, so flush only on close
 
 	go func() {
 		metricsIn <- &types.Metrics{
@@ -129,7 +153,7 @@ func TestUpdateMetrics_UpdaterReturnsError(t *testing.T) {
 
 	metricsIn := make(chan *types.Metrics)
 
-	errCh := updateMetrics(ctx, 1, mockUpdater, metricsIn)
+	errCh := updateMetrics(ctx, 1, 1, mockUpdater, metricsIn)
 
 	go func() {
 		metricsIn <- &types.Metrics{
@@ -161,13 +185,15 @@ func TestUpdateMetrics_ContextDoneFlushesBuffer(t *testing.T) {
 
 	metricsIn := make(chan *types.Metrics)
 
-	errCh := updateMetrics(ctx, 10, mockUpdater, metricsIn) // Long interval to avoid periodic flush
+	errCh := updateMetrics(ctx, 10, 1, mockUpdater, metricsIn) // Long interval to avoid periodic flush
 
 	mockUpdater.EXPECT().
 		Update(gomock.Any(), gomock.AssignableToTypeOf([]*types.Metrics{})).
 		DoAndReturn(func(ctx context.Context, buffer []*types.Metrics) error {
 			require.Len(t, buffer, 1)
 			require.Equal(t, "bufferedMetric", buffer[0].ID)
+			// The final batch is sent after the agent's context is done, with one still alive
+			require.NoError(t, ctx.Err())
 			return nil
 		}).
 		Times(1)
@@ -204,7 +230,7 @@ func TestUpdateMetrics_PeriodicFlushOnTicker(t *testing.T) {
 	// Use a short interval so ticker fires quickly
 	reportInterval := 1
 
-	errCh := updateMetrics(ctx, reportInterval, mockUpdater, metricsIn)
+	errCh := updateMetrics(ctx, reportInterval, 1, mockUpdater, metricsIn)
 
 	// Expect Update to be called at least once due to ticker firing
 	mockUpdater.EXPECT().
@@ -256,7 +282,7 @@ func TestUpdateMetrics_SendsErrorOnUpdateFailure(t *testing.T) {
 		Return(errExample).
 		MinTimes(1)
 
-	errCh := updateMetrics(ctx, 1, mockUpdater, metricsIn)
+	errCh := updateMetrics(ctx, 1, 1, mockUpdater, metricsIn)
 
 	// Send some metric to trigger buffering and update call
 	go func() {
@@ -304,7 +330,7 @@ func TestUpdateMetrics_TickerFlushSendsError(t *testing.T) {
 		Times(1)
 
 	// Use a very short interval for the ticker to trigger flush quickly
-	errCh := updateMetrics(ctx, 1, mockUpdater, metricsIn) // 1 second interval
+	errCh := updateMetrics(ctx, 1, 1, mockUpdater, metricsIn) // 1 second interval
 
 	go func() {
 		metricsIn <- &types.Metrics{
@@ -334,6 +360,109 @@ func TestUpdateMetrics_TickerFlushSendsError(t *testing.T) {
 	}
 }
 
+func TestSendMetrics_LimitsConcurrentUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockMetricUpdater(ctrl)
+
+	const numWorkers = 2
+	const numJobs = 6
+
+	var inFlight, maxInFlight int32
+
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, batch []*types.Metrics) error {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return nil
+		}).
+		Times(numJobs)
+
+	jobs := make(chan []*types.Metrics, numJobs)
+	for i := 0; i < numJobs; i++ {
+		jobs <- []*types.Metrics{{ID: "metric" + strconv.Itoa(i), Type: "gauge", Value: float64Ptr(float64(i))}}
+	}
+	close(jobs)
+
+	errCh := sendMetrics(context.Background(), numWorkers, mockUpdater, jobs)
+
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(numWorkers), atomic.LoadInt32(&maxInFlight))
+}
+
+func TestSendMetrics_ForwardsErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockMetricUpdater(ctrl)
+
+	errExample := errors.New("update failed")
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Return(errExample).
+		Times(3)
+
+	jobs := make(chan []*types.Metrics, 3)
+	for i := 0; i < 3; i++ {
+		jobs <- []*types.Metrics{{ID: "metric", Type: "gauge", Value: float64Ptr(1)}}
+	}
+	close(jobs)
+
+	var errs []error
+	for err := range sendMetrics(context.Background(), 2, mockUpdater, jobs) {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 3)
+	for _, err := range errs {
+		require.Equal(t, errExample, err)
+	}
+}
+
+func TestBatchMetrics_SlowSendersDoNotBlockCollection(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan *types.Metrics)
+	jobs := batchMetrics(ctx, 1, 1, in)
+
+	// Nobody reads jobs, yet the batcher must keep accepting metrics
+	// while the queue has room.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			in <- &types.Metrics{ID: "metric", Type: "gauge", Value: float64Ptr(float64(i))}
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("batcher blocked metric collection")
+	}
+
+	close(in)
+
+	var total int
+	for batch := range jobs {
+		total += len(batch)
+	}
+	require.Equal(t, 100, total)
+}
+
 func TestStartMetricAgentWorker_RunAndStops(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -351,7 +480,7 @@ func TestStartMetricAgentWorker_RunAndStops(t *testing.T) {
 	done := make(chan struct{})
 
 	go func() {
-		startMetricAgentWorker(ctx, mockUpdater, 1, 1)
+		startMetricAgentWorker(ctx, mockUpdater, 1, 1, 1, "")
 		close(done)
 	}()
 
@@ -378,7 +507,7 @@ func TestNewMetricAgentWorker_RunAndStops(t *testing.T) {
 		AnyTimes().
 		Return(nil)
 
-	worker := NewMetricAgentWorker(mockUpdater, 1, 1)
+	worker := NewMetricAgentWorker(mockUpdater, 1, 1, 1, types.NewLabels(map[string]string{"host": "a"}))
 
 	done := make(chan struct{})
 
@@ -398,57 +527,98 @@ func TestNewMetricAgentWorker_RunAndStops(t *testing.T) {
 	}
 }
 
-func TestLogErrors_ExitsImmediatelyOnContextDone(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
-	errCh := make(chan error)
+func TestLabelMetrics(t *testing.T) {
+	ctx := context.Background()
+	labels := types.NewLabels(map[string]string{"host": "a", "env": "prod"})
 
-	logErrors(ctx, errCh)
+	t.Run("sets labels on every metric", func(t *testing.T) {
+		in := make(chan *types.Metrics, 2)
+		in <- &types.Metrics{ID: "Alloc", Type: types.Gauge}
+		in <- &types.Metrics{ID: "PollCount", Type: types.Counter}
+		close(in)
 
-	// Cancel context immediately to hit <-ctx.Done() case
-	cancel()
+		var got []*types.Metrics
+		for m := range labelMetrics(ctx, labels, in) {
+			got = append(got, m)
+		}
 
-	// Close channel to ensure goroutine can exit if it's still waiting on errCh
-	close(errCh)
+		require.Len(t, got, 2)
+		for _, m := range got {
+			assert.Equal(t, labels, m.Labels)
+		}
+	})
 
-	// Wait briefly to allow goroutine to exit
-	time.Sleep(50 * time.Millisecond)
+	t.Run("no labels passes the input through", func(t *testing.T) {
+		in := make(chan *types.Metrics)
+		assert.Equal(t, (<-chan *types.Metrics)(in), labelMetrics(ctx, "", in))
+	})
 }
 
-func TestLogErrors_HandlesErrorsAndChannelClose(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
+func TestLogErrors_DrainsUntilClosed(t *testing.T) {
 	errCh := make(chan error)
+	done := make(chan struct{})
 
-	logErrors(ctx, errCh)
+	go func() {
+		logErrors(errCh)
+		close(done)
+	}()
 
-	// Send some errors and nil to errCh
-	errCh <- errors.New("test error")
+	// Errors are consumed, so senders never block on the channel
+	for i := 0; i < 3; i++ {
+		select {
+		case errCh <- errors.New("test error"):
+		case <-time.After(time.Second):
+			t.Fatal("logErrors stopped reading errors")
+		}
+	}
 	errCh <- nil
 
-	// Close channel and expect goroutine to finish
+	select {
+	case <-done:
+		t.Fatal("logErrors returned before the channel was closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
 	close(errCh)
 
-	// Give some time for goroutine to process
-	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logErrors did not return once the channel was closed")
+	}
 }
 
-func TestLogErrors_ContextDoneStopsGoroutine(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
-	errCh := make(chan error)
+func TestSendMetrics_FlushTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-	logErrors(ctx, errCh)
+	mockUpdater := NewMockMetricUpdater(ctrl)
 
-	// Cancel context immediately
+	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	// Give some time for goroutine to exit
-	time.Sleep(50 * time.Millisecond)
+	// A batch queued after the context is done gets a live context that expires
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ []*types.Metrics) error {
+			require.NoError(t, ctx.Err())
+			deadline := time.Now().Add(reportFlushTimeout + time.Second)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Until(deadline)):
+				t.Error("the flush context was not canceled after the flush timeout")
+			}
+			return ctx.Err()
+		})
 
-	// Close errCh to unblock the goroutine if it waits for errors
-	close(errCh)
+	jobs := make(chan []*types.Metrics, 1)
+	jobs <- []*types.Metrics{{ID: "metric", Type: "gauge", Value: float64Ptr(1)}}
+	close(jobs)
 
-	// Now do NOT send anything on errCh after closing it
-	// Just wait a little to be sure no panic occurs
-	time.Sleep(50 * time.Millisecond)
+	var errs []error
+	for err := range sendMetrics(ctx, 1, mockUpdater, jobs) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], context.Canceled)
 }
@@ -0,0 +1,177 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MetricSnapshotLister defines the interface to list all metrics from a storage.
+type MetricSnapshotLister interface {
+	// List returns all stored metrics.
+	List(ctx context.Context) ([]types.Metrics, error)
+}
+
+// MetricSnapshotSaver defines the interface to save a single metric into a storage.
+type MetricSnapshotSaver interface {
+	// Save stores the given metric as is.
+	Save(ctx context.Context, metric types.Metrics) error
+}
+
+// MetricSnapshotWriter defines the interface to persist a full snapshot of metrics.
+type MetricSnapshotWriter interface {
+	// Save replaces the persisted snapshot with the given metrics.
+	Save(ctx context.Context, metrics []types.Metrics) error
+}
+
+// MetricSnapshotCheckpointer defines the interface to a log of the updates made since the last snapshot.
+type MetricSnapshotCheckpointer interface {
+	// Checkpoint returns all stored metrics together with the position in the log they include.
+	Checkpoint(ctx context.Context) ([]types.Metrics, int64, error)
+
+	// Truncate drops the records of the log before the given position.
+	Truncate(ctx context.Context, position int64) error
+}
+
+// MetricSnapshotWorker copies metrics between the in-memory storage and a snapshot file.
+//
+// It restores metrics from the snapshot on start, periodically writes snapshots
+// every storeInterval seconds and can be asked to write a final snapshot on shutdown.
+// The interval can be changed while the worker runs.
+type MetricSnapshotWorker struct {
+	memoryLister MetricSnapshotLister
+	memorySaver  MetricSnapshotSaver
+	fileLister   MetricSnapshotLister
+	fileWriter   MetricSnapshotWriter
+	log          MetricSnapshotCheckpointer
+
+	mu              sync.Mutex
+	storeInterval   int
+	intervalChanged chan struct{} // signals Start to pick up a new interval
+}
+
+// NewMetricSnapshotWorker creates a new MetricSnapshotWorker.
+//
+// storeInterval specifies the frequency (in seconds) of writing snapshots.
+// A non-positive value disables periodic snapshots, leaving only the final one on shutdown.
+// log is the write-ahead log of the in-memory storage, truncated after every snapshot;
+// it may be nil if the storage has none.
+func NewMetricSnapshotWorker(
+	memoryLister MetricSnapshotLister,
+	memorySaver MetricSnapshotSaver,
+	fileLister MetricSnapshotLister,
+	fileWriter MetricSnapshotWriter,
+	log MetricSnapshotCheckpointer,
+	storeInterval int,
+) *MetricSnapshotWorker {
+	return &MetricSnapshotWorker{
+		memoryLister:    memoryLister,
+		memorySaver:     memorySaver,
+		fileLister:      fileLister,
+		fileWriter:      fileWriter,
+		log:             log,
+		storeInterval:   storeInterval,
+		intervalChanged: make(chan struct{}, 1),
+	}
+}
+
+// SetStoreInterval changes the frequency (in seconds) of writing snapshots.
+// A non-positive value disables periodic snapshots until a positive one is set.
+func (w *MetricSnapshotWorker) SetStoreInterval(storeInterval int) {
+	w.mu.Lock()
+	w.storeInterval = storeInterval
+	w.mu.Unlock()
+
+	select {
+	case w.intervalChanged <- struct{}{}:
+	default: // a change is already pending
+	}
+}
+
+// interval returns the current snapshot interval, or zero if periodic snapshots are disabled.
+func (w *MetricSnapshotWorker) interval() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.storeInterval <= 0 {
+		return 0
+	}
+	return time.Duration(w.storeInterval) * time.Second
+}
+
+// Restore loads all metrics from the snapshot file into the in-memory storage.
+//
+// Metrics are saved as is, so counter values from the snapshot replace
+// rather than add to the values already in memory.
+func (w *MetricSnapshotWorker) Restore(ctx context.Context) error {
+	metrics, err := w.fileLister.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range metrics {
+		if err := w.memorySaver.Save(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	logger.Log.Infof("Restored %d metrics from snapshot", len(metrics))
+	return nil
+}
+
+// Snapshot writes all metrics currently in memory to the snapshot file.
+//
+// With a write-ahead log, the metrics are taken at a checkpoint of the log, and the records
+// before it are dropped once the snapshot is written. If the snapshot fails, the log is kept whole.
+func (w *MetricSnapshotWorker) Snapshot(ctx context.Context) error {
+	if w.log == nil {
+		metrics, err := w.memoryLister.List(ctx)
+		if err != nil {
+			return err
+		}
+		return w.fileWriter.Save(ctx, metrics)
+	}
+
+	metrics, position, err := w.log.Checkpoint(ctx)
+	if err != nil {
+		return err
+	}
+	if err := w.fileWriter.Save(ctx, metrics); err != nil {
+		return err
+	}
+	return w.log.Truncate(ctx, position)
+}
+
+// Start writes snapshots every storeInterval seconds until the context is done.
+//
+// Errors are logged and do not stop the loop. While storeInterval is not positive,
+// no snapshots are written; a new interval set with SetStoreInterval takes effect
+// right away.
+func (w *MetricSnapshotWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	reset := func() {
+		if interval := w.interval(); interval > 0 {
+			ticker.Reset(interval)
+		} else {
+			ticker.Stop()
+		}
+	}
+	reset()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.intervalChanged:
+			reset()
+		case <-ticker.C:
+			if err := w.Snapshot(ctx); err != nil {
+				logger.Log.Error("snapshot error: ", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/workers/metric_snapshot.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockMetricSnapshotLister is a mock of MetricSnapshotLister interface.
+type MockMetricSnapshotLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricSnapshotListerMockRecorder
+}
+
+// MockMetricSnapshotListerMockRecorder is the mock recorder for MockMetricSnapshotLister.
+type MockMetricSnapshotListerMockRecorder struct {
+	mock *MockMetricSnapshotLister
+}
+
+// NewMockMetricSnapshotLister creates a new mock instance.
+func NewMockMetricSnapshotLister(ctrl *gomock.Controller) *MockMetricSnapshotLister {
+	mock := &MockMetricSnapshotLister{ctrl: ctrl}
+	mock.recorder = &MockMetricSnapshotListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricSnapshotLister) EXPECT() *MockMetricSnapshotListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMetricSnapshotLister) List(ctx context.Context) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMetricSnapshotListerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricSnapshotLister)(nil).List), ctx)
+}
+
+// MockMetricSnapshotSaver is a mock of MetricSnapshotSaver interface.
+type MockMetricSnapshotSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricSnapshotSaverMockRecorder
+}
+
+// MockMetricSnapshotSaverMockRecorder is the mock recorder for MockMetricSnapshotSaver.
+type MockMetricSnapshotSaverMockRecorder struct {
+	mock *MockMetricSnapshotSaver
+}
+
+// NewMockMetricSnapshotSaver creates a new mock instance.
+func NewMockMetricSnapshotSaver(ctrl *gomock.Controller) *MockMetricSnapshotSaver {
+	mock := &MockMetricSnapshotSaver{ctrl: ctrl}
+	mock.recorder = &MockMetricSnapshotSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricSnapshotSaver) EXPECT() *MockMetricSnapshotSaverMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method.
+func (m *MockMetricSnapshotSaver) Save(ctx context.Context, metric types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, metric)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockMetricSnapshotSaverMockRecorder) Save(ctx, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockMetricSnapshotSaver)(nil).Save), ctx, metric)
+}
+
+// MockMetricSnapshotWriter is a mock of MetricSnapshotWriter interface.
+type MockMetricSnapshotWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricSnapshotWriterMockRecorder
+}
+
+// MockMetricSnapshotWriterMockRecorder is the mock recorder for MockMetricSnapshotWriter.
+type MockMetricSnapshotWriterMockRecorder struct {
+	mock *MockMetricSnapshotWriter
+}
+
+// NewMockMetricSnapshotWriter creates a new mock instance.
+func NewMockMetricSnapshotWriter(ctrl *gomock.Controller) *MockMetricSnapshotWriter {
+	mock := &MockMetricSnapshotWriter{ctrl: ctrl}
+	mock.recorder = &MockMetricSnapshotWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricSnapshotWriter) EXPECT() *MockMetricSnapshotWriterMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method.
+func (m *MockMetricSnapshotWriter) Save(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockMetricSnapshotWriterMockRecorder) Save(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockMetricSnapshotWriter)(nil).Save), ctx, metrics)
+}
+
+// MockMetricSnapshotCheckpointer is a mock of MetricSnapshotCheckpointer interface.
+type MockMetricSnapshotCheckpointer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricSnapshotCheckpointerMockRecorder
+}
+
+// MockMetricSnapshotCheckpointerMockRecorder is the mock recorder for MockMetricSnapshotCheckpointer.
+type MockMetricSnapshotCheckpointerMockRecorder struct {
+	mock *MockMetricSnapshotCheckpointer
+}
+
+// NewMockMetricSnapshotCheckpointer creates a new mock instance.
+func NewMockMetricSnapshotCheckpointer(ctrl *gomock.Controller) *MockMetricSnapshotCheckpointer {
+	mock := &MockMetricSnapshotCheckpointer{ctrl: ctrl}
+	mock.recorder = &MockMetricSnapshotCheckpointerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricSnapshotCheckpointer) EXPECT() *MockMetricSnapshotCheckpointerMockRecorder {
+	return m.recorder
+}
+
+// Checkpoint mocks base method.
+func (m *MockMetricSnapshotCheckpointer) Checkpoint(ctx context.Context) ([]types.Metrics, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkpoint", ctx)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Checkpoint indicates an expected call of Checkpoint.
+func (mr *MockMetricSnapshotCheckpointerMockRecorder) Checkpoint(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkpoint", reflect.TypeOf((*MockMetricSnapshotCheckpointer)(nil).Checkpoint), ctx)
+}
+
+// Truncate mocks base method.
+func (m *MockMetricSnapshotCheckpointer) Truncate(ctx context.Context, position int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Truncate", ctx, position)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Truncate indicates an expected call of Truncate.
+func (mr *MockMetricSnapshotCheckpointerMockRecorder) Truncate(ctx, position interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Truncate", reflect.TypeOf((*MockMetricSnapshotCheckpointer)(nil).Truncate), ctx, position)
+}
@@ -0,0 +1,277 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricSnapshotWorker_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	delta := int64(7)
+	value := 1.5
+	snapshot := []types.Metrics{
+		{ID: "c", Type: types.Counter, Delta: &delta},
+		{ID: "g", Type: types.Gauge, Value: &value},
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(fileLister *MockMetricSnapshotLister, memorySaver *MockMetricSnapshotSaver)
+		wantErr bool
+	}{
+		{
+			name: "restores every metric",
+			setup: func(fileLister *MockMetricSnapshotLister, memorySaver *MockMetricSnapshotSaver) {
+				fileLister.EXPECT().List(gomock.Any()).Return(snapshot, nil)
+				memorySaver.EXPECT().Save(gomock.Any(), snapshot[0]).Return(nil)
+				memorySaver.EXPECT().Save(gomock.Any(), snapshot[1]).Return(nil)
+			},
+		},
+		{
+			name: "file lister error",
+			setup: func(fileLister *MockMetricSnapshotLister, memorySaver *MockMetricSnapshotSaver) {
+				fileLister.EXPECT().List(gomock.Any()).Return(nil, errors.New("read error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "memory saver error",
+			setup: func(fileLister *MockMetricSnapshotLister, memorySaver *MockMetricSnapshotSaver) {
+				fileLister.EXPECT().List(gomock.Any()).Return(snapshot, nil)
+				memorySaver.EXPECT().Save(gomock.Any(), snapshot[0]).Return(errors.New("save error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileLister := NewMockMetricSnapshotLister(ctrl)
+			memorySaver := NewMockMetricSnapshotSaver(ctrl)
+			tt.setup(fileLister, memorySaver)
+
+			w := NewMetricSnapshotWorker(nil, memorySaver, fileLister, nil, nil, 0)
+
+			err := w.Restore(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMetricSnapshotWorker_Snapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	value := 2.5
+	metrics := []types.Metrics{{ID: "g", Type: types.Gauge, Value: &value}}
+
+	tests := []struct {
+		name    string
+		setup   func(memoryLister *MockMetricSnapshotLister, fileWriter *MockMetricSnapshotWriter)
+		wantErr bool
+	}{
+		{
+			name: "writes memory contents",
+			setup: func(memoryLister *MockMetricSnapshotLister, fileWriter *MockMetricSnapshotWriter) {
+				memoryLister.EXPECT().List(gomock.Any()).Return(metrics, nil)
+				fileWriter.EXPECT().Save(gomock.Any(), metrics).Return(nil)
+			},
+		},
+		{
+			name: "memory lister error",
+			setup: func(memoryLister *MockMetricSnapshotLister, fileWriter *MockMetricSnapshotWriter) {
+				memoryLister.EXPECT().List(gomock.Any()).Return(nil, errors.New("list error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "file writer error",
+			setup: func(memoryLister *MockMetricSnapshotLister, fileWriter *MockMetricSnapshotWriter) {
+				memoryLister.EXPECT().List(gomock.Any()).Return(metrics, nil)
+				fileWriter.EXPECT().Save(gomock.Any(), metrics).Return(errors.New("write error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memoryLister := NewMockMetricSnapshotLister(ctrl)
+			fileWriter := NewMockMetricSnapshotWriter(ctrl)
+			tt.setup(memoryLister, fileWriter)
+
+			w := NewMetricSnapshotWorker(memoryLister, nil, nil, fileWriter, nil, 0)
+
+			err := w.Snapshot(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMetricSnapshotWorker_SnapshotWithLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	value := 2.5
+	metrics := []types.Metrics{{ID: "g", Type: types.Gauge, Value: &value}}
+
+	tests := []struct {
+		name    string
+		setup   func(log *MockMetricSnapshotCheckpointer, fileWriter *MockMetricSnapshotWriter)
+		wantErr bool
+	}{
+		{
+			name: "truncates log after snapshot",
+			setup: func(log *MockMetricSnapshotCheckpointer, fileWriter *MockMetricSnapshotWriter) {
+				gomock.InOrder(
+					log.EXPECT().Checkpoint(gomock.Any()).Return(metrics, int64(42), nil),
+					fileWriter.EXPECT().Save(gomock.Any(), metrics).Return(nil),
+					log.EXPECT().Truncate(gomock.Any(), int64(42)).Return(nil),
+				)
+			},
+		},
+		{
+			name: "checkpoint error",
+			setup: func(log *MockMetricSnapshotCheckpointer, fileWriter *MockMetricSnapshotWriter) {
+				log.EXPECT().Checkpoint(gomock.Any()).Return(nil, int64(0), errors.New("list error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "file writer error keeps log",
+			setup: func(log *MockMetricSnapshotCheckpointer, fileWriter *MockMetricSnapshotWriter) {
+				log.EXPECT().Checkpoint(gomock.Any()).Return(metrics, int64(42), nil)
+				fileWriter.EXPECT().Save(gomock.Any(), metrics).Return(errors.New("write error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "truncate error",
+			setup: func(log *MockMetricSnapshotCheckpointer, fileWriter *MockMetricSnapshotWriter) {
+				log.EXPECT().Checkpoint(gomock.Any()).Return(metrics, int64(42), nil)
+				fileWriter.EXPECT().Save(gomock.Any(), metrics).Return(nil)
+				log.EXPECT().Truncate(gomock.Any(), int64(42)).Return(errors.New("truncate error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := NewMockMetricSnapshotCheckpointer(ctrl)
+			fileWriter := NewMockMetricSnapshotWriter(ctrl)
+			tt.setup(log, fileWriter)
+
+			w := NewMetricSnapshotWorker(nil, nil, nil, fileWriter, log, 0)
+
+			err := w.Snapshot(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMetricSnapshotWorker_StartWritesPeriodically(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	memoryLister := NewMockMetricSnapshotLister(ctrl)
+	fileWriter := NewMockMetricSnapshotWriter(ctrl)
+
+	memoryLister.EXPECT().List(gomock.Any()).Return([]types.Metrics{}, nil).MinTimes(1)
+	fileWriter.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("write error")).MinTimes(1)
+
+	w := NewMetricSnapshotWorker(memoryLister, nil, nil, fileWriter, nil, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("worker did not stop in time")
+	}
+}
+
+func TestMetricSnapshotWorker_StartDisabled(t *testing.T) {
+	// Nothing is listed or written while the interval is disabled
+	w := NewMetricSnapshotWorker(nil, nil, nil, nil, nil, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop in time")
+	}
+}
+
+func TestMetricSnapshotWorker_SetStoreInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	memoryLister := NewMockMetricSnapshotLister(ctrl)
+	fileWriter := NewMockMetricSnapshotWriter(ctrl)
+
+	saved := make(chan struct{}, 1)
+	memoryLister.EXPECT().List(gomock.Any()).Return([]types.Metrics{}, nil).MinTimes(1)
+	fileWriter.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, []types.Metrics) error {
+		select {
+		case saved <- struct{}{}:
+		default:
+		}
+		return nil
+	}).MinTimes(1)
+
+	w := NewMetricSnapshotWorker(memoryLister, nil, nil, fileWriter, nil, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	// Enabling periodic snapshots takes effect without restarting the worker
+	w.SetStoreInterval(1)
+
+	select {
+	case <-saved:
+	case <-time.After(3 * time.Second):
+		t.Fatal("no snapshot written after the interval was set")
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,256 @@
+package workers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// procDir is the mount point of the proc filesystem read by the system metrics collector.
+const procDir = "/proc"
+
+// cpuTimes holds the cumulative idle and total time of a single CPU core, in clock ticks.
+type cpuTimes struct {
+	idle  uint64
+	total uint64
+}
+
+// collectSystemMetrics collects host memory, per-core CPU utilization and load averages
+// from the proc filesystem rooted at dir at the given poll interval. It returns a channel
+// that emits these metrics as gauges until the context is done.
+//
+// CPU utilization is computed from the difference between consecutive samples of /proc/stat,
+// so the first poll reports the average utilization since boot. Files that cannot be read
+// or parsed are logged and skipped, leaving the remaining metrics unaffected.
+func collectSystemMetrics(ctx context.Context, pollInterval int, dir string) <-chan *types.Metrics {
+	out := make(chan *types.Metrics)
+
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(time.Duration(pollInterval) * time.Second)
+		defer ticker.Stop()
+
+		prevCPU := map[int]cpuTimes{}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				var metrics []*types.Metrics
+
+				sendGauge := func(name string, val float64) {
+					metrics = append(metrics, &types.Metrics{ID: name, Type: types.Gauge, Value: &val})
+				}
+
+				if mem, err := readMeminfo(filepath.Join(dir, "meminfo")); err != nil {
+					logger.Log.Error("read meminfo error: ", err)
+				} else {
+					sendGauge("TotalMemory", mem["MemTotal"])
+					sendGauge("FreeMemory", mem["MemFree"])
+				}
+
+				if cpus, err := readCPUTimes(filepath.Join(dir, "stat")); err != nil {
+					logger.Log.Error("read stat error: ", err)
+				} else {
+					for core, cur := range cpus {
+						sendGauge(fmt.Sprintf("CPUutilization%d", core+1), cpuUtilization(prevCPU[core], cur))
+						prevCPU[core] = cur
+					}
+				}
+
+				if loads, err := readLoadAverages(filepath.Join(dir, "loadavg")); err != nil {
+					logger.Log.Error("read loadavg error: ", err)
+				} else {
+					sendGauge("LoadAverage1", loads[0])
+					sendGauge("LoadAverage5", loads[1])
+					sendGauge("LoadAverage15", loads[2])
+				}
+
+				for _, m := range metrics {
+					select {
+					case out <- m:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// readMeminfo parses a meminfo file and returns its fields in bytes, keyed by field name.
+func readMeminfo(path string) (map[string]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mem := make(map[string]float64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines look like "MemTotal:       16318480 kB"
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid meminfo value for %s: %w", name, err)
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			v *= 1024
+		}
+		mem[name] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := mem["MemTotal"]; !ok {
+		return nil, fmt.Errorf("MemTotal not found in %s", path)
+	}
+	if _, ok := mem["MemFree"]; !ok {
+		return nil, fmt.Errorf("MemFree not found in %s", path)
+	}
+	return mem, nil
+}
+
+// readCPUTimes parses a stat file and returns the cumulative times of every CPU core,
+// keyed by core number. The aggregate "cpu" line is ignored.
+func readCPUTimes(path string) (map[int]cpuTimes, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cpus := make(map[int]cpuTimes)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines look like "cpu0 user nice system idle iowait irq softirq steal guest guest_nice"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") || fields[0] == "cpu" {
+			continue
+		}
+		core, err := strconv.Atoi(strings.TrimPrefix(fields[0], "cpu"))
+		if err != nil {
+			continue
+		}
+
+		var times cpuTimes
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid stat value for %s: %w", fields[0], err)
+			}
+			// Guest time is already accounted for in user and nice time
+			if i < 8 {
+				times.total += v
+			}
+			// Both idle and iowait count as idle time
+			if i == 3 || i == 4 {
+				times.idle += v
+			}
+		}
+		cpus[core] = times
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("no CPU cores found in %s", path)
+	}
+	return cpus, nil
+}
+
+// cpuUtilization returns the percentage of non-idle time between two samples of a CPU core.
+func cpuUtilization(prev, cur cpuTimes) float64 {
+	if cur.total <= prev.total {
+		return 0
+	}
+	total := cur.total - prev.total
+
+	idle := total
+	if cur.idle >= prev.idle && cur.idle-prev.idle <= total {
+		idle = cur.idle - prev.idle
+	}
+	return float64(total-idle) / float64(total) * 100
+}
+
+// readLoadAverages parses a loadavg file and returns the 1, 5 and 15 minute load averages.
+func readLoadAverages(path string) ([3]float64, error) {
+	var loads [3]float64
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return loads, err
+	}
+
+	// Content looks like "0.26 0.33 0.30 2/72 23262"
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return loads, fmt.Errorf("unexpected loadavg format in %s", path)
+	}
+	for i := range loads {
+		loads[i], err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return loads, fmt.Errorf("invalid load average: %w", err)
+		}
+	}
+	return loads, nil
+}
+
+// mergeMetrics fans in metrics from several collectors into a single channel,
+// which is closed once every input channel is closed or the context is done.
+func mergeMetrics(ctx context.Context, ins ...<-chan *types.Metrics) <-chan *types.Metrics {
+	out := make(chan *types.Metrics)
+
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+
+	for _, in := range ins {
+		go func(in <-chan *types.Metrics) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case m, ok := <-in:
+					if !ok {
+						return
+					}
+					select {
+					case out <- m:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(in)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
@@ -0,0 +1,257 @@
+package workers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMeminfo = `MemTotal:        2048 kB
+MemFree:         1024 kB
+MemAvailable:    1536 kB
+HugePages_Total:       0
+`
+	testStat = `cpu  300 0 100 600 0 0 0 0 0 0
+cpu0 100 0 50 350 0 0 0 0 0 0
+cpu1 200 0 50 250 0 0 0 0 0 0
+intr 481441 0 0
+ctxt 1041694
+`
+	testLoadavg = "0.26 0.33 0.30 2/72 23262\n"
+)
+
+// writeProcDir creates a fake proc directory with the given files.
+func writeProcDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestReadMeminfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{
+			name:    "converts kB to bytes",
+			content: testMeminfo,
+			want: map[string]float64{
+				"MemTotal":        2048 * 1024,
+				"MemFree":         1024 * 1024,
+				"MemAvailable":    1536 * 1024,
+				"HugePages_Total": 0,
+			},
+		},
+		{
+			name:    "missing MemFree",
+			content: "MemTotal: 2048 kB\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			content: "MemTotal: many kB\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeProcDir(t, map[string]string{"meminfo": tt.content})
+
+			got, err := readMeminfo(filepath.Join(dir, "meminfo"))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := readMeminfo(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestReadCPUTimes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[int]cpuTimes
+		wantErr bool
+	}{
+		{
+			name:    "per-core times without aggregate line",
+			content: testStat,
+			want: map[int]cpuTimes{
+				0: {idle: 350, total: 500},
+				1: {idle: 250, total: 500},
+			},
+		},
+		{
+			name:    "iowait counts as idle and guest time is not counted twice",
+			content: "cpu0 100 0 0 50 50 0 0 0 30 10\n",
+			want:    map[int]cpuTimes{0: {idle: 100, total: 200}},
+		},
+		{
+			name:    "no cores",
+			content: "cpu  1 2 3 4 5\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			content: "cpu0 1 x 3 4 5\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeProcDir(t, map[string]string{"stat": tt.content})
+
+			got, err := readCPUTimes(filepath.Join(dir, "stat"))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCPUUtilization(t *testing.T) {
+	tests := []struct {
+		name string
+		prev cpuTimes
+		cur  cpuTimes
+		want float64
+	}{
+		{name: "since boot", prev: cpuTimes{}, cur: cpuTimes{idle: 75, total: 100}, want: 25},
+		{name: "between samples", prev: cpuTimes{idle: 75, total: 100}, cur: cpuTimes{idle: 85, total: 200}, want: 90},
+		{name: "fully idle", prev: cpuTimes{idle: 10, total: 10}, cur: cpuTimes{idle: 20, total: 20}, want: 0},
+		{name: "no time passed", prev: cpuTimes{idle: 10, total: 10}, cur: cpuTimes{idle: 10, total: 10}, want: 0},
+		{name: "counter reset", prev: cpuTimes{idle: 10, total: 100}, cur: cpuTimes{idle: 5, total: 50}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, cpuUtilization(tt.prev, tt.cur), 1e-9)
+		})
+	}
+}
+
+func TestReadLoadAverages(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    [3]float64
+		wantErr bool
+	}{
+		{name: "valid", content: testLoadavg, want: [3]float64{0.26, 0.33, 0.30}},
+		{name: "too few fields", content: "0.26 0.33\n", wantErr: true},
+		{name: "invalid value", content: "0.26 high 0.30 2/72 1\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeProcDir(t, map[string]string{"loadavg": tt.content})
+
+			got, err := readLoadAverages(filepath.Join(dir, "loadavg"))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCollectSystemMetrics_EmitsMetrics(t *testing.T) {
+	dir := writeProcDir(t, map[string]string{
+		"meminfo": testMeminfo,
+		"stat":    testStat,
+		"loadavg": testLoadavg,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	got := map[string]float64{}
+	for m := range collectSystemMetrics(ctx, 1, dir) {
+		require.Equal(t, types.Gauge, m.Type)
+		require.NotNil(t, m.Value)
+		got[m.ID] = *m.Value
+	}
+
+	assert.Equal(t, map[string]float64{
+		"TotalMemory":     2048 * 1024,
+		"FreeMemory":      1024 * 1024,
+		"CPUutilization1": 30,
+		"CPUutilization2": 50,
+		"LoadAverage1":    0.26,
+		"LoadAverage5":    0.33,
+		"LoadAverage15":   0.30,
+	}, got)
+}
+
+func TestCollectSystemMetrics_SkipsUnreadableFiles(t *testing.T) {
+	dir := writeProcDir(t, map[string]string{"loadavg": testLoadavg})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	var ids []string
+	for m := range collectSystemMetrics(ctx, 1, dir) {
+		ids = append(ids, m.ID)
+	}
+
+	assert.Equal(t, []string{"LoadAverage1", "LoadAverage5", "LoadAverage15"}, ids)
+}
+
+func TestMergeMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	a := make(chan *types.Metrics, 2)
+	b := make(chan *types.Metrics, 1)
+	a <- &types.Metrics{ID: "a1"}
+	a <- &types.Metrics{ID: "a2"}
+	b <- &types.Metrics{ID: "b1"}
+	close(a)
+	close(b)
+
+	var ids []string
+	for m := range mergeMetrics(ctx, a, b) {
+		ids = append(ids, m.ID)
+	}
+
+	assert.ElementsMatch(t, []string{"a1", "a2", "b1"}, ids)
+}
+
+func TestMergeMetrics_ClosesOnContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The input channel is never closed, so only the context can stop the merge
+	in := make(chan *types.Metrics)
+	out := mergeMetrics(ctx, in)
+	cancel()
+
+	select {
+	case _, ok := <-out:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("merge did not stop after context was canceled")
+	}
+}
@@ -0,0 +1,312 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/logger"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// statsdMaxPacketSize is the largest UDP payload the listener reads.
+const statsdMaxPacketSize = 65535
+
+// statsdTimerBuckets are the upper bounds, in milliseconds, of the histogram buckets timings are counted in.
+var statsdTimerBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// StatsDUpdater defines the interface to update metrics aggregated from StatsD samples.
+type StatsDUpdater interface {
+	// Update processes a batch of metrics and returns the updated metrics or an error.
+	Update(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error)
+}
+
+// statsdTimer accumulates the timings of a metric, weighted by their sample rates.
+type statsdTimer struct {
+	sum     float64
+	count   float64
+	buckets []float64 // cumulative counts per statsdTimerBuckets bound
+}
+
+// StatsDListenWorker receives StatsD samples over UDP and periodically flushes them
+// to the updater as metrics.
+//
+// Samples are aggregated per flush interval: counters are added up and sent as a
+// counter delta, so the updater accumulates them like any other counter update;
+// gauges are sent with their last value; timings and histograms are counted into
+// a histogram metric. Counter and timing samples are scaled by their sample rate.
+type StatsDListenWorker struct {
+	conn          net.PacketConn
+	updater       StatsDUpdater
+	validate      func(types.Metrics) error
+	flushInterval int
+
+	mu      sync.Mutex
+	counts  map[string]float64
+	gauges  map[string]float64 // last value of every gauge seen, kept for relative updates
+	changed map[string]bool    // gauges set since the last flush
+	timers  map[string]*statsdTimer
+}
+
+// NewStatsDListenWorker creates a new StatsDListenWorker reading from the given connection.
+//
+// Aggregated metrics are checked with validate before they are flushed to the updater.
+// flushInterval specifies the frequency (in seconds) of flushes; it is at least one second.
+func NewStatsDListenWorker(
+	conn net.PacketConn,
+	updater StatsDUpdater,
+	validate func(types.Metrics) error,
+	flushInterval int,
+) *StatsDListenWorker {
+	if flushInterval < 1 {
+		flushInterval = 1
+	}
+	return &StatsDListenWorker{
+		conn:          conn,
+		updater:       updater,
+		validate:      validate,
+		flushInterval: flushInterval,
+		counts:        make(map[string]float64),
+		gauges:        make(map[string]float64),
+		changed:       make(map[string]bool),
+		timers:        make(map[string]*statsdTimer),
+	}
+}
+
+// LocalAddr returns the address the worker receives samples on.
+func (w *StatsDListenWorker) LocalAddr() net.Addr {
+	return w.conn.LocalAddr()
+}
+
+// Start receives samples and flushes them every flushInterval seconds until the context is done.
+//
+// Once the context is done the connection is closed and the remaining samples are flushed.
+// Malformed lines and flush errors are logged and do not stop the worker.
+func (w *StatsDListenWorker) Start(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.listen()
+	}()
+
+	ticker := time.NewTicker(time.Duration(w.flushInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.conn.Close()
+			<-done
+			// The context is already canceled, but the last samples must still reach the storage
+			if err := w.Flush(context.WithoutCancel(ctx)); err != nil {
+				logger.Log.Errorf("Failed to flush StatsD metrics: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := w.Flush(ctx); err != nil {
+				logger.Log.Errorf("Failed to flush StatsD metrics: %v", err)
+			}
+		}
+	}
+}
+
+// listen reads packets from the connection until it is closed.
+func (w *StatsDListenWorker) listen() {
+	buf := make([]byte, statsdMaxPacketSize)
+	for {
+		n, _, err := w.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			logger.Log.Warnf("Failed to read StatsD packet: %v", err)
+			continue
+		}
+		w.Receive(string(buf[:n]))
+	}
+}
+
+// Receive aggregates the samples of a StatsD packet, one sample per line.
+// Malformed lines are logged and skipped.
+func (w *StatsDListenWorker) Receive(packet string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, line := range strings.Split(packet, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sample, err := types.ParseStatsDLine(line)
+		if err != nil {
+			logger.Log.Warnf("Skipping StatsD line: %v", err)
+			continue
+		}
+		w.add(sample)
+	}
+}
+
+// add aggregates a single sample. The caller must hold the mutex.
+func (w *StatsDListenWorker) add(s *types.StatsDSample) {
+	switch s.Type {
+	case types.StatsDCounter:
+		w.counts[s.Name] += s.Value / s.SampleRate
+	case types.StatsDGauge:
+		if s.Relative {
+			w.gauges[s.Name] += s.Value
+		} else {
+			w.gauges[s.Name] = s.Value
+		}
+		w.changed[s.Name] = true
+	case types.StatsDTimer, types.StatsDHistogram:
+		timer, ok := w.timers[s.Name]
+		if !ok {
+			timer = &statsdTimer{buckets: make([]float64, len(statsdTimerBuckets))}
+			w.timers[s.Name] = timer
+		}
+		weight := 1 / s.SampleRate
+		timer.sum += s.Value * weight
+		timer.count += weight
+		for i, bound := range statsdTimerBuckets {
+			if s.Value <= bound {
+				timer.buckets[i] += weight
+			}
+		}
+	}
+}
+
+// Flush sends the metrics aggregated since the previous flush to the updater as a single batch
+// and resets the aggregation, keeping only the fractions of sampled counts not sent yet.
+// Metrics failing validation are logged and skipped.
+//
+// Returns an error if the update fails. A batch failing because the storage is temporarily
+// unavailable is put back into the aggregation, so it is sent again with the next flush;
+// the updater applies a batch atomically, so none of it is counted twice. A batch failing
+// otherwise would fail again and is dropped.
+func (w *StatsDListenWorker) Flush(ctx context.Context) error {
+	batch := w.drain()
+	if len(batch) == 0 {
+		return nil
+	}
+	_, err := w.updater.Update(ctx, batch)
+	if errors.Is(err, internalErrors.ErrStorageUnavailable) {
+		w.restore(batch)
+	}
+	return err
+}
+
+// drain returns the aggregated counters, gauges and histograms, each sorted by name, and resets the aggregation.
+//
+// Sampled counts are fractional, while the metrics carry whole counts: every count is rounded,
+// and the remainder is kept for the next flush, so that none of it is lost over time.
+// A counter or timer whose count rounds to zero is not sent until it grows.
+func (w *StatsDListenWorker) drain() []*types.Metrics {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var batch []*types.Metrics
+	appendValid := func(m *types.Metrics) {
+		if err := w.validate(*m); err != nil {
+			logger.Log.Warnf("Skipping StatsD metric %q: %v", m.ID, err)
+			return
+		}
+		batch = append(batch, m)
+	}
+
+	for _, name := range sortedKeys(w.counts) {
+		count := w.counts[name]
+		delta := takeStatsDCount(&count)
+		if count == 0 {
+			delete(w.counts, name)
+		} else {
+			w.counts[name] = count
+		}
+		if delta != 0 {
+			appendValid(&types.Metrics{ID: name, Type: types.Counter, Delta: &delta})
+		}
+	}
+	for _, name := range sortedKeys(w.changed) {
+		value := w.gauges[name]
+		appendValid(&types.Metrics{ID: name, Type: types.Gauge, Value: &value})
+	}
+	for _, name := range sortedKeys(w.timers) {
+		timer := w.timers[name]
+		if math.Round(timer.count) == 0 {
+			continue
+		}
+
+		sum := timer.sum
+		timer.sum = 0
+		count := takeStatsDCount(&timer.count)
+		remains := timer.count != 0
+		buckets := make(types.HistogramBuckets, len(statsdTimerBuckets))
+		for i, bound := range statsdTimerBuckets {
+			buckets[i] = types.HistogramBucket{UpperBound: bound, Count: takeStatsDCount(&timer.buckets[i])}
+			remains = remains || timer.buckets[i] != 0
+		}
+		if !remains {
+			delete(w.timers, name)
+		}
+		appendValid(&types.Metrics{ID: name, Type: types.Histogram, Sum: &sum, Count: &count, Buckets: buckets})
+	}
+
+	clear(w.changed)
+	return batch
+}
+
+// statsdCountEpsilon is the remainder of a sampled count below which it is taken as zero,
+// so that rounding errors of floating point sums are not carried forever.
+const statsdCountEpsilon = 1e-9
+
+// takeStatsDCount returns the sampled count rounded to a whole one and leaves the remainder in it.
+func takeStatsDCount(count *float64) int64 {
+	whole := math.Round(*count)
+	*count -= whole
+	if math.Abs(*count) < statsdCountEpsilon {
+		*count = 0
+	}
+	return int64(whole)
+}
+
+// restore puts a drained batch back into the aggregation, merging it with the samples
+// received since it was drained. A gauge set again in the meantime keeps its newer value.
+func (w *StatsDListenWorker) restore(batch []*types.Metrics) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, m := range batch {
+		switch m.Type {
+		case types.Counter:
+			w.counts[m.ID] += float64(*m.Delta)
+		case types.Gauge:
+			// The last value of every gauge is kept, so it is the batch's one or a newer one
+			w.changed[m.ID] = true
+		case types.Histogram:
+			timer, ok := w.timers[m.ID]
+			if !ok {
+				timer = &statsdTimer{buckets: make([]float64, len(statsdTimerBuckets))}
+				w.timers[m.ID] = timer
+			}
+			timer.sum += *m.Sum
+			timer.count += float64(*m.Count)
+			for i, bucket := range m.Buckets {
+				timer.buckets[i] += float64(bucket.Count)
+			}
+		}
+	}
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/workers/statsd_listen.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-go-advanced/internal/types"
+)
+
+// MockStatsDUpdater is a mock of StatsDUpdater interface.
+type MockStatsDUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsDUpdaterMockRecorder
+}
+
+// MockStatsDUpdaterMockRecorder is the mock recorder for MockStatsDUpdater.
+type MockStatsDUpdaterMockRecorder struct {
+	mock *MockStatsDUpdater
+}
+
+// NewMockStatsDUpdater creates a new mock instance.
+func NewMockStatsDUpdater(ctrl *gomock.Controller) *MockStatsDUpdater {
+	mock := &MockStatsDUpdater{ctrl: ctrl}
+	mock.recorder = &MockStatsDUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsDUpdater) EXPECT() *MockStatsDUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockStatsDUpdater) Update(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].([]*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockStatsDUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockStatsDUpdater)(nil).Update), ctx, metrics)
+}
@@ -0,0 +1,242 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalErrors "github.com/sbilibin2017/yandex-go-advanced/internal/errors"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/types"
+	"github.com/sbilibin2017/yandex-go-advanced/internal/validators"
+)
+
+func newTestStatsDConn(t *testing.T) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestStatsDListenWorker_Flush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockStatsDUpdater(ctrl)
+	w := NewStatsDListenWorker(newTestStatsDConn(t), mockUpdater, validators.ValidateMetric, 10)
+
+	w.Receive("api.requests:1|c\napi.requests:2|c|@0.5\n\nqueue.size:10|g\nqueue.size:-3|g\nusers:42|s")
+	w.Receive("db.query:20|ms\ndb.query:300|ms|@0.5\nbad line")
+
+	var got []*types.Metrics
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+			got = metrics
+			return metrics, nil
+		})
+
+	require.NoError(t, w.Flush(context.Background()))
+	require.Len(t, got, 3)
+
+	assert.Equal(t, types.Counter, got[0].Type)
+	assert.Equal(t, "api.requests", got[0].ID)
+	assert.Equal(t, int64(5), *got[0].Delta) // 1 + 2 / 0.5
+
+	assert.Equal(t, types.Gauge, got[1].Type)
+	assert.Equal(t, "queue.size", got[1].ID)
+	assert.Equal(t, 7.0, *got[1].Value)
+
+	assert.Equal(t, types.Histogram, got[2].Type)
+	assert.Equal(t, "db.query", got[2].ID)
+	assert.Equal(t, int64(3), *got[2].Count)
+	assert.Equal(t, 620.0, *got[2].Sum)
+	assert.NoError(t, validators.ValidateMetric(*got[2]))
+	for _, b := range got[2].Buckets {
+		switch {
+		case b.UpperBound < 20:
+			assert.Equal(t, int64(0), b.Count, "le=%v", b.UpperBound)
+		case b.UpperBound < 300:
+			assert.Equal(t, int64(1), b.Count, "le=%v", b.UpperBound)
+		default:
+			assert.Equal(t, int64(3), b.Count, "le=%v", b.UpperBound)
+		}
+	}
+
+	// The aggregation is reset, but relative gauges keep counting from the last value
+	require.NoError(t, w.Flush(context.Background()))
+
+	w.Receive("queue.size:+1|g")
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+			require.Len(t, metrics, 1)
+			assert.Equal(t, 8.0, *metrics[0].Value)
+			return metrics, nil
+		})
+	require.NoError(t, w.Flush(context.Background()))
+}
+
+func TestStatsDListenWorker_FlushCarriesFractions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockStatsDUpdater(ctrl)
+	w := NewStatsDListenWorker(newTestStatsDConn(t), mockUpdater, validators.ValidateMetric, 10)
+
+	var counts, timerCounts, bucketCounts []int64
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+			require.Len(t, metrics, 2)
+			counts = append(counts, *metrics[0].Delta)
+			timerCounts = append(timerCounts, *metrics[1].Count)
+			bucketCounts = append(bucketCounts, metrics[1].Buckets[len(metrics[1].Buckets)-1].Count)
+			return metrics, nil
+		}).Times(3)
+
+	// Every sample counts as 3.33, so the flushes carry the fractions between them
+	for i := 0; i < 3; i++ {
+		w.Receive("api.requests:1|c|@0.3\ndb.query:20|ms|@0.3")
+		require.NoError(t, w.Flush(context.Background()))
+	}
+
+	assert.Equal(t, []int64{3, 4, 3}, counts)
+	assert.Equal(t, []int64{3, 4, 3}, timerCounts)
+	assert.Equal(t, []int64{3, 4, 3}, bucketCounts)
+
+	// Nothing is left over once the fractions add up to whole counts
+	require.NoError(t, w.Flush(context.Background()))
+}
+
+func TestStatsDListenWorker_FlushSkipsInvalidMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockStatsDUpdater(ctrl)
+	validate := func(m types.Metrics) error {
+		if m.ID == "rejected" {
+			return internalErrors.ErrMetricIDInvalid
+		}
+		return nil
+	}
+	w := NewStatsDListenWorker(newTestStatsDConn(t), mockUpdater, validate, 10)
+
+	w.Receive("rejected:1|c")
+	require.NoError(t, w.Flush(context.Background()))
+}
+
+func TestStatsDListenWorker_FlushError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockStatsDUpdater(ctrl)
+	w := NewStatsDListenWorker(newTestStatsDConn(t), mockUpdater, validators.ValidateMetric, 10)
+
+	w.Receive("api.requests:1|c")
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("storage down"))
+
+	assert.Error(t, w.Flush(context.Background()))
+
+	// The dropped batch is not sent again
+	require.NoError(t, w.Flush(context.Background()))
+}
+
+func TestStatsDListenWorker_FlushRetriableError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockStatsDUpdater(ctrl)
+	w := NewStatsDListenWorker(newTestStatsDConn(t), mockUpdater, validators.ValidateMetric, 10)
+
+	w.Receive("api.requests:1|c\nqueue.size:5|g\nold.size:1|g\napi.latency:20|ms")
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Return(nil, internalErrors.ErrStorageUnavailable)
+	assert.ErrorIs(t, w.Flush(context.Background()), internalErrors.ErrStorageUnavailable)
+
+	// The failed batch is sent again together with the samples received since
+	w.Receive("api.requests:2|c\nqueue.size:7|g\napi.latency:200|ms")
+
+	var got []*types.Metrics
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+			got = metrics
+			return metrics, nil
+		})
+	require.NoError(t, w.Flush(context.Background()))
+
+	require.Len(t, got, 4)
+	assert.Equal(t, "api.requests", got[0].ID)
+	assert.Equal(t, int64(3), *got[0].Delta)
+	assert.Equal(t, "old.size", got[1].ID)
+	assert.Equal(t, 1.0, *got[1].Value)
+	assert.Equal(t, "queue.size", got[2].ID)
+	assert.Equal(t, 7.0, *got[2].Value)
+	assert.Equal(t, "api.latency", got[3].ID)
+	assert.Equal(t, 220.0, *got[3].Sum)
+	assert.Equal(t, int64(2), *got[3].Count)
+}
+
+func TestStatsDListenWorker_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockStatsDUpdater(ctrl)
+	conn := newTestStatsDConn(t)
+	w := NewStatsDListenWorker(conn, mockUpdater, validators.ValidateMetric, 0)
+
+	updated := make(chan []*types.Metrics, 2)
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+			assert.NoError(t, ctx.Err())
+			updated <- metrics
+			return metrics, nil
+		}).
+		AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("api.requests:3|c"))
+	require.NoError(t, err)
+
+	// Wait for the packet to be received before stopping, the rest is flushed on stop
+	require.Eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.counts["api.requests"] == 3
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop in time")
+	}
+
+	select {
+	case metrics := <-updated:
+		require.Len(t, metrics, 1)
+		assert.Equal(t, int64(3), *metrics[0].Delta)
+	default:
+		t.Fatal("metrics were not flushed")
+	}
+}